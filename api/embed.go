@@ -0,0 +1,106 @@
+// Package api встраивает спецификацию OpenAPI (openapi.yml) в бинарник: сервис отдает ее клиентам
+// и при старте сверяет с зарегистрированными маршрутами.
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yml
+var spec []byte
+
+// httpMethods — ключи операций в объекте пути OpenAPI
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// document — часть спецификации, нужная для сверки маршрутов
+type document struct {
+	Paths map[string]map[string]yaml.Node `yaml:"paths"`
+}
+
+// SpecJSON возвращает спецификацию, преобразованную из YAML в JSON
+func SpecJSON() ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi.yml: %w", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert openapi.yml to JSON: %w", err)
+	}
+	return data, nil
+}
+
+// Operations возвращает операции спецификации в виде «METHOD /path» (метод в верхнем регистре)
+func Operations() (map[string]bool, error) {
+	var doc document
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi.yml: %w", err)
+	}
+
+	ops := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			if httpMethods[method] {
+				ops[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+	return ops, nil
+}
+
+// Undocumented возвращает отсортированные операции «METHOD /path» из routes, которых нет в спецификации.
+// Параметры пути в стиле Echo (:id) сравниваются с параметрами OpenAPI ({id}).
+func Undocumented(routes []string) ([]string, error) {
+	ops, err := Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if !ops[echoToOpenAPIPath(route)] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// Unrouted возвращает отсортированные операции спецификации «METHOD /path», которым не соответствует
+// ни один маршрут из routes, то есть описанные, но не реализованные эндпоинты
+func Unrouted(routes []string) ([]string, error) {
+	ops, err := Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, route := range routes {
+		delete(ops, echoToOpenAPIPath(route))
+	}
+	missing := make([]string, 0, len(ops))
+	for op := range ops {
+		missing = append(missing, op)
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// echoToOpenAPIPath заменяет параметры пути Echo (:id) на параметры OpenAPI ({id})
+func echoToOpenAPIPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
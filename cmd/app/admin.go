@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/untibullet/pr-manager-avito/internal/bootstrap"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// runReassignUser деактивирует пользователя и переназначает его открытые ревью, как
+// POST /users/setIsActive с reassign_reviews. Результат выводится в stdout в формате ответа API.
+// Коды завершения: exitOK, exitFailure, exitUsage, exitNotFound; exitIncomplete — часть ревью
+// осталась без замены (нет кандидатов или назначение моложе MIN_ASSIGNMENT_AGE_HOURS).
+func runReassignUser(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("reassign-user", reassignUserUsage, stderr)
+	userID := flags.String("user-id", "", "ID of the user to deactivate and move open reviews from")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	}
+	if *userID == "" {
+		return usageError(flags, "--user-id is required")
+	}
+
+	env, code := openCLIEnv(ctx)
+	if env == nil {
+		return code
+	}
+	defer env.Close()
+
+	ctx, repo := env.newRepo(ctx)
+	id := env.normalizeID(*userID)
+
+	result, err := repo.DeactivateAndReassign(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		env.logger.Error("user not found", zap.String("user_id", id))
+		return exitNotFound
+	}
+	if err != nil {
+		env.logger.Error("failed to reassign user reviews", zap.String("user_id", id), zap.Error(err))
+		return exitFailure
+	}
+
+	env.logger.Info("user deactivated, open reviews reassigned",
+		zap.String("user_id", id),
+		zap.Int("reassigned", len(result.Reassigned)),
+		zap.Int("not_reassigned", len(result.NotReassigned)),
+		zap.Int("skipped_recent", len(result.SkippedRecent)))
+
+	if err := writeJSON(stdout, result); err != nil {
+		env.logger.Error("failed to write result", zap.Error(err))
+		return exitFailure
+	}
+	if len(result.NotReassigned) > 0 || len(result.SkippedRecent) > 0 {
+		return exitIncomplete
+	}
+	return exitOK
+}
+
+// runSeed создает команды и PR из документа начального заполнения, как POST /admin/bootstrap.
+// Документ применяется целиком в одной транзакции; результат выводится в stdout.
+// Коды завершения: exitOK, exitFailure, exitUsage; exitInvalidInput — файл не читается,
+// не проходит проверку или содержит PR, который уже есть в БД.
+func runSeed(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("seed", seedUsage, stderr)
+	file := flags.String("file", "", "path to a bootstrap document with teams and pull requests (JSON)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	}
+	if *file == "" {
+		return usageError(flags, "--file is required")
+	}
+
+	// Файл проверяется до подключения к БД: ошибку в фикстурах видно и без запущенного Postgres
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open fixtures file: %v\n", err)
+		return exitInvalidInput
+	}
+	doc, err := bootstrap.Parse(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to parse fixtures file %s: %v\n", *file, err)
+		return exitInvalidInput
+	}
+
+	env, code := openCLIEnv(ctx)
+	if env == nil {
+		return code
+	}
+	defer env.Close()
+
+	bootstrap.Normalize(doc, env.normalizeID)
+	if problems := bootstrap.Validate(doc); len(problems) > 0 {
+		env.logger.Error("fixtures file is invalid", zap.String("file", *file), zap.Strings("problems", problems))
+		return exitInvalidInput
+	}
+
+	ctx, repo := env.newRepo(ctx)
+	result, err := repo.Bootstrap(ctx, *doc)
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		env.logger.Error("fixtures conflict with existing data", zap.Error(err))
+		return exitInvalidInput
+	}
+	if err != nil {
+		env.logger.Error("failed to apply fixtures", zap.Error(err))
+		return exitFailure
+	}
+
+	env.logger.Info("fixtures applied",
+		zap.String("file", *file),
+		zap.Int("teams_count", len(result.Teams)),
+		zap.Int("prs_count", len(result.PullRequests)))
+
+	if err := writeJSON(stdout, result); err != nil {
+		env.logger.Error("failed to write result", zap.Error(err))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeJSON выводит результат команды в JSON с отступами
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
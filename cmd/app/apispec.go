@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/api"
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"go.uber.org/zap"
+)
+
+// warnUndocumentedRoutes пишет в лог маршруты Echo, которых нет в спецификации OpenAPI,
+// чтобы расхождение контракта и кода было видно сразу после добавления эндпоинта.
+// Пути в спецификации указаны без префикса /api/v1: он задан в servers.
+func warnUndocumentedRoutes(e *echo.Echo, logger *zap.Logger) {
+	routes := make([]string, 0, len(e.Routes()))
+	for _, route := range e.Routes() {
+		routes = append(routes, route.Method+" "+strings.TrimPrefix(route.Path, handlers.APIV1Prefix))
+	}
+
+	missing, err := api.Undocumented(routes)
+	if err != nil {
+		logger.Error("failed to check routes against OpenAPI spec", zap.Error(err))
+		return
+	}
+	if len(missing) > 0 {
+		logger.Warn("routes missing from OpenAPI spec", zap.Strings("routes", missing))
+	}
+}
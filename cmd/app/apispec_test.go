@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/api"
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// routeTable собирает маршруты сервера так же, как serve, со всеми необязательными эндпоинтами
+// (вебхуки GitHub и GitLab, Swagger UI, /metrics на основном порту) и возвращает их в виде
+// «METHOD /path» без префикса /api/v1, как пути в спецификации
+func routeTable(t *testing.T) []string {
+	t.Helper()
+	st := &mocks.Store{}
+	appMetrics := metrics.New(prometheus.NewRegistry())
+	handler := handlers.New(st, service.New(st), appMetrics, zap.NewNop(), handlers.Config{
+		GitHubWebhookSecret: "secret",
+		GitLabWebhookSecret: "token",
+	})
+	specJSON, err := api.SpecJSON()
+	require.NoError(t, err)
+
+	e := echo.New()
+	registerRoutes(e, handler, handlers.Idempotency(nil, time.Hour, time.Minute, zap.NewNop()),
+		handlers.NewHealthHandler(nil, 1, zap.NewNop()), handlers.NewDocsHandler(specJSON, true), appMetrics.Handler())
+
+	routes := make([]string, 0, len(e.Routes()))
+	for _, route := range e.Routes() {
+		routes = append(routes, route.Method+" "+strings.TrimPrefix(route.Path, handlers.APIV1Prefix))
+	}
+	return routes
+}
+
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	routes := routeTable(t)
+	require.Contains(t, routes, http.MethodPost+" /pullRequest/create")
+
+	undocumented, err := api.Undocumented(routes)
+	require.NoError(t, err)
+	assert.Empty(t, undocumented, "every route is described in api/openapi.yml")
+
+	unrouted, err := api.Unrouted(routes)
+	require.NoError(t, err)
+	assert.Empty(t, unrouted, "every operation in api/openapi.yml has a route")
+}
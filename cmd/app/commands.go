@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/untibullet/pr-manager-avito/internal/config"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Коды завершения команд. Общие коды 0–2 одинаковы для всех команд, остальные описаны у команд,
+// которые их возвращают.
+const (
+	// exitOK — команда выполнена полностью
+	exitOK = 0
+	// exitFailure — ошибка выполнения: конфигурация, недоступная БД, ошибка запроса
+	exitFailure = 1
+	// exitUsage — неизвестная команда или неверные аргументы
+	exitUsage = 2
+	// exitNotFound — reassign-user: пользователь не найден
+	exitNotFound = 3
+	// exitIncomplete — migrate status: есть неприменённые миграции;
+	// reassign-user: часть ревью осталась без замены
+	exitIncomplete = 4
+	// exitInvalidInput — seed: файл фикстур не читается, не проходит проверку или конфликтует с данными в БД
+	exitInvalidInput = 5
+)
+
+// cliActor — инициатор изменений, сделанных административными командами, в журнале назначений
+const cliActor = "cli"
+
+// Строки использования команд
+const (
+	serveUsage        = "serve"
+	migrateUsage      = "migrate up|down|status"
+	reassignUserUsage = "reassign-user --user-id=ID"
+	seedUsage         = "seed --file=fixtures.json"
+)
+
+// command — подкоманда бинарника
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string, stdout, stderr io.Writer) int
+}
+
+// commands — подкоманды по имени; без имени запускается serve
+var commands = map[string]command{
+	"serve": {
+		usage:   serveUsage,
+		summary: "run the HTTP server, background workers and, if enabled, the gRPC server (default)",
+		run:     runServe,
+	},
+	"migrate": {
+		usage:   migrateUsage,
+		summary: "apply all pending migrations, roll back the latest one or show migration status",
+		run:     runMigrate,
+	},
+	"reassign-user": {
+		usage:   reassignUserUsage,
+		summary: "deactivate a user and move their open reviews to other team members",
+		run:     runReassignUser,
+	},
+	"seed": {
+		usage:   seedUsage,
+		summary: "create teams and pull requests from a bootstrap document (local development data)",
+		run:     runSeed,
+	},
+}
+
+// run выбирает подкоманду по первому аргументу и возвращает код завершения.
+// Без аргументов или с флагом на месте имени команды запускается serve.
+func run(args []string, stdout, stderr io.Writer) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	// SIGINT и SIGTERM отменяют контекст: serve завершается gracefully, остальные команды прерывают работу
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return cmd.run(ctx, args, stdout, stderr)
+}
+
+// printUsage выводит список команд
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: pr-manager-service [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-28s %s\n", commands[name].usage, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Configuration is read from the environment and CONFIG_FILE, as for the server.")
+}
+
+// newFlagSet создает набор флагов команды, который сообщает об ошибках в stderr, а не завершает процесс
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: pr-manager-service %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags разбирает флаги команды. ok=false — команду выполнять не нужно, code — ее код завершения:
+// exitOK для -h/--help и exitUsage для неверных флагов.
+func parseFlags(flags *flag.FlagSet, args []string) (code int, ok bool) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK, false
+	}
+	if err != nil {
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// usageError выводит ошибку аргументов и справку команды
+func usageError(flags *flag.FlagSet, format string, args ...any) int {
+	fmt.Fprintf(flags.Output(), format+"\n", args...)
+	flags.Usage()
+	return exitUsage
+}
+
+// cliEnv — окружение административной команды: конфигурация, логгер и пул подключений к БД
+type cliEnv struct {
+	cfg    *config.Config
+	logger *zap.Logger
+	pool   *pgxpool.Pool
+}
+
+// openCLIEnv загружает конфигурацию, настраивает логгер и подключается к БД так же, как serve.
+// Логи, настроенные на stdout, переводятся в stderr: stdout команд отдан под результат.
+// При ошибке возвращается nil и код завершения.
+func openCLIEnv(ctx context.Context) (*cliEnv, int) {
+	bootLogger := newBootstrapLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		bootLogger.Error("failed to load config", zap.Error(err))
+		return nil, exitFailure
+	}
+
+	loggerCfg := cfg.Logger
+	if loggerCfg.Output == config.LogOutputStdout {
+		loggerCfg.Output = config.LogOutputStderr
+	}
+	logger, err := initLogger(loggerCfg)
+	if err != nil {
+		bootLogger.Error("failed to initialize logger", zap.Error(err), zap.String("output", loggerCfg.Output))
+		return nil, exitFailure
+	}
+
+	pool, err := initDatabase(ctx, cfg.Database, logger)
+	if err != nil {
+		logger.Error("failed to connect to database", zap.Error(err))
+		syncLogger(logger)
+		return nil, exitFailure
+	}
+
+	return &cliEnv{cfg: cfg, logger: logger, pool: pool}, exitOK
+}
+
+// newRepo создает репозиторий с теми же настройками, что и у сервера, но без пула реплики:
+// административные команды читают то, что только что записали.
+// Контекст команды помечается инициатором cli для журнала назначений и полей *_by у PR.
+func (e *cliEnv) newRepo(ctx context.Context) (context.Context, *repository.Repository) {
+	repo := newRepository(e.pool, nil, e.cfg, metrics.New(prometheus.NewRegistry()), e.logger)
+	return repository.WithActor(ctx, cliActor), repo
+}
+
+// normalizeID приводит внешний ID к виду, в котором его хранит сервер (ID_NORMALIZATION=fold)
+func (e *cliEnv) normalizeID(id string) string {
+	if !e.cfg.IDs.FoldIDs() {
+		return id
+	}
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// Close закрывает пул подключений и сбрасывает буферы логгера
+func (e *cliEnv) Close() {
+	e.pool.Close()
+	syncLogger(e.logger)
+}
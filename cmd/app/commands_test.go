@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cliRun вызывает команду так же, как run, и возвращает код завершения и вывод в stdout и stderr
+func cliRun(t *testing.T, cmd func(context.Context, []string, io.Writer, io.Writer) int, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cmd(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// unreachableDB настраивает окружение команды на БД, где никто не слушает, с одной попыткой подключения.
+// Логи пишутся в файл, путь к которому возвращается.
+func unreachableDB(t *testing.T) string {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "cli.log")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DATABASE_URL", "postgres://postgres:postgres@"+freeAddr(t)+"/pr_manager_db?sslmode=disable")
+	t.Setenv("DB_STARTUP_TIMEOUT", "0")
+	t.Setenv("DB_CONNECT_TIMEOUT", "1s")
+	t.Setenv("LOG_OUTPUT", logPath)
+	return logPath
+}
+
+// writeFixtures записывает файл фикстур для seed и возвращает путь к нему
+func writeFixtures(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestRunDispatch(t *testing.T) {
+	t.Run("help", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"help"}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stderr.String())
+		for _, usage := range []string{serveUsage, migrateUsage, reassignUserUsage, seedUsage} {
+			assert.Contains(t, stdout.String(), usage)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"deploy"}, &stdout, &stderr)
+
+		assert.Equal(t, exitUsage, code)
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, stderr.String(), `unknown command "deploy"`)
+		assert.Contains(t, stderr.String(), "Usage: pr-manager-service [command] [flags]")
+	})
+
+	t.Run("flag without a command runs serve", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"--help"}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stderr.String(), "Usage: pr-manager-service "+serveUsage)
+	})
+}
+
+func TestCommandUsage(t *testing.T) {
+	cases := []struct {
+		name   string
+		cmd    func(context.Context, []string, io.Writer, io.Writer) int
+		args   []string
+		code   int
+		stderr string
+	}{
+		{name: "serve help", cmd: runServe, args: []string{"-h"}, code: exitOK, stderr: "Usage: pr-manager-service " + serveUsage},
+		{name: "serve extra argument", cmd: runServe, args: []string{"now"}, code: exitUsage, stderr: "unexpected arguments: [now]"},
+		{name: "migrate help", cmd: runMigrate, args: []string{"--help"}, code: exitOK, stderr: "Usage: pr-manager-service " + migrateUsage},
+		{name: "migrate without action", cmd: runMigrate, code: exitUsage, stderr: "expected exactly one action: up, down or status"},
+		{name: "migrate two actions", cmd: runMigrate, args: []string{"up", "down"}, code: exitUsage, stderr: "expected exactly one action"},
+		{name: "migrate unknown action", cmd: runMigrate, args: []string{"redo"}, code: exitUsage, stderr: `unknown action "redo"`},
+		{name: "reassign-user help", cmd: runReassignUser, args: []string{"-h"}, code: exitOK, stderr: "-user-id"},
+		{name: "reassign-user without user", cmd: runReassignUser, code: exitUsage, stderr: "--user-id is required"},
+		{name: "reassign-user unknown flag", cmd: runReassignUser, args: []string{"--user=u1"}, code: exitUsage, stderr: "flag provided but not defined: -user"},
+		{name: "reassign-user extra argument", cmd: runReassignUser, args: []string{"--user-id=u1", "u2"}, code: exitUsage, stderr: "unexpected arguments: [u2]"},
+		{name: "seed help", cmd: runSeed, args: []string{"-h"}, code: exitOK, stderr: "-file"},
+		{name: "seed without file", cmd: runSeed, code: exitUsage, stderr: "--file is required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, stdout, stderr := cliRun(t, tc.cmd, tc.args...)
+
+			assert.Equal(t, tc.code, code)
+			assert.Empty(t, stdout, "stdout is reserved for command results")
+			assert.Contains(t, stderr, tc.stderr)
+		})
+	}
+}
+
+func TestSeedInvalidFixtures(t *testing.T) {
+	cases := []struct {
+		name   string
+		file   string
+		stderr string
+	}{
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.json"), stderr: "failed to open fixtures file"},
+		{name: "malformed JSON", file: writeFixtures(t, `{"teams": [`), stderr: "failed to parse fixtures file"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Файл проверяется до подключения: недоступная БД не меняет код завершения
+			unreachableDB(t)
+
+			code, stdout, stderr := cliRun(t, runSeed, "--file="+tc.file)
+
+			assert.Equal(t, exitInvalidInput, code)
+			assert.Empty(t, stdout)
+			assert.Contains(t, stderr, tc.stderr)
+		})
+	}
+}
+
+func TestCommandsFailWithoutDatabase(t *testing.T) {
+	fixtures := writeFixtures(t, `{"teams": [{"team_name": "backend", "members": [{"user_id": "u1", "username": "Alice", "is_active": true}]}]}`)
+	cases := []struct {
+		name string
+		cmd  func(context.Context, []string, io.Writer, io.Writer) int
+		args []string
+	}{
+		{name: "migrate status", cmd: runMigrate, args: []string{"status"}},
+		{name: "reassign-user", cmd: runReassignUser, args: []string{"--user-id=u1"}},
+		{name: "seed", cmd: runSeed, args: []string{"--file=" + fixtures}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			logPath := unreachableDB(t)
+
+			code, stdout, _ := cliRun(t, tc.cmd, tc.args...)
+
+			assert.Equal(t, exitFailure, code)
+			assert.Empty(t, stdout)
+			logs, err := os.ReadFile(logPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(logs), "failed to connect to database")
+		})
+	}
+}
+
+func TestCommandsFailOnInvalidConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("LOG_OUTPUT", "")
+
+	code, stdout, _ := cliRun(t, runReassignUser, "--user-id=u1")
+
+	assert.Equal(t, exitFailure, code)
+	assert.Empty(t, stdout)
+}
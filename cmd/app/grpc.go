@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// stopGRPC дожидается завершения активных вызовов gRPC, а по истечении ctx обрывает их
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+	}
+}
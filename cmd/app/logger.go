@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/untibullet/pr-manager-avito/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newBootstrapLogger создает консольный логгер в stderr, доступный до загрузки конфигурации
+func newBootstrapLogger() *zap.Logger {
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.Lock(os.Stderr), zapcore.DebugLevel)
+	return zap.New(core)
+}
+
+// initLogger инициализирует zap логгер на основе конфигурации
+func initLogger(cfg config.LoggerConfig) (*zap.Logger, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = zapcore.InfoLevel
+	}
+
+	var encoder zapcore.Encoder
+	opts := []zap.Option{zap.AddCaller()}
+	if cfg.Format == "json" {
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+	} else {
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+		opts = append(opts, zap.AddStacktrace(zapcore.WarnLevel))
+	}
+
+	sink, err := openLogSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	core := zapcore.NewCore(encoder, sink, zap.NewAtomicLevelAt(level))
+	return zap.New(core, opts...), nil
+}
+
+// openLogSink открывает вывод логов: stdout, stderr или файл с ротацией
+func openLogSink(cfg config.LoggerConfig) (zapcore.WriteSyncer, error) {
+	switch cfg.Output {
+	case config.LogOutputStdout:
+		return zapcore.Lock(os.Stdout), nil
+	case config.LogOutputStderr:
+		return zapcore.Lock(os.Stderr), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Output), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.Output,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+	}), nil
+}
+
+// syncErrors — куда syncLogger выводит ошибку sync: stderr, в тестах подменяется
+var syncErrors io.Writer = os.Stderr
+
+// syncLogger сбрасывает буферы логгера. Ошибка sync выводится в stderr, чтобы не потерять ее молча;
+// EINVAL/ENOTTY при sync консольных потоков ожидаемы и игнорируются.
+func syncLogger(logger *zap.Logger) {
+	err := logger.Sync()
+	if err == nil || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return
+	}
+	fmt.Fprintf(syncErrors, "failed to sync logger: %v\n", err)
+}
+
+// fatal логирует ошибку, сбрасывает буферы логгера и завершает процесс.
+// В отличие от logger.Fatal, ошибка sync не теряется, а выводится в stderr.
+func fatal(logger *zap.Logger, msg string, fields ...zap.Field) {
+	logger.Error(msg, fields...)
+	syncLogger(logger)
+	os.Exit(1)
+}
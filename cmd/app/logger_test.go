@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/untibullet/pr-manager-avito/internal/config"
+)
+
+func TestOpenLogSink(t *testing.T) {
+	t.Run("stdout", func(t *testing.T) {
+		sink, err := openLogSink(config.LoggerConfig{Output: config.LogOutputStdout})
+		require.NoError(t, err)
+		assert.NotNil(t, sink)
+	})
+
+	t.Run("stderr", func(t *testing.T) {
+		sink, err := openLogSink(config.LoggerConfig{Output: config.LogOutputStderr})
+		require.NoError(t, err)
+		assert.NotNil(t, sink)
+	})
+
+	t.Run("file in a missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "nested", "app.log")
+		logger, err := initLogger(config.LoggerConfig{Level: "info", Format: "json", Output: path, MaxSizeMB: 1})
+		require.NoError(t, err)
+
+		logger.Info("written to file", zap.String("key", "value"))
+		logger.Debug("below the level")
+		require.NoError(t, logger.Sync())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `"msg":"written to file"`)
+		assert.Contains(t, lines[0], `"key":"value"`)
+	})
+
+	t.Run("directory cannot be created", func(t *testing.T) {
+		// Родитель пути — обычный файл, каталог под ним не создать
+		parent := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(parent, nil, 0o600))
+
+		_, err := openLogSink(config.LoggerConfig{Output: filepath.Join(parent, "app.log")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create log directory")
+	})
+}
+
+func TestInitLoggerLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// Неизвестный уровень не мешает запуску: используется info
+	logger, err := initLogger(config.LoggerConfig{Level: "verbose", Format: "console", Output: path})
+	require.NoError(t, err)
+
+	assert.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+	assert.True(t, logger.Core().Enabled(zapcore.InfoLevel))
+}
+
+// failingSyncer — вывод логов, Sync которого возвращает err
+type failingSyncer struct {
+	bytes.Buffer
+	err error
+}
+
+func (s *failingSyncer) Sync() error { return s.err }
+
+func TestSyncLoggerFallback(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "success"},
+		{name: "console EINVAL", err: &os.PathError{Op: "sync", Path: "/dev/stdout", Err: syscall.EINVAL}},
+		{name: "console ENOTTY", err: &os.PathError{Op: "sync", Path: "/dev/stderr", Err: syscall.ENOTTY}},
+		{name: "disk error", err: &os.PathError{Op: "sync", Path: "/var/log/app.log", Err: syscall.EIO},
+			want: "failed to sync logger: sync /var/log/app.log: input/output error\n"},
+		{name: "other error", err: errors.New("flush failed"), want: "failed to sync logger: flush failed\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			prev := syncErrors
+			syncErrors = &stderr
+			t.Cleanup(func() { syncErrors = prev })
+
+			sink := &failingSyncer{err: tc.err}
+			core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), sink, zapcore.InfoLevel)
+			syncLogger(zap.New(core))
+
+			assert.Equal(t, tc.want, stderr.String())
+		})
+	}
+}
@@ -4,75 +4,196 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/untibullet/pr-manager-avito/api"
 	"github.com/untibullet/pr-manager-avito/internal/config"
+	grpcapi "github.com/untibullet/pr-manager-avito/internal/grpc"
 	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/notify"
 	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+	"github.com/untibullet/pr-manager-avito/internal/slack"
+	"github.com/untibullet/pr-manager-avito/internal/worker"
+	"github.com/untibullet/pr-manager-avito/migrations"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// runServe запускает HTTP-сервер, вспомогательные серверы и фоновые задачи до сигнала завершения
+func runServe(ctx context.Context, args []string, _, stderr io.Writer) int {
+	flags := newFlagSet("serve", serveUsage, stderr)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments: %v", flags.Args())
+	}
+
+	// Логгер начальной загрузки доступен до построения настроенного логгера
+	bootLogger := newBootstrapLogger()
+
 	// Загрузка конфигурации
 	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
-		os.Exit(1)
+		fatal(bootLogger, "failed to load config", zap.Error(err))
 	}
 
 	// Инициализация логгера
 	logger, err := initLogger(cfg.Logger)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
-		os.Exit(1)
+		fatal(bootLogger, "failed to initialize logger", zap.Error(err), zap.String("output", cfg.Logger.Output))
 	}
-	defer logger.Sync()
+	defer syncLogger(logger)
 
 	logger.Info("starting PR reviewer assignment service",
 		zap.String("server_address", cfg.Server.GetAddress()))
 
-	// Подключение к базе данных
-	dbPool, err := initDatabase(context.Background(), cfg.Database, logger)
+	// Трассировка OpenTelemetry (no-op без OTEL_EXPORTER_OTLP_ENDPOINT)
+	shutdownTracing, err := initTracing(context.Background(), cfg.Tracing)
 	if err != nil {
-		logger.Fatal("failed to connect to database", zap.Error(err))
+		fatal(logger, "failed to initialize tracing", zap.Error(err))
+	}
+	if cfg.Tracing.Enabled() {
+		logger.Info("tracing enabled",
+			zap.String("endpoint", cfg.Tracing.Endpoint),
+			zap.Float64("sample_ratio", cfg.Tracing.SampleRatio))
+	}
+
+	// Подключение к базе данных; сигнал во время ожидания БД при старте тоже завершает сервис
+	dbPool, err := initDatabase(ctx, cfg.Database, logger)
+	if errors.Is(err, context.Canceled) {
+		logger.Info("shutdown requested while waiting for database")
+		return exitOK
+	}
+	if err != nil {
+		fatal(logger, "failed to connect to database", zap.Error(err))
 	}
 	defer dbPool.Close()
 
 	logger.Info("database connection established")
 
+	// Необязательный пул реплики для чистого чтения
+	var readPool *pgxpool.Pool
+	if readCfg, ok := cfg.Database.ReadReplica(); ok {
+		readPool, err = initDatabase(ctx, readCfg, logger)
+		if errors.Is(err, context.Canceled) {
+			logger.Info("shutdown requested while waiting for read replica")
+			return exitOK
+		}
+		if err != nil {
+			fatal(logger, "failed to connect to read replica", zap.Error(err))
+		}
+		defer readPool.Close()
+
+		logger.Info("read replica connection established")
+	}
+
+	// Версия последней миграции, которую ожидает код, для проверки готовности
+	migrationVersion, err := migrations.LatestVersion()
+	if err != nil {
+		fatal(logger, "failed to read embedded migrations", zap.Error(err))
+	}
+
+	// Метрики Prometheus
+	appMetrics := metrics.New(prometheus.NewRegistry())
+
 	// Инициализация слоя данных
-	repo := repository.New(dbPool)
+	repo := newRepository(dbPool, readPool, cfg, appMetrics, logger)
+
+	// В режиме fold пользователи ищутся по нормализованному индексу: без его уникальности
+	// (коллизии при миграции 0004) один ID мог бы означать нескольких пользователей
+	if cfg.IDs.FoldIDs() {
+		if err := repo.CheckFoldedIDIndex(ctx); err != nil {
+			var collisions *repository.IDCollisionError
+			if errors.As(err, &collisions) {
+				fmt.Fprint(stderr, collisions.Report())
+			}
+			fatal(logger, "ID_NORMALIZATION=fold requires a unique folded external_id index", zap.Error(err))
+		}
+	}
+
+	// Доставка событий подписчикам исходящих вебхуков
+	dispatcher := notify.New(repo, notify.Config{
+		QueueSize:      cfg.Webhooks.QueueSize,
+		Workers:        cfg.Webhooks.Workers,
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.RetryBackoff,
+		Timeout:        cfg.Webhooks.Timeout,
+	}, appMetrics, logger)
+
+	// События сервиса получают исходящие вебхуки и, если настроено, уведомления в Slack
+	notifiers := []service.Notifier{dispatcher}
+	var slackNotifier *slack.Notifier
+	if cfg.Slack.Enabled() {
+		slackNotifier = slack.New(repo, slack.Config{
+			WebhookURL: cfg.Slack.WebhookURL,
+			DryRun:     cfg.Slack.DryRun,
+		}, logger)
+		notifiers = append(notifiers, slackNotifier)
+	}
+
+	// Инициализация сервисного слоя
+	services := service.New(repo, notifiers...)
 
 	// Инициализация обработчиков
-	handler := handlers.New(repo, logger)
+	handler := handlers.New(repo, services, appMetrics, logger, handlers.Config{
+		FoldIDs:             cfg.IDs.FoldIDs(),
+		GitHubWebhookSecret: cfg.Webhooks.GitHubSecret,
+		GitLabWebhookSecret: cfg.Webhooks.GitLabSecret,
+		WebhookDeliveryTTL:  cfg.Idempotency.KeyTTL,
+		MaxReviewers:        cfg.Assignment.MaxReviewers,
+		OrphanInactiveAfter: cfg.OrphanSweep.InactiveAfter,
+		OrphanSweepLimit:    cfg.OrphanSweep.MaxReassignments,
+		LegacySunset:        cfg.Server.LegacySunset,
+		AdminAPIKeys:        cfg.Server.AdminAPIKeys,
+	})
 
 	// Настройка Echo сервера
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	// Ошибки маршрутизации, паники и ошибки middleware отдаются в стандартном формате ErrorResponse
+	e.HTTPErrorHandler = handlers.ErrorHandler(logger)
+	// Тело запросов принимается только в JSON и без неизвестных полей
+	e.Binder = &handlers.Binder{}
 
 	// Middleware
+	// Спан запроса открывается первым, чтобы логгер запроса получил trace_id и span_id
+	e.Use(otelecho.Middleware(serviceName))
+	// ID запроса берется из входящего X-Request-Id или генерируется и возвращается в том же заголовке
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: handlers.BindRequestID(logger),
+	}))
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:    true,
 		LogStatus: true,
 		LogError:  true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
 			if v.Error == nil {
-				logger.Info("request",
+				handlers.RequestLogger(c, logger).Info("request",
 					zap.String("method", c.Request().Method),
 					zap.String("uri", v.URI),
 					zap.Int("status", v.Status),
 				)
 			} else {
-				logger.Error("request error",
+				handlers.RequestLogger(c, logger).Error("request error",
 					zap.String("method", c.Request().Method),
 					zap.String("uri", v.URI),
 					zap.Int("status", v.Status),
@@ -82,68 +203,239 @@ func main() {
 			return nil
 		},
 	}))
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(handlers.Recover(logger))
+	// CORS-заголовки отдаются только для источников из CORS_ALLOWED_ORIGINS; без них CORS выключен
+	if cfg.CORS.Enabled() {
+		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOrigins:     cfg.CORS.AllowedOrigins,
+			AllowMethods:     cfg.CORS.AllowedMethods,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			ExposeHeaders: []string{
+				echo.HeaderXRequestID,
+				echo.HeaderRetryAfter,
+				handlers.HeaderIdempotentReplayed,
+				handlers.HeaderDeprecation,
+				handlers.HeaderSunset,
+				handlers.HeaderLink,
+			},
+		}))
+	}
+	e.Use(appMetrics.Middleware())
+	// Ограничение частоты запросов на клиента (выключено при RATE_LIMIT_RPS=0)
+	if cfg.RateLimit.Enabled() {
+		e.Use(handlers.RateLimit(cfg.RateLimit.RPS, cfg.RateLimit.Burst, cfg.RateLimit.APIKeys, logger))
+	}
+	e.Use(handlers.BodyLimit(cfg.Server.MaxRequestBodySize, logger))
+	e.Use(handlers.Actor())
 
-	// Регистрация роутов
-	handler.RegisterRoutes(e)
+	// Liveness и readiness: готовность требует прогрева пула, доступной БД и применённых миграций
+	health := handlers.NewHealthHandler(dbPool, migrationVersion, logger)
 
-	// Health check endpoint
-	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
-	})
+	// Спецификация OpenAPI на /openapi.json и Swagger UI на /docs (при DOCS_ENABLED=true)
+	specJSON, err := api.SpecJSON()
+	if err != nil {
+		fatal(logger, "failed to load OpenAPI spec", zap.Error(err))
+	}
+
+	// Метрики отдаются на основном порту или, при заданном METRICS_PORT, отдельным сервером
+	var metricsHandler http.Handler
+	if cfg.Server.MetricsPort == "" {
+		metricsHandler = appMetrics.Handler()
+	}
+
+	idempotency := handlers.Idempotency(repo, cfg.Idempotency.KeyTTL, cfg.Idempotency.Lease, logger)
+	registerRoutes(e, handler, idempotency, health, handlers.NewDocsHandler(specJSON, cfg.Server.EnableDocs), metricsHandler)
+
+	// Вспомогательные серверы на отдельных портах запускаются и останавливаются вместе с основным
+	auxServers := map[string]*http.Server{}
+
+	if cfg.Server.MetricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", appMetrics.Handler())
+		auxServers["metrics"] = &http.Server{
+			Addr:              cfg.Server.GetMetricsAddress(),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	// Маршрут без описания в спецификации — признак расхождения кода и контракта
+	warnUndocumentedRoutes(e, logger)
+
+	// pprof доступен только при ENABLE_PPROF=true и только на DEBUG_PORT
+	if cfg.Server.EnablePprof {
+		auxServers["debug"] = newDebugServer(cfg.Server.GetDebugAddress())
+	}
+
+	// gRPC API поверх тех же репозитория и сервисного слоя, только при GRPC_ENABLED=true
+	var grpcServer *grpc.Server
+	if cfg.Server.EnableGRPC {
+		grpcService := grpcapi.New(repo, services, appMetrics, logger, grpcapi.Config{
+			FoldIDs: cfg.IDs.FoldIDs(),
+		})
+		grpcServer = grpcapi.NewServer(grpcService, logger)
+	}
+
+	// Фоновые снимки загрузки ревьюеров
+	if cfg.Stats.SnapshotInterval > 0 {
+		snapshotWorker := worker.NewLoadSnapshotWorker(repo, logger, cfg.Stats.SnapshotInterval, cfg.Stats.HistoryRetention)
+		go snapshotWorker.Run(ctx)
+	}
+
+	// Очистка истекших ключей идемпотентности, отметок доставок вебхуков и истории исходящих доставок
+	if cfg.Idempotency.CleanupInterval > 0 {
+		cleanupWorker := worker.NewIdempotencyCleanupWorker(repo, logger, cfg.Idempotency.CleanupInterval, cfg.Webhooks.HistoryRetention)
+		go cleanupWorker.Run(ctx)
+	}
+
+	// Воркеры доставки исходящих вебхуков
+	go dispatcher.Run(ctx)
 
-	// Graceful shutdown
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	// Отправка уведомлений в Slack
+	if slackNotifier != nil {
+		go slackNotifier.Run(ctx)
+	}
+
+	// Напоминания о давних неодобренных назначениях
+	if cfg.Reminders.Enabled {
+		reminderWorker := worker.NewReviewReminderWorker(repo, service.MultiNotifier(notifiers...), logger, cfg.Reminders.Interval, cfg.Reminders.After)
+		go reminderWorker.Run(ctx)
+	}
+
+	// Переназначение ревью, зависших на давно деактивированных пользователях
+	if cfg.OrphanSweep.Enabled {
+		sweepWorker := worker.NewOrphanSweepWorker(repo, appMetrics, logger,
+			cfg.OrphanSweep.Interval, cfg.OrphanSweep.InactiveAfter, cfg.OrphanSweep.MaxReassignments)
+		go sweepWorker.Run(ctx)
+	}
 
 	// Запуск сервера в горутине
+	// Таймауты задаются на встроенном сервере Echo, чтобы e.Shutdown останавливал именно его
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
+	e.Server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	e.Server.MaxHeaderBytes = cfg.Server.MaxHeaderBytes
 	go func() {
 		addr := cfg.Server.GetAddress()
-		logger.Info("server listening", zap.String("address", addr))
+		logger.Info("server listening",
+			zap.String("address", addr),
+			zap.Duration("read_timeout", cfg.Server.ReadTimeout),
+			zap.Duration("write_timeout", cfg.Server.WriteTimeout),
+			zap.Duration("idle_timeout", cfg.Server.IdleTimeout),
+			zap.Int64("max_body_bytes", cfg.Server.MaxRequestBodySize))
 		if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Fatal("server start failed", zap.Error(err))
+			fatal(logger, "server start failed", zap.Error(err))
 		}
 	}()
 
+	for name, srv := range auxServers {
+		go func() {
+			logger.Info("auxiliary server listening", zap.String("server", name), zap.String("address", srv.Addr))
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal(logger, "auxiliary server start failed", zap.String("server", name), zap.Error(err))
+			}
+		}()
+	}
+
+	if grpcServer != nil {
+		go func() {
+			addr := cfg.Server.GetGRPCAddress()
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				fatal(logger, "grpc server start failed", zap.Error(err))
+			}
+			logger.Info("grpc server listening", zap.String("address", addr))
+			if err := grpcServer.Serve(listener); err != nil {
+				fatal(logger, "grpc server start failed", zap.Error(err))
+			}
+		}()
+	}
+
+	// Прогрев соединений и prepared statements
+	if err := warmUp(ctx, dbPool, repo, cfg.Database.Warmup, logger); err != nil {
+		logger.Error("database warm-up failed", zap.Error(err))
+	}
+	if readPool != nil {
+		// Прогревочные запросы — чистое чтение и уже выполнены через пул реплики, остается открыть его соединения
+		if err := warmUp(ctx, readPool, repo, false, logger); err != nil {
+			logger.Error("read replica warm-up failed", zap.Error(err))
+		}
+	}
+	health.MarkWarmedUp()
+
 	// Ожидание сигнала завершения
 	<-ctx.Done()
 	logger.Info("shutting down server gracefully")
 
 	// Таймаут для graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown error", zap.Error(err))
 	}
+	for name, srv := range auxServers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("auxiliary server shutdown error", zap.String("server", name), zap.Error(err))
+		}
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("tracing shutdown error", zap.Error(err))
+	}
 
 	logger.Info("server stopped")
+	return exitOK
 }
 
-// initLogger инициализирует zap логгер на основе конфигурации
-func initLogger(cfg config.LoggerConfig) (*zap.Logger, error) {
-	var level zapcore.Level
-	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
-		level = zapcore.InfoLevel
+// registerRoutes регистрирует маршруты основного сервера: API под /api/v1 и прежние пути без версии
+// с адаптером старого формата, проверки здоровья, спецификацию и, если metricsHandler не nil, /metrics.
+// Идемпотентность подключается к маршрутам, чтобы у прежних путей она работала внутри адаптера.
+func registerRoutes(e *echo.Echo, handler *handlers.Handler, idempotency echo.MiddlewareFunc,
+	health *handlers.HealthHandler, docs *handlers.DocsHandler, metricsHandler http.Handler) {
+	handler.RegisterRoutes(e.Group(handlers.APIV1Prefix), idempotency)
+	handler.RegisterLegacyRoutes(e.Group(""), idempotency)
+	health.RegisterRoutes(e)
+	docs.RegisterRoutes(e)
+	if metricsHandler != nil {
+		e.GET("/metrics", echo.WrapHandler(metricsHandler))
 	}
+}
 
-	var zapConfig zap.Config
-	if cfg.Format == "json" {
-		zapConfig = zap.NewProductionConfig()
-	} else {
-		zapConfig = zap.NewDevelopmentConfig()
-		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	}
-
-	zapConfig.Level = zap.NewAtomicLevelAt(level)
-
-	logger, err := zapConfig.Build()
-	if err != nil {
-		return nil, fmt.Errorf("failed to build logger: %w", err)
+// newRepository создает репозиторий с настройками назначения, кэша и повторов из конфигурации.
+// readPool — пул реплики для чистого чтения, nil — все запросы идут через pool.
+func newRepository(pool, readPool *pgxpool.Pool, cfg *config.Config, appMetrics *metrics.Metrics, logger *zap.Logger) *repository.Repository {
+	var read repository.DB
+	if readPool != nil {
+		read = readPool
 	}
-
-	return logger, nil
+	return repository.New(pool, repository.Options{
+		ReadPool:            read,
+		FoldUserIDs:         cfg.IDs.FoldIDs(),
+		AssignmentStrategy:  cfg.Assignment.Strategy,
+		CooldownPRs:         cfg.Assignment.CooldownPRs,
+		MinAssignmentAge:    cfg.Assignment.MinAssignmentAge,
+		RejectAmbiguousTeam: cfg.Assignment.RejectAmbiguousTeam,
+		RejectZeroReviewers: !cfg.Assignment.AllowZeroReviewers,
+		FallbackTeam:        cfg.Assignment.FallbackTeamName(),
+		MaxReviewers:        cfg.Assignment.MaxReviewers,
+		RequireApprovals:    cfg.Merge.RequireApprovals,
+		CacheTTL:            cfg.Cache.TTL,
+		OnRetry: func(op string, attempt int, err error) {
+			appMetrics.DBRetries.WithLabelValues(op).Inc()
+			logger.Warn("retrying database operation after transient error",
+				zap.String("operation", op), zap.Int("attempt", attempt), zap.Error(err))
+		},
+		OnCapacityLimited: func(team string, requested, assigned, capped int) {
+			appMetrics.CapacityLimitedAssignments.Inc()
+			logger.Warn("assigned fewer reviewers than required: team members are at review capacity",
+				zap.String("team_name", team), zap.Int("requested", requested),
+				zap.Int("assigned", assigned), zap.Int("at_capacity", capped))
+		},
+	})
 }
 
 // initDatabase инициализирует пул подключений к PostgreSQL
@@ -153,12 +445,35 @@ func initDatabase(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Lo
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
 
+	// Явно задаем режим выполнения запросов (кэширование prepared statements)
+	poolConfig.ConnConfig.DefaultQueryExecMode = queryExecModes[cfg.QueryExecMode]
+
+	// Спаны запросов pgx (пишутся только при настроенном провайдере трасс) и журнал запросов
+	poolConfig.ConnConfig.Tracer = multitracer.New(
+		otelpgx.NewTracer(),
+		newQueryLogger(logger, cfg.SlowQueryThreshold),
+	)
+
 	// Настройки пула
-	poolConfig.MaxConns = 25
-	poolConfig.MinConns = 5
-	poolConfig.MaxConnLifetime = time.Hour
-	poolConfig.MaxConnIdleTime = 30 * time.Minute
-	poolConfig.HealthCheckPeriod = time.Minute
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	if cfg.ConnectTimeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+
+	logger.Info("database pool settings",
+		zap.Int32("max_conns", poolConfig.MaxConns),
+		zap.Int32("min_conns", poolConfig.MinConns),
+		zap.Duration("max_conn_lifetime", poolConfig.MaxConnLifetime),
+		zap.Duration("max_conn_idle_time", poolConfig.MaxConnIdleTime),
+		zap.Duration("health_check_period", poolConfig.HealthCheckPeriod),
+		zap.Duration("connect_timeout", poolConfig.ConnConfig.ConnectTimeout),
+		zap.String("query_exec_mode", cfg.QueryExecMode),
+		zap.Duration("slow_query_threshold", cfg.SlowQueryThreshold),
+	)
 
 	// Создание пула
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
@@ -166,11 +481,67 @@ func initDatabase(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Lo
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	// Проверка подключения
-	if err := pool.Ping(ctx); err != nil {
+	// Проверка подключения: при старте БД может быть еще недоступна
+	if err := waitForDatabase(ctx, pool, cfg.StartupTimeout, logger); err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
 	return pool, nil
 }
+
+// Паузы между проверками соединения с БД при старте
+const (
+	dbStartupInitialDelay = 500 * time.Millisecond
+	dbStartupMaxDelay     = 5 * time.Second
+)
+
+// waitForDatabase проверяет соединение с БД, повторяя попытки с экспоненциальной паузой,
+// пока БД не ответит, не истечет timeout или не будет отменен ctx. timeout 0 — одна попытка.
+func waitForDatabase(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration, logger *zap.Logger) error {
+	if timeout <= 0 {
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	pingCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	delay := dbStartupInitialDelay
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(pingCtx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("database is ready", zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Попытку, прерванную общим сроком, не считаем причиной отказа: сообщаем последнюю ошибку соединения
+		if pingCtx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		wait := min(delay, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("failed to ping database after %d attempts within %s: %w", attempt, timeout, lastErr)
+		}
+		logger.Warn("database is not ready, retrying",
+			zap.Int("attempt", attempt), zap.Duration("retry_in", wait), zap.Error(err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, dbStartupMaxDelay)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// freeAddr возвращает свободный адрес на localhost, на котором пока никто не слушает
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// servePostgres через delay начинает принимать соединения на addr и отвечает на них как Postgres:
+// принимает вход без пароля и отвечает на простые запросы, которыми pgx проверяет соединение
+func servePostgres(t *testing.T, addr string, delay time.Duration) {
+	t.Helper()
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+
+	go func() {
+		select {
+		case <-time.After(delay):
+		case <-done:
+			return
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Errorf("listen %s: %v", addr, err)
+			return
+		}
+		go func() {
+			<-done
+			l.Close()
+		}()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go fakePostgresConn(conn)
+		}
+	}()
+}
+
+func fakePostgresConn(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: "16.0"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg.(type) {
+		case *pgproto3.Query:
+			backend.Send(&pgproto3.EmptyQueryResponse{})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+// newLazyPool создает пул к addr без установки соединений
+func newLazyPool(t *testing.T, addr string) *pgxpool.Pool {
+	t.Helper()
+	cfg, err := pgxpool.ParseConfig("postgres://app@" + addr + "/app?sslmode=disable&connect_timeout=1")
+	require.NoError(t, err)
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestWaitForDatabaseRetriesUntilDatabaseAccepts(t *testing.T) {
+	addr := freeAddr(t)
+	// Первая попытка сразу и повтор через dbStartupInitialDelay еще не застают сервер
+	servePostgres(t, addr, dbStartupInitialDelay+200*time.Millisecond)
+	core, logs := observer.New(zap.WarnLevel)
+
+	start := time.Now()
+	err := waitForDatabase(context.Background(), newLazyPool(t, addr), 10*time.Second, zap.New(core))
+	require.NoError(t, err)
+
+	retries := logs.FilterMessage("database is not ready, retrying").All()
+	require.GreaterOrEqual(t, len(retries), 2, "every failed attempt is logged")
+	assert.Equal(t, int64(1), retries[0].ContextMap()["attempt"])
+	assert.Equal(t, dbStartupInitialDelay, retries[0].ContextMap()["retry_in"])
+	assert.Equal(t, 2*dbStartupInitialDelay, retries[1].ContextMap()["retry_in"], "the pause doubles")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestWaitForDatabaseGivesUpAfterTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	timeout := time.Second
+
+	start := time.Now()
+	err := waitForDatabase(context.Background(), newLazyPool(t, addr), timeout, zap.NewNop())
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to ping database after")
+	assert.Contains(t, err.Error(), "within 1s")
+	assert.GreaterOrEqual(t, elapsed, timeout-100*time.Millisecond)
+	assert.Less(t, elapsed, timeout+time.Second, "the deadline bounds the total wait")
+}
+
+func TestWaitForDatabaseSingleAttemptWithoutTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	core, logs := observer.New(zap.WarnLevel)
+
+	err := waitForDatabase(context.Background(), newLazyPool(t, addr), 0, zap.New(core))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to ping database")
+	assert.Zero(t, logs.Len(), "no retries without a timeout")
+}
+
+func TestWaitForDatabaseStopsOnCancel(t *testing.T) {
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := waitForDatabase(ctx, newLazyPool(t, addr), 30*time.Second, zap.NewNop())
+
+	require.True(t, errors.Is(err, context.Canceled), "got %v", err)
+	assert.Less(t, time.Since(start), time.Second, "SIGTERM during startup exits promptly")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/untibullet/pr-manager-avito/migrations"
+	"go.uber.org/zap"
+)
+
+// runMigrate применяет, откатывает или показывает встроенные миграции goose.
+// Коды завершения: exitOK, exitFailure, exitUsage; status — exitIncomplete, если есть неприменённые миграции.
+func runMigrate(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("migrate", migrateUsage, stderr)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "expected exactly one action: up, down or status")
+	}
+	action := flags.Arg(0)
+	if action != "up" && action != "down" && action != "status" {
+		return usageError(flags, "unknown action %q", action)
+	}
+
+	env, code := openCLIEnv(ctx)
+	if env == nil {
+		return code
+	}
+	defer env.Close()
+
+	// goose работает через database/sql поверх того же пула, что и сервер.
+	// Advisory lock не дает двум migrate (или migrate и контейнеру migrator) применять миграции одновременно.
+	db := stdlib.OpenDBFromPool(env.pool)
+	defer db.Close()
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		env.logger.Error("failed to create migration lock", zap.Error(err))
+		return exitFailure
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS(), goose.WithSessionLocker(locker))
+	if err != nil {
+		env.logger.Error("failed to load migrations", zap.Error(err))
+		return exitFailure
+	}
+
+	switch action {
+	case "up":
+		return migrateUp(ctx, provider, stdout, env.logger)
+	case "down":
+		return migrateDown(ctx, provider, stdout, env.logger)
+	default:
+		return migrateStatus(ctx, provider, stdout, env.logger)
+	}
+}
+
+// migrateUp применяет все неприменённые миграции; при ошибке выводит и те, что успели примениться
+func migrateUp(ctx context.Context, provider *goose.Provider, stdout io.Writer, logger *zap.Logger) int {
+	results, err := provider.Up(ctx)
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		results = partial.Applied
+	}
+	for _, result := range results {
+		fmt.Fprintln(stdout, result)
+	}
+	if err != nil {
+		logger.Error("failed to apply migrations", zap.Error(err))
+		return exitFailure
+	}
+
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		logger.Error("failed to get database version", zap.Error(err))
+		return exitFailure
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(stdout, "no pending migrations, current version: %d\n", version)
+	} else {
+		fmt.Fprintf(stdout, "applied %d migrations, current version: %d\n", len(results), version)
+	}
+	return exitOK
+}
+
+// migrateDown откатывает последнюю примененную миграцию
+func migrateDown(ctx context.Context, provider *goose.Provider, stdout io.Writer, logger *zap.Logger) int {
+	result, err := provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		fmt.Fprintln(stdout, "no applied migrations to roll back")
+		return exitOK
+	}
+	if err != nil {
+		logger.Error("failed to roll back migration", zap.Error(err))
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, result)
+	return exitOK
+}
+
+// migrateStatus выводит состояние каждой миграции
+func migrateStatus(ctx context.Context, provider *goose.Provider, stdout io.Writer, logger *zap.Logger) int {
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		logger.Error("failed to get migration status", zap.Error(err))
+		return exitFailure
+	}
+
+	pending := 0
+	for _, st := range statuses {
+		appliedAt := "-"
+		if st.State == goose.StateApplied {
+			appliedAt = st.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		fmt.Fprintf(stdout, "%-8s %-19s %s\n", st.State, appliedAt, path.Base(st.Source.Path))
+	}
+
+	if pending > 0 {
+		fmt.Fprintf(stdout, "%d pending migrations\n", pending)
+		return exitIncomplete
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// queryLogger — pgx.QueryTracer, который пишет каждый запрос в лог на уровне Debug,
+// а запросы дольше slowThreshold — на уровне Warn. Поле request_id связывает запрос с HTTP-запросом.
+type queryLogger struct {
+	logger *zap.Logger
+	// slowThreshold — порог медленного запроса, 0 — медленные запросы не выделяются
+	slowThreshold time.Duration
+	now           func() time.Time
+}
+
+// queryStartKey — ключ данных начала запроса в контексте между TraceQueryStart и TraceQueryEnd
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql     string
+	args    int
+	startAt time.Time
+}
+
+func newQueryLogger(logger *zap.Logger, slowThreshold time.Duration) *queryLogger {
+	return &queryLogger{logger: logger, slowThreshold: slowThreshold, now: time.Now}
+}
+
+// enabled сообщает, будет ли записан хоть один запрос; иначе трассировка не выполняет лишней работы
+func (l *queryLogger) enabled() bool {
+	return l.slowThreshold > 0 || l.logger.Core().Enabled(zapcore.DebugLevel)
+}
+
+func (l *queryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !l.enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, args: len(data.Args), startAt: l.now()})
+}
+
+func (l *queryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	duration := l.now().Sub(start.startAt)
+	slow := l.slowThreshold > 0 && duration >= l.slowThreshold
+	level := zapcore.DebugLevel
+	if slow {
+		level = zapcore.WarnLevel
+	}
+	ce := l.logger.Check(level, "database query")
+	if ce == nil {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("sql", normalizeSQL(start.sql)),
+		zap.Int("args", start.args),
+		zap.Duration("duration", duration),
+		zap.Int64("rows_affected", data.CommandTag.RowsAffected()),
+		zap.Bool("slow", slow),
+	}
+	if id := handlers.RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	ce.Write(fields...)
+}
+
+// normalizeSQL схлопывает переводы строк и отступы запроса в одиночные пробелы
+func normalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
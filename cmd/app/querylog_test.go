@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+)
+
+// traceQuery прогоняет через l один запрос, который длится duration по часам l.now
+func traceQuery(ctx context.Context, l *queryLogger, sql string, args []any, duration time.Duration, err error) {
+	at := time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return at }
+	ctx = l.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: args})
+	at = at.Add(duration)
+	l.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 2"), Err: err})
+}
+
+func TestQueryLoggerSlowThreshold(t *testing.T) {
+	const threshold = 200 * time.Millisecond
+	cases := []struct {
+		name      string
+		level     zapcore.Level
+		threshold time.Duration
+		duration  time.Duration
+		want      zapcore.Level
+		logged    bool
+	}{
+		{name: "below threshold at info", level: zapcore.InfoLevel, threshold: threshold, duration: threshold - time.Millisecond},
+		{name: "at threshold", level: zapcore.InfoLevel, threshold: threshold, duration: threshold, want: zapcore.WarnLevel, logged: true},
+		{name: "above threshold", level: zapcore.InfoLevel, threshold: threshold, duration: time.Second, want: zapcore.WarnLevel, logged: true},
+		{name: "below threshold at debug", level: zapcore.DebugLevel, threshold: threshold, duration: time.Millisecond, want: zapcore.DebugLevel, logged: true},
+		{name: "threshold disabled", level: zapcore.InfoLevel, duration: time.Hour},
+		{name: "threshold disabled at debug", level: zapcore.DebugLevel, duration: time.Hour, want: zapcore.DebugLevel, logged: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(tc.level)
+			l := newQueryLogger(zap.New(core), tc.threshold)
+
+			traceQuery(context.Background(), l, "SELECT 1", nil, tc.duration, nil)
+
+			if !tc.logged {
+				assert.Zero(t, logs.Len())
+				return
+			}
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, "database query", entry.Message)
+			assert.Equal(t, tc.want, entry.Level)
+			fields := entry.ContextMap()
+			assert.Equal(t, tc.duration, fields["duration"])
+			assert.Equal(t, tc.want == zapcore.WarnLevel, fields["slow"])
+		})
+	}
+}
+
+func TestQueryLoggerRedactsArguments(t *testing.T) {
+	const secret = "s3cr3t-token"
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newQueryLogger(zap.New(core), 100*time.Millisecond)
+
+	for _, duration := range []time.Duration{time.Millisecond, time.Second} {
+		traceQuery(context.Background(), l,
+			"UPDATE users\n\t\tSET api_token = $1\n\t\tWHERE external_id = $2",
+			[]any{secret, "u1"}, duration, errors.New("deadlock detected"))
+	}
+
+	require.Equal(t, 2, logs.Len(), "fast and slow queries are both logged at debug")
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		assert.Equal(t, "UPDATE users SET api_token = $1 WHERE external_id = $2", fields["sql"],
+			"placeholders stay in the SQL text, whitespace is collapsed")
+		assert.Equal(t, int64(2), fields["args"], "only the number of arguments is logged")
+		assert.Equal(t, int64(2), fields["rows_affected"])
+		assert.Equal(t, "deadlock detected", fields["error"])
+		for key, value := range fields {
+			assert.NotContains(t, fmt.Sprint(value), secret, "field %s leaks an argument value", key)
+			assert.NotContains(t, fmt.Sprint(value), "u1", "field %s leaks an argument value", key)
+		}
+	}
+}
+
+func TestQueryLoggerRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newQueryLogger(zap.New(core), 0)
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	handlers.BindRequestID(zap.NewNop())(c, "req-42")
+	traceQuery(c.Request().Context(), l, "SELECT 1", nil, time.Millisecond, nil)
+	traceQuery(context.Background(), l, "SELECT 1", nil, time.Millisecond, nil)
+
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, "req-42", logs.All()[0].ContextMap()["request_id"])
+	assert.NotContains(t, logs.All()[1].ContextMap(), "request_id", "queries outside a request have no request_id")
+}
+
+func TestQueryLoggerDisabled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newQueryLogger(zap.New(core), 0)
+	calls := 0
+	l.now = func() time.Time {
+		calls++
+		return time.Now()
+	}
+
+	ctx := context.Background()
+	traced := l.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	l.TraceQueryEnd(traced, nil, pgx.TraceQueryEndData{})
+
+	assert.Equal(t, ctx, traced, "nothing is stored without a threshold or debug level")
+	assert.Zero(t, calls, "the clock is not read when nothing can be logged")
+	assert.Zero(t, logs.Len())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/untibullet/pr-manager-avito/internal/config"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// warmupMissingID — заведомо отсутствующий внешний ID для прогревочных запросов
+const warmupMissingID = "__warmup__"
+
+// queryExecModes сопоставляет значения DB_QUERY_EXEC_MODE режимам pgx
+var queryExecModes = map[string]pgx.QueryExecMode{
+	config.QueryExecModeCacheStatement: pgx.QueryExecModeCacheStatement,
+	config.QueryExecModeCacheDescribe:  pgx.QueryExecModeCacheDescribe,
+	config.QueryExecModeDescribeExec:   pgx.QueryExecModeDescribeExec,
+	config.QueryExecModeExec:           pgx.QueryExecModeExec,
+	config.QueryExecModeSimpleProtocol: pgx.QueryExecModeSimpleProtocol,
+}
+
+// warmUp заранее открывает MinConns соединений пула и, если включено, выполняет самые частые
+// запросы на каждом из них, чтобы первые запросы после деплоя не платили за подготовку statements
+func warmUp(ctx context.Context, pool *pgxpool.Pool, repo *repository.Repository, runQueries bool, logger *zap.Logger) error {
+	started := time.Now()
+	minConns := int(pool.Config().MinConns)
+
+	// Удерживаем соединения одновременно, чтобы пул открыл MinConns разных соединений
+	conns := make([]*pgxpool.Conn, 0, minConns)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+	for i := 0; i < minConns; i++ {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire connection: %w", err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Release()
+	}
+	conns = conns[:0]
+
+	if runQueries {
+		// Параллельные прогоны разбираются по разным соединениям пула
+		var wg sync.WaitGroup
+		errs := make(chan error, minConns)
+		for i := 0; i < minConns; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := runWarmupQueries(ctx, repo); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		if err, ok := <-errs; ok {
+			return err
+		}
+	}
+
+	logger.Info("database warm-up completed",
+		zap.Int("connections", minConns),
+		zap.Bool("queries", runQueries),
+		zap.Duration("duration", time.Since(started)))
+
+	return nil
+}
+
+// runWarmupQueries выполняет самые частые запросы по заведомо отсутствующему ключу.
+// ErrNotFound ожидаем и ошибкой не считается.
+func runWarmupQueries(ctx context.Context, repo *repository.Repository) error {
+	queries := []func() error{
+		func() error { _, err := repo.GetPR(ctx, models.PRRef{ID: warmupMissingID}); return err },
+		func() error { _, err := repo.GetTeam(ctx, warmupMissingID); return err },
+		func() error { _, err := repo.GetUser(ctx, warmupMissingID); return err },
+		func() error {
+			_, _, err := repo.GetPRsByReviewer(ctx, warmupMissingID, repository.ReviewFilter{PRListFilter: repository.PRListFilter{Limit: 1}})
+			return err
+		},
+	}
+	for _, query := range queries {
+		if err := query(); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to run warm-up query: %w", err)
+		}
+	}
+	return nil
+}
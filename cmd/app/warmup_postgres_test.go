@@ -0,0 +1,116 @@
+//go:build postgres
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/config"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/migrations"
+)
+
+// benchDatabaseConfig создает в базе BENCH_DATABASE_URL (postgres://...) отдельную схему с миграциями
+// и возвращает настройки пула, как при запуске сервера, с search_path на эту схему.
+// Схема удаляется по завершении бенчмарка.
+func benchDatabaseConfig(b *testing.B) config.DatabaseConfig {
+	b.Helper()
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(b, err)
+	b.Cleanup(admin.Close)
+
+	schema := fmt.Sprintf("bench_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(b, err)
+	b.Cleanup(func() {
+		_, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		require.NoError(b, err)
+	})
+
+	u, err := url.Parse(dsn)
+	require.NoError(b, err)
+	q := u.Query()
+	q.Set("search_path", schema+",public")
+	u.RawQuery = q.Encode()
+
+	cfg := config.DatabaseConfig{
+		URL:           u.String(),
+		QueryExecMode: config.QueryExecModeCacheStatement,
+		MaxConns:      4,
+		MinConns:      4,
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.URL)
+	require.NoError(b, err)
+	defer pool.Close()
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS())
+	require.NoError(b, err)
+	_, err = provider.Up(ctx)
+	require.NoError(b, err)
+
+	return cfg
+}
+
+// BenchmarkFirstRequest измеряет первый запрос после старта: на холодном пуле и после warmUp
+// с прогревом запросов. Каждая итерация создает новый пул, поэтому кэш statements пуст до прогрева.
+//
+//	BENCH_DATABASE_URL=postgres://... go test -tags postgres -run '^$' -bench FirstRequest ./cmd/app
+func BenchmarkFirstRequest(b *testing.B) {
+	cfg := benchDatabaseConfig(b)
+	ctx := context.Background()
+
+	for _, bc := range []struct {
+		name string
+		warm bool
+	}{
+		{name: "cold"},
+		{name: "warm", warm: true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for b.Loop() {
+				b.StopTimer()
+				pool, err := initDatabase(ctx, cfg, zap.NewNop())
+				require.NoError(b, err)
+				repo := repository.New(pool, repository.Options{})
+				if bc.warm {
+					require.NoError(b, warmUp(ctx, pool, repo, true, zap.NewNop()))
+				}
+				b.StartTimer()
+
+				_, _, err = repo.GetPRsByReviewer(ctx, "u1", repository.ReviewFilter{PRListFilter: repository.PRListFilter{Limit: 20}})
+				if err != nil && !errors.Is(err, repository.ErrNotFound) {
+					b.Fatal(err)
+				}
+				_, err = repo.GetPR(ctx, models.PRRef{ID: "pr-1"})
+				if err != nil && !errors.Is(err, repository.ErrNotFound) {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				pool.Close()
+				b.StartTimer()
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Package announcement рендерит текст комментария о назначении ревьюеров для Git-хостингов и чатов.
+package announcement
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// Поддерживаемые форматы упоминаний
+const (
+	FormatGitHub = "github"
+	FormatGitLab = "gitlab"
+	FormatSlack  = "slack"
+)
+
+// ErrUnknownFormat возвращается для неподдерживаемого формата упоминаний
+var ErrUnknownFormat = errors.New("unknown mention format")
+
+//go:embed templates/default.md.tmpl
+var defaultTemplate string
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Data описывает данные, доступные в шаблоне
+type Data struct {
+	PullRequestID   string
+	PullRequestName string
+	Author          string
+	Reviewers       []string
+}
+
+// IsSupportedFormat сообщает, поддерживается ли формат упоминаний
+func IsSupportedFormat(format string) bool {
+	switch format {
+	case FormatGitHub, FormatGitLab, FormatSlack:
+		return true
+	}
+	return false
+}
+
+// Mention форматирует упоминание пользователя для выбранной платформы
+func Mention(format, userID string) (string, error) {
+	switch format {
+	case FormatGitHub, FormatGitLab:
+		return "@" + userID, nil
+	case FormatSlack:
+		return "<@" + userID + ">", nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// Validate проверяет, что пользовательский шаблон парсится и исполняется на тестовых данных
+func Validate(text string) error {
+	tmpl, err := parse(text)
+	if err != nil {
+		return err
+	}
+
+	sample := Data{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Sample PR",
+		Author:          "@author",
+		Reviewers:       []string{"@reviewer"},
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+// Render рендерит текст анонса для PR. Пустой override означает шаблон по умолчанию.
+func Render(pr *models.PullRequest, format, override string) (string, error) {
+	text := defaultTemplate
+	if override != "" {
+		text = override
+	}
+
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	author, err := Mention(format, pr.AuthorID)
+	if err != nil {
+		return "", err
+	}
+
+	reviewers := make([]string, 0, len(pr.AssignedReviewerIDs))
+	for _, id := range pr.AssignedReviewerIDs {
+		m, err := Mention(format, id)
+		if err != nil {
+			return "", err
+		}
+		reviewers = append(reviewers, m)
+	}
+
+	data := Data{
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		Author:          author,
+		Reviewers:       reviewers,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("announcement").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
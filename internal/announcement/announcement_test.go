@@ -0,0 +1,88 @@
+package announcement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+func TestRender(t *testing.T) {
+	withReviewers := &models.PullRequest{
+		PullRequestID:       "pr-1",
+		PullRequestName:     "Add cache",
+		AuthorID:            "u1",
+		AssignedReviewerIDs: []string{"u2", "u3"},
+	}
+	noReviewers := &models.PullRequest{
+		PullRequestID:       "pr-2",
+		PullRequestName:     "Fix typo",
+		AuthorID:            "u1",
+		AssignedReviewerIDs: []string{},
+	}
+
+	cases := []struct {
+		name   string
+		pr     *models.PullRequest
+		format string
+		want   string
+	}{
+		{
+			name: "github", pr: withReviewers, format: FormatGitHub,
+			want: "### Ревьюеры назначены\n\n**Add cache** (`pr-1`) от @u1\n\nРевьюеры: @u2, @u3",
+		},
+		{
+			name: "gitlab", pr: withReviewers, format: FormatGitLab,
+			want: "### Ревьюеры назначены\n\n**Add cache** (`pr-1`) от @u1\n\nРевьюеры: @u2, @u3",
+		},
+		{
+			name: "slack", pr: withReviewers, format: FormatSlack,
+			want: "### Ревьюеры назначены\n\n**Add cache** (`pr-1`) от <@u1>\n\nРевьюеры: <@u2>, <@u3>",
+		},
+		{
+			name: "github without reviewers", pr: noReviewers, format: FormatGitHub,
+			want: "### Ревьюеры назначены\n\n**Fix typo** (`pr-2`) от @u1\n\nСвободных ревьюеров в команде не нашлось — PR ждёт ручного назначения.",
+		},
+		{
+			name: "slack without reviewers", pr: noReviewers, format: FormatSlack,
+			want: "### Ревьюеры назначены\n\n**Fix typo** (`pr-2`) от <@u1>\n\nСвободных ревьюеров в команде не нашлось — PR ждёт ручного назначения.",
+		},
+		{
+			name: "nil reviewer list", pr: &models.PullRequest{PullRequestID: "pr-3", PullRequestName: "Draft", AuthorID: "u1"},
+			format: FormatGitLab,
+			want:   "### Ревьюеры назначены\n\n**Draft** (`pr-3`) от @u1\n\nСвободных ревьюеров в команде не нашлось — PR ждёт ручного назначения.",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Render(tc.pr, tc.format, "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRenderOverride(t *testing.T) {
+	pr := &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "Add cache", AuthorID: "u1", AssignedReviewerIDs: []string{"u2"}}
+
+	got, err := Render(pr, FormatSlack, "  {{ .Author }} → {{ join .Reviewers \" \" }} ({{ .PullRequestID }})\n")
+	require.NoError(t, err)
+	assert.Equal(t, "<@u1> → <@u2> (pr-1)", got)
+
+	_, err = Render(pr, FormatSlack, "{{ .Unknown }}")
+	assert.ErrorContains(t, err, "failed to execute template")
+
+	_, err = Render(pr, FormatSlack, "{{ .Author ")
+	assert.ErrorContains(t, err, "failed to parse template")
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	pr := &models.PullRequest{PullRequestID: "pr-1", AuthorID: "u1"}
+
+	_, err := Render(pr, "teams", "")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.False(t, IsSupportedFormat("teams"))
+}
@@ -0,0 +1,112 @@
+// Package bootstrap разбирает и проверяет документ начального заполнения (команды и PR)
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// Parse разбирает JSON-документ начального заполнения. Неизвестные поля считаются ошибкой.
+func Parse(r io.Reader) (*models.BootstrapDocument, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var doc models.BootstrapDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode bootstrap document: %w", err)
+	}
+	return &doc, nil
+}
+
+// ParseBytes разбирает документ начального заполнения из среза байт
+func ParseBytes(data []byte) (*models.BootstrapDocument, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+// Normalize применяет fn ко всем внешним ID пользователей документа
+func Normalize(doc *models.BootstrapDocument, fn func(string) string) {
+	for i := range doc.Teams {
+		for j := range doc.Teams[i].Members {
+			doc.Teams[i].Members[j].UserID = fn(doc.Teams[i].Members[j].UserID)
+		}
+	}
+	for i := range doc.PullRequests {
+		doc.PullRequests[i].AuthorID = fn(doc.PullRequests[i].AuthorID)
+	}
+}
+
+// Validate проверяет документ целиком и возвращает все найденные проблемы.
+// Пустой статус PR трактуется как OPEN.
+func Validate(doc *models.BootstrapDocument) []string {
+	var problems []string
+
+	teams := make(map[string]struct{}, len(doc.Teams))
+	users := make(map[string]string)
+	for i, team := range doc.Teams {
+		if team.TeamName == "" {
+			problems = append(problems, fmt.Sprintf("teams[%d]: team_name is required", i))
+		} else if _, ok := teams[team.TeamName]; ok {
+			problems = append(problems, fmt.Sprintf("teams[%d]: duplicate team_name %q", i, team.TeamName))
+		}
+		teams[team.TeamName] = struct{}{}
+
+		for j, member := range team.Members {
+			if member.UserID == "" {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: user_id is required", i, j))
+				continue
+			}
+			if member.Username == "" {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: username is required", i, j))
+			}
+			if !models.ValidSlackUserID(member.SlackUserID) {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: slack_user_id must be a Slack member ID", i, j))
+			}
+			if !models.ValidMaxOpenReviews(member.MaxOpenReviews) {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: max_open_reviews must be a positive integer", i, j))
+			}
+			if !models.ValidTeamRole(member.Role) {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: role must be one of member, lead", i, j))
+			}
+			if other, ok := users[member.UserID]; ok {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: user %q is already a member of team %q", i, j, member.UserID, other))
+				continue
+			}
+			users[member.UserID] = team.TeamName
+		}
+	}
+
+	// ID PR уникален в пределах репозитория
+	prs := make(map[[2]string]struct{}, len(doc.PullRequests))
+	for i, pr := range doc.PullRequests {
+		key := [2]string{pr.Repository, pr.PullRequestID}
+		if pr.PullRequestID == "" {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: pull_request_id is required", i))
+		} else if _, ok := prs[key]; ok {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: duplicate pull_request_id %q in repository %q", i, pr.PullRequestID, pr.Repository))
+		}
+		prs[key] = struct{}{}
+		if !models.ValidRepository(&pr.Repository) {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: repository must be at most %d characters", i, models.MaxRepositoryLength))
+		}
+
+		if pr.PullRequestName == "" {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: pull_request_name is required", i))
+		}
+		if pr.AuthorID == "" {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: author_id is required", i))
+		} else if _, ok := users[pr.AuthorID]; !ok {
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: author %q is not a member of any team in the document", i, pr.AuthorID))
+		}
+		switch pr.Status {
+		case "", models.StatusOpen, models.StatusMerged:
+		default:
+			problems = append(problems, fmt.Sprintf("pull_requests[%d]: unknown status %q", i, pr.Status))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,123 @@
+// Package cache реализует потокобезопасный LRU-кэш с ограничением числа записей и временем жизни.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU — кэш на maxEntries записей: при переполнении вытесняется давно не читавшаяся запись,
+// записи старше ttl считаются отсутствующими
+type LRU[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List
+	items      map[K]*list.Element
+	// generation увеличивается при каждой инвалидации; Load не сохраняет значения,
+	// прочитанные до инвалидации
+	generation uint64
+	now        func() time.Time
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// New создает кэш на maxEntries записей со временем жизни ttl
+func New[K comparable, V any](maxEntries int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		items:      make(map[K]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get возвращает значение по ключу, если оно есть и не устарело
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !c.now().Before(e.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Load возвращает значение из кэша, а при промахе вызывает load и сохраняет результат.
+// Ошибки load не кэшируются. Если во время load кэш был инвалидирован, результат возвращается,
+// но не сохраняется: он мог быть прочитан до изменения данных.
+func (c *LRU[K, V]) Load(key K, load func() (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.set(key, v)
+	}
+	return v, nil
+}
+
+// Purge удаляет все записи и отменяет сохранение значений, загружаемых в этот момент
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.order.Init()
+	clear(c.items)
+}
+
+// Len возвращает число записей, включая еще не удаленные устаревшие
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// set сохраняет значение и вытесняет самую старую по чтению запись при переполнении.
+// Вызывается под c.mu.
+func (c *LRU[K, V]) set(key K, value V) {
+	expiresAt := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// removeElement удаляет запись из списка и индекса. Вызывается под c.mu.
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock — управляемое время для проверки TTL
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+// newTestLRU создает кэш с управляемым временем
+func newTestLRU(maxEntries int, ttl time.Duration) (*LRU[string, int], *clock) {
+	c := New[string, int](maxEntries, ttl)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c.now = clk.Now
+	return c, clk
+}
+
+// value возвращает load, отдающий v и считающий вызовы в calls
+func value(v int, calls *int) func() (int, error) {
+	return func() (int, error) {
+		*calls++
+		return v, nil
+	}
+}
+
+func TestLRUEvictsLeastRecentlyRead(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	calls := 0
+	_, _ = c.Load("a", value(1, &calls))
+	_, _ = c.Load("b", value(2, &calls))
+
+	// Чтение a делает вытесняемой b
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	_, _ = c.Load("c", value(3, &calls))
+
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b was read least recently")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+}
+
+func TestLRUExpiresAfterTTL(t *testing.T) {
+	c, clk := newTestLRU(10, time.Minute)
+	calls := 0
+
+	v, err := c.Load("a", value(1, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	clk.now = clk.now.Add(time.Minute - time.Second)
+	v, err = c.Load("a", value(2, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "still fresh")
+	assert.Equal(t, 1, calls)
+
+	clk.now = clk.now.Add(time.Second)
+	v, err = c.Load("a", value(2, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "expired exactly at ttl")
+	assert.Equal(t, 2, calls)
+}
+
+func TestLRULoadErrorNotCached(t *testing.T) {
+	c, _ := newTestLRU(10, time.Minute)
+	errLoad := errors.New("db down")
+
+	_, err := c.Load("a", func() (int, error) { return 0, errLoad })
+	require.ErrorIs(t, err, errLoad)
+	assert.Zero(t, c.Len())
+
+	calls := 0
+	v, err := c.Load("a", value(1, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLRUPurge(t *testing.T) {
+	c, _ := newTestLRU(10, time.Minute)
+	calls := 0
+	_, _ = c.Load("a", value(1, &calls))
+	_, _ = c.Load("b", value(2, &calls))
+
+	c.Purge()
+
+	assert.Zero(t, c.Len())
+	v, err := c.Load("a", value(10, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 10, v, "the value loaded after Purge is cached again")
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+}
+
+func TestLRULoadRacingPurgeIsNotStored(t *testing.T) {
+	c, _ := newTestLRU(10, time.Minute)
+	started, release := make(chan struct{}), make(chan struct{})
+	result := make(chan int)
+
+	// Загрузка прочитала старое значение, и до ее завершения данные изменились и кэш очищен
+	go func() {
+		v, _ := c.Load("a", func() (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		result <- v
+	}()
+	<-started
+	c.Purge()
+	close(release)
+
+	assert.Equal(t, 1, <-result, "the caller still gets its own read")
+	_, ok := c.Get("a")
+	assert.False(t, ok, "a value read before Purge must not be cached")
+
+	calls := 0
+	v, err := c.Load("a", value(2, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLRUNoStaleReadUnderConcurrentPurge(t *testing.T) {
+	c := New[int, uint64](4, time.Minute)
+
+	// version — версия данных в «БД», purged — последняя версия, после изменения до которой кэш очищен.
+	// Запись меняет данные и затем очищает кэш, как Repository.invalidateCache после фиксации.
+	var version, purged atomic.Uint64
+	stop := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			v := version.Add(1)
+			c.Purge()
+			purged.Store(v)
+			runtime.Gosched()
+		}
+	}()
+
+	var readers sync.WaitGroup
+	for r := range 8 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for i := range 500 {
+				key := (r + i) % 6
+				before := purged.Load()
+				got, err := c.Load(key, func() (uint64, error) {
+					v := version.Load()
+					runtime.Gosched() // окно между чтением из «БД» и сохранением в кэш
+					return v, nil
+				})
+				if !assert.NoError(t, err) {
+					return
+				}
+				// Значение, прочитанное до изменения, уже очищенного из кэша, — устаревшее чтение
+				if !assert.GreaterOrEqual(t, got, before, "stale read for key %d", key) {
+					return
+				}
+			}
+		}()
+	}
+	readers.Wait()
+	close(stop)
+	writer.Wait()
+}
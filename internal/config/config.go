@@ -2,80 +2,863 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Logger   LoggerConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Logger      LoggerConfig
+	IDs         IDConfig
+	Assignment  AssignmentConfig
+	Merge       MergeConfig
+	Stats       StatsConfig
+	Tracing     TracingConfig
+	Idempotency IdempotencyConfig
+	RateLimit   RateLimitConfig
+	CORS        CORSConfig
+	Webhooks    WebhooksConfig
+	Slack       SlackConfig
+	Reminders   RemindersConfig
+	OrphanSweep OrphanSweepConfig
+	Cache       CacheConfig
 }
 
 type DatabaseConfig struct {
+	// URL — полный DSN из DATABASE_URL; если задан, отдельные DB_* параметры подключения не используются
+	URL string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
-	Name     string
-	SSLMode  string
+	// PasswordFile — путь к файлу с паролем (DB_PASSWORD_FILE); содержимое без пробельных символов
+	// по краям заменяет Password
+	PasswordFile string
+	Name         string
+	SSLMode      string
+	// SSLRootCert, SSLCert и SSLKey — пути к CA-сертификату, клиентскому сертификату и ключу,
+	// передаются в DSN как sslrootcert, sslcert и sslkey
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	// ReadURL — полный DSN реплики для чистого чтения (DB_READ_URL); важнее ReadHost и ReadPort
+	ReadURL string
+	// ReadHost и ReadPort — адрес реплики; остальные параметры подключения берутся у основной БД.
+	// Пустой ReadPort означает порт основной БД.
+	ReadHost string
+	ReadPort string
+	// QueryExecMode — режим выполнения запросов pgx (кэширование prepared statements)
+	QueryExecMode string
+	// Warmup включает прогрев самых частых запросов при старте
+	Warmup bool
+
+	// Настройки пула соединений pgxpool
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// ConnectTimeout ограничивает установку соединения, 0 — без ограничения
+	ConnectTimeout time.Duration
+	// StartupTimeout — сколько при старте ждать доступности БД, повторяя проверку соединения;
+	// 0 — одна попытка. Задается DB_STARTUP_TIMEOUT, а не DB_CONNECT_TIMEOUT: та уже ограничивает
+	// одну попытку соединения (ConnectTimeout)
+	StartupTimeout time.Duration
+	// SlowQueryThreshold — запросы дольше этого порога пишутся в лог на уровне Warn, 0 — выключено
+	SlowQueryThreshold time.Duration
 }
 
+// Режимы выполнения запросов pgx
+const (
+	QueryExecModeCacheStatement = "cache_statement"
+	QueryExecModeCacheDescribe  = "cache_describe"
+	QueryExecModeDescribeExec   = "describe_exec"
+	QueryExecModeExec           = "exec"
+	QueryExecModeSimpleProtocol = "simple_protocol"
+)
+
 type ServerConfig struct {
 	Host string
 	Port string
+	// MetricsPort — порт отдельного сервера для /metrics; пустой — /metrics на основном порту
+	MetricsPort string
+	// EnablePprof включает обработчики /debug/pprof/ на отдельном порту DebugPort
+	EnablePprof bool
+	DebugPort   string
+	// EnableDocs включает Swagger UI на /docs; спецификация /openapi.json отдается всегда
+	EnableDocs bool
+	// EnableGRPC запускает gRPC API на отдельном порту GRPCPort
+	EnableGRPC bool
+	GRPCPort   string
+
+	// Таймауты и лимиты HTTP-сервера
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	MaxHeaderBytes    int
+	// MaxRequestBodySize — максимальный размер тела запроса в байтах
+	MaxRequestBodySize int64
+	// ShutdownTimeout — сколько ждать завершения активных запросов при остановке
+	ShutdownTimeout time.Duration
+	// LegacySunset — дата отключения прежних путей без версии для заголовка Sunset; нулевая — без заголовка
+	LegacySunset time.Time
+	// AdminAPIKeys — ключи X-API-Key с доступом к /admin/*; пустой список закрывает /admin/* для всех
+	AdminAPIKeys []string
 }
 
 type LoggerConfig struct {
 	Level  string
 	Format string
+	// Output: stdout, stderr или путь к файлу (с ротацией)
+	Output string
+	// Параметры ротации файла логов
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// Специальные значения LOG_OUTPUT
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+)
+
+// Режимы нормализации внешних ID пользователей
+const (
+	IDNormalizationStrict = "strict"
+	IDNormalizationFold   = "fold"
+)
+
+// Стратегии выбора ревьюеров
+const (
+	AssignmentStrategyRandom      = "random"
+	AssignmentStrategyLeastLoaded = "least_loaded"
+	AssignmentStrategyRoundRobin  = "round_robin"
+)
+
+type AssignmentConfig struct {
+	// Strategy: least_loaded — наименее загруженные открытыми ревью, random — случайные,
+	// round_robin — по кругу внутри команды
+	Strategy string
+	// CooldownPRs — ревьюеры последних K PR автора идут в конце очереди (0 — выключено)
+	CooldownPRs int
+	// MinAssignmentAge — автоматические переназначения не трогают более молодые назначения (0 — выключено)
+	MinAssignmentAge time.Duration
+	// RejectAmbiguousTeam — автор из нескольких команд должен указывать team_name при создании PR,
+	// иначе выбирается команда, созданная раньше остальных
+	RejectAmbiguousTeam bool
+	// AllowZeroReviewers — создавать PR, даже если не нашлось ни одного ревьюера (ответ содержит предупреждение);
+	// при false такой PR отклоняется с 409 NO_CANDIDATE
+	AllowZeroReviewers bool
+	// FallbackToOrgPool — добирать ревьюеров из FallbackTeam, если в команде PR не хватает кандидатов
+	FallbackToOrgPool bool
+	// FallbackTeam — имя резервной команды
+	FallbackTeam string
+	// MaxReviewers — сколько ревьюеров можно назначить на PR через /pullRequest/addReviewer
+	MaxReviewers int
+}
+
+// FallbackTeamName возвращает резервную команду или пустую строку, если добор выключен
+func (c *AssignmentConfig) FallbackTeamName() string {
+	if !c.FallbackToOrgPool {
+		return ""
+	}
+	return c.FallbackTeam
+}
+
+type MergeConfig struct {
+	// RequireApprovals — сколько одобрений ревьюеров нужно для слияния открытого PR (0 — без проверки)
+	RequireApprovals int
+}
+
+type StatsConfig struct {
+	// SnapshotInterval — период снимков загрузки ревьюеров, 0 отключает воркер
+	SnapshotInterval time.Duration
+	// HistoryRetention — срок хранения снимков, 0 отключает удаление
+	HistoryRetention time.Duration
+}
+
+type IdempotencyConfig struct {
+	// KeyTTL — сколько хранится ответ по ключу Idempotency-Key
+	KeyTTL time.Duration
+	// Lease — сколько действует резерв ключа незавершенным запросом; после него ключ можно занять снова
+	Lease time.Duration
+	// CleanupInterval — период удаления истекших ключей, 0 отключает воркер
+	CleanupInterval time.Duration
+}
+
+type RateLimitConfig struct {
+	// RPS — сколько запросов в секунду разрешено одному клиенту, 0 отключает ограничение
+	RPS float64
+	// Burst — сколько запросов клиент может сделать сверх RPS одномоментно
+	Burst int
+	// APIKeys — известные ключи X-API-Key, получающие отдельную корзину; остальные клиенты считаются по IP
+	APIKeys []string
+}
+
+// Enabled сообщает, включено ли ограничение частоты запросов
+func (c *RateLimitConfig) Enabled() bool {
+	return c.RPS > 0
+}
+
+type CORSConfig struct {
+	// AllowedOrigins — разрешенные источники; пустой список отключает CORS
+	AllowedOrigins []string
+	// AllowedMethods — методы, разрешенные в ответе на preflight
+	AllowedMethods []string
+	// AllowCredentials разрешает запросы с cookie и заголовком Authorization
+	AllowCredentials bool
+}
+
+// Enabled сообщает, нужно ли отдавать CORS-заголовки
+func (c *CORSConfig) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+type WebhooksConfig struct {
+	// GitHubSecret — секрет подписи вебхуков GitHub (X-Hub-Signature-256); пустой отключает прием
+	GitHubSecret string
+	// GitLabSecret — секрет вебхуков GitLab (X-Gitlab-Token); пустой отключает прием
+	GitLabSecret string
+
+	// QueueSize — размер очереди событий исходящих вебхуков; при заполнении события отбрасываются
+	QueueSize int
+	// Workers — число горутин доставки исходящих вебхуков
+	Workers int
+	// MaxAttempts — сколько раз пытаться доставить событие одному подписчику
+	MaxAttempts int
+	// RetryBackoff — пауза перед первым повтором, далее удваивается
+	RetryBackoff time.Duration
+	// Timeout — таймаут одного запроса к подписчику
+	Timeout time.Duration
+	// HistoryRetention — срок хранения истории доставок, 0 отключает удаление
+	HistoryRetention time.Duration
 }
 
-// Load загружает конфигурацию ТОЛЬКО из переменных окружения
+type SlackConfig struct {
+	// WebhookURL — incoming webhook Slack для уведомлений о назначении ревьюеров; пустой отключает уведомления
+	WebhookURL string
+	// DryRun — писать сообщения в лог вместо отправки (включает уведомления и без WebhookURL)
+	DryRun bool
+}
+
+// Enabled сообщает, нужно ли формировать уведомления в Slack
+func (c *SlackConfig) Enabled() bool {
+	return c.WebhookURL != "" || c.DryRun
+}
+
+type RemindersConfig struct {
+	// Enabled включает фоновые напоминания о давних неодобренных назначениях
+	Enabled bool
+	// Interval — период проверки назначений
+	Interval time.Duration
+	// After — через сколько после назначения напоминать ревьюеру
+	After time.Duration
+}
+
+type OrphanSweepConfig struct {
+	// Enabled включает фоновое переназначение ревью, зависших на давно деактивированных пользователях
+	Enabled bool
+	// Interval — период запуска очистки
+	Interval time.Duration
+	// InactiveAfter — через сколько после деактивации ревью пользователя переназначаются
+	InactiveAfter time.Duration
+	// MaxReassignments — сколько ревью обрабатывается за один запуск
+	MaxReassignments int
+}
+
+type CacheConfig struct {
+	// TTL — время жизни записей кэша чтения команд и пользователей; 0 выключает кэш
+	TTL time.Duration
+}
+
+type TracingConfig struct {
+	// Endpoint — адрес OTLP/HTTP коллектора; пустой отключает трассировку
+	Endpoint string
+	// SampleRatio — доля трассируемых корневых запросов от 0 до 1
+	SampleRatio float64
+}
+
+// Enabled сообщает, нужно ли экспортировать трассы
+func (c *TracingConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+type IDConfig struct {
+	// Normalization: strict — ID сравниваются как есть,
+	// fold — ID обрезаются по пробелам и приводятся к нижнему регистру
+	Normalization string
+}
+
+// Load загружает конфигурацию из переменных окружения и, если задан CONFIG_FILE, из YAML-файла.
+// Переменные окружения всегда важнее значений из файла; валидация общая для обоих источников.
 func Load() (*Config, error) {
+	env, err := loadSource(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			Name:     getEnv("DB_NAME", "pr_manager_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			URL: env.get("DATABASE_URL", ""),
+
+			Host:     env.get("DB_HOST", "localhost"),
+			Port:     env.get("DB_PORT", "5432"),
+			User:     env.get("DB_USER", "postgres"),
+			Password: env.get("DB_PASSWORD", "postgres"),
+			Name:     env.get("DB_NAME", "pr_manager_db"),
+			SSLMode:  env.get("DB_SSLMODE", "disable"),
+
+			PasswordFile: env.get("DB_PASSWORD_FILE", ""),
+			SSLRootCert:  env.get("DB_SSLROOTCERT", ""),
+			SSLCert:      env.get("DB_SSLCERT", ""),
+			SSLKey:       env.get("DB_SSLKEY", ""),
+
+			ReadURL:  env.get("DB_READ_URL", ""),
+			ReadHost: env.get("DB_READ_HOST", ""),
+			ReadPort: env.get("DB_READ_PORT", ""),
+
+			QueryExecMode: env.get("DB_QUERY_EXEC_MODE", QueryExecModeCacheStatement),
+			Warmup:        env.get("WARMUP", "false") == "true",
 		},
 		Server: ServerConfig{
-			Host: getEnv("APP_HOST", "0.0.0.0"),
-			Port: getEnv("APP_PORT", "9000"),
+			Host: env.get("APP_HOST", "0.0.0.0"),
+			Port: env.get("APP_PORT", "9000"),
+
+			MetricsPort: env.get("METRICS_PORT", ""),
+			EnablePprof: env.get("ENABLE_PPROF", "false") == "true",
+			DebugPort:   env.get("DEBUG_PORT", "6060"),
+			EnableDocs:  env.get("DOCS_ENABLED", "false") == "true",
+			EnableGRPC:  env.get("GRPC_ENABLED", "false") == "true",
+			GRPCPort:    env.get("GRPC_PORT", "9090"),
 		},
 		Logger: LoggerConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:  env.get("LOG_LEVEL", "info"),
+			Format: env.get("LOG_FORMAT", "json"),
+			Output: env.get("LOG_OUTPUT", LogOutputStdout),
 		},
+		IDs: IDConfig{
+			Normalization: env.get("ID_NORMALIZATION", IDNormalizationStrict),
+		},
+		Assignment: AssignmentConfig{
+			Strategy:            env.get("ASSIGNMENT_STRATEGY", AssignmentStrategyLeastLoaded),
+			RejectAmbiguousTeam: env.get("REJECT_AMBIGUOUS_TEAM", "false") == "true",
+			AllowZeroReviewers:  env.get("ALLOW_ZERO_REVIEWERS", "true") == "true",
+			FallbackToOrgPool:   env.get("FALLBACK_TO_ORG_POOL", "false") == "true",
+			FallbackTeam:        env.get("FALLBACK_TEAM_NAME", ""),
+		},
+		Tracing: TracingConfig{
+			Endpoint: env.get("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		},
+		Webhooks: WebhooksConfig{
+			GitHubSecret: env.get("GITHUB_WEBHOOK_SECRET", ""),
+			GitLabSecret: env.get("GITLAB_WEBHOOK_SECRET", ""),
+		},
+		Slack: SlackConfig{
+			WebhookURL: env.get("SLACK_WEBHOOK_URL", ""),
+			DryRun:     env.get("SLACK_DRY_RUN", "false") == "true",
+		},
+		Reminders: RemindersConfig{
+			Enabled: env.get("REMINDERS_ENABLED", "false") == "true",
+		},
+		OrphanSweep: OrphanSweepConfig{
+			Enabled: env.get("ORPHAN_SWEEP_ENABLED", "false") == "true",
+		},
+	}
+
+	if cfg.Logger.Output == "" {
+		return nil, fmt.Errorf("invalid LOG_OUTPUT: must be %q, %q or a file path", LogOutputStdout, LogOutputStderr)
+	}
+	logLimits := []struct {
+		key   string
+		def   string
+		value *int
+	}{
+		{"LOG_FILE_MAX_SIZE_MB", "100", &cfg.Logger.MaxSizeMB},
+		{"LOG_FILE_MAX_BACKUPS", "5", &cfg.Logger.MaxBackups},
+		{"LOG_FILE_MAX_AGE_DAYS", "30", &cfg.Logger.MaxAgeDays},
+	}
+	for _, limit := range logLimits {
+		v, err := strconv.Atoi(env.get(limit.key, limit.def))
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s: must be a non-negative integer", limit.key)
+		}
+		*limit.value = v
 	}
 
-	// Валидация критически важных параметров
-	if cfg.Database.Host == "" || cfg.Database.Name == "" {
-		return nil, fmt.Errorf("critical database config missing: DB_HOST or DB_NAME not set")
+	snapshotInterval, err := time.ParseDuration(env.get("LOAD_SNAPSHOT_INTERVAL", "24h"))
+	if err != nil || snapshotInterval < 0 {
+		return nil, fmt.Errorf("invalid LOAD_SNAPSHOT_INTERVAL: must be a non-negative duration")
+	}
+	cfg.Stats.SnapshotInterval = snapshotInterval
+
+	serverDurations := []struct {
+		key   string
+		def   string
+		value *time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", "10s", &cfg.Server.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", "10s", &cfg.Server.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", "60s", &cfg.Server.IdleTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", "5s", &cfg.Server.ReadHeaderTimeout},
+		{"SHUTDOWN_TIMEOUT", "10s", &cfg.Server.ShutdownTimeout},
+	}
+	for _, d := range serverDurations {
+		v, err := time.ParseDuration(env.get(d.key, d.def))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid %s: must be a positive duration", d.key)
+		}
+		*d.value = v
+	}
+
+	if sunset := env.get("LEGACY_API_SUNSET", "2026-12-31"); sunset != "" {
+		cfg.Server.LegacySunset, err = time.Parse(time.DateOnly, sunset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEGACY_API_SUNSET: must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	cfg.Server.AdminAPIKeys = splitList(env.get("ADMIN_API_KEYS", ""))
+
+	maxHeaderBytes, err := strconv.Atoi(env.get("HTTP_MAX_HEADER_BYTES", "1048576"))
+	if err != nil || maxHeaderBytes <= 0 {
+		return nil, fmt.Errorf("invalid HTTP_MAX_HEADER_BYTES: must be a positive integer")
+	}
+	cfg.Server.MaxHeaderBytes = maxHeaderBytes
+
+	maxBodyBytes, err := strconv.ParseInt(env.get("HTTP_MAX_BODY_BYTES", "1048576"), 10, 64)
+	if err != nil || maxBodyBytes <= 0 {
+		return nil, fmt.Errorf("invalid HTTP_MAX_BODY_BYTES: must be a positive integer")
+	}
+	cfg.Server.MaxRequestBodySize = maxBodyBytes
+
+	poolSizes := []struct {
+		key   string
+		def   string
+		value *int32
+	}{
+		{"DB_MAX_CONNS", "25", &cfg.Database.MaxConns},
+		{"DB_MIN_CONNS", "5", &cfg.Database.MinConns},
+	}
+	for _, size := range poolSizes {
+		v, err := strconv.ParseInt(env.get(size.key, size.def), 10, 32)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s: must be a non-negative integer", size.key)
+		}
+		*size.value = int32(v)
+	}
+	if cfg.Database.MaxConns < 1 {
+		return nil, fmt.Errorf("invalid DB_MAX_CONNS: must be at least 1")
+	}
+	if cfg.Database.MinConns > cfg.Database.MaxConns {
+		return nil, fmt.Errorf("invalid DB_MIN_CONNS: must not exceed DB_MAX_CONNS (%d > %d)",
+			cfg.Database.MinConns, cfg.Database.MaxConns)
+	}
+
+	poolDurations := []struct {
+		key   string
+		def   string
+		value *time.Duration
+	}{
+		{"DB_MAX_CONN_LIFETIME", "1h", &cfg.Database.MaxConnLifetime},
+		{"DB_MAX_CONN_IDLE_TIME", "30m", &cfg.Database.MaxConnIdleTime},
+		{"DB_HEALTH_CHECK_PERIOD", "1m", &cfg.Database.HealthCheckPeriod},
+		{"DB_CONNECT_TIMEOUT", "0s", &cfg.Database.ConnectTimeout},
+		{"DB_STARTUP_TIMEOUT", "30s", &cfg.Database.StartupTimeout},
+	}
+	for _, d := range poolDurations {
+		v, err := time.ParseDuration(env.get(d.key, d.def))
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s: must be a non-negative duration", d.key)
+		}
+		*d.value = v
+	}
+	if cfg.Database.HealthCheckPeriod == 0 {
+		return nil, fmt.Errorf("invalid DB_HEALTH_CHECK_PERIOD: must be a positive duration")
+	}
+
+	slowQueryMS, err := strconv.Atoi(env.get("SLOW_QUERY_MS", "200"))
+	if err != nil || slowQueryMS < 0 {
+		return nil, fmt.Errorf("invalid SLOW_QUERY_MS: must be a non-negative integer")
+	}
+	cfg.Database.SlowQueryThreshold = time.Duration(slowQueryMS) * time.Millisecond
+
+	idempotencyTTL, err := time.ParseDuration(env.get("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil || idempotencyTTL <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL: must be a positive duration")
+	}
+	cfg.Idempotency.KeyTTL = idempotencyTTL
+
+	idempotencyLease, err := time.ParseDuration(env.get("IDEMPOTENCY_LEASE", "1m"))
+	if err != nil || idempotencyLease <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_LEASE: must be a positive duration")
+	}
+	cfg.Idempotency.Lease = idempotencyLease
+
+	idempotencyCleanup, err := time.ParseDuration(env.get("IDEMPOTENCY_CLEANUP_INTERVAL", "1h"))
+	if err != nil || idempotencyCleanup < 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_CLEANUP_INTERVAL: must be a non-negative duration")
+	}
+	cfg.Idempotency.CleanupInterval = idempotencyCleanup
+
+	retentionDays, err := strconv.Atoi(env.get("LOAD_HISTORY_RETENTION_DAYS", "90"))
+	if err != nil || retentionDays < 0 {
+		return nil, fmt.Errorf("invalid LOAD_HISTORY_RETENTION_DAYS: must be a non-negative integer")
+	}
+	cfg.Stats.HistoryRetention = time.Duration(retentionDays) * 24 * time.Hour
+
+	cooldownPRs, err := strconv.Atoi(env.get("ASSIGNMENT_COOLDOWN_PRS", "0"))
+	if err != nil || cooldownPRs < 0 {
+		return nil, fmt.Errorf("invalid ASSIGNMENT_COOLDOWN_PRS: must be a non-negative integer")
+	}
+	cfg.Assignment.CooldownPRs = cooldownPRs
+
+	minAssignmentAgeHours, err := strconv.Atoi(env.get("MIN_ASSIGNMENT_AGE_HOURS", "0"))
+	if err != nil || minAssignmentAgeHours < 0 {
+		return nil, fmt.Errorf("invalid MIN_ASSIGNMENT_AGE_HOURS: must be a non-negative integer")
+	}
+	cfg.Assignment.MinAssignmentAge = time.Duration(minAssignmentAgeHours) * time.Hour
+
+	maxReviewers, err := strconv.Atoi(env.get("MAX_REVIEWERS_PER_PR", "5"))
+	if err != nil || maxReviewers < 2 {
+		return nil, fmt.Errorf("invalid MAX_REVIEWERS_PER_PR: must be an integer not less than 2")
+	}
+	cfg.Assignment.MaxReviewers = maxReviewers
+
+	if cfg.Assignment.FallbackToOrgPool && cfg.Assignment.FallbackTeam == "" {
+		return nil, fmt.Errorf("invalid FALLBACK_TEAM_NAME: required when FALLBACK_TO_ORG_POOL is true")
+	}
+
+	requireApprovals, err := strconv.Atoi(env.get("REQUIRE_APPROVALS", "0"))
+	if err != nil || requireApprovals < 0 {
+		return nil, fmt.Errorf("invalid REQUIRE_APPROVALS: must be a non-negative integer")
+	}
+	cfg.Merge.RequireApprovals = requireApprovals
+
+	reminderDurations := []struct {
+		key   string
+		def   string
+		value *time.Duration
+	}{
+		{"REMINDER_INTERVAL", "15m", &cfg.Reminders.Interval},
+		{"REMINDER_AFTER", "24h", &cfg.Reminders.After},
+	}
+	for _, d := range reminderDurations {
+		v, err := time.ParseDuration(env.get(d.key, d.def))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid %s: must be a positive duration", d.key)
+		}
+		*d.value = v
+	}
+
+	orphanSweepInterval, err := time.ParseDuration(env.get("ORPHAN_SWEEP_INTERVAL", "15m"))
+	if err != nil || orphanSweepInterval <= 0 {
+		return nil, fmt.Errorf("invalid ORPHAN_SWEEP_INTERVAL: must be a positive duration")
+	}
+	cfg.OrphanSweep.Interval = orphanSweepInterval
+
+	orphanInactiveHours, err := strconv.Atoi(env.get("ORPHAN_SWEEP_INACTIVE_HOURS", "24"))
+	if err != nil || orphanInactiveHours < 0 {
+		return nil, fmt.Errorf("invalid ORPHAN_SWEEP_INACTIVE_HOURS: must be a non-negative integer")
+	}
+	cfg.OrphanSweep.InactiveAfter = time.Duration(orphanInactiveHours) * time.Hour
+
+	orphanMaxReassignments, err := strconv.Atoi(env.get("ORPHAN_SWEEP_MAX_REASSIGNMENTS", "100"))
+	if err != nil || orphanMaxReassignments < 1 {
+		return nil, fmt.Errorf("invalid ORPHAN_SWEEP_MAX_REASSIGNMENTS: must be a positive integer")
+	}
+	cfg.OrphanSweep.MaxReassignments = orphanMaxReassignments
+
+	cacheTTL, err := time.ParseDuration(env.get("CACHE_TTL", "30s"))
+	if err != nil || cacheTTL < 0 {
+		return nil, fmt.Errorf("invalid CACHE_TTL: must be a non-negative duration")
+	}
+	cfg.Cache.TTL = cacheTTL
+
+	rateLimitRPS, err := strconv.ParseFloat(env.get("RATE_LIMIT_RPS", "0"), 64)
+	if err != nil || rateLimitRPS < 0 || math.IsNaN(rateLimitRPS) || math.IsInf(rateLimitRPS, 0) {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_RPS: must be a non-negative number")
+	}
+	cfg.RateLimit.RPS = rateLimitRPS
+
+	rateLimitBurst, err := strconv.Atoi(env.get("RATE_LIMIT_BURST", "20"))
+	if err != nil || rateLimitBurst < 1 {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_BURST: must be a positive integer")
+	}
+	cfg.RateLimit.Burst = rateLimitBurst
+	cfg.RateLimit.APIKeys = splitList(env.get("RATE_LIMIT_API_KEYS", ""))
+
+	webhookLimits := []struct {
+		key   string
+		def   string
+		value *int
+	}{
+		{"WEBHOOK_QUEUE_SIZE", "1000", &cfg.Webhooks.QueueSize},
+		{"WEBHOOK_WORKERS", "2", &cfg.Webhooks.Workers},
+		{"WEBHOOK_MAX_ATTEMPTS", "5", &cfg.Webhooks.MaxAttempts},
+	}
+	for _, limit := range webhookLimits {
+		v, err := strconv.Atoi(env.get(limit.key, limit.def))
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid %s: must be a positive integer", limit.key)
+		}
+		*limit.value = v
+	}
+
+	webhookDurations := []struct {
+		key   string
+		def   string
+		value *time.Duration
+	}{
+		{"WEBHOOK_RETRY_BACKOFF", "1s", &cfg.Webhooks.RetryBackoff},
+		{"WEBHOOK_TIMEOUT", "5s", &cfg.Webhooks.Timeout},
+	}
+	for _, d := range webhookDurations {
+		v, err := time.ParseDuration(env.get(d.key, d.def))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid %s: must be a positive duration", d.key)
+		}
+		*d.value = v
+	}
+
+	webhookRetentionDays, err := strconv.Atoi(env.get("WEBHOOK_HISTORY_RETENTION_DAYS", "30"))
+	if err != nil || webhookRetentionDays < 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_HISTORY_RETENTION_DAYS: must be a non-negative integer")
+	}
+	cfg.Webhooks.HistoryRetention = time.Duration(webhookRetentionDays) * 24 * time.Hour
+
+	if webhookURL := cfg.Slack.WebhookURL; webhookURL != "" && !strings.HasPrefix(webhookURL, "https://") && !strings.HasPrefix(webhookURL, "http://") {
+		return nil, fmt.Errorf("invalid SLACK_WEBHOOK_URL: must be an http or https URL")
+	}
+
+	cfg.CORS.AllowedOrigins = splitList(env.get("CORS_ALLOWED_ORIGINS", ""))
+	cfg.CORS.AllowedMethods = splitList(env.get("CORS_ALLOWED_METHODS", "GET,POST"))
+	cfg.CORS.AllowCredentials = env.get("CORS_ALLOW_CREDENTIALS", "false") == "true"
+	if cfg.CORS.Enabled() && len(cfg.CORS.AllowedMethods) == 0 {
+		return nil, fmt.Errorf("invalid CORS_ALLOWED_METHODS: must not be empty when CORS_ALLOWED_ORIGINS is set")
+	}
+	for _, method := range cfg.CORS.AllowedMethods {
+		if !corsMethods[method] {
+			return nil, fmt.Errorf("invalid CORS_ALLOWED_METHODS: unsupported method %q", method)
+		}
+	}
+	if cfg.CORS.AllowCredentials && slices.Contains(cfg.CORS.AllowedOrigins, "*") {
+		return nil, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: credentials cannot be allowed for wildcard origin \"*\"")
+	}
+
+	sampleRatio, err := strconv.ParseFloat(env.get("OTEL_TRACES_SAMPLER_ARG", "1"), 64)
+	if err != nil || sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG: must be a number between 0 and 1")
+	}
+	cfg.Tracing.SampleRatio = sampleRatio
+
+	if port := cfg.Server.MetricsPort; port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			return nil, fmt.Errorf("invalid METRICS_PORT %q: must be a port number", port)
+		}
+		if port == cfg.Server.Port {
+			return nil, fmt.Errorf("invalid METRICS_PORT %q: must differ from APP_PORT", port)
+		}
+	}
+
+	if cfg.Server.EnablePprof {
+		port := cfg.Server.DebugPort
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			return nil, fmt.Errorf("invalid DEBUG_PORT %q: must be a port number", port)
+		}
+		if port == cfg.Server.Port || port == cfg.Server.MetricsPort {
+			return nil, fmt.Errorf("invalid DEBUG_PORT %q: must differ from APP_PORT and METRICS_PORT", port)
+		}
+	}
+
+	if cfg.Server.EnableGRPC {
+		port := cfg.Server.GRPCPort
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			return nil, fmt.Errorf("invalid GRPC_PORT %q: must be a port number", port)
+		}
+		if port == cfg.Server.Port || port == cfg.Server.MetricsPort || (cfg.Server.EnablePprof && port == cfg.Server.DebugPort) {
+			return nil, fmt.Errorf("invalid GRPC_PORT %q: must differ from APP_PORT, METRICS_PORT and DEBUG_PORT", port)
+		}
+	}
+
+	// Валидация критически важных параметров: DATABASE_URL важнее отдельных DB_*
+	if cfg.Database.URL != "" {
+		if _, err := pgxpool.ParseConfig(cfg.Database.URL); err != nil {
+			return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+	} else {
+		if cfg.Database.Host == "" || cfg.Database.Name == "" {
+			return nil, fmt.Errorf("critical database config missing: DB_HOST or DB_NAME not set")
+		}
+		if err := cfg.Database.loadFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Реплика для чтения: DB_READ_URL важнее DB_READ_HOST/DB_READ_PORT
+	if cfg.Database.ReadURL != "" {
+		if _, err := pgxpool.ParseConfig(cfg.Database.ReadURL); err != nil {
+			return nil, fmt.Errorf("invalid DB_READ_URL: %w", err)
+		}
+	} else if cfg.Database.ReadHost != "" {
+		if cfg.Database.URL != "" {
+			return nil, fmt.Errorf("invalid DB_READ_HOST: not supported with DATABASE_URL, set DB_READ_URL instead")
+		}
+	} else if cfg.Database.ReadPort != "" {
+		return nil, fmt.Errorf("invalid DB_READ_PORT: requires DB_READ_HOST")
+	}
+
+	switch cfg.Database.QueryExecMode {
+	case QueryExecModeCacheStatement, QueryExecModeCacheDescribe, QueryExecModeDescribeExec,
+		QueryExecModeExec, QueryExecModeSimpleProtocol:
+	default:
+		return nil, fmt.Errorf("invalid DB_QUERY_EXEC_MODE %q", cfg.Database.QueryExecMode)
+	}
+
+	if cfg.IDs.Normalization != IDNormalizationStrict && cfg.IDs.Normalization != IDNormalizationFold {
+		return nil, fmt.Errorf("invalid ID_NORMALIZATION %q: must be %q or %q",
+			cfg.IDs.Normalization, IDNormalizationStrict, IDNormalizationFold)
+	}
+
+	switch cfg.Assignment.Strategy {
+	case AssignmentStrategyRandom, AssignmentStrategyLeastLoaded, AssignmentStrategyRoundRobin:
+	default:
+		return nil, fmt.Errorf("invalid ASSIGNMENT_STRATEGY %q: must be %q, %q or %q",
+			cfg.Assignment.Strategy, AssignmentStrategyLeastLoaded, AssignmentStrategyRandom, AssignmentStrategyRoundRobin)
 	}
 
 	return cfg, nil
 }
 
-// getEnv возвращает значение переменной окружения или значение по умолчанию
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// corsMethods — методы, допустимые в CORS_ALLOWED_METHODS
+var corsMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// splitList разбирает список через запятую, отбрасывая пробелы и пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return defaultValue
+	return items
 }
 
-// GetDSN возвращает строку подключения к PostgreSQL
+// GetDSN возвращает строку подключения к PostgreSQL: DATABASE_URL без изменений, если он задан,
+// иначе DSN, собранный из отдельных DB_* параметров
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf(
+	if c.URL != "" {
+		return c.URL
+	}
+	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
+		dsnValue(c.Host), dsnValue(c.Port), dsnValue(c.User), dsnValue(c.Password), dsnValue(c.Name), dsnValue(c.SSLMode),
 	)
+	for _, param := range []struct{ key, value string }{
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+	} {
+		if param.value != "" {
+			dsn += " " + param.key + "=" + dsnValue(param.value)
+		}
+	}
+	return dsn
+}
+
+// ReadReplica возвращает настройки подключения к реплике для чистого чтения и true, если реплика задана.
+// Настройки пула и режим запросов совпадают с основной БД.
+func (c DatabaseConfig) ReadReplica() (DatabaseConfig, bool) {
+	switch {
+	case c.ReadURL != "":
+		c.URL = c.ReadURL
+	case c.ReadHost != "":
+		c.Host = c.ReadHost
+		if c.ReadPort != "" {
+			c.Port = c.ReadPort
+		}
+	default:
+		return DatabaseConfig{}, false
+	}
+	c.ReadURL, c.ReadHost, c.ReadPort = "", "", ""
+	return c, true
+}
+
+// dsnValue экранирует значение DSN формата key=value: пустые значения и значения с пробелами,
+// кавычками или обратной косой чертой заключаются в одинарные кавычки
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// loadFiles читает пароль из PasswordFile и проверяет, что файлы сертификатов доступны для чтения,
+// чтобы ошибка в пути останавливала запуск сразу, а не при первом соединении с БД
+func (c *DatabaseConfig) loadFiles() error {
+	if c.PasswordFile != "" {
+		data, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read DB_PASSWORD_FILE: %w", err)
+		}
+		c.Password = strings.TrimSpace(string(data))
+	}
+
+	if (c.SSLCert == "") != (c.SSLKey == "") {
+		return fmt.Errorf("invalid DB_SSLCERT and DB_SSLKEY: must be set together")
+	}
+	for _, file := range []struct{ name, path string }{
+		{"DB_SSLROOTCERT", c.SSLRootCert},
+		{"DB_SSLCERT", c.SSLCert},
+		{"DB_SSLKEY", c.SSLKey},
+	} {
+		if file.path == "" {
+			continue
+		}
+		f, err := os.Open(file.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.name, err)
+		}
+		f.Close()
+	}
+	return nil
 }
 
 // GetAddress возвращает адрес сервера в формате host:port
 func (c *ServerConfig) GetAddress() string {
 	return fmt.Sprintf("%s:%s", c.Host, c.Port)
 }
+
+// GetMetricsAddress возвращает адрес отдельного сервера метрик в формате host:port
+func (c *ServerConfig) GetMetricsAddress() string {
+	return fmt.Sprintf("%s:%s", c.Host, c.MetricsPort)
+}
+
+// GetDebugAddress возвращает адрес отладочного сервера pprof в формате host:port
+func (c *ServerConfig) GetDebugAddress() string {
+	return fmt.Sprintf("%s:%s", c.Host, c.DebugPort)
+}
+
+// GetGRPCAddress возвращает адрес gRPC-сервера в формате host:port
+func (c *ServerConfig) GetGRPCAddress() string {
+	return fmt.Sprintf("%s:%s", c.Host, c.GRPCPort)
+}
+
+// FoldIDs сообщает, включена ли нормализация внешних ID
+func (c *IDConfig) FoldIDs() bool {
+	return c.Normalization == IDNormalizationFold
+}
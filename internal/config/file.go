@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileKeys сопоставляет ключи YAML-файла (секция.параметр) с переменными окружения.
+// Значения из файла проходят тот же разбор и ту же валидацию, что и переменные окружения.
+var fileKeys = map[string]map[string]string{
+	"database": {
+		"url":                 "DATABASE_URL",
+		"host":                "DB_HOST",
+		"port":                "DB_PORT",
+		"user":                "DB_USER",
+		"password":            "DB_PASSWORD",
+		"password_file":       "DB_PASSWORD_FILE",
+		"name":                "DB_NAME",
+		"sslmode":             "DB_SSLMODE",
+		"sslrootcert":         "DB_SSLROOTCERT",
+		"sslcert":             "DB_SSLCERT",
+		"sslkey":              "DB_SSLKEY",
+		"read_url":            "DB_READ_URL",
+		"read_host":           "DB_READ_HOST",
+		"read_port":           "DB_READ_PORT",
+		"query_exec_mode":     "DB_QUERY_EXEC_MODE",
+		"warmup":              "WARMUP",
+		"max_conns":           "DB_MAX_CONNS",
+		"min_conns":           "DB_MIN_CONNS",
+		"max_conn_lifetime":   "DB_MAX_CONN_LIFETIME",
+		"max_conn_idle_time":  "DB_MAX_CONN_IDLE_TIME",
+		"health_check_period": "DB_HEALTH_CHECK_PERIOD",
+		"connect_timeout":     "DB_CONNECT_TIMEOUT",
+		"startup_timeout":     "DB_STARTUP_TIMEOUT",
+		"slow_query_ms":       "SLOW_QUERY_MS",
+	},
+	"server": {
+		"host":                "APP_HOST",
+		"port":                "APP_PORT",
+		"metrics_port":        "METRICS_PORT",
+		"enable_pprof":        "ENABLE_PPROF",
+		"debug_port":          "DEBUG_PORT",
+		"docs_enabled":        "DOCS_ENABLED",
+		"grpc_enabled":        "GRPC_ENABLED",
+		"grpc_port":           "GRPC_PORT",
+		"read_timeout":        "HTTP_READ_TIMEOUT",
+		"write_timeout":       "HTTP_WRITE_TIMEOUT",
+		"idle_timeout":        "HTTP_IDLE_TIMEOUT",
+		"read_header_timeout": "HTTP_READ_HEADER_TIMEOUT",
+		"max_header_bytes":    "HTTP_MAX_HEADER_BYTES",
+		"max_body_bytes":      "HTTP_MAX_BODY_BYTES",
+		"shutdown_timeout":    "SHUTDOWN_TIMEOUT",
+		"legacy_api_sunset":   "LEGACY_API_SUNSET",
+		"admin_api_keys":      "ADMIN_API_KEYS",
+	},
+	"logger": {
+		"level":             "LOG_LEVEL",
+		"format":            "LOG_FORMAT",
+		"output":            "LOG_OUTPUT",
+		"file_max_size_mb":  "LOG_FILE_MAX_SIZE_MB",
+		"file_max_backups":  "LOG_FILE_MAX_BACKUPS",
+		"file_max_age_days": "LOG_FILE_MAX_AGE_DAYS",
+	},
+	"ids": {
+		"normalization": "ID_NORMALIZATION",
+	},
+	"assignment": {
+		"strategy":                 "ASSIGNMENT_STRATEGY",
+		"cooldown_prs":             "ASSIGNMENT_COOLDOWN_PRS",
+		"min_assignment_age_hours": "MIN_ASSIGNMENT_AGE_HOURS",
+		"reject_ambiguous_team":    "REJECT_AMBIGUOUS_TEAM",
+		"allow_zero_reviewers":     "ALLOW_ZERO_REVIEWERS",
+		"fallback_to_org_pool":     "FALLBACK_TO_ORG_POOL",
+		"fallback_team_name":       "FALLBACK_TEAM_NAME",
+		"max_reviewers_per_pr":     "MAX_REVIEWERS_PER_PR",
+	},
+	"merge": {
+		"require_approvals": "REQUIRE_APPROVALS",
+	},
+	"stats": {
+		"load_snapshot_interval":      "LOAD_SNAPSHOT_INTERVAL",
+		"load_history_retention_days": "LOAD_HISTORY_RETENTION_DAYS",
+	},
+	"tracing": {
+		"otlp_endpoint": "OTEL_EXPORTER_OTLP_ENDPOINT",
+		"sampler_arg":   "OTEL_TRACES_SAMPLER_ARG",
+	},
+	"cors": {
+		"allowed_origins":   "CORS_ALLOWED_ORIGINS",
+		"allowed_methods":   "CORS_ALLOWED_METHODS",
+		"allow_credentials": "CORS_ALLOW_CREDENTIALS",
+	},
+	"webhooks": {
+		"github_secret":          "GITHUB_WEBHOOK_SECRET",
+		"gitlab_secret":          "GITLAB_WEBHOOK_SECRET",
+		"queue_size":             "WEBHOOK_QUEUE_SIZE",
+		"workers":                "WEBHOOK_WORKERS",
+		"max_attempts":           "WEBHOOK_MAX_ATTEMPTS",
+		"retry_backoff":          "WEBHOOK_RETRY_BACKOFF",
+		"timeout":                "WEBHOOK_TIMEOUT",
+		"history_retention_days": "WEBHOOK_HISTORY_RETENTION_DAYS",
+	},
+	"slack": {
+		"webhook_url": "SLACK_WEBHOOK_URL",
+		"dry_run":     "SLACK_DRY_RUN",
+	},
+	"reminders": {
+		"enabled":  "REMINDERS_ENABLED",
+		"interval": "REMINDER_INTERVAL",
+		"after":    "REMINDER_AFTER",
+	},
+	"orphan_sweep": {
+		"enabled":           "ORPHAN_SWEEP_ENABLED",
+		"interval":          "ORPHAN_SWEEP_INTERVAL",
+		"inactive_hours":    "ORPHAN_SWEEP_INACTIVE_HOURS",
+		"max_reassignments": "ORPHAN_SWEEP_MAX_REASSIGNMENTS",
+	},
+	"cache": {
+		"ttl": "CACHE_TTL",
+	},
+	"rate_limit": {
+		"rps":      "RATE_LIMIT_RPS",
+		"burst":    "RATE_LIMIT_BURST",
+		"api_keys": "RATE_LIMIT_API_KEYS",
+	},
+	"idempotency": {
+		"key_ttl":          "IDEMPOTENCY_KEY_TTL",
+		"lease":            "IDEMPOTENCY_LEASE",
+		"cleanup_interval": "IDEMPOTENCY_CLEANUP_INTERVAL",
+	},
+}
+
+// source — источник параметров: переменные окружения поверх значений из CONFIG_FILE
+type source struct {
+	file map[string]string
+}
+
+// get возвращает значение переменной окружения, иначе значение из файла, иначе значение по умолчанию
+func (s source) get(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	if value, exists := s.file[key]; exists {
+		return value
+	}
+	return defaultValue
+}
+
+// loadSource читает YAML-файл конфигурации; пустой путь означает конфигурацию только из окружения
+func loadSource(path string) (source, error) {
+	if path == "" {
+		return source{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return source{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values, err := parseFile(data)
+	if err != nil {
+		return source{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return source{file: values}, nil
+}
+
+// parseFile разбирает YAML в значения переменных окружения.
+// Неизвестные секции и ключи — ошибка, чтобы опечатки не проходили молча.
+func parseFile(data []byte) (map[string]string, error) {
+	var doc map[string]yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for section, node := range doc {
+		keys, ok := fileKeys[section]
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q", section)
+		}
+		var params map[string]yaml.Node
+		if err := node.Decode(&params); err != nil {
+			return nil, fmt.Errorf("section %q must be a mapping", section)
+		}
+		for name, node := range params {
+			envKey, ok := keys[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown key %q", section+"."+name)
+			}
+			if node.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("key %q must be a scalar value", section+"."+name)
+			}
+			// Ключ без значения равносилен его отсутствию
+			if node.Tag == "!!null" {
+				continue
+			}
+			values[envKey] = node.Value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/grpc/prmanagerv1"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// statusToProto и statusFromProto переводят статус PR между моделью и proto
+var statusToProto = map[string]prmanagerv1.PullRequestStatus{
+	models.StatusOpen:   prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN,
+	models.StatusMerged: prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED,
+	models.StatusClosed: prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_CLOSED,
+}
+
+var statusFromProto = map[prmanagerv1.PullRequestStatus]string{
+	prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED: "",
+	prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN:        models.StatusOpen,
+	prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED:      models.StatusMerged,
+	prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_CLOSED:      models.StatusClosed,
+}
+
+// stringOrEmpty возвращает пустую строку для незаданного значения
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// timestampOrNil возвращает nil для незаданного времени
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func teamToProto(team *models.Team) *prmanagerv1.Team {
+	members := make([]*prmanagerv1.TeamMember, 0, len(team.Members))
+	for _, m := range team.Members {
+		member := &prmanagerv1.TeamMember{
+			UserId:      m.UserID,
+			Username:    m.Username,
+			IsActive:    m.IsActive,
+			SlackUserId: m.SlackUserID,
+		}
+		if m.MaxOpenReviews != nil {
+			limit := int32(*m.MaxOpenReviews)
+			member.MaxOpenReviews = &limit
+		}
+		if m.IsReviewer != nil {
+			isReviewer := *m.IsReviewer
+			member.IsReviewer = &isReviewer
+		}
+		members = append(members, member)
+	}
+	return &prmanagerv1.Team{TeamName: team.TeamName, Members: members}
+}
+
+func teamFromProto(team *prmanagerv1.Team) models.Team {
+	members := make([]models.TeamMember, 0, len(team.GetMembers()))
+	for _, m := range team.GetMembers() {
+		member := models.TeamMember{
+			UserID:      m.GetUserId(),
+			Username:    m.GetUsername(),
+			IsActive:    m.GetIsActive(),
+			SlackUserID: m.GetSlackUserId(),
+		}
+		if m.MaxOpenReviews != nil {
+			limit := int(m.GetMaxOpenReviews())
+			member.MaxOpenReviews = &limit
+		}
+		if m.IsReviewer != nil {
+			isReviewer := m.GetIsReviewer()
+			member.IsReviewer = &isReviewer
+		}
+		members = append(members, member)
+	}
+	return models.Team{TeamName: team.GetTeamName(), Members: members}
+}
+
+func userToProto(user *models.User) *prmanagerv1.User {
+	return &prmanagerv1.User{
+		UserId:     user.UserID,
+		Username:   user.Username,
+		TeamName:   user.TeamName,
+		IsActive:   user.IsActive,
+		IsReviewer: user.IsReviewer,
+	}
+}
+
+func reassignmentToProto(result *models.ReassignmentResult) *prmanagerv1.ReassignmentResult {
+	reassigned := make([]*prmanagerv1.ReviewReassignment, 0, len(result.Reassigned))
+	for _, r := range result.Reassigned {
+		reassigned = append(reassigned, &prmanagerv1.ReviewReassignment{
+			PullRequestId: r.PullRequestID,
+			NewReviewerId: r.NewReviewerID,
+		})
+	}
+	return &prmanagerv1.ReassignmentResult{
+		Reassigned:    reassigned,
+		NotReassigned: result.NotReassigned,
+		SkippedRecent: result.SkippedRecent,
+	}
+}
+
+// prRefFromProto собирает ссылку на PR; незаданный repository — поиск по одному ID
+func prRefFromProto(prID string, repository *string) models.PRRef {
+	return models.PRRef{Repository: repository, ID: prID}
+}
+
+func pullRequestToProto(pr *models.PullRequest) *prmanagerv1.PullRequest {
+	reviewers := make([]*prmanagerv1.AssignedReviewer, 0, len(pr.AssignedReviewers))
+	for _, r := range pr.AssignedReviewers {
+		reviewers = append(reviewers, &prmanagerv1.AssignedReviewer{
+			UserId:      r.UserID,
+			Username:    r.Username,
+			IsActive:    r.IsActive,
+			Approved:    r.Approved,
+			ApprovedAt:  timestampOrNil(r.ApprovedAt),
+			Source:      r.Source,
+			ReviewDueAt: timestampOrNil(r.ReviewDueAt),
+		})
+	}
+	return &prmanagerv1.PullRequest{
+		PullRequestId:     pr.PullRequestID,
+		PullRequestName:   pr.PullRequestName,
+		AuthorId:          pr.AuthorID,
+		Status:            statusToProto[pr.Status],
+		AssignedReviewers: reviewers,
+		CreatedAt:         timestampOrNil(pr.CreatedAt),
+		MergedAt:          timestampOrNil(pr.MergedAt),
+		ClosedAt:          timestampOrNil(pr.ClosedAt),
+		Description:       pr.Description,
+		SourceBranch:      pr.SourceBranch,
+		TargetBranch:      pr.TargetBranch,
+		Url:               pr.URL,
+		Labels:            pr.Labels,
+		Repository:        pr.Repository,
+		CreatedBy:         stringOrEmpty(pr.CreatedBy),
+		MergedBy:          stringOrEmpty(pr.MergedBy),
+		ClosedBy:          stringOrEmpty(pr.ClosedBy),
+	}
+}
+
+func pullRequestShortToProto(pr models.PullRequestShort) *prmanagerv1.PullRequestShort {
+	return &prmanagerv1.PullRequestShort{
+		PullRequestId:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorId:        pr.AuthorID,
+		Status:          statusToProto[pr.Status],
+		Repository:      pr.Repository,
+	}
+}
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain — домен google.rpc.ErrorInfo в деталях ошибок
+const errorDomain = "pr-manager"
+
+// errorMappings сопоставляет ошибки репозитория статусам gRPC и кодам ошибок HTTP API.
+// Проверяются по порядку через errors.Is.
+var errorMappings = []struct {
+	err    error
+	code   codes.Code
+	reason string
+}{
+	{repository.ErrAlreadyExists, codes.AlreadyExists, handlers.ErrCodePRExists},
+	{repository.ErrNotApproved, codes.FailedPrecondition, handlers.ErrCodeNotEnoughApprovals},
+	{repository.ErrAlreadyMerged, codes.FailedPrecondition, handlers.ErrCodePRMerged},
+	{repository.ErrAlreadyClosed, codes.FailedPrecondition, handlers.ErrCodePRClosed},
+	{repository.ErrInvalidTransition, codes.FailedPrecondition, handlers.ErrCodeInvalidTransition},
+	{repository.ErrNotAssigned, codes.FailedPrecondition, handlers.ErrCodeNotAssigned},
+	{repository.ErrNoCandidate, codes.FailedPrecondition, handlers.ErrCodeNoCandidate},
+	{repository.ErrNoReviewers, codes.FailedPrecondition, handlers.ErrCodeNoCandidate},
+	{repository.ErrAlreadyAssigned, codes.FailedPrecondition, handlers.ErrCodeAlreadyAssigned},
+	{repository.ErrCandidateNotEligible, codes.FailedPrecondition, handlers.ErrCodeCandidateNotEligible},
+	{repository.ErrMaxReviewers, codes.FailedPrecondition, handlers.ErrCodeMaxReviewers},
+	{repository.ErrLeadRequired, codes.FailedPrecondition, handlers.ErrCodeLeadRequired},
+	{repository.ErrAuthorNotInTeam, codes.FailedPrecondition, handlers.ErrCodeAuthorNotInTeam},
+	{repository.ErrAmbiguousTeam, codes.FailedPrecondition, handlers.ErrCodeAmbiguousTeam},
+	{repository.ErrAmbiguousPR, codes.FailedPrecondition, handlers.ErrCodeAmbiguousPR},
+	{repository.ErrInvalidInput, codes.InvalidArgument, handlers.ErrCodeValidation},
+}
+
+// newStatus возвращает ошибку со статусом code и кодом ошибки HTTP API в деталях ErrorInfo
+func newStatus(code codes.Code, reason, message string, metadata map[string]string) error {
+	st := status.New(code, message)
+	if withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: metadata,
+	}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// invalidArgument возвращает ошибку INVALID_ARGUMENT с кодом reason
+func invalidArgument(reason, message string) error {
+	return newStatus(codes.InvalidArgument, reason, message, nil)
+}
+
+// toStatus переводит ошибку репозитория или сервиса в статус gRPC.
+// notFound — сообщение для ErrNotFound, оно зависит от вызова («PR not found», «user not found»).
+// Неизвестные ошибки пишутся в лог и возвращаются клиенту как Internal без подробностей.
+func (s *Service) toStatus(ctx context.Context, method string, err error, notFound string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return newStatus(codes.NotFound, handlers.ErrCodeNotFound, notFound, nil)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return status.FromContextError(ctxErr).Err()
+	}
+
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		message, metadata := m.err.Error(), map[string]string(nil)
+
+		// Типизированные ошибки несут подробности, которые клиенту нужны для решения
+		var notApproved *repository.NotApprovedError
+		var ambiguous *repository.AmbiguousTeamError
+		var transition *repository.TransitionError
+		switch {
+		case errors.As(err, &notApproved):
+			message = notApproved.Error()
+			metadata = map[string]string{
+				"approvals": strconv.Itoa(notApproved.Approvals),
+				"required":  strconv.Itoa(notApproved.Required),
+			}
+		case errors.As(err, &ambiguous):
+			message = ambiguous.Error()
+			metadata = map[string]string{"teams": strings.Join(ambiguous.Teams, ",")}
+		case errors.As(err, &transition) && m.err == repository.ErrInvalidTransition:
+			message = transition.Error()
+		}
+		return newStatus(m.code, m.reason, message, metadata)
+	}
+
+	s.logger.Error("gRPC: внутренняя ошибка", zap.String("method", method), zap.Error(err))
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/untibullet/pr-manager-avito/internal/grpc/prmanagerv1"
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// Ограничения страниц совпадают с HTTP API
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+
+	// maxTeamMembersUnpaged ограничивает число участников в ответе GetTeam без пагинации
+	maxTeamMembersUnpaged = 1000
+)
+
+// repositoryTooLongMessage — сообщение об ошибке валидации имени репозитория PR
+var repositoryTooLongMessage = fmt.Sprintf("repository must be at most %d characters", models.MaxRepositoryLength)
+
+// pagination проверяет limit/offset запроса; нулевой limit заменяется на defaultLimit
+func pagination(limit, offset int32, defaultLimit int) (int, int, error) {
+	if limit < 0 || limit > maxPageLimit {
+		return 0, 0, invalidArgument(handlers.ErrCodeInvalidParam, fmt.Sprintf("limit must be an integer between 1 and %d", maxPageLimit))
+	}
+	if offset < 0 {
+		return 0, 0, invalidArgument(handlers.ErrCodeInvalidParam, "offset must be a non-negative integer")
+	}
+	if limit == 0 {
+		return defaultLimit, int(offset), nil
+	}
+	return int(limit), int(offset), nil
+}
+
+// CreateTeam создает команду с участниками или обновляет существующую
+func (s *Service) CreateTeam(ctx context.Context, req *prmanagerv1.CreateTeamRequest) (*prmanagerv1.CreateTeamResponse, error) {
+	team := teamFromProto(req.GetTeam())
+	if team.TeamName == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "team.team_name is required")
+	}
+	for i := range team.Members {
+		team.Members[i].UserID = s.normalizeID(team.Members[i].UserID)
+		if !models.ValidSlackUserID(team.Members[i].SlackUserID) {
+			return nil, invalidArgument(handlers.ErrCodeInvalidBody, "slack_user_id must be a Slack member ID")
+		}
+		if !models.ValidMaxOpenReviews(team.Members[i].MaxOpenReviews) {
+			return nil, invalidArgument(handlers.ErrCodeInvalidBody, "max_open_reviews must be a positive integer")
+		}
+	}
+
+	created, err := s.services.Teams.Create(ctx, team)
+	if err != nil {
+		return nil, s.toStatus(ctx, "CreateTeam", err, "team not found")
+	}
+	return &prmanagerv1.CreateTeamResponse{Team: teamToProto(created)}, nil
+}
+
+// GetTeam возвращает команду с участниками; без limit и offset — не больше maxTeamMembersUnpaged участников
+func (s *Service) GetTeam(ctx context.Context, req *prmanagerv1.GetTeamRequest) (*prmanagerv1.GetTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "team_name is required")
+	}
+
+	limit, offset := maxTeamMembersUnpaged, 0
+	if req.GetLimit() != 0 || req.GetOffset() != 0 {
+		var err error
+		if limit, offset, err = pagination(req.GetLimit(), req.GetOffset(), defaultPageLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	team, total, err := s.repo.GetTeamPage(ctx, req.GetTeamName(), limit, offset)
+	if err != nil {
+		return nil, s.toStatus(ctx, "GetTeam", err, "team not found")
+	}
+	return &prmanagerv1.GetTeamResponse{Team: teamToProto(team), MembersTotal: int32(total)}, nil
+}
+
+// SetUserIsActive меняет активность пользователя; при деактивации с reassign_reviews
+// открытые ревью переназначаются той же транзакцией
+func (s *Service) SetUserIsActive(ctx context.Context, req *prmanagerv1.SetUserIsActiveRequest) (*prmanagerv1.SetUserIsActiveResponse, error) {
+	userID := s.normalizeID(req.GetUserId())
+	if userID == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "user_id is required")
+	}
+
+	var reassignment *models.ReassignmentResult
+	var err error
+	if !req.GetIsActive() && req.GetReassignReviews() {
+		reassignment, err = s.repo.DeactivateAndReassign(ctx, userID)
+	} else {
+		err = s.repo.UpdateUserStatus(ctx, userID, req.GetIsActive())
+	}
+	if err != nil {
+		return nil, s.toStatus(ctx, "SetUserIsActive", err, "user not found")
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, s.toStatus(ctx, "SetUserIsActive", err, "user not found")
+	}
+
+	resp := &prmanagerv1.SetUserIsActiveResponse{User: userToProto(user)}
+	if reassignment != nil {
+		s.metrics.ReviewersReassigned.Add(float64(len(reassignment.Reassigned)))
+		resp.Reassignment = reassignmentToProto(reassignment)
+	}
+	return resp, nil
+}
+
+// CreatePullRequest создает PR и назначает ревьюеров
+func (s *Service) CreatePullRequest(ctx context.Context, req *prmanagerv1.CreatePullRequestRequest) (*prmanagerv1.CreatePullRequestResponse, error) {
+	if req.GetPullRequestId() == "" || req.GetPullRequestName() == "" || req.GetAuthorId() == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "pull_request_id, pull_request_name and author_id are required")
+	}
+
+	meta := models.PRMetadata{
+		Description:  req.GetDescription(),
+		SourceBranch: req.GetSourceBranch(),
+		TargetBranch: req.GetTargetBranch(),
+		URL:          req.GetUrl(),
+		Labels:       req.GetLabels(),
+	}
+	if problems := handlers.ValidatePRMetadata(&meta.Description, &meta.URL, &meta.Labels); len(problems) > 0 {
+		return nil, newStatus(codes.InvalidArgument, handlers.ErrCodeValidation, "pull request metadata is invalid: "+strings.Join(problems, "; "), nil)
+	}
+	repo := req.GetRepository()
+	if !models.ValidRepository(&repo) {
+		return nil, invalidArgument(handlers.ErrCodeInvalidBody, repositoryTooLongMessage)
+	}
+
+	authorID := s.normalizeID(req.GetAuthorId())
+	pr, err := s.services.PRs.Create(ctx, repo, req.GetPullRequestId(), req.GetPullRequestName(), authorID, req.GetTeamName(), meta)
+	if err != nil {
+		return nil, s.toStatus(ctx, "CreatePullRequest", err, "author or team not found")
+	}
+
+	s.metrics.PRsCreated.Inc()
+	resp := &prmanagerv1.CreatePullRequestResponse{PullRequest: pullRequestToProto(pr)}
+	if len(pr.AssignedReviewers) == 0 {
+		s.metrics.ZeroReviewerAssignments.Inc()
+		resp.Warnings = []string{handlers.WarnNoReviewersAssigned}
+	}
+	s.logger.Info("gRPC: PR создан",
+		zap.String("pr_id", pr.PullRequestID),
+		zap.Int("reviewers_count", len(pr.AssignedReviewers)))
+	return resp, nil
+}
+
+// MergePullRequest переводит PR в статус MERGED; повторный вызов для смерженного PR не меняет его
+func (s *Service) MergePullRequest(ctx context.Context, req *prmanagerv1.MergePullRequestRequest) (*prmanagerv1.MergePullRequestResponse, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "pull_request_id is required")
+	}
+	if !models.ValidRepository(req.Repository) {
+		return nil, invalidArgument(handlers.ErrCodeInvalidBody, repositoryTooLongMessage)
+	}
+
+	pr, merged, err := s.services.PRs.Merge(ctx, prRefFromProto(req.GetPullRequestId(), req.Repository), nil)
+	if err != nil {
+		return nil, s.toStatus(ctx, "MergePullRequest", err, "PR not found")
+	}
+
+	if merged {
+		s.metrics.PRsMerged.Inc()
+	}
+	return &prmanagerv1.MergePullRequestResponse{PullRequest: pullRequestToProto(pr)}, nil
+}
+
+// ReassignReviewer заменяет ревьюера PR на new_user_id или на автоматически выбранного кандидата
+func (s *Service) ReassignReviewer(ctx context.Context, req *prmanagerv1.ReassignReviewerRequest) (*prmanagerv1.ReassignReviewerResponse, error) {
+	oldUserID := s.normalizeID(req.GetOldUserId())
+	newUserID := s.normalizeID(req.GetNewUserId())
+	if req.GetPullRequestId() == "" || oldUserID == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "pull_request_id and old_user_id are required")
+	}
+	if !models.ValidRepository(req.Repository) {
+		return nil, invalidArgument(handlers.ErrCodeInvalidBody, repositoryTooLongMessage)
+	}
+
+	pr, replacedBy, err := s.services.PRs.Reassign(ctx, prRefFromProto(req.GetPullRequestId(), req.Repository), oldUserID, newUserID, nil)
+	if err != nil {
+		return nil, s.toStatus(ctx, "ReassignReviewer", err, "PR or user not found")
+	}
+
+	s.metrics.ReviewersReassigned.Inc()
+	s.logger.Info("gRPC: ревьюер переназначен",
+		zap.String("pr_id", req.GetPullRequestId()),
+		zap.String("old_reviewer", oldUserID),
+		zap.String("new_reviewer", replacedBy))
+	return &prmanagerv1.ReassignReviewerResponse{PullRequest: pullRequestToProto(pr), ReplacedBy: replacedBy}, nil
+}
+
+// GetUserReviews возвращает страницу PR, где пользователь назначен ревьюером
+func (s *Service) GetUserReviews(ctx context.Context, req *prmanagerv1.GetUserReviewsRequest) (*prmanagerv1.GetUserReviewsResponse, error) {
+	userID := s.normalizeID(req.GetUserId())
+	if userID == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "user_id is required")
+	}
+
+	status, ok := statusFromProto[req.GetStatus()]
+	if !ok {
+		return nil, invalidArgument(handlers.ErrCodeInvalidParam, "status must be one of OPEN, MERGED, CLOSED")
+	}
+
+	if !models.ValidRepository(req.Repository) {
+		return nil, invalidArgument(handlers.ErrCodeInvalidParam, repositoryTooLongMessage)
+	}
+
+	limit, offset, err := pagination(req.GetLimit(), req.GetOffset(), defaultPageLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, page, err := s.repo.GetPRsByReviewer(ctx, userID, repository.ReviewFilter{
+		PRListFilter: repository.PRListFilter{
+			Status:     status,
+			Label:      handlers.NormalizeLabel(req.GetLabel()),
+			Repository: req.Repository,
+			Limit:      limit,
+			Offset:     offset,
+		},
+		UnapprovedOnly: req.GetUnapproved(),
+	})
+	if err != nil {
+		return nil, s.toStatus(ctx, "GetUserReviews", err, "user not found")
+	}
+
+	resp := &prmanagerv1.GetUserReviewsResponse{
+		UserId:       userID,
+		PullRequests: make([]*prmanagerv1.PullRequestShort, 0, len(prs)),
+		Total:        int32(page.Total),
+	}
+	for _, pr := range prs {
+		resp.PullRequests = append(resp.PullRequests, pullRequestShortToProto(pr))
+	}
+	return resp, nil
+}
+
+// GetPullRequest возвращает PR вместе с назначенными ревьюерами
+func (s *Service) GetPullRequest(ctx context.Context, req *prmanagerv1.GetPullRequestRequest) (*prmanagerv1.GetPullRequestResponse, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, invalidArgument(handlers.ErrCodeMissingParam, "pull_request_id is required")
+	}
+	if !models.ValidRepository(req.Repository) {
+		return nil, invalidArgument(handlers.ErrCodeInvalidParam, repositoryTooLongMessage)
+	}
+
+	pr, err := s.repo.GetPR(ctx, prRefFromProto(req.GetPullRequestId(), req.Repository))
+	if err != nil {
+		return nil, s.toStatus(ctx, "GetPullRequest", err, "PR not found")
+	}
+	return &prmanagerv1.GetPullRequestResponse{PullRequest: pullRequestToProto(pr)}, nil
+}
@@ -0,0 +1,154 @@
+// Package grpc реализует gRPC API сервиса (proto/prmanager/v1) поверх тех же репозитория
+// и сервисного слоя, что и HTTP-хендлеры. Сгенерированный код лежит в prmanagerv1.
+package grpc
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/grpc/prmanagerv1"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// metadataActorID — ключ метаданных вызова с инициатором изменений, как заголовок X-Actor-Id в HTTP API
+	metadataActorID = "x-actor-id"
+	// actorGRPC — инициатор вызова без метаданных x-actor-id
+	actorGRPC = "grpc"
+	// maxActorLength — максимальная длина инициатора, более длинное значение обрезается
+	maxActorLength = 255
+)
+
+// Store — операции хранилища, которые gRPC API вызывает напрямую (подмножество handlers.Store).
+// Реализуется *repository.Repository.
+type Store interface {
+	service.Store
+
+	GetTeamPage(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	UpdateUserStatus(ctx context.Context, userID string, isActive bool) error
+	DeactivateAndReassign(ctx context.Context, userID string) (*models.ReassignmentResult, error)
+	GetPRsByReviewer(ctx context.Context, reviewerID string, filter repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error)
+}
+
+var _ Store = (*repository.Repository)(nil)
+
+// Config задает настройки gRPC API
+type Config struct {
+	// FoldIDs включает нормализацию внешних ID пользователей (trim + lower) на входе, как в HTTP API
+	FoldIDs bool
+}
+
+// Service реализует prmanagerv1.PRManagerServiceServer
+type Service struct {
+	prmanagerv1.UnimplementedPRManagerServiceServer
+
+	repo     Store
+	services *service.Services
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	cfg      Config
+}
+
+// New создает реализацию gRPC API.
+// Сценарии сервисного слоя вызываются через services, остальные — напрямую через repo.
+// Бизнес-счетчики увеличиваются в тех же metrics, что и у HTTP API.
+func New(repo Store, services *service.Services, m *metrics.Metrics, logger *zap.Logger, cfg Config) *Service {
+	return &Service{
+		repo:     repo,
+		services: services,
+		metrics:  m,
+		logger:   logger,
+		cfg:      cfg,
+	}
+}
+
+// NewServer создает *grpc.Server с перехватчиками восстановления после паники, логирования вызовов
+// и инициатора изменений и регистрирует в нем svc
+func NewServer(svc *Service, logger *zap.Logger, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(logCalls(logger), recoverPanics(logger), bindActor()))
+	server := grpc.NewServer(opts...)
+	prmanagerv1.RegisterPRManagerServiceServer(server, svc)
+	return server
+}
+
+// normalizeID приводит внешний ID пользователя к каноничному виду, если включена нормализация
+func (s *Service) normalizeID(id string) string {
+	if !s.cfg.FoldIDs {
+		return id
+	}
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// logCalls пишет в лог каждый вызов с кодом ответа и длительностью: внутренние ошибки — на уровне Error
+func logCalls(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("actor", actorFromMetadata(ctx)),
+			zap.String("code", code.String()),
+			zap.Duration("duration", time.Since(start)),
+		}
+		switch code {
+		case codes.OK:
+			logger.Info("gRPC: вызов обработан", fields...)
+		case codes.Internal, codes.Unknown:
+			logger.Error("gRPC: вызов завершился ошибкой", append(fields, zap.Error(err))...)
+		default:
+			logger.Warn("gRPC: вызов отклонен", append(fields, zap.Error(err))...)
+		}
+		return resp, err
+	}
+}
+
+// recoverPanics перехватывает панику в обработчике, пишет ее в лог со стеком и отвечает Internal
+func recoverPanics(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("gRPC: паника при обработке вызова",
+					zap.String("method", info.FullMethod),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// actorFromMetadata возвращает инициатора вызова из метаданных x-actor-id, без них — "grpc"
+func actorFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, actor := range md.Get(metadataActorID) {
+		if actor = strings.TrimSpace(actor); actor != "" {
+			if len(actor) > maxActorLength {
+				actor = actor[:maxActorLength]
+			}
+			return actor
+		}
+	}
+	return actorGRPC
+}
+
+// bindActor сохраняет инициатора вызова в контексте: он попадает в журнал назначений
+// и в created_by / merged_by PR
+func bindActor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(repository.WithActor(ctx, actorFromMetadata(ctx)), req)
+	}
+}
@@ -0,0 +1,256 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	prgrpc "github.com/untibullet/pr-manager-avito/internal/grpc"
+	"github.com/untibullet/pr-manager-avito/internal/grpc/prmanagerv1"
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// newTestClient поднимает gRPC-сервер поверх мока на bufconn так же, как main.go, и возвращает клиента к нему
+func newTestClient(t *testing.T, st *mocks.Store, cfg prgrpc.Config) prmanagerv1.PRManagerServiceClient {
+	t.Helper()
+	svc := prgrpc.New(st, service.New(st), metrics.New(prometheus.NewRegistry()), zap.NewNop(), cfg)
+	server := prgrpc.NewServer(svc, zap.NewNop())
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return prmanagerv1.NewPRManagerServiceClient(conn)
+}
+
+// requireStatus проверяет код статуса gRPC и код ошибки HTTP API в ErrorInfo
+func requireStatus(t *testing.T, err error, code codes.Code, reason string) *status.Status {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a gRPC status: %v", err)
+	require.Equal(t, code, st.Code(), st.Message())
+	if reason == "" {
+		assert.Empty(t, st.Details())
+		return st
+	}
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, reason, info.GetReason())
+	assert.Equal(t, "pr-manager", info.GetDomain())
+	return st
+}
+
+// openPR — открытый PR pr-1 с ревьюерами reviewers
+func openPR(reviewers ...string) *models.PullRequest {
+	pr := &models.PullRequest{PullRequestID: "pr-1", Repository: "backend", PullRequestName: "Add search", AuthorID: "u1", Status: models.StatusOpen}
+	for _, id := range reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, models.AssignedReviewer{UserID: id, Username: "User " + id, IsActive: true})
+		pr.AssignedReviewerIDs = append(pr.AssignedReviewerIDs, id)
+	}
+	return pr
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var gotRepo, gotID, gotName, gotAuthor, gotTeam string
+	var gotMeta models.PRMetadata
+	st := &mocks.Store{
+		CreatePRFunc: func(_ context.Context, repo, prID, prName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error) {
+			gotRepo, gotID, gotName, gotAuthor, gotTeam, gotMeta = repo, prID, prName, authorID, teamName, meta
+			return openPR("u2", "u3"), nil
+		},
+	}
+	client := newTestClient(t, st, prgrpc.Config{FoldIDs: true})
+
+	resp, err := client.CreatePullRequest(context.Background(), &prmanagerv1.CreatePullRequestRequest{
+		PullRequestId:   "pr-1",
+		PullRequestName: "Add search",
+		AuthorId:        " U1 ",
+		TeamName:        "backend",
+		Repository:      "backend",
+		SourceBranch:    "feature/search",
+		TargetBranch:    "main",
+		Labels:          []string{"backend"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "backend", gotRepo)
+	assert.Equal(t, "pr-1", gotID)
+	assert.Equal(t, "Add search", gotName)
+	assert.Equal(t, "u1", gotAuthor, "author_id is folded with FoldIDs")
+	assert.Equal(t, "backend", gotTeam)
+	assert.Equal(t, "feature/search", gotMeta.SourceBranch)
+	assert.Equal(t, []string{"backend"}, gotMeta.Labels)
+
+	pr := resp.GetPullRequest()
+	assert.Equal(t, "pr-1", pr.GetPullRequestId())
+	assert.Equal(t, "backend", pr.GetRepository())
+	assert.Equal(t, prmanagerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN, pr.GetStatus())
+	require.Len(t, pr.GetAssignedReviewers(), 2)
+	assert.Equal(t, "u2", pr.GetAssignedReviewers()[0].GetUserId())
+	assert.Equal(t, "u3", pr.GetAssignedReviewers()[1].GetUserId())
+	assert.Empty(t, resp.GetWarnings())
+}
+
+func TestCreatePullRequestWithoutReviewers(t *testing.T) {
+	st := &mocks.Store{
+		CreatePRFunc: func(context.Context, string, string, string, string, string, models.PRMetadata) (*models.PullRequest, error) {
+			return openPR(), nil
+		},
+	}
+	client := newTestClient(t, st, prgrpc.Config{})
+
+	resp, err := client.CreatePullRequest(context.Background(), &prmanagerv1.CreatePullRequestRequest{
+		PullRequestId: "pr-1", PullRequestName: "Add search", AuthorId: "u1",
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.GetPullRequest().GetAssignedReviewers())
+	assert.Equal(t, []string{handlers.WarnNoReviewersAssigned}, resp.GetWarnings())
+}
+
+func TestCreatePullRequestErrors(t *testing.T) {
+	valid := &prmanagerv1.CreatePullRequestRequest{PullRequestId: "pr-1", PullRequestName: "Add search", AuthorId: "u1"}
+	cases := []struct {
+		name   string
+		req    *prmanagerv1.CreatePullRequestRequest
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{name: "missing author", req: &prmanagerv1.CreatePullRequestRequest{PullRequestId: "pr-1", PullRequestName: "Add search"},
+			code: codes.InvalidArgument, reason: handlers.ErrCodeMissingParam},
+		{name: "invalid url", req: &prmanagerv1.CreatePullRequestRequest{PullRequestId: "pr-1", PullRequestName: "Add search", AuthorId: "u1", Url: "not a url"},
+			code: codes.InvalidArgument, reason: handlers.ErrCodeValidation},
+		{name: "already exists", req: valid, err: repository.ErrAlreadyExists, code: codes.AlreadyExists, reason: handlers.ErrCodePRExists},
+		{name: "author not found", req: valid, err: repository.ErrNotFound, code: codes.NotFound, reason: handlers.ErrCodeNotFound},
+		{name: "author outside the team", req: valid, err: repository.ErrAuthorNotInTeam, code: codes.FailedPrecondition, reason: handlers.ErrCodeAuthorNotInTeam},
+		{name: "database error", req: valid, err: errors.New("connection refused"), code: codes.Internal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			st := &mocks.Store{
+				CreatePRFunc: func(context.Context, string, string, string, string, string, models.PRMetadata) (*models.PullRequest, error) {
+					calls++
+					return nil, tc.err
+				},
+			}
+			client := newTestClient(t, st, prgrpc.Config{})
+
+			_, err := client.CreatePullRequest(context.Background(), tc.req)
+
+			s := requireStatus(t, err, tc.code, tc.reason)
+			if tc.err == nil {
+				assert.Zero(t, calls, "invalid requests do not reach the store")
+			}
+			if tc.code == codes.Internal {
+				assert.Equal(t, "internal server error", s.Message(), "internal details are not exposed")
+			}
+		})
+	}
+}
+
+func TestReassignReviewer(t *testing.T) {
+	var gotRef models.PRRef
+	var gotOld, gotNew string
+	st := &mocks.Store{
+		ReassignReviewerFunc: func(_ context.Context, ref models.PRRef, oldID, newID string, expectedVersion *int64) (string, error) {
+			gotRef, gotOld, gotNew = ref, oldID, newID
+			assert.Nil(t, expectedVersion)
+			return "u4", nil
+		},
+		GetPRFunc: func(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+			assert.Equal(t, "pr-1", ref.ID)
+			return openPR("u3", "u4"), nil
+		},
+	}
+	client := newTestClient(t, st, prgrpc.Config{FoldIDs: true})
+
+	t.Run("automatic replacement", func(t *testing.T) {
+		resp, err := client.ReassignReviewer(context.Background(), &prmanagerv1.ReassignReviewerRequest{
+			PullRequestId: "pr-1", OldUserId: " U2 ",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "u2", gotOld, "old_user_id is folded with FoldIDs")
+		assert.Empty(t, gotNew)
+		assert.Nil(t, gotRef.Repository, "without repository the PR is looked up by ID alone")
+		assert.Equal(t, "u4", resp.GetReplacedBy())
+		require.Len(t, resp.GetPullRequest().GetAssignedReviewers(), 2)
+		assert.Equal(t, "u4", resp.GetPullRequest().GetAssignedReviewers()[1].GetUserId())
+	})
+
+	t.Run("explicit replacement in a repository", func(t *testing.T) {
+		repo := "backend"
+		_, err := client.ReassignReviewer(context.Background(), &prmanagerv1.ReassignReviewerRequest{
+			PullRequestId: "pr-1", OldUserId: "u2", NewUserId: "U4", Repository: &repo,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "u4", gotNew)
+		require.NotNil(t, gotRef.Repository)
+		assert.Equal(t, "backend", *gotRef.Repository)
+	})
+}
+
+func TestReassignReviewerErrors(t *testing.T) {
+	valid := &prmanagerv1.ReassignReviewerRequest{PullRequestId: "pr-1", OldUserId: "u2"}
+	cases := []struct {
+		name   string
+		req    *prmanagerv1.ReassignReviewerRequest
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{name: "missing old reviewer", req: &prmanagerv1.ReassignReviewerRequest{PullRequestId: "pr-1"},
+			code: codes.InvalidArgument, reason: handlers.ErrCodeMissingParam},
+		{name: "PR not found", req: valid, err: repository.ErrNotFound, code: codes.NotFound, reason: handlers.ErrCodeNotFound},
+		{name: "merged PR", req: valid, err: repository.ErrAlreadyMerged, code: codes.FailedPrecondition, reason: handlers.ErrCodePRMerged},
+		{name: "not assigned", req: valid, err: repository.ErrNotAssigned, code: codes.FailedPrecondition, reason: handlers.ErrCodeNotAssigned},
+		{name: "no candidate", req: valid, err: repository.ErrNoCandidate, code: codes.FailedPrecondition, reason: handlers.ErrCodeNoCandidate},
+		{name: "ambiguous PR", req: valid, err: repository.ErrAmbiguousPR, code: codes.FailedPrecondition, reason: handlers.ErrCodeAmbiguousPR},
+		{name: "database error", req: valid, err: errors.New("connection refused"), code: codes.Internal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			st := &mocks.Store{
+				ReassignReviewerFunc: func(context.Context, models.PRRef, string, string, *int64) (string, error) {
+					calls++
+					return "", tc.err
+				},
+			}
+			client := newTestClient(t, st, prgrpc.Config{})
+
+			_, err := client.ReassignReviewer(context.Background(), tc.req)
+
+			requireStatus(t, err, tc.code, tc.reason)
+			if tc.err == nil {
+				assert.Zero(t, calls, "invalid requests do not reach the store")
+			}
+		})
+	}
+}
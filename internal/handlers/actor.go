@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderActorID — кто выполняет запрос; попадает в журнал назначений, в createdBy / mergedBy / closedBy PR
+	// и в лог запроса
+	HeaderActorID = "X-Actor-Id"
+	// HeaderActor — прежнее название заголовка X-Actor-Id, учитывается, если X-Actor-Id не передан
+	HeaderActor = "X-Actor"
+	// actorAPI — инициатор запроса к API без заголовка X-Actor-Id
+	actorAPI = "api"
+	// maxActorLength — максимальная длина инициатора, более длинное значение обрезается
+	maxActorLength = 255
+	// actorBaseLoggerKey — ключ логгера запроса без поля actor, чтобы поле не дублировалось при смене инициатора
+	actorBaseLoggerKey = "actor_base_logger"
+)
+
+// Actor возвращает middleware, сохраняющее инициатора запроса в контексте для журнала назначений
+// и в логгере запроса: значение заголовка X-Actor-Id (или X-Actor) либо "api", если заголовок не передан
+func Actor() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			actor := strings.TrimSpace(c.Request().Header.Get(HeaderActorID))
+			if actor == "" {
+				actor = strings.TrimSpace(c.Request().Header.Get(HeaderActor))
+			}
+			if actor == "" {
+				actor = actorAPI
+			}
+			setActor(c, actor)
+			return next(c)
+		}
+	}
+}
+
+// bodyActor применяет поле actor_id тела запроса: оно важнее заголовка X-Actor-Id.
+// Пустое значение оставляет инициатора из заголовка.
+func bodyActor(c echo.Context, actorID string) {
+	if actorID = strings.TrimSpace(actorID); actorID != "" {
+		setActor(c, actorID)
+	}
+}
+
+// setActor сохраняет инициатора в контексте запроса и добавляет поле actor в логгер запроса
+func setActor(c echo.Context, actor string) {
+	if len(actor) > maxActorLength {
+		actor = actor[:maxActorLength]
+	}
+	req := c.Request()
+	c.SetRequest(req.WithContext(repository.WithActor(req.Context(), actor)))
+
+	base, ok := c.Get(actorBaseLoggerKey).(*zap.Logger)
+	if !ok {
+		if base, ok = c.Get(requestLoggerKey).(*zap.Logger); !ok {
+			return
+		}
+		c.Set(actorBaseLoggerKey, base)
+	}
+	c.Set(requestLoggerKey, base.With(zap.String("actor", actor)))
+}
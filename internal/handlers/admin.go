@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/bootstrap"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PauseUserAssignment приостанавливает автоназначение пользователя без изменения is_active.
+// Флаг виден в ответах GetTeam/GetUser и предпросмотре назначения только администраторам.
+func (h *Handler) PauseUserAssignment(c echo.Context) error {
+	h.log(c).Info("PauseUserAssignment: начало обработки запроса")
+
+	var req struct {
+		UserID string     `json:"user_id"`
+		Paused bool       `json:"paused"`
+		Until  *time.Time `json:"until"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("PauseUserAssignment: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		h.log(c).Warn("PauseUserAssignment: until в прошлом", zap.Time("until", *req.Until))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "until must be in the future"))
+	}
+
+	h.log(c).Info("PauseUserAssignment: обновление паузы автоназначения",
+		zap.String("user_id", req.UserID),
+		zap.Bool("paused", req.Paused))
+
+	err := h.repo.SetAssignmentPaused(c.Request().Context(), req.UserID, req.Paused, req.Until)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("PauseUserAssignment: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("PauseUserAssignment: ошибка обновления паузы", zap.Error(err), zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update assignment pause"))
+	}
+
+	if !req.Paused {
+		req.Until = nil
+	}
+
+	h.log(c).Info("PauseUserAssignment: пауза автоназначения обновлена", zap.String("user_id", req.UserID))
+
+	response := map[string]interface{}{
+		"user_id":           req.UserID,
+		"assignment_paused": req.Paused,
+		"until":             req.Until,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Bootstrap атомарно создает команды и PR из вложенного документа.
+// Документ проверяется целиком, все найденные проблемы возвращаются одним ответом.
+func (h *Handler) Bootstrap(c echo.Context) error {
+	h.log(c).Info("Bootstrap: начало обработки запроса")
+
+	doc, err := bootstrap.Parse(c.Request().Body)
+	if err != nil {
+		h.log(c).Error("Bootstrap: ошибка парсинга документа", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+	bootstrap.Normalize(doc, h.normalizeID)
+
+	if problems := bootstrap.Validate(doc); len(problems) > 0 {
+		h.log(c).Warn("Bootstrap: документ не прошел валидацию", zap.Strings("problems", problems))
+		resp := newErrorResponse(c, ErrCodeValidation, "bootstrap document is invalid")
+		resp.Error.Details = problems
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	h.log(c).Info("Bootstrap: применение документа",
+		zap.Int("teams_count", len(doc.Teams)),
+		zap.Int("prs_count", len(doc.PullRequests)))
+
+	result, err := h.repo.Bootstrap(c.Request().Context(), *doc)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			h.log(c).Warn("Bootstrap: PR уже существует", zap.Error(err))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRExists, err.Error()))
+		}
+		h.log(c).Error("Bootstrap: ошибка применения документа", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to apply bootstrap document"))
+	}
+
+	h.metrics.PRsCreated.Add(float64(len(result.PullRequests)))
+	for _, pr := range result.PullRequests {
+		if len(pr.AssignedReviewers) == 0 {
+			h.metrics.ZeroReviewerAssignments.Inc()
+		}
+	}
+
+	h.log(c).Info("Bootstrap: документ успешно применен",
+		zap.Int("teams_count", len(result.Teams)),
+		zap.Int("prs_count", len(result.PullRequests)))
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// LinkExternalAccount привязывает учетную запись GitHub или GitLab к пользователю,
+// чтобы вебхуки этой системы находили автора PR
+func (h *Handler) LinkExternalAccount(c echo.Context) error {
+	h.log(c).Info("LinkExternalAccount: начало обработки запроса")
+
+	var req models.ExternalAccount
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("LinkExternalAccount: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+	req.AccountID = normalizeAccountID(req.Provider, req.AccountID)
+
+	if !supportedProvider(req.Provider) {
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, errUnsupportedProvider))
+	}
+	if req.UserID == "" || req.AccountID == "" {
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id and account_id are required"))
+	}
+
+	h.log(c).Info("LinkExternalAccount: привязка учетной записи",
+		zap.String("user_id", req.UserID),
+		zap.String("provider", req.Provider),
+		zap.String("account_id", req.AccountID))
+
+	if err := h.repo.LinkExternalAccount(c.Request().Context(), req); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("LinkExternalAccount: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("LinkExternalAccount: ошибка привязки учетной записи", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to link external account"))
+	}
+
+	h.log(c).Info("LinkExternalAccount: учетная запись привязана", zap.String("user_id", req.UserID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"account": req})
+}
+
+// SweepOrphans запускает очистку ревью давно деактивированных пользователей, не дожидаясь фонового воркера:
+// ревью в открытых PR пользователей, неактивных дольше ORPHAN_SWEEP_INACTIVE_HOURS, переназначаются,
+// за вызов — не больше ORPHAN_SWEEP_MAX_REASSIGNMENTS. Назначения моложе MIN_ASSIGNMENT_AGE_HOURS
+// возвращаются в skipped_recent
+func (h *Handler) SweepOrphans(c echo.Context) error {
+	h.log(c).Info("SweepOrphans: запуск очистки",
+		zap.Duration("inactive_after", h.cfg.OrphanInactiveAfter),
+		zap.Int("limit", h.cfg.OrphanSweepLimit))
+
+	inactiveBefore := time.Now().UTC().Add(-h.cfg.OrphanInactiveAfter)
+	result, err := h.repo.SweepOrphanedReviews(c.Request().Context(), inactiveBefore, h.cfg.OrphanSweepLimit)
+	if err != nil {
+		h.log(c).Error("SweepOrphans: ошибка переназначения ревью", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to sweep orphaned reviews"))
+	}
+	h.metrics.ObserveOrphanSweep(len(result.Reassigned), len(result.Unassigned))
+
+	h.log(c).Info("SweepOrphans: очистка завершена",
+		zap.Int("reassigned_count", len(result.Reassigned)),
+		zap.Int("unassigned_count", len(result.Unassigned)),
+		zap.Int("skipped_recent_count", len(result.SkippedRecent)))
+	return c.JSON(http.StatusOK, result)
+}
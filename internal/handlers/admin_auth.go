@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// isAdmin сообщает, передан ли в запросе ключ X-API-Key из AdminAPIKeys.
+// Ключи сравниваются за постоянное время.
+func (h *Handler) isAdmin(c echo.Context) bool {
+	key := c.Request().Header.Get(HeaderAPIKey)
+	if key == "" {
+		return false
+	}
+	admin := false
+	for _, adminKey := range h.cfg.AdminAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1 {
+			admin = true
+		}
+	}
+	return admin
+}
+
+// adminOnly пропускает к маршрутам /admin/* только запросы с административным ключом X-API-Key.
+// Без AdminAPIKeys административные маршруты недоступны никому.
+func (h *Handler) adminOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !h.isAdmin(c) {
+			h.log(c).Warn("AdminOnly: запрос без административного ключа", zap.String("path", c.Path()))
+			return c.JSON(http.StatusUnauthorized, newErrorResponse(c, ErrCodeUnauthorized, "admin API key required"))
+		}
+		return next(c)
+	}
+}
+
+// userView возвращает пользователя для ответа: флаг приостановки автоназначения видят только администраторы
+func (h *Handler) userView(c echo.Context, user *models.User) *models.User {
+	if user == nil || h.isAdmin(c) {
+		return user
+	}
+	view := *user
+	view.AssignmentPaused = nil
+	view.AssignmentPausedUntil = nil
+	return &view
+}
+
+// teamView возвращает команду для ответа: флаг приостановки автоназначения участников видят только администраторы
+func (h *Handler) teamView(c echo.Context, team *models.Team) *models.Team {
+	if team == nil || h.isAdmin(c) {
+		return team
+	}
+	view := *team
+	view.Members = make([]models.TeamMember, len(team.Members))
+	for i, member := range team.Members {
+		member.AssignmentPaused = nil
+		member.AssignmentPausedUntil = nil
+		view.Members[i] = member
+	}
+	return &view
+}
@@ -0,0 +1,225 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+const adminKey = "admin-key"
+
+// adminConfig — настройки обработчиков с одним административным ключом
+var adminConfig = handlers.Config{AdminAPIKeys: []string{adminKey}}
+
+// pausedStore — мок, в котором у пользователя u2 команды backend действует пауза автоназначения до pausedUntil
+func pausedStore(pausedUntil time.Time) *mocks.Store {
+	paused, active := true, false
+	return &mocks.Store{
+		GetTeamPageFunc: func(_ context.Context, teamName string, _, _ int) (*models.Team, int, error) {
+			return &models.Team{TeamName: teamName, Members: []models.TeamMember{
+				{UserID: "u1", Username: "Alice", IsActive: true, AssignmentPaused: &active},
+				{UserID: "u2", Username: "Bob", IsActive: true, AssignmentPaused: &paused, AssignmentPausedUntil: &pausedUntil},
+			}}, 2, nil
+		},
+		GetTeamAssignmentFunc: func(context.Context, string) (*models.AssignmentSettings, error) {
+			return &models.AssignmentSettings{ReviewersPerPR: 2, Strategy: "least_loaded"}, nil
+		},
+		GetUserFunc: func(_ context.Context, userID string) (*models.User, error) {
+			return &models.User{UserID: userID, Username: "Bob", TeamName: "backend", IsActive: true,
+				AssignmentPaused: &paused, AssignmentPausedUntil: &pausedUntil}, nil
+		},
+		PreviewReviewersFunc: func(_ context.Context, authorID, _ string, _ int) (*models.ReviewerPreview, error) {
+			return &models.ReviewerPreview{AuthorID: authorID, TeamName: "backend", Strategy: "least_loaded", Count: 1,
+				Candidates:    []models.ReviewerCandidate{{UserID: "u3", Username: "Carol", Source: models.ReviewerSourceTeam}},
+				PausedMembers: []string{"u2"}}, nil
+		},
+		SetAssignmentPausedFunc: func(context.Context, string, bool, *time.Time) error { return nil },
+	}
+}
+
+func TestAdminRoutesRequireAdminKey(t *testing.T) {
+	routes := []string{
+		"/admin/users/pauseAssignment",
+		"/admin/bootstrap",
+		"/admin/users/externalAccount",
+		"/admin/sweepOrphans",
+	}
+	cases := []struct {
+		name   string
+		cfg    handlers.Config
+		header map[string]string
+	}{
+		{name: "without key", cfg: adminConfig},
+		{name: "with unknown key", cfg: adminConfig, header: map[string]string{handlers.HeaderAPIKey: "someone-else"}},
+		{name: "admin keys not configured", header: map[string]string{handlers.HeaderAPIKey: adminKey}},
+	}
+	for _, tc := range cases {
+		for _, route := range routes {
+			for _, prefix := range []string{handlers.APIV1Prefix, ""} {
+				t.Run(tc.name+" "+prefix+route, func(t *testing.T) {
+					st := &mocks.Store{}
+					e := newVersionedServer(st, tc.cfg)
+					rec := serve(e, http.MethodPost, prefix+route, `{"user_id":"u2","paused":true}`, tc.header)
+
+					require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+					var resp handlers.ErrorResponse
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+					assert.Equal(t, handlers.ErrCodeUnauthorized, resp.Error.Code)
+					assert.Equal(t, "admin API key required", resp.Error.Message)
+				})
+			}
+		}
+	}
+
+	t.Run("admin key reaches the handler", func(t *testing.T) {
+		var pausedUser string
+		st := &mocks.Store{
+			SetAssignmentPausedFunc: func(_ context.Context, userID string, _ bool, _ *time.Time) error {
+				pausedUser = userID
+				return nil
+			},
+		}
+		cfg := handlers.Config{AdminAPIKeys: []string{"other-admin", adminKey}}
+		rec := serve(newTestServer(st, cfg), http.MethodPost, "/admin/users/pauseAssignment",
+			`{"user_id":"u2","paused":true}`, map[string]string{handlers.HeaderAPIKey: adminKey})
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "u2", pausedUser)
+	})
+}
+
+func TestPauseUserAssignmentUntil(t *testing.T) {
+	admin := map[string]string{handlers.HeaderAPIKey: adminKey}
+
+	t.Run("until in the past is rejected", func(t *testing.T) {
+		st := pausedStore(time.Now().Add(time.Hour))
+		past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+		rec := serve(newTestServer(st, adminConfig), http.MethodPost, "/admin/users/pauseAssignment",
+			`{"user_id":"u2","paused":true,"until":"`+past+`"}`, admin)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "until must be in the future", resp.Error.Message)
+	})
+
+	t.Run("future until is passed to the store", func(t *testing.T) {
+		until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+		var got *time.Time
+		st := &mocks.Store{
+			SetAssignmentPausedFunc: func(_ context.Context, _ string, _ bool, u *time.Time) error {
+				got = u
+				return nil
+			},
+		}
+		rec := serve(newTestServer(st, adminConfig), http.MethodPost, "/admin/users/pauseAssignment",
+			`{"user_id":"u2","paused":true,"until":"`+until.Format(time.RFC3339)+`"}`, admin)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NotNil(t, got)
+		assert.True(t, until.Equal(*got))
+	})
+}
+
+func TestAssignmentPausedVisibility(t *testing.T) {
+	until := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		cfg     handlers.Config
+		header  map[string]string
+		visible bool
+	}{
+		{name: "without key", cfg: adminConfig},
+		{name: "with non-admin key", cfg: adminConfig, header: map[string]string{handlers.HeaderAPIKey: "client-key"}},
+		{name: "with admin key", cfg: adminConfig, header: map[string]string{handlers.HeaderAPIKey: adminKey}, visible: true},
+		{name: "admin keys not configured", header: map[string]string{handlers.HeaderAPIKey: adminKey}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestServer(pausedStore(until), tc.cfg)
+
+			t.Run("GetTeam", func(t *testing.T) {
+				rec := serve(e, http.MethodGet, "/team/get?team_name=backend", "", tc.header)
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				var resp struct {
+					Members []map[string]any `json:"members"`
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				require.Len(t, resp.Members, 2)
+				for _, member := range resp.Members {
+					_, ok := member["assignment_paused"]
+					assert.Equal(t, tc.visible, ok, "assignment_paused of %v", member["user_id"])
+				}
+				if tc.visible {
+					assert.Equal(t, false, resp.Members[0]["assignment_paused"])
+					assert.NotContains(t, resp.Members[0], "assignment_paused_until")
+					assert.Equal(t, true, resp.Members[1]["assignment_paused"])
+					assert.Equal(t, "2025-12-01T00:00:00Z", resp.Members[1]["assignment_paused_until"])
+				} else {
+					assert.NotContains(t, resp.Members[1], "assignment_paused_until")
+				}
+			})
+
+			t.Run("GetUser", func(t *testing.T) {
+				rec := serve(e, http.MethodGet, "/users/get?user_id=u2", "", tc.header)
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				var resp struct {
+					User map[string]any `json:"user"`
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				if tc.visible {
+					assert.Equal(t, true, resp.User["assignment_paused"])
+					assert.Equal(t, "2025-12-01T00:00:00Z", resp.User["assignment_paused_until"])
+				} else {
+					assert.NotContains(t, resp.User, "assignment_paused")
+					assert.NotContains(t, resp.User, "assignment_paused_until")
+				}
+			})
+
+			t.Run("PreviewReviewers", func(t *testing.T) {
+				rec := serve(e, http.MethodGet, "/pullRequest/previewReviewers?author_id=u1", "", tc.header)
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				if tc.visible {
+					assert.Equal(t, []any{"u2"}, resp["paused_members"])
+				} else {
+					assert.NotContains(t, resp, "paused_members")
+				}
+			})
+		})
+	}
+}
+
+func TestAssignmentPausedHiddenWithoutChangingStoredValue(t *testing.T) {
+	// Хранилище с кэшем отдает один и тот же объект: скрытие флага для клиента не должно его менять
+	paused := true
+	user := &models.User{UserID: "u2", Username: "Bob", AssignmentPaused: &paused}
+	team := &models.Team{TeamName: "backend", Members: []models.TeamMember{{UserID: "u2", AssignmentPaused: &paused}}}
+	st := &mocks.Store{
+		GetUserFunc: func(context.Context, string) (*models.User, error) { return user, nil },
+		GetTeamPageFunc: func(context.Context, string, int, int) (*models.Team, int, error) {
+			return team, 1, nil
+		},
+		GetTeamAssignmentFunc: func(context.Context, string) (*models.AssignmentSettings, error) {
+			return &models.AssignmentSettings{}, nil
+		},
+	}
+	e := newTestServer(st, adminConfig)
+
+	require.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/users/get?user_id=u2", "", nil).Code)
+	require.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/team/get?team_name=backend", "", nil).Code)
+
+	assert.NotNil(t, user.AssignmentPaused)
+	assert.NotNil(t, team.Members[0].AssignmentPaused)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/announcement"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetPullRequestAnnouncement рендерит текст комментария о назначенных ревьюерах
+func (h *Handler) GetPullRequestAnnouncement(c echo.Context) error {
+	prID := c.QueryParam("pull_request_id")
+	format := c.QueryParam("format")
+	if format == "" {
+		format = announcement.FormatGitHub
+	}
+
+	h.log(c).Info("GetPullRequestAnnouncement: рендеринг анонса", zap.String("pr_id", prID), zap.String("format", format))
+
+	if prID == "" {
+		h.log(c).Warn("GetPullRequestAnnouncement: параметр pull_request_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "pull_request_id parameter is required"))
+	}
+	if !announcement.IsSupportedFormat(format) {
+		h.log(c).Warn("GetPullRequestAnnouncement: неизвестный формат", zap.String("format", format))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, "format must be one of github, gitlab, slack"))
+	}
+
+	ctx := c.Request().Context()
+
+	pr, err := h.repo.GetPR(ctx, prRef(nil, prID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetPullRequestAnnouncement: PR не найден", zap.String("pr_id", prID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("GetPullRequestAnnouncement: ID PR есть в нескольких репозиториях", zap.String("pr_id", prID))
+			return ambiguousPR(c)
+		}
+		h.log(c).Error("GetPullRequestAnnouncement: ошибка получения PR", zap.Error(err), zap.String("pr_id", prID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get PR"))
+	}
+
+	// Автор может быть вне команды — тогда используется шаблон по умолчанию
+	var override string
+	settings, err := h.repo.GetPRTeamSettings(ctx, prID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		h.log(c).Error("GetPullRequestAnnouncement: ошибка получения настроек команды", zap.Error(err), zap.String("pr_id", prID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get team settings"))
+	}
+	if settings != nil && settings.AnnouncementTemplate != nil {
+		override = *settings.AnnouncementTemplate
+	}
+
+	text, err := announcement.Render(pr, format, override)
+	if err != nil {
+		h.log(c).Error("GetPullRequestAnnouncement: ошибка рендеринга", zap.Error(err), zap.String("pr_id", prID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to render announcement"))
+	}
+
+	h.log(c).Info("GetPullRequestAnnouncement: анонс сформирован", zap.String("pr_id", prID))
+
+	response := map[string]interface{}{
+		"pull_request_id": prID,
+		"format":          format,
+		"text":            text,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// APIV1Prefix — префикс версионированных маршрутов API. Несовместимые изменения контракта
+	// вносятся только в маршруты с версией, прежние пути без версии сохраняют старый формат.
+	APIV1Prefix = "/api/v1"
+
+	// HeaderDeprecation выставляется в ответах на прежние пути без версии
+	HeaderDeprecation = "Deprecation"
+	// HeaderSunset в ответах на прежние пути без версии сообщает дату их отключения (RFC 8594)
+	HeaderSunset = "Sunset"
+	// HeaderLink в ответах на прежние пути указывает путь-преемник с версией
+	HeaderLink = "Link"
+)
+
+// RegisterLegacyRoutes регистрирует в g прежние пути без версии (/team/add, /users/setIsActive, ...)
+// с теми же обработчиками, что и под APIV1Prefix, обернутыми адаптером старого формата (см. LegacyAdapter).
+// Middleware m применяются внутри адаптера: например, идемпотентность сохраняет ответ в формате /api/v1,
+// а повтор по прежнему пути получает его в старом формате.
+func (h *Handler) RegisterLegacyRoutes(g *echo.Group, m ...echo.MiddlewareFunc) {
+	h.RegisterRoutes(g, append([]echo.MiddlewareFunc{LegacyAdapter(h.cfg.LegacySunset)}, m...)...)
+}
+
+// LegacyAdapter возвращает адаптер обработчика для прежнего пути без версии. Ответ помечается заголовками
+// Deprecation: true, Sunset с датой отключения (если sunset не нулевая) и Link с путем-преемником
+// под APIV1Prefix (rel="successor-version"), а JSON-тело приводится к старому формату (см. legacyBody).
+func LegacyAdapter(sunset time.Time) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(HeaderDeprecation, "true")
+			if !sunset.IsZero() {
+				header.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+			header.Add(HeaderLink, `<`+APIV1Prefix+c.Path()+`>; rel="successor-version"`)
+
+			writer := &legacyResponseWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = writer
+			err := next(c)
+			c.Response().Writer = writer.ResponseWriter
+			if flushErr := writer.flush(); err == nil {
+				err = flushErr
+			}
+			return err
+		}
+	}
+}
+
+// legacyResponseWriter придерживает JSON-тело ответа, чтобы отдать его в старом формате;
+// остальные ответы пишутся как есть
+type legacyResponseWriter struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+func (w *legacyResponseWriter) Write(b []byte) (int, error) {
+	if !w.buffered && w.body.Len() == 0 {
+		w.buffered = strings.HasPrefix(w.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+	}
+	if !w.buffered {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (w *legacyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush пишет придержанное тело в старом формате
+func (w *legacyResponseWriter) flush() error {
+	if !w.buffered {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(legacyBody(w.body.Bytes()))
+	return err
+}
+
+// legacyBody приводит JSON-тело ответа /api/v1 к формату прежних путей: assigned_reviewers в PR —
+// плоский список ID ревьюеров, а не объекты. Тело без таких полей возвращается без изменений.
+func legacyBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || !flattenReviewers(value) {
+		return body
+	}
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(value); err != nil {
+		return body
+	}
+	return out.Bytes()
+}
+
+// flattenReviewers заменяет во всех объектах value массив assigned_reviewers из объектов
+// на массив их user_id. Возвращает true, если что-то заменено.
+func flattenReviewers(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if key == "assigned_reviewers" {
+				if ids, ok := reviewerIDs(field); ok {
+					v[key] = ids
+					changed = true
+					continue
+				}
+			}
+			changed = flattenReviewers(field) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = flattenReviewers(item) || changed
+		}
+	}
+	return changed
+}
+
+// reviewerIDs возвращает user_id ревьюеров из массива объектов assigned_reviewers
+func reviewerIDs(field any) ([]any, bool) {
+	reviewers, ok := field.([]any)
+	if !ok || len(reviewers) == 0 {
+		return nil, false
+	}
+	ids := make([]any, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		object, ok := reviewer.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, object["user_id"])
+	}
+	return ids, true
+}
+
+// unversionedPath возвращает путь маршрута без префикса версии, чтобы проверки по таблицам путей
+// одинаково работали для /api/v1 и прежних путей
+func unversionedPath(path string) string {
+	return strings.TrimPrefix(path, APIV1Prefix)
+}
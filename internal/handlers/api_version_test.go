@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// legacySunset — дата отключения прежних путей в тестах
+var legacySunset = time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// newVersionedServer собирает Echo с маршрутами под /api/v1 и прежними путями, как main.go
+func newVersionedServer(st *mocks.Store, cfg handlers.Config) *echo.Echo {
+	e := echo.New()
+	e.Binder = &handlers.Binder{}
+	e.HTTPErrorHandler = handlers.ErrorHandler(zap.NewNop())
+	h := handlers.New(st, service.New(st), metrics.New(prometheus.NewRegistry()), zap.NewNop(), cfg)
+	h.RegisterRoutes(e.Group(handlers.APIV1Prefix))
+	h.RegisterLegacyRoutes(e.Group(""))
+	return e
+}
+
+func TestLegacyRoutesMirrorV1(t *testing.T) {
+	e := newVersionedServer(&mocks.Store{}, handlers.Config{
+		GitHubWebhookSecret: "secret",
+		GitLabWebhookSecret: "token",
+	})
+
+	var v1, legacy []string
+	for _, route := range e.Routes() {
+		if path, ok := strings.CutPrefix(route.Path, handlers.APIV1Prefix); ok {
+			v1 = append(v1, route.Method+" "+path)
+			continue
+		}
+		legacy = append(legacy, route.Method+" "+route.Path)
+	}
+	sort.Strings(v1)
+	sort.Strings(legacy)
+
+	require.NotEmpty(t, v1)
+	assert.Equal(t, v1, legacy, "every /api/v1 route has a legacy alias and vice versa")
+	for _, route := range []string{"POST /team/add", "GET /team/get", "POST /users/setIsActive", "POST /pullRequest/create", "POST /webhooks/github"} {
+		assert.Contains(t, v1, route)
+	}
+}
+
+func TestLegacyRouteHeaders(t *testing.T) {
+	st := &mocks.Store{
+		GetPRFunc: func(context.Context, models.PRRef) (*models.PullRequest, error) {
+			return &models.PullRequest{PullRequestID: "pr-1", Status: models.StatusOpen}, nil
+		},
+	}
+
+	cases := []struct {
+		name        string
+		sunset      time.Time
+		target      string
+		deprecation string
+		sunsetValue string
+		link        string
+	}{
+		{
+			name: "legacy path", sunset: legacySunset, target: "/pullRequest/get?pull_request_id=pr-1",
+			deprecation: "true", sunsetValue: "Thu, 31 Dec 2026 00:00:00 GMT",
+			link: `</api/v1/pullRequest/get>; rel="successor-version"`,
+		},
+		{
+			name: "legacy path without sunset date", target: "/pullRequest/get?pull_request_id=pr-1",
+			deprecation: "true", link: `</api/v1/pullRequest/get>; rel="successor-version"`,
+		},
+		{
+			name: "versioned path", sunset: legacySunset, target: "/api/v1/pullRequest/get?pull_request_id=pr-1",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newVersionedServer(st, handlers.Config{LegacySunset: tc.sunset})
+			rec := serve(e, http.MethodGet, tc.target, "", nil)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, tc.deprecation, rec.Header().Get(handlers.HeaderDeprecation))
+			assert.Equal(t, tc.sunsetValue, rec.Header().Get(handlers.HeaderSunset))
+			assert.Equal(t, tc.link, rec.Header().Get(handlers.HeaderLink))
+		})
+	}
+}
+
+func TestLegacyRoutesKeepFlatReviewers(t *testing.T) {
+	reviewers := []models.AssignedReviewer{
+		{UserID: "u2", Username: "Bob", IsActive: true, Source: models.ReviewerSourceTeam},
+		{UserID: "u3", Username: "Carol", IsActive: true, Approved: true, Source: models.ReviewerSourceTeam},
+	}
+	st := &mocks.Store{
+		GetPRFunc: func(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+			pr := &models.PullRequest{PullRequestID: ref.ID, AuthorID: "u1", Status: models.StatusOpen,
+				AssignedReviewerIDs: []string{}, AssignedReviewers: []models.AssignedReviewer{}}
+			if ref.ID == "pr-1" {
+				pr.AssignedReviewers = reviewers
+				pr.AssignedReviewerIDs = []string{"u2", "u3"}
+			}
+			return pr, nil
+		},
+	}
+	e := newVersionedServer(st, handlers.Config{})
+
+	t.Run("versioned path returns reviewer objects", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=pr-1", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, reviewers, resp.PR.AssignedReviewers)
+	})
+
+	t.Run("legacy path returns reviewer IDs", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			PR struct {
+				PullRequestID       string   `json:"pull_request_id"`
+				Status              string   `json:"status"`
+				AssignedReviewers   []string `json:"assigned_reviewers"`
+				AssignedReviewerIDs []string `json:"assigned_reviewer_ids"`
+			} `json:"pr"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "pr-1", resp.PR.PullRequestID)
+		assert.Equal(t, models.StatusOpen, resp.PR.Status)
+		assert.Equal(t, []string{"u2", "u3"}, resp.PR.AssignedReviewers)
+		assert.Equal(t, []string{"u2", "u3"}, resp.PR.AssignedReviewerIDs)
+	})
+
+	t.Run("legacy path without reviewers is unchanged", func(t *testing.T) {
+		legacy := serve(e, http.MethodGet, "/pullRequest/get?pull_request_id=pr-2", "", nil)
+		v1 := serve(e, http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=pr-2", "", nil)
+		require.Equal(t, http.StatusOK, legacy.Code, legacy.Body.String())
+		assert.Equal(t, v1.Body.String(), legacy.Body.String())
+	})
+
+	t.Run("legacy errors keep their format", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/pullRequest/get", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, handlers.ErrCodeMissingParam, resp.Error.Code)
+		assert.Equal(t, "true", rec.Header().Get(handlers.HeaderDeprecation))
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetPullRequestHistory возвращает журнал изменений ревьюеров PR от старых событий к новым
+func (h *Handler) GetPullRequestHistory(c echo.Context) error {
+	prID := c.QueryParam("pull_request_id")
+	h.log(c).Info("GetPullRequestHistory: получение журнала назначений", zap.String("pr_id", prID))
+
+	if prID == "" {
+		h.log(c).Warn("GetPullRequestHistory: параметр pull_request_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "pull_request_id parameter is required"))
+	}
+
+	events, err := h.repo.GetAssignmentHistory(c.Request().Context(), prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetPullRequestHistory: PR не найден", zap.String("pr_id", prID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("GetPullRequestHistory: ID PR есть в нескольких репозиториях", zap.String("pr_id", prID))
+			return ambiguousPR(c)
+		}
+		h.log(c).Error("GetPullRequestHistory: ошибка получения журнала", zap.Error(err), zap.String("pr_id", prID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get PR history"))
+	}
+
+	h.log(c).Info("GetPullRequestHistory: журнал получен", zap.String("pr_id", prID), zap.Int("events_count", len(events)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
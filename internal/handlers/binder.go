@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrCodeUnsupportedMediaType — тело запроса передано не в JSON
+const ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+// Binder — echo.Binder со строгим разбором тела: принимается только application/json,
+// неизвестные поля отклоняются, чтобы опечатка в имени поля не превращалась в пустое значение.
+// Параметры пути и запроса связываются так же, как в echo.DefaultBinder.
+type Binder struct {
+	echo.DefaultBinder
+}
+
+// Bind связывает параметры пути, параметры запроса (для GET, DELETE и HEAD) и JSON-тело с i.
+// Ошибки возвращаются как *echo.HTTPError с сообщением для клиента (см. bindFailed).
+func (b *Binder) Bind(i any, c echo.Context) error {
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+
+	method := c.Request().Method
+	if method == http.MethodGet || method == http.MethodDelete || method == http.MethodHead {
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	return b.bindJSON(c, i)
+}
+
+// bindJSON разбирает JSON-тело запроса с отказом от неизвестных полей; пустое тело пропускается
+func (b *Binder) bindJSON(c echo.Context, i any) error {
+	req := c.Request()
+	if req.ContentLength == 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if err != nil || mediaType != echo.MIMEApplicationJSON {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be application/json").
+			SetInternal(err)
+	}
+
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(i); err != nil {
+		// encoding/json не экспортирует тип ошибки неизвестного поля, только текст: json: unknown field "name"
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return echo.NewHTTPError(http.StatusBadRequest, "unknown field "+field).SetInternal(err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				fmt.Sprintf("field %q must be %s", typeErr.Field, typeErr.Type)).SetInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body").SetInternal(err)
+	}
+	return nil
+}
+
+// bindFailed отвечает на ошибку c.Bind: 415 UNSUPPORTED_MEDIA_TYPE для тела не в JSON,
+// иначе 400 INVALID_BODY с причиной из Binder
+func bindFailed(c echo.Context, err error) error {
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+	if he.Code == http.StatusUnsupportedMediaType {
+		return c.JSON(http.StatusUnsupportedMediaType, newErrorResponse(c, ErrCodeUnsupportedMediaType, fmt.Sprint(he.Message)))
+	}
+	return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, fmt.Sprint(he.Message)))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// BodyLimit возвращает middleware, ограничивающее размер тела запроса limit байтами.
+// Тело дочитывается заранее (не больше limit+1 байт), поэтому превышение определяется
+// и по Content-Length, и для chunked-запросов, а ответ — 413 в стандартном формате ошибки.
+func BodyLimit(limit int64, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return bodyTooLarge(c, limit)
+			}
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+			if err != nil {
+				RequestLogger(c, logger).Error("BodyLimit: ошибка чтения тела запроса", zap.Error(err))
+				return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+			}
+			if int64(len(body)) > limit {
+				return bodyTooLarge(c, limit)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			return next(c)
+		}
+	}
+}
+
+// bodyTooLarge отвечает 413 с указанием допустимого размера тела
+func bodyTooLarge(c echo.Context, limit int64) error {
+	return c.JSON(http.StatusRequestEntityTooLarge, newErrorResponse(c, ErrCodePayloadTooLarge,
+		fmt.Sprintf("request body exceeds %d bytes", limit)))
+}
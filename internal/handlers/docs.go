@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// swaggerUIPage — минимальная страница Swagger UI; скрипты и стили загружаются с CDN unpkg
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>PR Reviewer Service — API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler отдает спецификацию OpenAPI и Swagger UI.
+// Отделен от Handler, потому что не обращается к хранилищу.
+type DocsHandler struct {
+	spec     []byte
+	enableUI bool
+}
+
+// NewDocsHandler создает обработчик документации: spec — спецификация в JSON,
+// enableUI включает страницу Swagger UI на /docs
+func NewDocsHandler(spec []byte, enableUI bool) *DocsHandler {
+	return &DocsHandler{spec: spec, enableUI: enableUI}
+}
+
+// RegisterRoutes регистрирует GET /openapi.json и, если включено, GET /docs
+func (h *DocsHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/openapi.json", h.Spec)
+	if h.enableUI {
+		e.GET("/docs", h.SwaggerUI)
+	}
+}
+
+// Spec отдает спецификацию OpenAPI в JSON
+func (h *DocsHandler) Spec(c echo.Context) error {
+	return c.JSONBlob(http.StatusOK, h.spec)
+}
+
+// SwaggerUI отдает страницу Swagger UI, которая загружает /openapi.json
+func (h *DocsHandler) SwaggerUI(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUIPage)
+}
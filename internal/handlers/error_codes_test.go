@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// storeError описывает, как заставить обработчик получить err от хранилища
+type storeError struct {
+	method string
+	target string
+	body   string
+	fail   func(st *mocks.Store, err error)
+}
+
+var (
+	createPR = storeError{
+		method: http.MethodPost, target: "/pullRequest/create",
+		body: `{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.CreatePRFunc = func(context.Context, string, string, string, string, string, models.PRMetadata) (*models.PullRequest, error) {
+				return nil, err
+			}
+		},
+	}
+	reassign = storeError{
+		method: http.MethodPost, target: "/pullRequest/reassign", body: `{"pull_request_id":"pr-1","old_user_id":"u2"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.ReassignReviewerFunc = func(context.Context, models.PRRef, string, string, *int64) (string, error) { return "", err }
+		},
+	}
+	approve = storeError{
+		method: http.MethodPost, target: "/pullRequest/approve", body: `{"pull_request_id":"pr-1","user_id":"u2"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.ApprovePRFunc = func(context.Context, models.PRRef, string) (*models.PullRequest, error) { return nil, err }
+		},
+	}
+	closePR = storeError{
+		method: http.MethodPost, target: "/pullRequest/close", body: `{"pull_request_id":"pr-1"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.ClosePRFunc = func(context.Context, models.PRRef, *int64) (*models.PullRequest, error) { return nil, err }
+		},
+	}
+	mergePR = storeError{
+		method: http.MethodPost, target: "/pullRequest/merge", body: `{"pull_request_id":"pr-1"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.MergePRFunc = func(context.Context, models.PRRef, *int64) (*models.PullRequest, bool, error) { return nil, false, err }
+		},
+	}
+	getUser = storeError{
+		method: http.MethodGet, target: "/users/get?user_id=u1",
+		fail: func(st *mocks.Store, err error) {
+			st.GetUserFunc = func(context.Context, string) (*models.User, error) { return nil, err }
+		},
+	}
+	deleteTeam = storeError{
+		method: http.MethodDelete, target: "/team/delete?team_name=backend",
+		fail: func(st *mocks.Store, err error) {
+			st.DeleteTeamFunc = func(context.Context, string, bool) error { return err }
+		},
+	}
+	addMember = storeError{
+		method: http.MethodPost, target: "/team/addMember", body: `{"team_name":"backend","user_id":"u1","username":"Alice"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.AddTeamMemberFunc = func(context.Context, string, models.TeamMember) (*models.Team, error) { return nil, err }
+		},
+	}
+	removeMember = storeError{
+		method: http.MethodPost, target: "/team/removeMember", body: `{"team_name":"backend","user_id":"u1"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.RemoveTeamMemberFunc = func(context.Context, string, string) (*models.Team, error) { return nil, err }
+		},
+	}
+	linkAccount = storeError{
+		method: http.MethodPost, target: "/users/linkAccount", body: `{"user_id":"u1","provider":"github","account_id":"octocat"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.AddExternalAccountFunc = func(context.Context, models.ExternalAccount) error { return err }
+		},
+	}
+	addVacation = storeError{
+		method: http.MethodPost, target: "/users/vacation",
+		body: `{"user_id":"u1","from":"2025-12-01T00:00:00Z","to":"2025-12-10T00:00:00Z"}`,
+		fail: func(st *mocks.Store, err error) {
+			st.AddVacationFunc = func(context.Context, string, time.Time, time.Time) (*models.Vacation, error) { return nil, err }
+		},
+	}
+)
+
+// TestStoreErrorCodes проверяет, что каждая ошибка хранилища отдается своим статусом и кодом,
+// в том числе обернутая: обработчики сравнивают ошибки через errors.Is/errors.As
+func TestStoreErrorCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		call   storeError
+		err    error
+		status int
+		code   string
+	}{
+		{"not found", getUser, repository.ErrNotFound, http.StatusNotFound, handlers.ErrCodeNotFound},
+		{"PR exists", createPR, repository.ErrAlreadyExists, http.StatusConflict, handlers.ErrCodePRExists},
+		{"no reviewers on create", createPR, repository.ErrNoReviewers, http.StatusConflict, handlers.ErrCodeNoCandidate},
+		{"author not in team", createPR, repository.ErrAuthorNotInTeam, http.StatusConflict, handlers.ErrCodeAuthorNotInTeam},
+		{"ambiguous team", createPR, &repository.AmbiguousTeamError{Teams: []string{"a", "b"}}, http.StatusConflict, handlers.ErrCodeAmbiguousTeam},
+		{"not assigned on reassign", reassign, repository.ErrNotAssigned, http.StatusConflict, handlers.ErrCodeNotAssigned},
+		{"not assigned on approve", approve, repository.ErrNotAssigned, http.StatusConflict, handlers.ErrCodeNotAssigned},
+		{"no candidate", reassign, repository.ErrNoCandidate, http.StatusConflict, handlers.ErrCodeNoCandidate},
+		{"merged PR on reassign", reassign, repository.ErrAlreadyMerged, http.StatusConflict, handlers.ErrCodePRMerged},
+		{"merged PR on close", closePR, repository.ErrAlreadyMerged, http.StatusConflict, handlers.ErrCodePRMerged},
+		{"closed PR", reassign, repository.ErrAlreadyClosed, http.StatusConflict, handlers.ErrCodePRClosed},
+		{"ambiguous PR", mergePR, repository.ErrAmbiguousPR, http.StatusConflict, handlers.ErrCodeAmbiguousPR},
+		{"not enough approvals", mergePR, &repository.NotApprovedError{Approvals: 0, Required: 1}, http.StatusConflict, handlers.ErrCodeNotEnoughApprovals},
+		{"invalid transition", mergePR, repository.ErrInvalidTransition, http.StatusConflict, handlers.ErrCodeInvalidTransition},
+		{"lead required", reassign, repository.ErrLeadRequired, http.StatusConflict, handlers.ErrCodeLeadRequired},
+		{"already assigned", reassign, repository.ErrAlreadyAssigned, http.StatusConflict, handlers.ErrCodeAlreadyAssigned},
+		{"candidate not eligible", reassign, repository.ErrCandidateNotEligible, http.StatusConflict, handlers.ErrCodeCandidateNotEligible},
+		{"team has open PRs", deleteTeam, repository.ErrTeamHasOpenPRs, http.StatusConflict, handlers.ErrCodeTeamHasOpenPRs},
+		{"already member", addMember, repository.ErrAlreadyMember, http.StatusConflict, handlers.ErrCodeAlreadyMember},
+		{"not member", removeMember, repository.ErrNotMember, http.StatusConflict, handlers.ErrCodeNotMember},
+		{"account linked", linkAccount, repository.ErrAccountLinked, http.StatusConflict, handlers.ErrCodeAccountLinked},
+		{"vacation overlap", addVacation, repository.ErrVacationOverlap, http.StatusConflict, handlers.ErrCodeVacationOverlap},
+		{"internal", getUser, errDB, http.StatusInternalServerError, handlers.ErrCodeInternal},
+	}
+	for _, tc := range cases {
+		for _, wrapped := range []bool{false, true} {
+			name := tc.name
+			err := tc.err
+			if wrapped {
+				name += " wrapped"
+				err = fmt.Errorf("failed to do something: %w", tc.err)
+			}
+			t.Run(name, func(t *testing.T) {
+				st := &mocks.Store{}
+				tc.call.fail(st, err)
+				rec := serve(newTestServer(st, handlers.Config{}), tc.call.method, tc.call.target, tc.call.body, nil)
+
+				require.Equal(t, tc.status, rec.Code, rec.Body.String())
+				var resp handlers.ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tc.code, resp.Error.Code)
+				assert.NotEmpty(t, resp.Error.Message)
+			})
+		}
+	}
+}
+
+// TestRequestErrorCodes проверяет коды ошибок самого запроса: до хранилища он не доходит
+func TestRequestErrorCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   string
+	}{
+		{"malformed JSON", http.MethodPost, "/pullRequest/create", `{"pull_request_id":`, handlers.ErrCodeInvalidBody},
+		{"wrong field type", http.MethodPost, "/users/setIsActive", `{"user_id":1}`, handlers.ErrCodeInvalidBody},
+		{"missing query parameter", http.MethodGet, "/team/get", "", handlers.ErrCodeMissingParam},
+		{"invalid query parameter", http.MethodGet, "/users/getReview?user_id=u1&status=DRAFT", "", handlers.ErrCodeInvalidParam},
+		{"invalid pagination", http.MethodGet, "/team/list?limit=0", "", handlers.ErrCodeInvalidParam},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(newTestServer(&mocks.Store{}, handlers.Config{}), tc.method, tc.target, tc.body, nil)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			var resp handlers.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.code, resp.Error.Code)
+			assert.NotEqual(t, handlers.ErrCodeNotFound, resp.Error.Code, "NOT_FOUND is only for missing resources")
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxExclusionReasonLength ограничивает длину причины запрета назначения (в символах)
+const maxExclusionReasonLength = 500
+
+// AddAssignmentExclusion запрещает назначать reviewer_id ревьюером PR автора author_id.
+// Запрет направленный; повторный вызов для той же пары обновляет причину.
+func (h *Handler) AddAssignmentExclusion(c echo.Context) error {
+	h.log(c).Info("AddAssignmentExclusion: начало обработки запроса")
+
+	var req struct {
+		AuthorID   string `json:"author_id"`
+		ReviewerID string `json:"reviewer_id"`
+		Reason     string `json:"reason"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("AddAssignmentExclusion: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.AuthorID = h.normalizeID(req.AuthorID)
+	req.ReviewerID = h.normalizeID(req.ReviewerID)
+
+	if req.AuthorID == "" || req.ReviewerID == "" {
+		h.log(c).Warn("AddAssignmentExclusion: не заполнены обязательные поля")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "author_id and reviewer_id are required"))
+	}
+	if req.AuthorID == req.ReviewerID {
+		h.log(c).Warn("AddAssignmentExclusion: запрет на самого себя", zap.String("user_id", req.AuthorID))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "author_id and reviewer_id must differ"))
+	}
+	if utf8.RuneCountInString(req.Reason) > maxExclusionReasonLength {
+		h.log(c).Warn("AddAssignmentExclusion: слишком длинная причина")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody,
+			fmt.Sprintf("reason must be at most %d characters", maxExclusionReasonLength)))
+	}
+
+	exclusion, err := h.repo.AddExclusion(c.Request().Context(), req.AuthorID, req.ReviewerID, req.Reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("AddAssignmentExclusion: пользователь не найден",
+				zap.String("author_id", req.AuthorID),
+				zap.String("reviewer_id", req.ReviewerID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "author or reviewer not found"))
+		}
+		if errors.Is(err, repository.ErrInvalidInput) {
+			// Разные внешние ID одного пользователя (например, при нормализации ID в хранилище)
+			h.log(c).Warn("AddAssignmentExclusion: запрет на самого себя", zap.Error(err))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "author_id and reviewer_id must differ"))
+		}
+		h.log(c).Error("AddAssignmentExclusion: ошибка добавления запрета", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to add exclusion"))
+	}
+
+	h.log(c).Info("AddAssignmentExclusion: запрет назначения добавлен",
+		zap.String("author_id", exclusion.AuthorID),
+		zap.String("reviewer_id", exclusion.ReviewerID))
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"exclusion": exclusion})
+}
+
+// RemoveAssignmentExclusion снимает запрет назначать reviewer_id ревьюером PR автора author_id
+func (h *Handler) RemoveAssignmentExclusion(c echo.Context) error {
+	authorID := h.normalizeID(c.QueryParam("author_id"))
+	reviewerID := h.normalizeID(c.QueryParam("reviewer_id"))
+	h.log(c).Info("RemoveAssignmentExclusion: удаление запрета назначения",
+		zap.String("author_id", authorID),
+		zap.String("reviewer_id", reviewerID))
+
+	if authorID == "" || reviewerID == "" {
+		h.log(c).Warn("RemoveAssignmentExclusion: параметры author_id или reviewer_id отсутствуют")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "author_id and reviewer_id parameters are required"))
+	}
+
+	if err := h.repo.RemoveExclusion(c.Request().Context(), authorID, reviewerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("RemoveAssignmentExclusion: запрет не найден",
+				zap.String("author_id", authorID),
+				zap.String("reviewer_id", reviewerID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "exclusion not found"))
+		}
+		h.log(c).Error("RemoveAssignmentExclusion: ошибка удаления запрета", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to remove exclusion"))
+	}
+
+	h.log(c).Info("RemoveAssignmentExclusion: запрет назначения снят",
+		zap.String("author_id", authorID),
+		zap.String("reviewer_id", reviewerID))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"author_id":   authorID,
+		"reviewer_id": reviewerID,
+	})
+}
+
+// ListAssignmentExclusions возвращает запреты назначения, где пользователь — автор или ревьюер
+func (h *Handler) ListAssignmentExclusions(c echo.Context) error {
+	userID := h.normalizeID(c.QueryParam("user_id"))
+	h.log(c).Info("ListAssignmentExclusions: получение запретов назначения", zap.String("user_id", userID))
+
+	if userID == "" {
+		h.log(c).Warn("ListAssignmentExclusions: параметр user_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id parameter is required"))
+	}
+
+	exclusions, err := h.repo.ListExclusions(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("ListAssignmentExclusions: пользователь не найден", zap.String("user_id", userID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("ListAssignmentExclusions: ошибка получения запретов", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list exclusions"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user_id":    userID,
+		"exclusions": exclusions,
+	})
+}
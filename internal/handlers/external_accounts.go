@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// supportedProvider сообщает, присылает ли внешняя система provider события PR
+func supportedProvider(provider string) bool {
+	return provider == models.ProviderGitHub || provider == models.ProviderGitLab
+}
+
+// errUnsupportedProvider — сообщение об ошибке для неизвестной внешней системы
+const errUnsupportedProvider = "provider must be " + models.ProviderGitHub + " or " + models.ProviderGitLab
+
+// LinkUserAccount привязывает учетную запись GitHub или GitLab к пользователю, чтобы вебхуки находили
+// по ней автора и ревьюеров. У пользователя может быть несколько учетных записей, но каждая учетная запись
+// принадлежит одному пользователю: привязанная к другому дает 409 ACCOUNT_ALREADY_LINKED.
+// Повторная привязка к тому же пользователю ничего не меняет.
+func (h *Handler) LinkUserAccount(c echo.Context) error {
+	h.log(c).Info("LinkUserAccount: начало обработки запроса")
+
+	var req models.ExternalAccount
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("LinkUserAccount: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+	req.AccountID = normalizeAccountID(req.Provider, req.AccountID)
+
+	if !supportedProvider(req.Provider) {
+		h.log(c).Warn("LinkUserAccount: неизвестная внешняя система", zap.String("provider", req.Provider))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, errUnsupportedProvider))
+	}
+	if req.UserID == "" || req.AccountID == "" {
+		h.log(c).Warn("LinkUserAccount: не заполнены обязательные поля")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id and account_id are required"))
+	}
+
+	log := h.log(c).With(
+		zap.String("user_id", req.UserID),
+		zap.String("provider", req.Provider),
+		zap.String("account_id", req.AccountID))
+
+	if err := h.repo.AddExternalAccount(c.Request().Context(), req); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("LinkUserAccount: пользователь не найден")
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		if errors.Is(err, repository.ErrAccountLinked) {
+			log.Warn("LinkUserAccount: учетная запись привязана к другому пользователю")
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAccountLinked, "account is already linked to another user"))
+		}
+		log.Error("LinkUserAccount: ошибка привязки учетной записи", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to link account"))
+	}
+
+	log.Info("LinkUserAccount: учетная запись привязана")
+	return c.JSON(http.StatusCreated, map[string]interface{}{"account": req})
+}
+
+// UnlinkUserAccount отвязывает учетную запись внешней системы от пользователя
+func (h *Handler) UnlinkUserAccount(c echo.Context) error {
+	provider := c.QueryParam("provider")
+	accountID := normalizeAccountID(provider, c.QueryParam("account_id"))
+	h.log(c).Info("UnlinkUserAccount: отвязка учетной записи",
+		zap.String("provider", provider),
+		zap.String("account_id", accountID))
+
+	if provider == "" || accountID == "" {
+		h.log(c).Warn("UnlinkUserAccount: параметры provider или account_id отсутствуют")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "provider and account_id parameters are required"))
+	}
+	if !supportedProvider(provider) {
+		h.log(c).Warn("UnlinkUserAccount: неизвестная внешняя система", zap.String("provider", provider))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, errUnsupportedProvider))
+	}
+
+	account, err := h.repo.UnlinkExternalAccount(c.Request().Context(), provider, accountID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("UnlinkUserAccount: учетная запись не привязана",
+				zap.String("provider", provider),
+				zap.String("account_id", accountID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "account is not linked"))
+		}
+		h.log(c).Error("UnlinkUserAccount: ошибка отвязки учетной записи", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to unlink account"))
+	}
+
+	h.log(c).Info("UnlinkUserAccount: учетная запись отвязана",
+		zap.String("user_id", account.UserID),
+		zap.String("provider", provider),
+		zap.String("account_id", accountID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"account": account})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetFairnessReport возвращает распределение назначений ревьюеров между активными участниками команды
+// с даты since и коэффициент Джини как оценку справедливости назначения
+func (h *Handler) GetFairnessReport(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("GetFairnessReport: получение распределения назначений", zap.String("team_name", teamName))
+
+	if teamName == "" {
+		h.log(c).Warn("GetFairnessReport: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
+	}
+
+	since, err := parseSinceParam(c)
+	if err != nil {
+		h.log(c).Warn("GetFairnessReport: некорректный параметр since", zap.String("since", c.QueryParam("since")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	report, err := h.repo.GetFairnessReport(c.Request().Context(), teamName, since)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetFairnessReport: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetFairnessReport: ошибка получения распределения", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get fairness report"))
+	}
+
+	h.log(c).Info("GetFairnessReport: распределение назначений получено",
+		zap.String("team_name", teamName),
+		zap.Int("members_count", len(report.Members)),
+		zap.Int("total_assignments", report.TotalAssignments))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"fairness": report})
+}
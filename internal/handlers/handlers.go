@@ -3,10 +3,14 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
 	"github.com/untibullet/pr-manager-avito/internal/models"
 	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
 	"go.uber.org/zap"
 )
 
@@ -15,277 +19,995 @@ const (
 	ErrCodeTeamExists  = "TEAM_EXISTS"
 	ErrCodePRExists    = "PR_EXISTS"
 	ErrCodePRMerged    = "PR_MERGED"
+	ErrCodePRClosed    = "PR_CLOSED"
 	ErrCodeNotAssigned = "NOT_ASSIGNED"
 	ErrCodeNoCandidate = "NO_CANDIDATE"
 	ErrCodeNotFound    = "NOT_FOUND"
+
+	ErrCodeInvalidTransition = "INVALID_STATUS_TRANSITION"
+
+	ErrCodeTeamHasOpenPRs = "TEAM_HAS_OPEN_PRS"
+	ErrCodeAlreadyMember  = "ALREADY_MEMBER"
+	ErrCodeNotMember      = "NOT_MEMBER"
+	ErrCodeAccountLinked  = "ACCOUNT_ALREADY_LINKED"
+	ErrCodeValidation     = "VALIDATION_FAILED"
+
+	ErrCodeVacationOverlap = "VACATION_OVERLAP"
+
+	ErrCodeNotEnoughApprovals = "NOT_ENOUGH_APPROVALS"
+
+	ErrCodeAuthorNotInTeam = "AUTHOR_NOT_IN_TEAM"
+	ErrCodeAmbiguousTeam   = "AMBIGUOUS_TEAM"
+	// ErrCodeAmbiguousPR — PR указан без репозитория, а его ID есть в нескольких репозиториях
+	ErrCodeAmbiguousPR = "AMBIGUOUS_PR"
+	// ErrCodeVersionConflict — PR изменили после того, как клиент прочитал версию из expected_version или If-Match
+	ErrCodeVersionConflict = "VERSION_CONFLICT"
+
+	ErrCodeCandidateNotEligible = "CANDIDATE_NOT_ELIGIBLE"
+	ErrCodeAlreadyAssigned      = "ALREADY_ASSIGNED"
+	ErrCodeMaxReviewers         = "MAX_REVIEWERS"
+	// ErrCodeLeadRequired — лидера команды с always_include_lead нельзя заменить автоматически
+	ErrCodeLeadRequired = "LEAD_REQUIRED"
+
+	ErrCodeIdempotencyMismatch   = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeIdempotencyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+
+	ErrCodePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+	ErrCodeRateLimited     = "RATE_LIMITED"
+
+	ErrCodeUnauthorized = "UNAUTHORIZED"
+
+	ErrCodeInvalidBody  = "INVALID_BODY"
+	ErrCodeMissingParam = "MISSING_PARAM"
+	ErrCodeInvalidParam = "INVALID_PARAM"
+	ErrCodeInternal     = "INTERNAL_ERROR"
+)
+
+// Предупреждения в успешных ответах
+const (
+	// WarnNoReviewersAssigned — PR создан, но ни одного ревьюера назначить не удалось
+	WarnNoReviewersAssigned = "NO_REVIEWERS_ASSIGNED"
+	// WarnAccountNotLinked — событие вебхука не применено: учетная запись автора или ревьюера
+	// не привязана к пользователю
+	WarnAccountNotLinked = "ACCOUNT_NOT_LINKED"
+)
+
+const (
+	// maxBatchSize ограничивает количество PR в одном batch-запросе
+	maxBatchSize = 100
+
+	// Параметры пагинации списков
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+
+	// maxTeamMembersUnpaged ограничивает число участников в ответе /team/get без пагинации
+	maxTeamMembersUnpaged = 1000
+
+	// dateLayout задает формат дат в параметрах запросов
+	dateLayout = "2006-01-02"
+
+	// defaultHistoryDays задает период истории загрузки по умолчанию, если from не указан
+	defaultHistoryDays = 30
 )
 
+// Config задает настройки обработчиков
+type Config struct {
+	// FoldIDs включает нормализацию внешних ID пользователей (trim + lower) на входе
+	FoldIDs bool
+	// GitHubWebhookSecret — секрет подписи вебхуков GitHub; пустой отключает /webhooks/github
+	GitHubWebhookSecret string
+	// GitLabWebhookSecret — секрет X-Gitlab-Token вебхуков GitLab; пустой отключает /webhooks/gitlab
+	GitLabWebhookSecret string
+	// WebhookDeliveryTTL — сколько помнить ID принятых доставок вебхуков для дедупликации
+	WebhookDeliveryTTL time.Duration
+	// MaxReviewers — верхняя граница reviewers_per_pr в настройках команды (0 — без ограничения)
+	MaxReviewers int
+	// OrphanInactiveAfter — через сколько после деактивации POST /admin/sweepOrphans переназначает ревью пользователя
+	OrphanInactiveAfter time.Duration
+	// OrphanSweepLimit — сколько ревью POST /admin/sweepOrphans обрабатывает за вызов
+	OrphanSweepLimit int
+	// LegacySunset — дата отключения прежних путей без версии для заголовка Sunset; нулевая — без заголовка
+	LegacySunset time.Time
+	// AdminAPIKeys — ключи X-API-Key с доступом к /admin/*; остальным клиентам не показывается
+	// приостановка автоназначения пользователей. Пустой список закрывает /admin/* для всех.
+	AdminAPIKeys []string
+}
+
 type Handler struct {
-	repo   *repository.Repository
-	logger *zap.Logger
+	repo     Store
+	services *service.Services
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	cfg      Config
 }
 
-// New создает новый экземпляр обработчика
-func New(repo *repository.Repository, logger *zap.Logger) *Handler {
+// New создает новый экземпляр обработчика.
+// Сценарии, перенесенные в сервисный слой, вызываются через services, остальные — напрямую через repo.
+// Бизнес-счетчики увеличиваются в metrics.
+func New(repo Store, services *service.Services, m *metrics.Metrics, logger *zap.Logger, cfg Config) *Handler {
 	return &Handler{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		services: services,
+		metrics:  m,
+		logger:   logger,
+		cfg:      cfg,
 	}
 }
 
-// RegisterRoutes регистрирует все маршруты API
-func (h *Handler) RegisterRoutes(e *echo.Echo) {
+// normalizeID приводит внешний ID пользователя к каноничному виду, если включена нормализация
+func (h *Handler) normalizeID(id string) string {
+	if !h.cfg.FoldIDs {
+		return id
+	}
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// RegisterRoutes регистрирует все маршруты API в группе g; в сервисе это группа APIV1Prefix.
+// Middleware m применяются к каждому маршруту: группу с middleware Echo дополняет маршрутом-ловушкой
+// для всех путей под префиксом, поэтому они передаются маршрутам, а не группе.
+func (h *Handler) RegisterRoutes(g *echo.Group, m ...echo.MiddlewareFunc) {
 	// Teams
-	e.POST("/team/add", h.CreateTeam)
-	e.GET("/team/get", h.GetTeam)
+	g.POST("/team/add", h.CreateTeam, m...)
+	g.GET("/team/get", h.GetTeam, m...)
+	g.GET("/team/list", h.ListTeams, m...)
+	g.POST("/team/settings", h.UpdateTeamSettings, m...)
+	g.DELETE("/team/delete", h.DeleteTeam, m...)
+	g.POST("/team/addMember", h.AddTeamMember, m...)
+	g.POST("/team/removeMember", h.RemoveTeamMember, m...)
 
 	// Users
-	e.POST("/users/setIsActive", h.SetUserIsActive)
-	e.GET("/users/getReview", h.GetUserReviews)
+	g.GET("/users/get", h.GetUser, m...)
+	g.POST("/users/setIsActive", h.SetUserIsActive, m...)
+	g.POST("/users/setIsActiveBatch", h.SetUsersIsActiveBatch, m...)
+	g.POST("/users/setCapacity", h.SetUserCapacity, m...)
+	g.POST("/users/setReviewerEligibility", h.SetReviewerEligibility, m...)
+	g.GET("/users/getReview", h.GetUserReviews, m...)
+	g.POST("/users/vacation", h.AddUserVacation, m...)
+	g.DELETE("/users/vacation", h.DeleteUserVacation, m...)
+	g.POST("/users/linkAccount", h.LinkUserAccount, m...)
+	g.DELETE("/users/unlinkAccount", h.UnlinkUserAccount, m...)
+
+	// Exclusions
+	g.POST("/exclusions/add", h.AddAssignmentExclusion, m...)
+	g.DELETE("/exclusions/remove", h.RemoveAssignmentExclusion, m...)
+	g.GET("/exclusions/list", h.ListAssignmentExclusions, m...)
 
 	// Pull Requests
-	e.POST("/pullRequest/create", h.CreatePullRequest)
-	e.POST("/pullRequest/merge", h.MergePullRequest)
-	e.POST("/pullRequest/reassign", h.ReassignReviewer)
-	
+	g.POST("/pullRequest/create", h.CreatePullRequest, m...)
+	g.GET("/pullRequest/get", h.GetPullRequest, m...)
+	g.GET("/pullRequest/history", h.GetPullRequestHistory, m...)
+	g.POST("/pullRequest/getBatch", h.GetPullRequestsBatch, m...)
+	g.GET("/pullRequest/announcement", h.GetPullRequestAnnouncement, m...)
+	g.GET("/pullRequest/unassigned", h.GetUnassignedPullRequests, m...)
+	g.GET("/pullRequest/previewReviewers", h.PreviewReviewers, m...)
+	g.GET("/pullRequest/overdue", h.GetOverduePullRequests, m...)
+	g.GET("/pullRequest/listByTeam", h.ListTeamPullRequests, m...)
+	g.GET("/pullRequest/listByAuthor", h.ListAuthorPullRequests, m...)
+	g.GET("/pullRequest/search", h.SearchPullRequests, m...)
+	g.POST("/pullRequest/merge", h.MergePullRequest, m...)
+	g.POST("/pullRequest/close", h.ClosePullRequest, m...)
+	g.POST("/pullRequest/reopen", h.ReopenPullRequest, m...)
+	g.POST("/pullRequest/approve", h.ApprovePullRequest, m...)
+	g.POST("/pullRequest/reassign", h.ReassignReviewer, m...)
+	g.POST("/pullRequest/addReviewer", h.AddReviewer, m...)
+	g.POST("/pullRequest/update", h.UpdatePullRequest, m...)
+
 	// Statistics
-	e.GET("/stats", h.GetStats)
+	g.GET("/stats", h.GetStats, m...)
+	g.GET("/stats/loadHistory", h.GetLoadHistory, m...)
+	g.GET("/stats/team", h.GetTeamStats, m...)
+	g.GET("/stats/fairness", h.GetFairnessReport, m...)
+
+	// Admin: ключ проверяется раньше остальных middleware, чтобы отказ не сохранялся как ответ идемпотентного запроса
+	admin := append([]echo.MiddlewareFunc{h.adminOnly}, m...)
+	g.POST("/admin/users/pauseAssignment", h.PauseUserAssignment, admin...)
+	g.POST("/admin/bootstrap", h.Bootstrap, admin...)
+	g.POST("/admin/users/externalAccount", h.LinkExternalAccount, admin...)
+	g.POST("/admin/sweepOrphans", h.SweepOrphans, admin...)
+
+	// Webhooks
+	if h.cfg.GitHubWebhookSecret != "" {
+		g.POST("/webhooks/github", h.GitHubWebhook, m...)
+	}
+	if h.cfg.GitLabWebhookSecret != "" {
+		g.POST("/webhooks/gitlab", h.GitLabWebhook, m...)
+	}
+	g.POST("/webhooks/create", h.CreateWebhook, m...)
+	g.GET("/webhooks/list", h.ListWebhooks, m...)
+	g.GET("/webhooks/get", h.GetWebhook, m...)
+	g.POST("/webhooks/update", h.UpdateWebhook, m...)
+	g.DELETE("/webhooks/delete", h.DeleteWebhook, m...)
+	g.GET("/webhooks/deliveries", h.ListWebhookDeliveries, m...)
 }
 
 // ErrorResponse представляет структуру ошибки API
 type ErrorResponse struct {
 	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code    string   `json:"code"`
+		Message string   `json:"message"`
+		Details []string `json:"details,omitempty"`
 	} `json:"error"`
+	// RequestID — ID запроса (совпадает с заголовком X-Request-Id), по нему запрос ищется в логах
+	RequestID string `json:"request_id,omitempty"`
 }
 
-// newErrorResponse создает стандартный ответ с ошибкой
-func newErrorResponse(code, message string) ErrorResponse {
+// newErrorResponse создает стандартный ответ с ошибкой и ID текущего запроса
+func newErrorResponse(c echo.Context, code, message string) ErrorResponse {
 	var resp ErrorResponse
 	resp.Error.Code = code
 	resp.Error.Message = message
+	resp.RequestID = RequestID(c.Request().Context())
 	return resp
 }
 
 // CreateTeam создает новую команду
 func (h *Handler) CreateTeam(c echo.Context) error {
-	h.logger.Info("CreateTeam: начало обработки запроса")
+	h.log(c).Info("CreateTeam: начало обработки запроса")
 
-	var req models.Team
+	var req struct {
+		models.Team
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+	}
 	if err := c.Bind(&req); err != nil {
-		h.logger.Error("CreateTeam: ошибка парсинга тела запроса", zap.Error(err))
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "invalid request body"))
+		h.log(c).Error("CreateTeam: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+
+	for i := range req.Members {
+		req.Members[i].UserID = h.normalizeID(req.Members[i].UserID)
+		if !models.ValidSlackUserID(req.Members[i].SlackUserID) {
+			h.log(c).Warn("CreateTeam: некорректный slack_user_id", zap.String("user_id", req.Members[i].UserID))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "slack_user_id must be a Slack member ID"))
+		}
+		if !models.ValidMaxOpenReviews(req.Members[i].MaxOpenReviews) {
+			h.log(c).Warn("CreateTeam: некорректный max_open_reviews", zap.String("user_id", req.Members[i].UserID))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "max_open_reviews must be a positive integer"))
+		}
+		if !models.ValidTeamRole(req.Members[i].Role) {
+			h.log(c).Warn("CreateTeam: некорректная роль участника", zap.String("user_id", req.Members[i].UserID), zap.String("role", req.Members[i].Role))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "role must be one of member, lead"))
+		}
+		if req.Members[i].Role == "" {
+			req.Members[i].Role = models.TeamRoleMember
+		}
+		// Приостановка автоназначения задается только через POST /admin/users/pauseAssignment
+		req.Members[i].AssignmentPaused = nil
+		req.Members[i].AssignmentPausedUntil = nil
 	}
 
-	h.logger.Info("CreateTeam: валидация данных команды", zap.String("team_name", req.TeamName), zap.Int("members_count", len(req.Members)))
+	h.log(c).Info("CreateTeam: валидация данных команды", zap.String("team_name", req.TeamName), zap.Int("members_count", len(req.Members)))
 
-	team, err := h.repo.CreateTeam(c.Request().Context(), req)
+	team, err := h.services.Teams.Create(c.Request().Context(), req.Team)
 	if err != nil {
-		h.logger.Error("CreateTeam: ошибка создания команды", zap.Error(err), zap.String("team_name", req.TeamName))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to create team"))
+		h.log(c).Error("CreateTeam: ошибка создания команды", zap.Error(err), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to create team"))
 	}
 
-	h.logger.Info("CreateTeam: команда успешно создана", zap.String("team_name", team.TeamName))
-	return c.JSON(http.StatusCreated, map[string]interface{}{"team": team})
+	h.log(c).Info("CreateTeam: команда успешно создана", zap.String("team_name", team.TeamName))
+	return c.JSON(http.StatusCreated, map[string]interface{}{"team": h.teamView(c, team)})
 }
 
 // GetTeam получает команду по имени
 func (h *Handler) GetTeam(c echo.Context) error {
 	teamName := c.QueryParam("team_name")
-	h.logger.Info("GetTeam: получение команды", zap.String("team_name", teamName))
+	h.log(c).Info("GetTeam: получение команды", zap.String("team_name", teamName))
+
+	if teamName == "" {
+		h.log(c).Warn("GetTeam: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
+	}
+
+	// Без limit/offset возвращается не больше maxTeamMembersUnpaged участников
+	limit, offset := maxTeamMembersUnpaged, 0
+	if c.QueryParam("limit") != "" || c.QueryParam("offset") != "" {
+		var err error
+		limit, offset, err = parsePagination(c)
+		if err != nil {
+			h.log(c).Warn("GetTeam: некорректные параметры пагинации", zap.Error(err))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+		}
+	}
+
+	team, total, err := h.repo.GetTeamPage(c.Request().Context(), teamName, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetTeam: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetTeam: ошибка получения команды", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get team"))
+	}
+
+	assignment, err := h.repo.GetTeamAssignment(c.Request().Context(), teamName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetTeam: команда удалена во время запроса", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetTeam: ошибка получения правил назначения", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get team"))
+	}
+
+	h.log(c).Info("GetTeam: команда успешно получена",
+		zap.String("team_name", teamName),
+		zap.Int("members_count", len(team.Members)),
+		zap.Int("members_total", total))
+
+	response := struct {
+		*models.Team
+		MembersTotal int `json:"members_total"`
+		// AssignmentSettings — действующие правила назначения ревьюеров команды
+		AssignmentSettings *models.AssignmentSettings `json:"assignment_settings"`
+	}{
+		Team:               h.teamView(c, team),
+		MembersTotal:       total,
+		AssignmentSettings: assignment,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DeleteTeam удаляет команду; с force=true удаляет даже при открытых PR участников
+func (h *Handler) DeleteTeam(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	force := c.QueryParam("force") == "true"
+	h.log(c).Info("DeleteTeam: удаление команды", zap.String("team_name", teamName), zap.Bool("force", force))
 
 	if teamName == "" {
-		h.logger.Warn("GetTeam: параметр team_name отсутствует")
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "team_name parameter is required"))
+		h.log(c).Warn("DeleteTeam: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
 	}
 
-	team, err := h.repo.GetTeam(c.Request().Context(), teamName)
+	err := h.repo.DeleteTeam(c.Request().Context(), teamName, force)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			h.logger.Warn("GetTeam: команда не найдена", zap.String("team_name", teamName))
-			return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "team not found"))
+			h.log(c).Warn("DeleteTeam: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		if errors.Is(err, repository.ErrTeamHasOpenPRs) {
+			h.log(c).Warn("DeleteTeam: у участников команды есть открытые PR", zap.String("team_name", teamName))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeTeamHasOpenPRs, "team members are involved in open PRs, use force=true to delete anyway"))
 		}
-		h.logger.Error("GetTeam: ошибка получения команды", zap.Error(err), zap.String("team_name", teamName))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to get team"))
+		h.log(c).Error("DeleteTeam: ошибка удаления команды", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to delete team"))
 	}
 
-	h.logger.Info("GetTeam: команда успешно получена", zap.String("team_name", teamName), zap.Int("members_count", len(team.Members)))
-	return c.JSON(http.StatusOK, team)
+	h.log(c).Info("DeleteTeam: команда удалена", zap.String("team_name", teamName))
+	return c.JSON(http.StatusOK, map[string]interface{}{"team_name": teamName, "deleted": true})
+}
+
+// ListTeams возвращает страницу команд с количеством участников
+func (h *Handler) ListTeams(c echo.Context) error {
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("ListTeams: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	h.log(c).Info("ListTeams: получение списка команд", zap.Int("limit", limit), zap.Int("offset", offset))
+
+	teams, total, err := h.repo.ListTeams(c.Request().Context(), limit, offset)
+	if err != nil {
+		h.log(c).Error("ListTeams: ошибка получения списка команд", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list teams"))
+	}
+
+	h.log(c).Info("ListTeams: список команд получен", zap.Int("teams_count", len(teams)), zap.Int("total", total))
+
+	response := map[string]interface{}{
+		"teams": teams,
+		"total": total,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetUser получает пользователя с командой, статусом активности и отпусками
+func (h *Handler) GetUser(c echo.Context) error {
+	userID := h.normalizeID(c.QueryParam("user_id"))
+	h.log(c).Info("GetUser: получение пользователя", zap.String("user_id", userID))
+
+	if userID == "" {
+		h.log(c).Warn("GetUser: параметр user_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id parameter is required"))
+	}
+
+	user, err := h.repo.GetUser(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetUser: пользователь не найден", zap.String("user_id", userID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("GetUser: ошибка получения пользователя", zap.Error(err), zap.String("user_id", userID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get user"))
+	}
+
+	h.log(c).Info("GetUser: пользователь успешно получен", zap.String("user_id", userID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"user": h.userView(c, user)})
 }
 
 // SetUserIsActive обновляет статус активности пользователя
 func (h *Handler) SetUserIsActive(c echo.Context) error {
-	h.logger.Info("SetUserIsActive: начало обработки запроса")
+	h.log(c).Info("SetUserIsActive: начало обработки запроса")
 
 	var req struct {
-		UserID   string `json:"user_id"`
-		IsActive bool   `json:"is_active"`
+		UserID          string `json:"user_id"`
+		IsActive        bool   `json:"is_active"`
+		ReassignReviews bool   `json:"reassign_reviews"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		h.logger.Error("SetUserIsActive: ошибка парсинга тела запроса", zap.Error(err))
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "invalid request body"))
+		h.log(c).Error("SetUserIsActive: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
 	}
+	bodyActor(c, req.ActorID)
+	req.UserID = h.normalizeID(req.UserID)
 
-	h.logger.Info("SetUserIsActive: обновление статуса пользователя", zap.String("user_id", req.UserID), zap.Bool("is_active", req.IsActive))
+	h.log(c).Info("SetUserIsActive: обновление статуса пользователя",
+		zap.String("user_id", req.UserID),
+		zap.Bool("is_active", req.IsActive),
+		zap.Bool("reassign_reviews", req.ReassignReviews))
 
-	err := h.repo.UpdateUserStatus(c.Request().Context(), req.UserID, req.IsActive)
+	// Деактивация с переназначением открытых ревью выполняется одной транзакцией
+	var reassignment *models.ReassignmentResult
+	var err error
+	if !req.IsActive && req.ReassignReviews {
+		reassignment, err = h.repo.DeactivateAndReassign(c.Request().Context(), req.UserID)
+	} else {
+		err = h.repo.UpdateUserStatus(c.Request().Context(), req.UserID, req.IsActive)
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			h.logger.Warn("SetUserIsActive: пользователь не найден", zap.String("user_id", req.UserID))
-			return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "user not found"))
+			h.log(c).Warn("SetUserIsActive: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
 		}
-		h.logger.Error("SetUserIsActive: ошибка обновления статуса", zap.Error(err), zap.String("user_id", req.UserID))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to update user status"))
+		h.log(c).Error("SetUserIsActive: ошибка обновления статуса", zap.Error(err), zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update user status"))
 	}
 
-	// Получаем обновленные данные пользователя
-	user, err := h.repo.GetUser(c.Request().Context(), req.UserID)
+	// Получаем обновленные данные пользователя из основного пула: реплика может еще не получить запись
+	user, err := h.repo.GetUser(repository.WithPrimary(c.Request().Context()), req.UserID)
 	if err != nil {
-		h.logger.Error("SetUserIsActive: ошибка получения обновленного пользователя", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to get updated user"))
+		h.log(c).Error("SetUserIsActive: ошибка получения обновленного пользователя", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get updated user"))
 	}
 
-	h.logger.Info("SetUserIsActive: статус пользователя обновлен", zap.String("user_id", req.UserID))
-	return c.JSON(http.StatusOK, map[string]interface{}{"user": user})
+	h.log(c).Info("SetUserIsActive: статус пользователя обновлен", zap.String("user_id", req.UserID))
+
+	response := map[string]interface{}{"user": h.userView(c, user)}
+	if reassignment != nil {
+		h.log(c).Info("SetUserIsActive: открытые ревью переназначены",
+			zap.String("user_id", req.UserID),
+			zap.Int("reassigned_count", len(reassignment.Reassigned)),
+			zap.Int("not_reassigned_count", len(reassignment.NotReassigned)),
+			zap.Int("skipped_recent_count", len(reassignment.SkippedRecent)))
+		h.metrics.ReviewersReassigned.Add(float64(len(reassignment.Reassigned)))
+		response["reassignment"] = reassignment
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 // CreatePullRequest создает новый PR с автоматическим назначением ревьюеров
 func (h *Handler) CreatePullRequest(c echo.Context) error {
-	h.logger.Info("CreatePullRequest: начало обработки запроса")
+	h.log(c).Info("CreatePullRequest: начало обработки запроса")
 
 	var req struct {
 		PullRequestID   string `json:"pull_request_id"`
 		PullRequestName string `json:"pull_request_name"`
 		AuthorID        string `json:"author_id"`
+		// TeamName — команда, из которой назначаются ревьюеры (обязательна при REJECT_AMBIGUOUS_TEAM
+		// для автора из нескольких команд)
+		TeamName string `json:"team_name"`
+		// Repository — репозиторий (проект) PR; внешний ID уникален в пределах репозитория
+		Repository string `json:"repository"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+		models.PRMetadata
 	}
 
 	if err := c.Bind(&req); err != nil {
-		h.logger.Error("CreatePullRequest: ошибка парсинга тела запроса", zap.Error(err))
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "invalid request body"))
+		h.log(c).Error("CreatePullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
 	}
+	bodyActor(c, req.ActorID)
+	req.AuthorID = h.normalizeID(req.AuthorID)
 
-	h.logger.Info("CreatePullRequest: создание PR",
+	if !models.ValidRepository(&req.Repository) {
+		h.log(c).Warn("CreatePullRequest: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+
+	if problems := ValidatePRMetadata(&req.Description, &req.URL, &req.Labels); len(problems) > 0 {
+		h.log(c).Warn("CreatePullRequest: сведения о PR не прошли валидацию", zap.Strings("problems", problems))
+		resp := newErrorResponse(c, ErrCodeValidation, "pull request metadata is invalid")
+		resp.Error.Details = problems
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	h.log(c).Info("CreatePullRequest: создание PR",
 		zap.String("pr_id", req.PullRequestID),
+		zap.String("repository", req.Repository),
 		zap.String("pr_name", req.PullRequestName),
-		zap.String("author_id", req.AuthorID))
+		zap.String("author_id", req.AuthorID),
+		zap.String("team_name", req.TeamName))
 
-	pr, err := h.repo.CreatePR(c.Request().Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := h.services.PRs.Create(c.Request().Context(), req.Repository, req.PullRequestID, req.PullRequestName, req.AuthorID, req.TeamName, req.PRMetadata)
 	if err != nil {
 		if errors.Is(err, repository.ErrAlreadyExists) {
-			h.logger.Warn("CreatePullRequest: PR уже существует", zap.String("pr_id", req.PullRequestID))
-			return c.JSON(http.StatusConflict, newErrorResponse(ErrCodePRExists, "PR id already exists"))
+			h.log(c).Warn("CreatePullRequest: PR уже существует",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("repository", req.Repository))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRExists, "PR id already exists in this repository"))
 		}
 		if errors.Is(err, repository.ErrNotFound) {
-			h.logger.Warn("CreatePullRequest: автор или команда не найдены", zap.String("author_id", req.AuthorID))
-			return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "author or team not found"))
+			h.log(c).Warn("CreatePullRequest: автор или команда не найдены", zap.String("author_id", req.AuthorID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "author or team not found"))
 		}
-		h.logger.Error("CreatePullRequest: ошибка создания PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to create PR"))
+		if errors.Is(err, repository.ErrAuthorNotInTeam) {
+			h.log(c).Warn("CreatePullRequest: автор не состоит в команде",
+				zap.String("author_id", req.AuthorID),
+				zap.String("team_name", req.TeamName))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAuthorNotInTeam, "author is not a member of the team"))
+		}
+		if errors.Is(err, repository.ErrNoReviewers) {
+			h.log(c).Warn("CreatePullRequest: нет кандидатов в ревьюеры, PR не создан",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("author_id", req.AuthorID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNoCandidate, "no active reviewer candidates for PR"))
+		}
+		var ambiguous *repository.AmbiguousTeamError
+		if errors.As(err, &ambiguous) {
+			h.log(c).Warn("CreatePullRequest: автор состоит в нескольких командах",
+				zap.String("author_id", req.AuthorID),
+				zap.Strings("teams", ambiguous.Teams))
+			resp := newErrorResponse(c, ErrCodeAmbiguousTeam, "author belongs to several teams, specify team_name")
+			resp.Error.Details = ambiguous.Teams
+			return c.JSON(http.StatusConflict, resp)
+		}
+		h.log(c).Error("CreatePullRequest: ошибка создания PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to create PR"))
 	}
 
-	h.logger.Info("CreatePullRequest: PR успешно создан",
+	h.metrics.PRsCreated.Inc()
+	response := map[string]interface{}{"pr": pr}
+	if len(pr.AssignedReviewers) == 0 {
+		h.metrics.ZeroReviewerAssignments.Inc()
+		h.log(c).Warn("CreatePullRequest: PR создан без ревьюеров", zap.String("pr_id", pr.PullRequestID))
+		response["warnings"] = []string{WarnNoReviewersAssigned}
+	}
+
+	h.log(c).Info("CreatePullRequest: PR успешно создан",
 		zap.String("pr_id", pr.PullRequestID),
 		zap.Int("reviewers_count", len(pr.AssignedReviewers)))
-	return c.JSON(http.StatusCreated, map[string]interface{}{"pr": pr})
+	return c.JSON(http.StatusCreated, response)
+}
+
+// GetPullRequest получает PR по внешнему ID вместе с назначенными ревьюерами
+func (h *Handler) GetPullRequest(c echo.Context) error {
+	prID := c.QueryParam("pull_request_id")
+	h.log(c).Info("GetPullRequest: получение PR", zap.String("pr_id", prID))
+
+	if prID == "" {
+		h.log(c).Warn("GetPullRequest: параметр pull_request_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "pull_request_id parameter is required"))
+	}
+
+	repo, err := parseRepositoryParam(c)
+	if err != nil {
+		h.log(c).Warn("GetPullRequest: некорректный параметр repository", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	pr, err := h.repo.GetPR(c.Request().Context(), prRef(repo, prID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetPullRequest: PR не найден", zap.String("pr_id", prID), zap.Stringp("repository", repo))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("GetPullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", prID))
+			return ambiguousPR(c)
+		}
+		h.log(c).Error("GetPullRequest: ошибка получения PR", zap.Error(err), zap.String("pr_id", prID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get PR"))
+	}
+
+	h.log(c).Info("GetPullRequest: PR успешно получен", zap.String("pr_id", prID), zap.String("status", pr.Status))
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// GetPullRequestsBatch получает несколько PR по списку внешних ID
+func (h *Handler) GetPullRequestsBatch(c echo.Context) error {
+	h.log(c).Info("GetPullRequestsBatch: начало обработки запроса")
+
+	var req struct {
+		PullRequestIDs []string `json:"pull_request_ids"`
+		// Repository — репозиторий, в котором ищутся PR; без него PR ищутся по одним ID
+		Repository *string `json:"repository"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("GetPullRequestsBatch: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	if len(req.PullRequestIDs) == 0 {
+		h.log(c).Warn("GetPullRequestsBatch: пустой список pull_request_ids")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "pull_request_ids must not be empty"))
+	}
+	if len(req.PullRequestIDs) > maxBatchSize {
+		h.log(c).Warn("GetPullRequestsBatch: превышен размер batch", zap.Int("ids_count", len(req.PullRequestIDs)))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "too many pull_request_ids, max is 100"))
+	}
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("GetPullRequestsBatch: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+
+	prs, notFound, err := h.repo.GetPRsBatch(c.Request().Context(), req.Repository, req.PullRequestIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("GetPullRequestsBatch: ID PR есть в нескольких репозиториях", zap.Error(err))
+			return ambiguousPR(c)
+		}
+		h.log(c).Error("GetPullRequestsBatch: ошибка получения PR", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get PRs"))
+	}
+
+	h.log(c).Info("GetPullRequestsBatch: PR успешно получены",
+		zap.Int("found_count", len(prs)),
+		zap.Int("not_found_count", len(notFound)))
+
+	response := map[string]interface{}{
+		"prs":       prs,
+		"not_found": notFound,
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 // MergePullRequest переводит PR в статус MERGED
 func (h *Handler) MergePullRequest(c echo.Context) error {
-	h.logger.Info("MergePullRequest: начало обработки запроса")
+	h.log(c).Info("MergePullRequest: начало обработки запроса")
 
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+		// ExpectedVersion — версия PR, которую видел клиент; важнее заголовка If-Match
+		ExpectedVersion *int64 `json:"expected_version"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		h.logger.Error("MergePullRequest: ошибка парсинга тела запроса", zap.Error(err))
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "invalid request body"))
+		h.log(c).Error("MergePullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("MergePullRequest: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+	version, err := expectedVersion(c, req.ExpectedVersion)
+	if err != nil {
+		h.log(c).Warn("MergePullRequest: некорректная ожидаемая версия", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
 	}
 
-	h.logger.Info("MergePullRequest: слияние PR", zap.String("pr_id", req.PullRequestID))
+	h.log(c).Info("MergePullRequest: слияние PR", zap.String("pr_id", req.PullRequestID), zap.Stringp("repository", req.Repository))
 
-	pr, err := h.repo.MergePR(c.Request().Context(), req.PullRequestID)
+	pr, merged, err := h.services.PRs.Merge(c.Request().Context(), prRef(req.Repository, req.PullRequestID), version)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			h.logger.Warn("MergePullRequest: PR не найден", zap.String("pr_id", req.PullRequestID))
-			return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "PR not found"))
+			h.log(c).Warn("MergePullRequest: PR не найден", zap.String("pr_id", req.PullRequestID), zap.Stringp("repository", req.Repository))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("MergePullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
 		}
-		h.logger.Error("MergePullRequest: ошибка слияния PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to merge PR"))
+		var notApproved *repository.NotApprovedError
+		if errors.As(err, &notApproved) {
+			h.log(c).Warn("MergePullRequest: недостаточно одобрений",
+				zap.String("pr_id", req.PullRequestID),
+				zap.Int("approvals", notApproved.Approvals),
+				zap.Int("required", notApproved.Required))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNotEnoughApprovals, notApproved.Error()))
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			h.log(c).Warn("MergePullRequest: версия PR изменилась", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return h.versionConflict(c, "MergePullRequest", prRef(req.Repository, req.PullRequestID))
+		}
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			h.log(c).Warn("MergePullRequest: недопустимый переход статуса", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeInvalidTransition, err.Error()))
+		}
+		h.log(c).Error("MergePullRequest: ошибка слияния PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to merge PR"))
+	}
+
+	// Повторный merge уже слитого PR успешен, но в счетчик не попадает
+	if merged {
+		h.metrics.PRsMerged.Inc()
 	}
 
-	h.logger.Info("MergePullRequest: PR успешно слит", zap.String("pr_id", pr.PullRequestID), zap.String("status", pr.Status))
+	h.log(c).Info("MergePullRequest: PR успешно слит", zap.String("pr_id", pr.PullRequestID), zap.String("status", pr.Status), zap.Bool("merged", merged))
 	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
 }
 
-// ReassignReviewer переназначает ревьюера на PR с автоматическим поиском замены
-func (h *Handler) ReassignReviewer(c echo.Context) error {
-	h.logger.Info("ReassignReviewer: начало обработки запроса")
+// ClosePullRequest закрывает PR без слияния
+func (h *Handler) ClosePullRequest(c echo.Context) error {
+	h.log(c).Info("ClosePullRequest: начало обработки запроса")
 
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
-		OldUserID     string `json:"old_user_id"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+		// ExpectedVersion — версия PR, которую видел клиент; важнее заголовка If-Match
+		ExpectedVersion *int64 `json:"expected_version"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		h.logger.Error("ReassignReviewer: ошибка парсинга тела запроса", zap.Error(err))
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "invalid request body"))
+		h.log(c).Error("ClosePullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("ClosePullRequest: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+	version, err := expectedVersion(c, req.ExpectedVersion)
+	if err != nil {
+		h.log(c).Warn("ClosePullRequest: некорректная ожидаемая версия", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
 	}
 
-	h.logger.Info("ReassignReviewer: переназначение ревьюера", 
-		zap.String("pr_id", req.PullRequestID), 
-		zap.String("old_user_id", req.OldUserID))
+	h.log(c).Info("ClosePullRequest: закрытие PR", zap.String("pr_id", req.PullRequestID), zap.Stringp("repository", req.Repository))
 
-	newReviewerID, err := h.repo.ReassignReviewerAuto(c.Request().Context(), req.PullRequestID, req.OldUserID)
+	pr, err := h.repo.ClosePR(c.Request().Context(), prRef(req.Repository, req.PullRequestID), version)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			// Может быть несколько причин: PR не найден, ревьюер не назначен, нет кандидатов
-			pr, getErr := h.repo.GetPR(c.Request().Context(), req.PullRequestID)
-			if getErr != nil {
-				h.logger.Warn("ReassignReviewer: PR не найден", zap.String("pr_id", req.PullRequestID))
-				return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "PR not found"))
-			}
-			
-			// Проверяем, был ли старый ревьюер назначен
-			oldReviewerAssigned := false
-			for _, reviewerID := range pr.AssignedReviewers {
-				if reviewerID == req.OldUserID {
-					oldReviewerAssigned = true
-					break
-				}
-			}
-			
-			if !oldReviewerAssigned {
-				h.logger.Warn("ReassignReviewer: пользователь не назначен ревьюером", 
-					zap.String("pr_id", req.PullRequestID), 
-					zap.String("old_user_id", req.OldUserID))
-				return c.JSON(http.StatusConflict, newErrorResponse(ErrCodeNotAssigned, "reviewer is not assigned to this PR"))
-			}
-			
-			// Значит нет подходящих кандидатов
-			h.logger.Warn("ReassignReviewer: нет активных кандидатов для замены", zap.String("pr_id", req.PullRequestID))
-			return c.JSON(http.StatusConflict, newErrorResponse(ErrCodeNoCandidate, "no active replacement candidate in team"))
-		}
-		
+			h.log(c).Warn("ClosePullRequest: PR не найден", zap.String("pr_id", req.PullRequestID), zap.Stringp("repository", req.Repository))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("ClosePullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		}
 		if errors.Is(err, repository.ErrAlreadyMerged) {
-			h.logger.Warn("ReassignReviewer: попытка переназначения на смерженный PR", zap.String("pr_id", req.PullRequestID))
-			return c.JSON(http.StatusConflict, newErrorResponse(ErrCodePRMerged, "cannot reassign on merged PR"))
+			h.log(c).Warn("ClosePullRequest: попытка закрыть смерженный PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRMerged, "cannot close merged PR"))
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			h.log(c).Warn("ClosePullRequest: версия PR изменилась", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return h.versionConflict(c, "ClosePullRequest", prRef(req.Repository, req.PullRequestID))
 		}
-		
-		h.logger.Error("ReassignReviewer: ошибка переназначения", zap.Error(err), zap.String("pr_id", req.PullRequestID))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to reassign reviewer"))
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			h.log(c).Warn("ClosePullRequest: недопустимый переход статуса", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeInvalidTransition, err.Error()))
+		}
+		h.log(c).Error("ClosePullRequest: ошибка закрытия PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to close PR"))
+	}
+
+	h.log(c).Info("ClosePullRequest: PR закрыт", zap.String("pr_id", pr.PullRequestID), zap.String("status", pr.Status))
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// ReopenPullRequest переоткрывает закрытый PR
+func (h *Handler) ReopenPullRequest(c echo.Context) error {
+	h.log(c).Info("ReopenPullRequest: начало обработки запроса")
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		Reassign      bool   `json:"reassign"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("ReopenPullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
 	}
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("ReopenPullRequest: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+
+	h.log(c).Info("ReopenPullRequest: переоткрытие PR",
+		zap.String("pr_id", req.PullRequestID),
+		zap.Stringp("repository", req.Repository),
+		zap.Bool("reassign", req.Reassign))
 
-	// Получаем обновленный PR
-	pr, err := h.repo.GetPR(c.Request().Context(), req.PullRequestID)
+	pr, err := h.repo.ReopenPR(c.Request().Context(), prRef(req.Repository, req.PullRequestID), req.Reassign)
 	if err != nil {
-		h.logger.Error("ReassignReviewer: ошибка получения обновленного PR", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to get updated PR"))
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("ReopenPullRequest: PR не найден", zap.String("pr_id", req.PullRequestID), zap.Stringp("repository", req.Repository))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("ReopenPullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		}
+		if errors.Is(err, repository.ErrAlreadyMerged) {
+			h.log(c).Warn("ReopenPullRequest: попытка переоткрыть смерженный PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRMerged, "cannot reopen merged PR"))
+		}
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			h.log(c).Warn("ReopenPullRequest: недопустимый переход статуса", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeInvalidTransition, err.Error()))
+		}
+		h.log(c).Error("ReopenPullRequest: ошибка переоткрытия PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to reopen PR"))
+	}
+
+	h.log(c).Info("ReopenPullRequest: PR переоткрыт",
+		zap.String("pr_id", pr.PullRequestID),
+		zap.Int("reviewers_count", len(pr.AssignedReviewers)))
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// ApprovePullRequest отмечает одобрение PR назначенным ревьюером
+func (h *Handler) ApprovePullRequest(c echo.Context) error {
+	h.log(c).Info("ApprovePullRequest: начало обработки запроса")
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
 	}
 
-	h.logger.Info("ReassignReviewer: ревьюер успешно переназначен", 
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("ApprovePullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("ApprovePullRequest: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+	req.UserID = h.normalizeID(req.UserID)
+
+	h.log(c).Info("ApprovePullRequest: одобрение PR",
+		zap.String("pr_id", req.PullRequestID),
+		zap.Stringp("repository", req.Repository),
+		zap.String("user_id", req.UserID))
+
+	pr, err := h.repo.ApprovePR(c.Request().Context(), prRef(req.Repository, req.PullRequestID), req.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			h.log(c).Warn("ApprovePullRequest: PR или пользователь не найден",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR or user not found"))
+		case errors.Is(err, repository.ErrAmbiguousPR):
+			h.log(c).Warn("ApprovePullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		case errors.Is(err, repository.ErrNotAssigned):
+			h.log(c).Warn("ApprovePullRequest: пользователь не назначен ревьюером",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNotAssigned, "reviewer is not assigned to this PR"))
+		case errors.Is(err, repository.ErrAlreadyMerged):
+			h.log(c).Warn("ApprovePullRequest: попытка одобрить смерженный PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRMerged, "cannot approve merged PR"))
+		case errors.Is(err, repository.ErrAlreadyClosed):
+			h.log(c).Warn("ApprovePullRequest: попытка одобрить закрытый PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRClosed, "cannot approve closed PR"))
+		}
+
+		h.log(c).Error("ApprovePullRequest: ошибка одобрения PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to approve PR"))
+	}
+
+	h.log(c).Info("ApprovePullRequest: PR одобрен",
+		zap.String("pr_id", pr.PullRequestID),
+		zap.String("user_id", req.UserID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// ReassignReviewer переназначает ревьюера на PR: на указанного пользователя или с автоматическим поиском замены
+func (h *Handler) ReassignReviewer(c echo.Context) error {
+	h.log(c).Info("ReassignReviewer: начало обработки запроса")
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		OldUserID     string `json:"old_user_id"`
+		// NewUserID — кого назначить вместо старого ревьюера; без него замена выбирается автоматически
+		NewUserID string `json:"new_user_id"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+		// ExpectedVersion — версия PR, которую видел клиент; важнее заголовка If-Match
+		ExpectedVersion *int64 `json:"expected_version"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("ReassignReviewer: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("ReassignReviewer: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+	version, err := expectedVersion(c, req.ExpectedVersion)
+	if err != nil {
+		h.log(c).Warn("ReassignReviewer: некорректная ожидаемая версия", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+	req.OldUserID = h.normalizeID(req.OldUserID)
+	req.NewUserID = h.normalizeID(req.NewUserID)
+
+	h.log(c).Info("ReassignReviewer: переназначение ревьюера",
+		zap.String("pr_id", req.PullRequestID),
+		zap.String("old_user_id", req.OldUserID),
+		zap.String("new_user_id", req.NewUserID))
+
+	pr, newReviewerID, err := h.services.PRs.Reassign(c.Request().Context(), prRef(req.Repository, req.PullRequestID), req.OldUserID, req.NewUserID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			h.log(c).Warn("ReassignReviewer: PR или пользователь не найден",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("old_user_id", req.OldUserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR or user not found"))
+		case errors.Is(err, repository.ErrAmbiguousPR):
+			h.log(c).Warn("ReassignReviewer: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		case errors.Is(err, repository.ErrVersionConflict):
+			h.log(c).Warn("ReassignReviewer: версия PR изменилась", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return h.versionConflict(c, "ReassignReviewer", prRef(req.Repository, req.PullRequestID))
+		case errors.Is(err, repository.ErrNotAssigned):
+			h.log(c).Warn("ReassignReviewer: пользователь не назначен ревьюером",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("old_user_id", req.OldUserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNotAssigned, "reviewer is not assigned to this PR"))
+		case errors.Is(err, repository.ErrNoCandidate):
+			h.log(c).Warn("ReassignReviewer: нет активных кандидатов для замены", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNoCandidate, "no active replacement candidate in team"))
+		case errors.Is(err, repository.ErrLeadRequired):
+			h.log(c).Warn("ReassignReviewer: лидер команды не заменяется автоматически",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("old_user_id", req.OldUserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeLeadRequired,
+				"team lead is always included in this team's PRs, pass new_user_id to replace them"))
+		case errors.Is(err, repository.ErrAlreadyAssigned):
+			h.log(c).Warn("ReassignReviewer: новый ревьюер уже назначен на PR",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("new_user_id", req.NewUserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAlreadyAssigned, "new reviewer is already assigned to this PR"))
+		case errors.Is(err, repository.ErrCandidateNotEligible):
+			h.log(c).Warn("ReassignReviewer: новый ревьюер не может ревьюить PR",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("new_user_id", req.NewUserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeCandidateNotEligible,
+				"new reviewer must be an active member of the PR team and not the author"))
+		case errors.Is(err, repository.ErrAlreadyMerged):
+			h.log(c).Warn("ReassignReviewer: попытка переназначения на смерженный PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRMerged, "cannot reassign on merged PR"))
+		case errors.Is(err, repository.ErrAlreadyClosed):
+			h.log(c).Warn("ReassignReviewer: попытка переназначения на закрытый PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRClosed, "cannot reassign on closed PR"))
+		}
+
+		h.log(c).Error("ReassignReviewer: ошибка переназначения", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to reassign reviewer"))
+	}
+
+	h.metrics.ReviewersReassigned.Inc()
+
+	h.log(c).Info("ReassignReviewer: ревьюер успешно переназначен",
 		zap.String("pr_id", req.PullRequestID),
 		zap.String("old_reviewer", req.OldUserID),
 		zap.String("new_reviewer", newReviewerID))
@@ -294,35 +1016,182 @@ func (h *Handler) ReassignReviewer(c echo.Context) error {
 		"pr":          pr,
 		"replaced_by": newReviewerID,
 	}
-	
+
 	return c.JSON(http.StatusOK, response)
 }
 
+// AddReviewer назначает на PR дополнительного ревьюера: указанного пользователя или автоматически выбранного
+func (h *Handler) AddReviewer(c echo.Context) error {
+	h.log(c).Info("AddReviewer: начало обработки запроса")
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+		Auto          bool   `json:"auto"`
+		// Repository — репозиторий PR; без него PR ищется по одному ID
+		Repository *string `json:"repository"`
+		// ExpectedVersion — версия PR, которую видел клиент; важнее заголовка If-Match
+		ExpectedVersion *int64 `json:"expected_version"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("AddReviewer: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	if !models.ValidRepository(req.Repository) {
+		h.log(c).Warn("AddReviewer: слишком длинное имя репозитория")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, errRepositoryTooLong.Error()))
+	}
+	req.UserID = h.normalizeID(req.UserID)
+	version, err := expectedVersion(c, req.ExpectedVersion)
+	if err != nil {
+		h.log(c).Warn("AddReviewer: некорректная ожидаемая версия", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	// Ревьюер задается либо явно, либо auto: true
+	if req.Auto == (req.UserID != "") {
+		h.log(c).Warn("AddReviewer: нужно указать ровно один из user_id и auto", zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "exactly one of user_id and auto=true is required"))
+	}
+
+	h.log(c).Info("AddReviewer: добавление ревьюера",
+		zap.String("pr_id", req.PullRequestID),
+		zap.Stringp("repository", req.Repository),
+		zap.String("user_id", req.UserID),
+		zap.Bool("auto", req.Auto))
+
+	pr, reviewerID, err := h.services.PRs.AddReviewer(c.Request().Context(), prRef(req.Repository, req.PullRequestID), req.UserID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			h.log(c).Warn("AddReviewer: PR или пользователь не найден",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR or user not found"))
+		case errors.Is(err, repository.ErrAmbiguousPR):
+			h.log(c).Warn("AddReviewer: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		case errors.Is(err, repository.ErrVersionConflict):
+			h.log(c).Warn("AddReviewer: версия PR изменилась", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+			return h.versionConflict(c, "AddReviewer", prRef(req.Repository, req.PullRequestID))
+		case errors.Is(err, repository.ErrAlreadyMerged):
+			h.log(c).Warn("AddReviewer: попытка добавить ревьюера на смерженный PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRMerged, "cannot add reviewer to merged PR"))
+		case errors.Is(err, repository.ErrAlreadyClosed):
+			h.log(c).Warn("AddReviewer: попытка добавить ревьюера на закрытый PR", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodePRClosed, "cannot add reviewer to closed PR"))
+		case errors.Is(err, repository.ErrMaxReviewers):
+			h.log(c).Warn("AddReviewer: достигнут лимит ревьюеров", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeMaxReviewers, "PR already has the maximum number of reviewers"))
+		case errors.Is(err, repository.ErrAlreadyAssigned):
+			h.log(c).Warn("AddReviewer: пользователь уже назначен на PR",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAlreadyAssigned, "user is already assigned to this PR"))
+		case errors.Is(err, repository.ErrCandidateNotEligible):
+			h.log(c).Warn("AddReviewer: пользователь не может ревьюить PR",
+				zap.String("pr_id", req.PullRequestID),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeCandidateNotEligible,
+				"reviewer must be an active member of the PR team and not the author"))
+		case errors.Is(err, repository.ErrNoCandidate):
+			h.log(c).Warn("AddReviewer: нет активных кандидатов", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNoCandidate, "no active candidate in team"))
+		}
+
+		h.log(c).Error("AddReviewer: ошибка добавления ревьюера", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to add reviewer"))
+	}
+
+	h.log(c).Info("AddReviewer: ревьюер добавлен",
+		zap.String("pr_id", pr.PullRequestID),
+		zap.String("reviewer_id", reviewerID),
+		zap.Int("reviewers_count", len(pr.AssignedReviewers)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"pr":                pr,
+		"added_reviewer_id": reviewerID,
+	})
+}
+
 // GetUserReviews получает список PR, где пользователь назначен ревьюером
 func (h *Handler) GetUserReviews(c echo.Context) error {
-	userID := c.QueryParam("user_id")
-	h.logger.Info("GetUserReviews: получение PR для ревьюера", zap.String("user_id", userID))
+	userID := h.normalizeID(c.QueryParam("user_id"))
+	h.log(c).Info("GetUserReviews: получение PR для ревьюера", zap.String("user_id", userID))
 
 	if userID == "" {
-		h.logger.Warn("GetUserReviews: параметр user_id отсутствует")
-		return c.JSON(http.StatusBadRequest, newErrorResponse(ErrCodeNotFound, "user_id parameter is required"))
+		h.log(c).Warn("GetUserReviews: параметр user_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id parameter is required"))
 	}
 
-	prs, err := h.repo.GetPRsByReviewer(c.Request().Context(), userID)
+	unapproved, err := parseBoolParam(c, "unapproved")
+	if err != nil {
+		h.log(c).Warn("GetUserReviews: некорректный параметр unapproved", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	status, err := parseStatusParam(c)
+	if err != nil {
+		h.log(c).Warn("GetUserReviews: некорректный статус", zap.String("status", c.QueryParam("status")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	label, err := parseLabelParam(c)
+	if err != nil {
+		h.log(c).Warn("GetUserReviews: некорректная метка", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	repo, err := parseRepositoryParam(c)
+	if err != nil {
+		h.log(c).Warn("GetUserReviews: некорректный параметр repository", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("GetUserReviews: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	filter := repository.ReviewFilter{
+		PRListFilter: repository.PRListFilter{
+			Status:     status,
+			Label:      label,
+			Repository: repo,
+			Limit:      limit,
+			Offset:     offset,
+		},
+		UnapprovedOnly: unapproved,
+	}
+	if err := parsePRListRange(c, &filter.PRListFilter); err != nil {
+		h.log(c).Warn("GetUserReviews: некорректные параметры периода, сортировки или курсора", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	prs, page, err := h.repo.GetPRsByReviewer(c.Request().Context(), userID, filter)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			h.logger.Warn("GetUserReviews: пользователь не найден", zap.String("user_id", userID))
-			return c.JSON(http.StatusNotFound, newErrorResponse(ErrCodeNotFound, "user not found"))
+			h.log(c).Warn("GetUserReviews: пользователь не найден", zap.String("user_id", userID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
 		}
-		h.logger.Error("GetUserReviews: ошибка получения PR", zap.Error(err), zap.String("user_id", userID))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse(ErrCodeNotFound, "failed to get user reviews"))
+		h.log(c).Error("GetUserReviews: ошибка получения PR", zap.Error(err), zap.String("user_id", userID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get user reviews"))
 	}
 
-	h.logger.Info("GetUserReviews: PR успешно получены", zap.String("user_id", userID), zap.Int("prs_count", len(prs)))
+	h.log(c).Info("GetUserReviews: PR успешно получены",
+		zap.String("user_id", userID),
+		zap.Int("prs_count", len(prs)),
+		zap.Int("total", page.Total))
 
 	response := map[string]interface{}{
 		"user_id":       userID,
 		"pull_requests": prs,
+		"total":         page.Total,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -330,15 +1199,15 @@ func (h *Handler) GetUserReviews(c echo.Context) error {
 
 // GetStats возвращает статистику по ревью
 func (h *Handler) GetStats(c echo.Context) error {
-	h.logger.Info("GetStats: получение статистики по назначениям")
+	h.log(c).Info("GetStats: получение статистики по назначениям")
 
 	stats, err := h.repo.GetUserReviewStats(c.Request().Context())
 	if err != nil {
-		h.logger.Error("GetStats: ошибка получения статистики", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, newErrorResponse("STATS_ERROR", "failed to get stats"))
+		h.log(c).Error("GetStats: ошибка получения статистики", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get stats"))
 	}
 
-	h.logger.Info("GetStats: статистика успешно получена", zap.Int("user_count", len(stats)))
-	
+	h.log(c).Info("GetStats: статистика успешно получена", zap.Int("user_count", len(stats)))
+
 	return c.JSON(http.StatusOK, map[string]interface{}{"stats": stats})
 }
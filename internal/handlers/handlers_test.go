@@ -0,0 +1,663 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// errDB — произвольная ошибка хранилища, которая должна давать 500
+var errDB = errors.New("connection refused")
+
+// errorCase — запрос к API и ожидаемый ответ с ошибкой
+type errorCase struct {
+	name    string
+	method  string
+	target  string
+	body    string
+	header  map[string]string
+	setup   func(st *mocks.Store)
+	status  int
+	code    string
+	message string
+}
+
+// newTestServer собирает Echo с обработчиками поверх хранилища так же, как main.go, но без middleware
+func newTestServer(st handlers.Store, cfg handlers.Config) *echo.Echo {
+	e, _ := newMeteredTestServer(st, cfg)
+	return e
+}
+
+// newMeteredTestServer собирает сервер как newTestServer и возвращает его метрики для проверки счетчиков
+func newMeteredTestServer(st handlers.Store, cfg handlers.Config) (*echo.Echo, *metrics.Metrics) {
+	e := echo.New()
+	e.Binder = &handlers.Binder{}
+	e.HTTPErrorHandler = handlers.ErrorHandler(zap.NewNop())
+	m := metrics.New(prometheus.NewRegistry())
+	h := handlers.New(st, service.New(st), m, zap.NewNop(), cfg)
+	h.RegisterRoutes(e.Group(""))
+	return e, m
+}
+
+// serve выполняет запрос к серверу; тело отправляется как JSON
+func serve(e *echo.Echo, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// runErrorCases проверяет статус, код и сообщение ошибки для каждого случая
+func runErrorCases(t *testing.T, cases []errorCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &mocks.Store{}
+			if tc.setup != nil {
+				tc.setup(st)
+			}
+			rec := serve(newTestServer(st, handlers.Config{}), tc.method, tc.target, tc.body, tc.header)
+
+			require.Equal(t, tc.status, rec.Code, rec.Body.String())
+			var resp handlers.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.code, resp.Error.Code)
+			if tc.message != "" {
+				assert.Equal(t, tc.message, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestCreateTeamErrors(t *testing.T) {
+	runErrorCases(t, []errorCase{
+		{
+			name: "malformed body", method: http.MethodPost, target: "/team/add", body: `{"team_name":`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "invalid slack user id", method: http.MethodPost, target: "/team/add",
+			body:   `{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true,"slack_user_id":"nope"}]}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody, message: "slack_user_id must be a Slack member ID",
+		},
+		{
+			name: "non-positive max open reviews", method: http.MethodPost, target: "/team/add",
+			body:   `{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true,"max_open_reviews":0}]}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody, message: "max_open_reviews must be a positive integer",
+		},
+		{
+			name: "unknown role", method: http.MethodPost, target: "/team/add",
+			body:   `{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true,"role":"owner"}]}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody, message: "role must be one of member, lead",
+		},
+		{
+			name: "store failure", method: http.MethodPost, target: "/team/add",
+			body: `{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true}]}`,
+			setup: func(st *mocks.Store) {
+				st.CreateTeamFunc = func(context.Context, models.Team) (*models.Team, error) { return nil, errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to create team",
+		},
+	})
+}
+
+func TestGetTeamErrors(t *testing.T) {
+	team := &models.Team{TeamName: "backend"}
+	runErrorCases(t, []errorCase{
+		{
+			name: "missing team_name", method: http.MethodGet, target: "/team/get",
+			status: http.StatusBadRequest, code: handlers.ErrCodeMissingParam, message: "team_name parameter is required",
+		},
+		{
+			name: "invalid limit", method: http.MethodGet, target: "/team/get?team_name=backend&limit=-1",
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidParam,
+		},
+		{
+			name: "team not found", method: http.MethodGet, target: "/team/get?team_name=backend",
+			setup: func(st *mocks.Store) {
+				st.GetTeamPageFunc = func(context.Context, string, int, int) (*models.Team, int, error) {
+					return nil, 0, repository.ErrNotFound
+				}
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "team not found",
+		},
+		{
+			name: "store failure", method: http.MethodGet, target: "/team/get?team_name=backend",
+			setup: func(st *mocks.Store) {
+				st.GetTeamPageFunc = func(context.Context, string, int, int) (*models.Team, int, error) { return nil, 0, errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get team",
+		},
+		{
+			name: "team deleted before settings read", method: http.MethodGet, target: "/team/get?team_name=backend",
+			setup: func(st *mocks.Store) {
+				st.GetTeamPageFunc = func(context.Context, string, int, int) (*models.Team, int, error) { return team, 0, nil }
+				st.GetTeamAssignmentFunc = func(context.Context, string) (*models.AssignmentSettings, error) {
+					return nil, repository.ErrNotFound
+				}
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "team not found",
+		},
+		{
+			name: "settings read failure", method: http.MethodGet, target: "/team/get?team_name=backend",
+			setup: func(st *mocks.Store) {
+				st.GetTeamPageFunc = func(context.Context, string, int, int) (*models.Team, int, error) { return team, 0, nil }
+				st.GetTeamAssignmentFunc = func(context.Context, string) (*models.AssignmentSettings, error) { return nil, errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get team",
+		},
+	})
+}
+
+func TestGetUserErrors(t *testing.T) {
+	runErrorCases(t, []errorCase{
+		{
+			name: "missing user_id", method: http.MethodGet, target: "/users/get",
+			status: http.StatusBadRequest, code: handlers.ErrCodeMissingParam, message: "user_id parameter is required",
+		},
+		{
+			name: "user not found", method: http.MethodGet, target: "/users/get?user_id=u1",
+			setup: func(st *mocks.Store) {
+				st.GetUserFunc = func(context.Context, string) (*models.User, error) { return nil, repository.ErrNotFound }
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "user not found",
+		},
+		{
+			name: "store failure", method: http.MethodGet, target: "/users/get?user_id=u1",
+			setup: func(st *mocks.Store) {
+				st.GetUserFunc = func(context.Context, string) (*models.User, error) { return nil, errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get user",
+		},
+	})
+}
+
+func TestSetUserIsActiveErrors(t *testing.T) {
+	runErrorCases(t, []errorCase{
+		{
+			name: "malformed body", method: http.MethodPost, target: "/users/setIsActive", body: `{"user_id":1}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "user not found", method: http.MethodPost, target: "/users/setIsActive",
+			body: `{"user_id":"u1","is_active":false}`,
+			setup: func(st *mocks.Store) {
+				st.UpdateUserStatusFunc = func(context.Context, string, bool) error { return repository.ErrNotFound }
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "user not found",
+		},
+		{
+			name: "deactivate with reassignment of unknown user", method: http.MethodPost, target: "/users/setIsActive",
+			body: `{"user_id":"u1","is_active":false,"reassign_reviews":true}`,
+			setup: func(st *mocks.Store) {
+				st.DeactivateAndReassignFunc = func(context.Context, string) (*models.ReassignmentResult, error) {
+					return nil, repository.ErrNotFound
+				}
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "user not found",
+		},
+		{
+			name: "update failure", method: http.MethodPost, target: "/users/setIsActive",
+			body: `{"user_id":"u1","is_active":true}`,
+			setup: func(st *mocks.Store) {
+				st.UpdateUserStatusFunc = func(context.Context, string, bool) error { return errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to update user status",
+		},
+		{
+			name: "reading updated user fails", method: http.MethodPost, target: "/users/setIsActive",
+			body: `{"user_id":"u1","is_active":true}`,
+			setup: func(st *mocks.Store) {
+				st.UpdateUserStatusFunc = func(context.Context, string, bool) error { return nil }
+				st.GetUserFunc = func(context.Context, string) (*models.User, error) { return nil, errDB }
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get updated user",
+		},
+	})
+}
+
+func TestCreatePullRequestErrors(t *testing.T) {
+	const body = `{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1"}`
+	createFails := func(err error) func(st *mocks.Store) {
+		return func(st *mocks.Store) {
+			st.CreatePRFunc = func(context.Context, string, string, string, string, string, models.PRMetadata) (*models.PullRequest, error) {
+				return nil, err
+			}
+		}
+	}
+	runErrorCases(t, []errorCase{
+		{
+			name: "malformed body", method: http.MethodPost, target: "/pullRequest/create", body: `[]`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "repository too long", method: http.MethodPost, target: "/pullRequest/create",
+			body:   `{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1","repository":"` + strings.Repeat("r", models.MaxRepositoryLength+1) + `"}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "invalid metadata", method: http.MethodPost, target: "/pullRequest/create",
+			body:   `{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1","url":"ftp://example.com"}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeValidation, message: "pull request metadata is invalid",
+		},
+		{
+			name: "PR already exists", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(repository.ErrAlreadyExists),
+			status: http.StatusConflict, code: handlers.ErrCodePRExists, message: "PR id already exists in this repository",
+		},
+		{
+			name: "author not found", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(repository.ErrNotFound),
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "author or team not found",
+		},
+		{
+			name: "author not in team", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(repository.ErrAuthorNotInTeam),
+			status: http.StatusConflict, code: handlers.ErrCodeAuthorNotInTeam, message: "author is not a member of the team",
+		},
+		{
+			name: "no reviewer candidates", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(repository.ErrNoReviewers),
+			status: http.StatusConflict, code: handlers.ErrCodeNoCandidate, message: "no active reviewer candidates for PR",
+		},
+		{
+			name: "ambiguous team", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(&repository.AmbiguousTeamError{Teams: []string{"backend", "frontend"}}),
+			status: http.StatusConflict, code: handlers.ErrCodeAmbiguousTeam, message: "author belongs to several teams, specify team_name",
+		},
+		{
+			name: "store failure", method: http.MethodPost, target: "/pullRequest/create", body: body,
+			setup:  createFails(errDB),
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to create PR",
+		},
+	})
+}
+
+func TestCreatePullRequestAmbiguousTeamDetails(t *testing.T) {
+	st := &mocks.Store{
+		CreatePRFunc: func(context.Context, string, string, string, string, string, models.PRMetadata) (*models.PullRequest, error) {
+			return nil, &repository.AmbiguousTeamError{Teams: []string{"backend", "frontend"}}
+		},
+	}
+	rec := serve(newTestServer(st, handlers.Config{}), http.MethodPost, "/pullRequest/create",
+		`{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1"}`, nil)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"backend", "frontend"}, resp.Error.Details)
+}
+
+func TestGetPullRequest(t *testing.T) {
+	createdAt := time.Date(2025, 11, 18, 9, 0, 0, 0, time.UTC)
+	backend := "backend"
+	cases := []struct {
+		name     string
+		target   string
+		wantRepo *string
+	}{
+		{name: "by ID", target: "/pullRequest/get?pull_request_id=pr-1"},
+		{name: "in repository", target: "/pullRequest/get?pull_request_id=pr-1&repository=backend", wantRepo: &backend},
+		{name: "in default repository", target: "/pullRequest/get?pull_request_id=pr-1&repository=", wantRepo: new(string)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got models.PRRef
+			st := &mocks.Store{
+				GetPRFunc: func(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+					got = ref
+					return &models.PullRequest{
+						PullRequestID:   "pr-1",
+						PullRequestName: "Fix",
+						AuthorID:        "u1",
+						Status:          models.StatusOpen,
+						AssignedReviewers: []models.AssignedReviewer{
+							{UserID: "u2", Username: "Bob", IsActive: true, Source: models.ReviewerSourceTeam},
+						},
+						CreatedAt: &createdAt,
+					}, nil
+				},
+			}
+			rec := serve(newTestServer(st, handlers.Config{}), http.MethodGet, tc.target, "", nil)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, "pr-1", got.ID)
+			assert.Equal(t, tc.wantRepo, got.Repository)
+
+			var resp struct {
+				PR models.PullRequest `json:"pr"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "pr-1", resp.PR.PullRequestID)
+			assert.Equal(t, "Fix", resp.PR.PullRequestName)
+			assert.Equal(t, models.StatusOpen, resp.PR.Status)
+			require.Len(t, resp.PR.AssignedReviewers, 1)
+			assert.Equal(t, "u2", resp.PR.AssignedReviewers[0].UserID)
+			require.NotNil(t, resp.PR.CreatedAt)
+			assert.True(t, createdAt.Equal(*resp.PR.CreatedAt))
+		})
+	}
+}
+
+func TestGetPullRequestErrors(t *testing.T) {
+	getFails := func(err error) func(st *mocks.Store) {
+		return func(st *mocks.Store) {
+			st.GetPRFunc = func(context.Context, models.PRRef) (*models.PullRequest, error) { return nil, err }
+		}
+	}
+	runErrorCases(t, []errorCase{
+		{
+			name: "missing pull_request_id", method: http.MethodGet, target: "/pullRequest/get",
+			status: http.StatusBadRequest, code: handlers.ErrCodeMissingParam, message: "pull_request_id parameter is required",
+		},
+		{
+			name: "empty pull_request_id", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=",
+			status: http.StatusBadRequest, code: handlers.ErrCodeMissingParam,
+		},
+		{
+			name: "repository too long", method: http.MethodGet,
+			target: "/pullRequest/get?pull_request_id=pr-1&repository=" + strings.Repeat("r", models.MaxRepositoryLength+1),
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidParam,
+		},
+		{
+			name: "not found", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-1",
+			setup:  getFails(repository.ErrNotFound),
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "PR not found",
+		},
+		{
+			name: "ambiguous PR", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-1",
+			setup:  getFails(repository.ErrAmbiguousPR),
+			status: http.StatusConflict, code: handlers.ErrCodeAmbiguousPR,
+		},
+		{
+			name: "store error", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-1",
+			setup:  getFails(errDB),
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get PR",
+		},
+	})
+}
+
+func TestGetPullRequestRoute(t *testing.T) {
+	st := &mocks.Store{
+		GetPRFunc: func(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+			return &models.PullRequest{PullRequestID: ref.ID, Status: models.StatusOpen}, nil
+		},
+	}
+	e := newVersionedServer(st, handlers.Config{})
+
+	for _, prefix := range []string{handlers.APIV1Prefix, ""} {
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, prefix+"/pullRequest/get?pull_request_id=pr-1", "", nil).Code,
+			"GET %s/pullRequest/get", prefix)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(e, http.MethodPost, prefix+"/pullRequest/get", `{"pull_request_id":"pr-1"}`, nil).Code,
+			"POST %s/pullRequest/get", prefix)
+	}
+}
+
+func TestPROperationsRepository(t *testing.T) {
+	backend := "backend"
+	pr := &models.PullRequest{PullRequestID: "pr-1", Status: models.StatusOpen}
+	cases := []struct {
+		name   string
+		target string
+		body   string
+		// setup подменяет операцию хранилища, записывая переданную ссылку на PR в got
+		setup func(st *mocks.Store, got *models.PRRef)
+	}{
+		{
+			name: "close", target: "/pullRequest/close", body: `{"pull_request_id":"pr-1"%s}`,
+			setup: func(st *mocks.Store, got *models.PRRef) {
+				st.ClosePRFunc = func(_ context.Context, ref models.PRRef, _ *int64) (*models.PullRequest, error) {
+					*got = ref
+					return pr, nil
+				}
+			},
+		},
+		{
+			name: "reopen", target: "/pullRequest/reopen", body: `{"pull_request_id":"pr-1"%s}`,
+			setup: func(st *mocks.Store, got *models.PRRef) {
+				st.ReopenPRFunc = func(_ context.Context, ref models.PRRef, _ bool) (*models.PullRequest, error) {
+					*got = ref
+					return pr, nil
+				}
+			},
+		},
+		{
+			name: "approve", target: "/pullRequest/approve", body: `{"pull_request_id":"pr-1","user_id":"u2"%s}`,
+			setup: func(st *mocks.Store, got *models.PRRef) {
+				st.ApprovePRFunc = func(_ context.Context, ref models.PRRef, _ string) (*models.PullRequest, error) {
+					*got = ref
+					return pr, nil
+				}
+			},
+		},
+		{
+			name: "add reviewer", target: "/pullRequest/addReviewer", body: `{"pull_request_id":"pr-1","auto":true%s}`,
+			setup: func(st *mocks.Store, got *models.PRRef) {
+				st.AddReviewerFunc = func(_ context.Context, ref models.PRRef, _ string, _ *int64) (*models.PullRequest, string, error) {
+					*got = ref
+					return pr, "u3", nil
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		for _, repo := range []struct {
+			name  string
+			field string
+			want  *string
+		}{
+			{name: "by ID"},
+			{name: "in repository", field: `,"repository":"backend"`, want: &backend},
+			{name: "in default repository", field: `,"repository":""`, want: new(string)},
+		} {
+			t.Run(tc.name+" "+repo.name, func(t *testing.T) {
+				var got models.PRRef
+				st := &mocks.Store{}
+				tc.setup(st, &got)
+
+				rec := serve(newTestServer(st, handlers.Config{}), http.MethodPost, tc.target, fmt.Sprintf(tc.body, repo.field), nil)
+
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				assert.Equal(t, "pr-1", got.ID)
+				assert.Equal(t, repo.want, got.Repository)
+			})
+		}
+
+		t.Run(tc.name+" repository too long", func(t *testing.T) {
+			var got models.PRRef
+			st := &mocks.Store{}
+			tc.setup(st, &got)
+			field := `,"repository":"` + strings.Repeat("r", models.MaxRepositoryLength+1) + `"`
+
+			rec := serve(newTestServer(st, handlers.Config{}), http.MethodPost, tc.target, fmt.Sprintf(tc.body, field), nil)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			assert.Empty(t, got.ID, "the store must not be called")
+		})
+	}
+}
+
+func TestMergePullRequestErrors(t *testing.T) {
+	const body = `{"pull_request_id":"pr-1"}`
+	mergeFails := func(err error) func(st *mocks.Store) {
+		return func(st *mocks.Store) {
+			st.MergePRFunc = func(context.Context, models.PRRef, *int64) (*models.PullRequest, bool, error) { return nil, false, err }
+		}
+	}
+	runErrorCases(t, []errorCase{
+		{
+			name: "malformed body", method: http.MethodPost, target: "/pullRequest/merge", body: `{`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "invalid If-Match", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			header: map[string]string{"If-Match": `"abc"`},
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidParam,
+		},
+		{
+			name: "PR not found", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			setup:  mergeFails(repository.ErrNotFound),
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "PR not found",
+		},
+		{
+			name: "ambiguous PR", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			setup:  mergeFails(repository.ErrAmbiguousPR),
+			status: http.StatusConflict, code: handlers.ErrCodeAmbiguousPR,
+		},
+		{
+			name: "not enough approvals", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			setup:  mergeFails(&repository.NotApprovedError{Approvals: 1, Required: 2}),
+			status: http.StatusConflict, code: handlers.ErrCodeNotEnoughApprovals, message: "PR has 1 of 2 required approvals",
+		},
+		{
+			name: "version conflict", method: http.MethodPost, target: "/pullRequest/merge", body: `{"pull_request_id":"pr-1","expected_version":1}`,
+			setup: func(st *mocks.Store) {
+				mergeFails(repository.ErrVersionConflict)(st)
+				st.GetPRFunc = func(context.Context, models.PRRef) (*models.PullRequest, error) {
+					return &models.PullRequest{PullRequestID: "pr-1", Version: 2}, nil
+				}
+			},
+			status: http.StatusConflict, code: handlers.ErrCodeVersionConflict,
+		},
+		{
+			name: "invalid transition", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			setup:  mergeFails(repository.ErrInvalidTransition),
+			status: http.StatusConflict, code: handlers.ErrCodeInvalidTransition,
+		},
+		{
+			name: "store failure", method: http.MethodPost, target: "/pullRequest/merge", body: body,
+			setup:  mergeFails(errDB),
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to merge PR",
+		},
+	})
+}
+
+func TestMergePullRequestCountsActualMerges(t *testing.T) {
+	// Первый merge переводит PR в MERGED, повторные возвращают уже слитый PR
+	mergedBefore := false
+	st := &mocks.Store{
+		MergePRFunc: func(context.Context, models.PRRef, *int64) (*models.PullRequest, bool, error) {
+			merged := !mergedBefore
+			mergedBefore = true
+			return &models.PullRequest{PullRequestID: "pr-1", Status: models.StatusMerged}, merged, nil
+		},
+	}
+	e, m := newMeteredTestServer(st, handlers.Config{})
+
+	for range 3 {
+		rec := serve(e, http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.PRsMerged), "repeated merges of a merged PR are not counted")
+}
+
+func TestReassignReviewerErrors(t *testing.T) {
+	const body = `{"pull_request_id":"pr-1","old_user_id":"u2"}`
+	reassignFails := func(err error) func(st *mocks.Store) {
+		return func(st *mocks.Store) {
+			st.ReassignReviewerFunc = func(context.Context, models.PRRef, string, string, *int64) (string, error) { return "", err }
+		}
+	}
+	cases := []errorCase{
+		{
+			name: "malformed body", method: http.MethodPost, target: "/pullRequest/reassign", body: `{"old_user_id":[]}`,
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidBody,
+		},
+		{
+			name: "invalid If-Match", method: http.MethodPost, target: "/pullRequest/reassign", body: body,
+			header: map[string]string{"If-Match": `"0"`},
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidParam,
+		},
+	}
+	for _, tc := range []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"PR or user not found", repository.ErrNotFound, http.StatusNotFound, handlers.ErrCodeNotFound, "PR or user not found"},
+		{"ambiguous PR", repository.ErrAmbiguousPR, http.StatusConflict, handlers.ErrCodeAmbiguousPR, ""},
+		{"not assigned", repository.ErrNotAssigned, http.StatusConflict, handlers.ErrCodeNotAssigned, "reviewer is not assigned to this PR"},
+		{"no candidate", repository.ErrNoCandidate, http.StatusConflict, handlers.ErrCodeNoCandidate, "no active replacement candidate in team"},
+		{"lead required", repository.ErrLeadRequired, http.StatusConflict, handlers.ErrCodeLeadRequired, ""},
+		{"already assigned", repository.ErrAlreadyAssigned, http.StatusConflict, handlers.ErrCodeAlreadyAssigned, "new reviewer is already assigned to this PR"},
+		{"candidate not eligible", repository.ErrCandidateNotEligible, http.StatusConflict, handlers.ErrCodeCandidateNotEligible, ""},
+		{"merged PR", repository.ErrAlreadyMerged, http.StatusConflict, handlers.ErrCodePRMerged, "cannot reassign on merged PR"},
+		{"closed PR", repository.ErrAlreadyClosed, http.StatusConflict, handlers.ErrCodePRClosed, "cannot reassign on closed PR"},
+		{"store failure", errDB, http.StatusInternalServerError, handlers.ErrCodeInternal, "failed to reassign reviewer"},
+	} {
+		cases = append(cases, errorCase{
+			name: tc.name, method: http.MethodPost, target: "/pullRequest/reassign", body: body,
+			setup:  reassignFails(tc.err),
+			status: tc.status, code: tc.code, message: tc.message,
+		})
+	}
+	cases = append(cases, errorCase{
+		name: "reading updated PR fails", method: http.MethodPost, target: "/pullRequest/reassign", body: body,
+		setup: func(st *mocks.Store) {
+			st.ReassignReviewerFunc = func(context.Context, models.PRRef, string, string, *int64) (string, error) { return "u3", nil }
+			st.GetPRFunc = func(context.Context, models.PRRef) (*models.PullRequest, error) { return nil, errDB }
+		},
+		status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to reassign reviewer",
+	})
+	runErrorCases(t, cases)
+}
+
+func TestGetUserReviewsErrors(t *testing.T) {
+	runErrorCases(t, []errorCase{
+		{
+			name: "missing user_id", method: http.MethodGet, target: "/users/getReview",
+			status: http.StatusBadRequest, code: handlers.ErrCodeMissingParam, message: "user_id parameter is required",
+		},
+		{
+			name: "invalid status", method: http.MethodGet, target: "/users/getReview?user_id=u1&status=DRAFT",
+			status: http.StatusBadRequest, code: handlers.ErrCodeInvalidParam,
+		},
+		{
+			name: "reviewer not found", method: http.MethodGet, target: "/users/getReview?user_id=u1",
+			setup: func(st *mocks.Store) {
+				st.GetPRsByReviewerFunc = func(context.Context, string, repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error) {
+					return nil, repository.PageInfo{}, repository.ErrNotFound
+				}
+			},
+			status: http.StatusNotFound, code: handlers.ErrCodeNotFound, message: "user not found",
+		},
+		{
+			name: "store failure", method: http.MethodGet, target: "/users/getReview?user_id=u1",
+			setup: func(st *mocks.Store) {
+				st.GetPRsByReviewerFunc = func(context.Context, string, repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error) {
+					return nil, repository.PageInfo{}, errDB
+				}
+			},
+			status: http.StatusInternalServerError, code: handlers.ErrCodeInternal, message: "failed to get user reviews",
+		},
+	})
+}
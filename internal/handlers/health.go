@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// readinessTimeout ограничивает время всех проверок готовности
+const readinessTimeout = time.Second
+
+// Статусы проверок здоровья
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFail     = "fail"
+	HealthStatusReady    = "ready"
+	HealthStatusNotReady = "not_ready"
+)
+
+// HealthDB — операции пула соединений, нужные для проверок готовности
+type HealthDB interface {
+	Ping(ctx context.Context) error
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ComponentStatus — результат проверки одного компонента
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse — ответ проверки готовности с результатами по компонентам
+type HealthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// HealthHandler обслуживает liveness и readiness проверки.
+// Отделен от Handler, потому что работает с пулом напрямую, минуя репозиторий.
+type HealthHandler struct {
+	db     HealthDB
+	logger *zap.Logger
+
+	// migrationVersion — версия последней миграции, которую ожидает код
+	migrationVersion int64
+	warmedUp         atomic.Bool
+}
+
+// NewHealthHandler создает обработчик проверок здоровья.
+// До вызова MarkWarmedUp сервис считается не готовым.
+func NewHealthHandler(db HealthDB, migrationVersion int64, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{db: db, logger: logger, migrationVersion: migrationVersion}
+}
+
+// MarkWarmedUp отмечает завершение прогрева пула соединений
+func (h *HealthHandler) MarkWarmedUp() {
+	h.warmedUp.Store(true)
+}
+
+// RegisterRoutes регистрирует /live, /ready и /health (синоним /ready для обратной совместимости)
+func (h *HealthHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/live", h.Live)
+	e.GET("/ready", h.Ready)
+	e.GET("/health", h.Ready)
+}
+
+// Live сообщает, что процесс запущен и обрабатывает запросы; зависимости не проверяются
+func (h *HealthHandler) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": HealthStatusOK})
+}
+
+// Ready проверяет прогрев, доступность БД и применение всех миграций.
+// При любой неуспешной проверке возвращает 503 с описанием компонента.
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+	defer cancel()
+
+	resp := HealthResponse{
+		Status:     HealthStatusReady,
+		Components: make(map[string]ComponentStatus, 3),
+	}
+	check := func(name string, err error) {
+		if err == nil {
+			resp.Components[name] = ComponentStatus{Status: HealthStatusOK}
+			return
+		}
+		resp.Status = HealthStatusNotReady
+		resp.Components[name] = ComponentStatus{Status: HealthStatusFail, Error: err.Error()}
+	}
+
+	var warmupErr error
+	if !h.warmedUp.Load() {
+		warmupErr = errors.New("connection pool warm-up in progress")
+	}
+	check("warmup", warmupErr)
+
+	dbErr := h.db.Ping(ctx)
+	check("database", dbErr)
+
+	// Без БД проверить миграции нельзя — помечаем их тем же сбоем
+	migrationsErr := dbErr
+	if dbErr == nil {
+		migrationsErr = h.checkMigrations(ctx)
+	}
+	check("migrations", migrationsErr)
+
+	if resp.Status != HealthStatusReady {
+		RequestLogger(c, h.logger).Warn("Ready: сервис не готов", zap.Any("components", resp.Components))
+		return c.JSON(http.StatusServiceUnavailable, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// checkMigrations проверяет, что последняя известная коду миграция применена goose
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	var applied bool
+	err := h.db.QueryRow(ctx, `
+		SELECT is_applied FROM goose_db_version
+		WHERE version_id = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`, h.migrationVersion).Scan(&applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		applied = false
+	} else if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	if !applied {
+		return fmt.Errorf("migration %d is not applied", h.migrationVersion)
+	}
+	return nil
+}
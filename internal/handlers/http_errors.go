@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+// Коды ошибок маршрутизации Echo
+const (
+	ErrCodeRouteNotFound    = "ROUTE_NOT_FOUND"
+	ErrCodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+)
+
+// httpErrorCodes сопоставляет статусы echo.HTTPError с кодами ошибок API
+var httpErrorCodes = map[int]string{
+	http.StatusNotFound:              ErrCodeRouteNotFound,
+	http.StatusMethodNotAllowed:      ErrCodeMethodNotAllowed,
+	http.StatusBadRequest:            ErrCodeInvalidBody,
+	http.StatusUnauthorized:          ErrCodeUnauthorized,
+	http.StatusRequestEntityTooLarge: ErrCodePayloadTooLarge,
+	http.StatusTooManyRequests:       ErrCodeRateLimited,
+}
+
+// ErrorHandler возвращает echo.HTTPErrorHandler, который отвечает на ошибки, не записанные обработчиками
+// (неизвестный маршрут, неподходящий метод, паника, ошибка middleware), в стандартном формате ErrorResponse.
+// Уже записанный ответ не меняется. Ошибки 5xx пишутся в лог со стеком вызовов.
+func ErrorHandler(logger *zap.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		req := c.Request()
+		status, code, message := http.StatusInternalServerError, ErrCodeInternal, "internal server error"
+		var he *echo.HTTPError
+		if errors.As(err, &he) && he.Code < http.StatusInternalServerError {
+			status, code, message = he.Code, httpErrorCodes[he.Code], fmt.Sprint(he.Message)
+			switch status {
+			case http.StatusNotFound:
+				message = fmt.Sprintf("route %s %s not found", req.Method, req.URL.Path)
+			case http.StatusMethodNotAllowed:
+				message = fmt.Sprintf("method %s is not allowed for %s", req.Method, req.URL.Path)
+			}
+			if code == "" {
+				code = ErrCodeInvalidParam
+			}
+		} else if he != nil {
+			status = he.Code
+		}
+
+		// Паника уже записана в лог со своим стеком в Recover
+		var panicked *recoveredPanic
+		if status >= http.StatusInternalServerError && !errors.As(err, &panicked) {
+			RequestLogger(c, logger).Error("ErrorHandler: необработанная ошибка запроса",
+				zap.Error(err), zap.Int("status", status), zap.Stack("stack"))
+		}
+
+		if req.Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, newErrorResponse(c, code, message))
+		}
+		if err != nil {
+			RequestLogger(c, logger).Error("ErrorHandler: ошибка записи ответа", zap.Error(err))
+		}
+	}
+}
+
+// recoveredPanic — ошибка паники, перехваченной Recover
+type recoveredPanic struct {
+	err error
+}
+
+func (p *recoveredPanic) Error() string { return p.err.Error() }
+func (p *recoveredPanic) Unwrap() error { return p.err }
+
+// Recover возвращает middleware восстановления после паники: стек паники пишется в лог запроса,
+// а ответ 500 INTERNAL_ERROR формирует ErrorHandler
+func Recover(logger *zap.Logger) echo.MiddlewareFunc {
+	return middleware.RecoverWithConfig(middleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			RequestLogger(c, logger).Error("Recover: паника при обработке запроса",
+				zap.Error(err), zap.ByteString("stack", stack))
+			return &recoveredPanic{err: err}
+		},
+	})
+}
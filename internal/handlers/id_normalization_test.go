@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// TestIDNormalization проверяет, что в режиме fold внешние ID пользователей приходят в хранилище
+// без пробелов по краям и в нижнем регистре, а в режиме strict — как есть
+func TestIDNormalization(t *testing.T) {
+	// seen запоминает ID, с которыми обработчик обратился к хранилищу; дальше запрос завершается 404
+	routes := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		setup  func(st *mocks.Store, seen *[]string)
+	}{
+		{
+			name: "query parameter", method: http.MethodGet, target: "/users/get?user_id=%20U123%20",
+			setup: func(st *mocks.Store, seen *[]string) {
+				st.GetUserFunc = func(_ context.Context, userID string) (*models.User, error) {
+					*seen = append(*seen, userID)
+					return nil, repository.ErrNotFound
+				}
+			},
+		},
+		{
+			name: "body field", method: http.MethodPost, target: "/users/setIsActive", body: `{"user_id":" U123 ","is_active":false}`,
+			setup: func(st *mocks.Store, seen *[]string) {
+				st.UpdateUserStatusFunc = func(_ context.Context, userID string, _ bool) error {
+					*seen = append(*seen, userID)
+					return repository.ErrNotFound
+				}
+			},
+		},
+		{
+			name: "team members", method: http.MethodPost, target: "/team/add",
+			body: `{"team_name":"backend","members":[{"user_id":" U123 ","username":"Alice","is_active":true},{"user_id":"u456","username":"Bob","is_active":true}]}`,
+			setup: func(st *mocks.Store, seen *[]string) {
+				st.CreateTeamFunc = func(_ context.Context, team models.Team) (*models.Team, error) {
+					for _, member := range team.Members {
+						*seen = append(*seen, member.UserID)
+					}
+					return nil, errDB
+				}
+			},
+		},
+	}
+	modes := []struct {
+		name string
+		fold bool
+		want map[string][]string
+	}{
+		{name: "fold", fold: true, want: map[string][]string{
+			"query parameter": {"u123"},
+			"body field":      {"u123"},
+			"team members":    {"u123", "u456"},
+		}},
+		{name: "strict", fold: false, want: map[string][]string{
+			"query parameter": {" U123 "},
+			"body field":      {" U123 "},
+			"team members":    {" U123 ", "u456"},
+		}},
+	}
+	for _, mode := range modes {
+		for _, route := range routes {
+			t.Run(mode.name+" "+route.name, func(t *testing.T) {
+				var seen []string
+				st := &mocks.Store{}
+				route.setup(st, &seen)
+
+				serve(newTestServer(st, handlers.Config{FoldIDs: mode.fold}), route.method, route.target, route.body, nil)
+
+				assert.Equal(t, mode.want[route.name], seen)
+			})
+		}
+	}
+
+	t.Run("fold round trip", func(t *testing.T) {
+		// Разные написания одного ID попадают в хранилище одинаковыми
+		var seen []string
+		st := &mocks.Store{
+			GetUserFunc: func(_ context.Context, userID string) (*models.User, error) {
+				seen = append(seen, userID)
+				return &models.User{UserID: userID}, nil
+			},
+		}
+		e := newTestServer(st, handlers.Config{FoldIDs: true})
+		for _, id := range []string{"U123", "u123", "%20U123%20", "%09u123"} {
+			assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/users/get?user_id="+id, "", nil).Code)
+		}
+		assert.Equal(t, []string{"u123", "u123", "u123", "u123"}, seen)
+	})
+}
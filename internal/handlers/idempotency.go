@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderIdempotencyKey — заголовок с ключом идемпотентности
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed выставляется в ответах, воспроизведенных из сохраненных
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength ограничивает длину ключа идемпотентности
+	maxIdempotencyKeyLength = 255
+)
+
+// inboundWebhookPaths — маршруты входящих вебхуков (без префикса версии); управление подписками под /webhooks/
+// к ним не относится
+var inboundWebhookPaths = map[string]bool{
+	"/webhooks/github": true,
+	"/webhooks/gitlab": true,
+}
+
+// IdempotencyStore — хранилище ключей идемпотентности
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, key models.IdempotencyKey, requestHash string, lease time.Duration) (*models.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, key models.IdempotencyKey, statusCode int, body []byte, ttl time.Duration) error
+	ReleaseIdempotencyKey(ctx context.Context, key models.IdempotencyKey) error
+}
+
+// Idempotency возвращает middleware для POST-запросов с заголовком Idempotency-Key.
+// Первый запрос с ключом выполняется, его ответ (кроме 5xx) сохраняется на ttl.
+// Повтор с тем же ключом и телом получает сохраненный ответ без повторного выполнения,
+// с другим телом — 422, а пока первый запрос не завершился — 409. Незавершенный запрос держит ключ
+// не дольше lease, поэтому резерв, брошенный упавшим процессом, не блокирует повторы на весь ttl.
+// Ключ действует в пределах метода и пути без префикса версии: /api/v1/... и прежний путь делят ключи.
+// Запросы без заголовка не затрагиваются. Входящие вебхуки пропускаются: они дедуплицируются
+// по ID доставки после проверки подписи или токена.
+func Idempotency(store IdempotencyStore, ttl, lease time.Duration, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			keyValue := req.Header.Get(HeaderIdempotencyKey)
+			path := unversionedPath(req.URL.Path)
+			if req.Method != http.MethodPost || keyValue == "" || inboundWebhookPaths[path] {
+				return next(c)
+			}
+
+			log := RequestLogger(c, logger)
+			if len(keyValue) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, "Idempotency-Key is too long"))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				log.Error("Idempotency: ошибка чтения тела запроса", zap.Error(err))
+				return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.Sum256(body)
+			requestHash := hex.EncodeToString(hash[:])
+			key := models.IdempotencyKey{Key: keyValue, Method: req.Method, Path: path}
+			ctx := req.Context()
+
+			record, err := store.ReserveIdempotencyKey(ctx, key, requestHash, lease)
+			if err != nil {
+				log.Error("Idempotency: ошибка резервирования ключа", zap.Error(err))
+				return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "internal server error"))
+			}
+
+			if record != nil {
+				switch {
+				case record.RequestHash != requestHash:
+					log.Warn("Idempotency: ключ переиспользован с другим телом", zap.String("idempotency_key", keyValue))
+					return c.JSON(http.StatusUnprocessableEntity, newErrorResponse(c, ErrCodeIdempotencyMismatch,
+						"Idempotency-Key was already used with a different request body"))
+				case record.StatusCode == 0:
+					return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeIdempotencyInProgress,
+						"request with this Idempotency-Key is still in progress"))
+				}
+
+				log.Info("Idempotency: ответ воспроизведен", zap.String("idempotency_key", keyValue),
+					zap.Int("status", record.StatusCode))
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.JSONBlob(record.StatusCode, record.ResponseBody)
+			}
+
+			// Дублируем тело ответа в буфер, чтобы сохранить его после выполнения обработчика
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			handlerErr := next(c)
+
+			// Ответ уже отправлен клиенту, поэтому сохранение не зависит от отмены запроса
+			saveCtx := context.WithoutCancel(ctx)
+			status := c.Response().Status
+			if handlerErr != nil || status >= http.StatusInternalServerError || !c.Response().Committed {
+				if err := store.ReleaseIdempotencyKey(saveCtx, key); err != nil {
+					log.Error("Idempotency: ошибка снятия резерва ключа", zap.Error(err))
+				}
+				return handlerErr
+			}
+
+			if err := store.CompleteIdempotencyKey(saveCtx, key, status, recorder.body.Bytes(), ttl); err != nil {
+				log.Error("Idempotency: ошибка сохранения ответа", zap.Error(err))
+			}
+			return nil
+		}
+	}
+}
+
+// responseRecorder пишет ответ клиенту и одновременно копирует тело в буфер
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
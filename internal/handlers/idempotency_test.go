@@ -0,0 +1,224 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+const (
+	idempotencyTTL   = 24 * time.Hour
+	idempotencyLease = time.Minute
+)
+
+// memIdempotencyStore — IdempotencyStore в памяти со своими часами; истекшая запись считается свободной,
+// как в repository.ReserveIdempotencyKey
+type memIdempotencyStore struct {
+	mu      sync.Mutex
+	now     time.Time
+	records map[models.IdempotencyKey]*memIdempotencyRecord
+}
+
+type memIdempotencyRecord struct {
+	record    models.IdempotencyRecord
+	expiresAt time.Time
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{
+		now:     time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC),
+		records: make(map[models.IdempotencyKey]*memIdempotencyRecord),
+	}
+}
+
+// advance сдвигает часы хранилища
+func (s *memIdempotencyStore) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+func (s *memIdempotencyStore) ReserveIdempotencyKey(_ context.Context, key models.IdempotencyKey, requestHash string, lease time.Duration) (*models.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok && rec.expiresAt.After(s.now) {
+		record := rec.record
+		return &record, nil
+	}
+	s.records[key] = &memIdempotencyRecord{
+		record:    models.IdempotencyRecord{RequestHash: requestHash},
+		expiresAt: s.now.Add(lease),
+	}
+	return nil, nil
+}
+
+func (s *memIdempotencyStore) CompleteIdempotencyKey(_ context.Context, key models.IdempotencyKey, statusCode int, body []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		rec.record.StatusCode = statusCode
+		rec.record.ResponseBody = append([]byte(nil), body...)
+		rec.expiresAt = s.now.Add(ttl)
+	}
+	return nil
+}
+
+func (s *memIdempotencyStore) ReleaseIdempotencyKey(_ context.Context, key models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// reviewerStore — мок, в котором у PR pr-1 один ревьюер; каждый вызов ReassignReviewer переводит PR
+// на следующего участника и считается
+type reviewerStore struct {
+	mocks.Store
+	mu        sync.Mutex
+	reviewer  string
+	reassigns int
+}
+
+func newReviewerStore() *reviewerStore {
+	st := &reviewerStore{reviewer: "u2"}
+	next := map[string]string{"u2": "u3", "u3": "u4"}
+	st.ReassignReviewerFunc = func(_ context.Context, _ models.PRRef, oldReviewerID, _ string, _ *int64) (string, error) {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		if oldReviewerID != st.reviewer {
+			return "", repository.ErrNotAssigned
+		}
+		st.reassigns++
+		st.reviewer = next[st.reviewer]
+		return st.reviewer, nil
+	}
+	st.GetPRFunc = func(context.Context, models.PRRef) (*models.PullRequest, error) {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return &models.PullRequest{
+			PullRequestID: "pr-1", AuthorID: "u1", Status: "OPEN",
+			AssignedReviewers:   []models.AssignedReviewer{{UserID: st.reviewer, Username: "User " + st.reviewer, IsActive: true}},
+			AssignedReviewerIDs: []string{st.reviewer}, Version: int64(st.reassigns + 1),
+		}, nil
+	}
+	return st
+}
+
+// newIdempotentServer собирает Echo с маршрутами под /api/v1 и без версии и идемпотентностью на них, как main.go
+func newIdempotentServer(st *reviewerStore, keys handlers.IdempotencyStore) *echo.Echo {
+	e := echo.New()
+	e.Binder = &handlers.Binder{}
+	e.HTTPErrorHandler = handlers.ErrorHandler(zap.NewNop())
+	idempotency := handlers.Idempotency(keys, idempotencyTTL, idempotencyLease, zap.NewNop())
+	h := handlers.New(st, service.New(st), metrics.New(prometheus.NewRegistry()), zap.NewNop(), handlers.Config{})
+	h.RegisterRoutes(e.Group(handlers.APIV1Prefix), idempotency)
+	h.RegisterLegacyRoutes(e.Group(""), idempotency)
+	return e
+}
+
+func TestIdempotentReassignReplay(t *testing.T) {
+	const body = `{"pull_request_id":"pr-1","old_user_id":"u2"}`
+	st := newReviewerStore()
+	e := newIdempotentServer(st, newMemIdempotencyStore())
+	header := map[string]string{handlers.HeaderIdempotencyKey: "retry-1"}
+
+	first := serve(e, http.MethodPost, "/api/v1/pullRequest/reassign", body, header)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	require.Equal(t, 1, st.reassigns)
+	assert.Empty(t, first.Header().Get(handlers.HeaderIdempotentReplayed))
+
+	t.Run("retry under /api/v1", func(t *testing.T) {
+		retry := serve(e, http.MethodPost, "/api/v1/pullRequest/reassign", body, header)
+		require.Equal(t, http.StatusOK, retry.Code, retry.Body.String())
+		assert.Equal(t, "true", retry.Header().Get(handlers.HeaderIdempotentReplayed))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, 1, st.reassigns, "a retried reassign must not move the reviewer twice")
+	})
+
+	t.Run("retry under the legacy path", func(t *testing.T) {
+		retry := serve(e, http.MethodPost, "/pullRequest/reassign", body, header)
+		require.Equal(t, http.StatusOK, retry.Code, retry.Body.String())
+		assert.Equal(t, "true", retry.Header().Get(handlers.HeaderIdempotentReplayed))
+		assert.Equal(t, "true", retry.Header().Get(handlers.HeaderDeprecation))
+		assert.Equal(t, 1, st.reassigns, "a retried reassign must not move the reviewer twice")
+
+		// Сохраненный ответ /api/v1 отдается в старом формате прежнего пути
+		var resp struct {
+			PR struct {
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+			ReplacedBy string `json:"replaced_by"`
+		}
+		require.NoError(t, json.Unmarshal(retry.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"u3"}, resp.PR.AssignedReviewers)
+		assert.Equal(t, "u3", resp.ReplacedBy)
+	})
+
+	t.Run("same key with another body", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"u3"}`, header)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, handlers.ErrCodeIdempotencyMismatch, resp.Error.Code)
+		assert.Equal(t, 1, st.reassigns)
+	})
+
+	t.Run("without key the request runs again", func(t *testing.T) {
+		rec := serve(e, http.MethodPost, "/api/v1/pullRequest/reassign", body, nil)
+		require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		assert.Equal(t, 1, st.reassigns)
+	})
+}
+
+func TestIdempotencyAbandonedReservationExpires(t *testing.T) {
+	const body = `{"pull_request_id":"pr-1","old_user_id":"u2"}`
+	st := newReviewerStore()
+	keys := newMemIdempotencyStore()
+	e := newIdempotentServer(st, keys)
+	header := map[string]string{handlers.HeaderIdempotencyKey: "crashed-1"}
+
+	// Резерв, оставленный процессом, упавшим посреди запроса
+	key := models.IdempotencyKey{Key: "crashed-1", Method: http.MethodPost, Path: "/pullRequest/reassign"}
+	hash := sha256.Sum256([]byte(body))
+	record, err := keys.ReserveIdempotencyKey(context.Background(), key, hex.EncodeToString(hash[:]), idempotencyLease)
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	rec := serve(e, http.MethodPost, "/api/v1/pullRequest/reassign", body, header)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, handlers.ErrCodeIdempotencyInProgress, resp.Error.Code)
+	assert.Zero(t, st.reassigns)
+
+	// После срока резерва ключ снова свободен, хотя до конца TTL еще далеко
+	keys.advance(idempotencyLease + time.Second)
+	rec = serve(e, http.MethodPost, "/api/v1/pullRequest/reassign", body, header)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, st.reassigns)
+
+	// Завершенный ответ хранится TTL, а не срок резерва
+	keys.advance(2 * idempotencyLease)
+	rec = serve(e, http.MethodPost, "/pullRequest/reassign", body, header)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get(handlers.HeaderIdempotentReplayed))
+	assert.Equal(t, 1, st.reassigns)
+}
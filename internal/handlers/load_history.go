@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetLoadHistory возвращает временные ряды числа открытых ревью по снимкам
+// для пользователя (user_id) или для текущих участников команды (team_name)
+func (h *Handler) GetLoadHistory(c echo.Context) error {
+	h.log(c).Info("GetLoadHistory: начало обработки запроса")
+
+	userID := h.normalizeID(c.QueryParam("user_id"))
+	teamName := c.QueryParam("team_name")
+	if (userID == "") == (teamName == "") {
+		h.log(c).Warn("GetLoadHistory: нужно указать ровно один из user_id и team_name")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "exactly one of user_id or team_name is required"))
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		h.log(c).Warn("GetLoadHistory: некорректный период", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	var series []models.LoadHistorySeries
+	if userID != "" {
+		series, err = h.repo.GetUserLoadHistory(c.Request().Context(), userID, from, to)
+	} else {
+		series, err = h.repo.GetTeamLoadHistory(c.Request().Context(), teamName, from, to)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetLoadHistory: пользователь или команда не найдены",
+				zap.String("user_id", userID),
+				zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user or team not found"))
+		}
+		h.log(c).Error("GetLoadHistory: ошибка получения истории загрузки", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get load history"))
+	}
+
+	h.log(c).Info("GetLoadHistory: история загрузки успешно получена", zap.Int("series_count", len(series)))
+
+	response := map[string]interface{}{
+		"from":   from.Format(dateLayout),
+		"to":     to.Format(dateLayout),
+		"series": series,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
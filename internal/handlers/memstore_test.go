@@ -0,0 +1,578 @@
+package handlers_test
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// memReviewersPerPR — сколько ревьюеров назначается по умолчанию, как reviewers_per_pr команды без настроек
+const memReviewersPerPR = 2
+
+// memUser — пользователь memStore
+type memUser struct {
+	name      string
+	active    bool
+	paused    bool
+	vacations []models.Vacation
+}
+
+// memReview — назначение ревьюера на PR в memStore
+type memReview struct {
+	userID     string
+	approvedAt *time.Time
+}
+
+// memPR — PR в memStore: сам PR без ревьюеров, команда, из которой они назначаются, и назначения
+type memPR struct {
+	pr        models.PullRequest
+	team      string
+	reviewers []*memReview
+}
+
+// memStore — хранилище в памяти для сценарных тестов обработчиков. Повторяет правила репозитория
+// для команд, назначения (least_loaded: меньше всего открытых ревью, затем по ID), статусов PR,
+// одобрений, переназначений, пауз и отпусков без cooldown, исключений, лимитов и резервной команды.
+// Остальные методы хранилища не заданы и возвращают mocks.ErrNotConfigured.
+type memStore struct {
+	mu sync.Mutex
+	// users — пользователи по внешнему ID
+	users map[string]*memUser
+	// teams — участники команд в порядке добавления, teamOrder — команды в порядке создания
+	teams     map[string][]string
+	teamOrder []string
+	prs       []*memPR
+	// nextVacationID — ID следующего отпуска
+	nextVacationID int64
+}
+
+// newMemStore возвращает пустое хранилище в памяти в виде мока handlers.Store
+func newMemStore() *mocks.Store {
+	s := &memStore{users: map[string]*memUser{}, teams: map[string][]string{}, nextVacationID: 1}
+	return &mocks.Store{
+		CreateTeamFunc:            s.CreateTeam,
+		AddTeamMemberFunc:         s.AddTeamMember,
+		GetUserFunc:               s.GetUser,
+		UpdateUserStatusFunc:      s.UpdateUserStatus,
+		DeactivateAndReassignFunc: s.DeactivateAndReassign,
+		SetAssignmentPausedFunc:   s.SetAssignmentPaused,
+		AddVacationFunc:           s.AddVacation,
+		GetPRsByReviewerFunc:      s.GetPRsByReviewer,
+		CreatePRFunc:              s.CreatePR,
+		GetPRFunc:                 s.GetPR,
+		MergePRFunc:               s.MergePR,
+		ClosePRFunc:               s.ClosePR,
+		ReopenPRFunc:              s.ReopenPR,
+		ApprovePRFunc:             s.ApprovePR,
+		ReassignReviewerFunc:      s.ReassignReviewer,
+		GetUserReviewStatsFunc:    s.GetUserReviewStats,
+	}
+}
+
+// CreateTeam создает команду или добавляет в нее участников, обновляя имя и активность существующих
+func (s *memStore) CreateTeam(_ context.Context, team models.Team) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[team.TeamName]; !ok {
+		s.teams[team.TeamName] = nil
+		s.teamOrder = append(s.teamOrder, team.TeamName)
+	}
+	for _, member := range team.Members {
+		s.upsertMember(team.TeamName, member)
+	}
+	return s.team(team.TeamName), nil
+}
+
+// AddTeamMember добавляет пользователя в существующую команду
+func (s *memStore) AddTeamMember(_ context.Context, teamName string, member models.TeamMember) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, ok := s.teams[teamName]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if slices.Contains(members, member.UserID) {
+		return nil, repository.ErrAlreadyMember
+	}
+	s.upsertMember(teamName, member)
+	return s.team(teamName), nil
+}
+
+// upsertMember создает или обновляет пользователя и включает его в команду teamName
+func (s *memStore) upsertMember(teamName string, member models.TeamMember) {
+	user, ok := s.users[member.UserID]
+	if !ok {
+		user = &memUser{}
+		s.users[member.UserID] = user
+	}
+	user.name, user.active = member.Username, member.IsActive
+	if !slices.Contains(s.teams[teamName], member.UserID) {
+		s.teams[teamName] = append(s.teams[teamName], member.UserID)
+	}
+}
+
+// team возвращает команду с участниками
+func (s *memStore) team(teamName string) *models.Team {
+	team := &models.Team{TeamName: teamName, Members: []models.TeamMember{}}
+	for _, id := range s.teams[teamName] {
+		user := s.users[id]
+		paused := user.paused
+		team.Members = append(team.Members, models.TeamMember{
+			UserID:           id,
+			Username:         user.name,
+			IsActive:         user.active,
+			Role:             models.TeamRoleMember,
+			AssignmentPaused: &paused,
+		})
+	}
+	return team
+}
+
+// userTeam возвращает самую раннюю команду пользователя, как команду автора по умолчанию
+func (s *memStore) userTeam(userID string) string {
+	for _, name := range s.teamOrder {
+		if slices.Contains(s.teams[name], userID) {
+			return name
+		}
+	}
+	return ""
+}
+
+// GetUser возвращает пользователя с командой и отпусками
+func (s *memStore) GetUser(_ context.Context, userID string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	paused := user.paused
+	return &models.User{
+		UserID:           userID,
+		Username:         user.name,
+		TeamName:         s.userTeam(userID),
+		IsActive:         user.active,
+		IsReviewer:       true,
+		Vacations:        append([]models.Vacation{}, user.vacations...),
+		ExternalAccounts: []models.ExternalAccount{},
+		AssignmentPaused: &paused,
+	}, nil
+}
+
+// UpdateUserStatus меняет активность пользователя без переназначения его ревью
+func (s *memStore) UpdateUserStatus(_ context.Context, userID string, isActive bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.active = isActive
+	return nil
+}
+
+// DeactivateAndReassign деактивирует пользователя и заменяет его в открытых PR кандидатом из команды PR.
+// PR без кандидата попадают в NotReassigned, и пользователь остается в них ревьюером.
+func (s *memStore) DeactivateAndReassign(_ context.Context, userID string) (*models.ReassignmentResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	user.active = false
+
+	result := &models.ReassignmentResult{
+		Reassigned:    []models.ReviewReassignment{},
+		NotReassigned: []string{},
+		SkippedRecent: []string{},
+	}
+	for _, p := range s.prs {
+		review := p.review(userID)
+		if p.pr.Status != models.StatusOpen || review == nil {
+			continue
+		}
+		candidates := s.candidates(p, 1)
+		if len(candidates) == 0 {
+			result.NotReassigned = append(result.NotReassigned, p.pr.PullRequestID)
+			continue
+		}
+		*review = memReview{userID: candidates[0]}
+		p.pr.Version++
+		result.Reassigned = append(result.Reassigned, models.ReviewReassignment{
+			PullRequestID: p.pr.PullRequestID,
+			NewReviewerID: candidates[0],
+		})
+	}
+	return result, nil
+}
+
+// SetAssignmentPaused приостанавливает или возобновляет автоназначение пользователя; until не учитывается
+func (s *memStore) SetAssignmentPaused(_ context.Context, userID string, paused bool, _ *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.paused = paused
+	return nil
+}
+
+// AddVacation добавляет пользователю отпуск с from по to
+func (s *memStore) AddVacation(_ context.Context, userID string, from, to time.Time) (*models.Vacation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	for _, v := range user.vacations {
+		if from.Before(v.To) && v.From.Before(to) {
+			return nil, repository.ErrVacationOverlap
+		}
+	}
+	vacation := models.Vacation{VacationID: s.nextVacationID, From: from, To: to}
+	s.nextVacationID++
+	user.vacations = append(user.vacations, vacation)
+	return &vacation, nil
+}
+
+// GetPRsByReviewer возвращает PR, где пользователь назначен ревьюером, с фильтрами по статусу и одобрению.
+// Пагинация не поддерживается: возвращаются все подходящие PR.
+func (s *memStore) GetPRsByReviewer(_ context.Context, reviewerID string, filter repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[reviewerID]; !ok {
+		return nil, repository.PageInfo{}, repository.ErrNotFound
+	}
+	prs := []models.PullRequestShort{}
+	for _, p := range s.prs {
+		review := p.review(reviewerID)
+		if review == nil || filter.Status != "" && p.pr.Status != filter.Status || filter.UnapprovedOnly && review.approvedAt != nil {
+			continue
+		}
+		prs = append(prs, models.PullRequestShort{
+			PullRequestID:   p.pr.PullRequestID,
+			Repository:      p.pr.Repository,
+			PullRequestName: p.pr.PullRequestName,
+			AuthorID:        p.pr.AuthorID,
+			Status:          p.pr.Status,
+		})
+	}
+	return prs, repository.PageInfo{Total: len(prs)}, nil
+}
+
+// CreatePR создает PR и назначает до memReviewersPerPR ревьюеров из команды teamName или команды автора
+func (s *memStore) CreatePR(_ context.Context, repo, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.prs {
+		if p.pr.Repository == repo && p.pr.PullRequestID == pullRequestID {
+			return nil, repository.ErrAlreadyExists
+		}
+	}
+	if _, ok := s.users[authorID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+	if teamName == "" {
+		teamName = s.userTeam(authorID)
+	}
+	members, ok := s.teams[teamName]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if !slices.Contains(members, authorID) {
+		return nil, repository.ErrAuthorNotInTeam
+	}
+
+	now := time.Now().UTC()
+	p := &memPR{
+		pr: models.PullRequest{
+			PullRequestID:   pullRequestID,
+			Repository:      repo,
+			PullRequestName: pullRequestName,
+			AuthorID:        authorID,
+			Status:          models.StatusOpen,
+			CreatedAt:       &now,
+			Version:         1,
+			PRMetadata:      meta,
+		},
+		team: teamName,
+	}
+	p.assign(s.candidates(p, memReviewersPerPR))
+	s.prs = append(s.prs, p)
+	return s.view(p), nil
+}
+
+// GetPR возвращает PR по ссылке ref
+func (s *memStore) GetPR(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.find(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(p), nil
+}
+
+// MergePR сливает открытый PR; смерженный возвращается без изменений, закрытый слить нельзя
+func (s *memStore) MergePR(_ context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, bool, error) {
+	return s.setStatus(ref, models.StatusMerged, expectedVersion, func(p *memPR, now *time.Time) {
+		p.pr.MergedAt = now
+	})
+}
+
+// ClosePR закрывает открытый PR; закрытый возвращается без изменений, смерженный закрыть нельзя
+func (s *memStore) ClosePR(_ context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, error) {
+	pr, _, err := s.setStatus(ref, models.StatusClosed, expectedVersion, func(p *memPR, now *time.Time) {
+		p.pr.ClosedAt = now
+	})
+	return pr, err
+}
+
+// ReopenPR переоткрывает закрытый PR; при reassign назначает ревьюеров PR, у которого их нет
+func (s *memStore) ReopenPR(_ context.Context, ref models.PRRef, reassign bool) (*models.PullRequest, error) {
+	pr, _, err := s.setStatus(ref, models.StatusOpen, nil, func(p *memPR, _ *time.Time) {
+		p.pr.ClosedAt = nil
+		if reassign && len(p.reviewers) == 0 {
+			p.assign(s.candidates(p, memReviewersPerPR))
+		}
+	})
+	return pr, err
+}
+
+// setStatus переводит PR в статус to по правилам models.CanTransition; повторный перевод в тот же
+// статус ничего не меняет, и changed при этом false. apply дополняет изменение полями, зависящими от статуса.
+func (s *memStore) setStatus(ref models.PRRef, to string, expectedVersion *int64, apply func(p *memPR, now *time.Time)) (_ *models.PullRequest, changed bool, _ error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.find(ref)
+	if err != nil {
+		return nil, false, err
+	}
+	if p.pr.Status == to {
+		return s.view(p), false, nil
+	}
+	if !models.CanTransition(p.pr.Status, to) {
+		return nil, false, &repository.TransitionError{From: p.pr.Status, To: to}
+	}
+	if expectedVersion != nil && *expectedVersion != p.pr.Version {
+		return nil, false, repository.ErrVersionConflict
+	}
+
+	now := time.Now().UTC()
+	p.pr.Status = to
+	p.pr.Version++
+	apply(p, &now)
+	return s.view(p), true, nil
+}
+
+// ApprovePR отмечает одобрение открытого PR назначенным ревьюером; повторное одобрение не меняет approved_at
+func (s *memStore) ApprovePR(_ context.Context, ref models.PRRef, userID string) (*models.PullRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.find(ref)
+	if err != nil {
+		return nil, err
+	}
+	switch p.pr.Status {
+	case models.StatusMerged:
+		return nil, repository.ErrAlreadyMerged
+	case models.StatusClosed:
+		return nil, repository.ErrAlreadyClosed
+	}
+	review := p.review(userID)
+	if review == nil {
+		return nil, repository.ErrNotAssigned
+	}
+	if review.approvedAt == nil {
+		now := time.Now().UTC()
+		review.approvedAt = &now
+	}
+	return s.view(p), nil
+}
+
+// ReassignReviewer заменяет ревьюера oldReviewerID на newReviewerID или, если он пуст, на кандидата из команды PR
+func (s *memStore) ReassignReviewer(_ context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[oldReviewerID]; !ok {
+		return "", repository.ErrNotFound
+	}
+	p, err := s.find(ref)
+	if err != nil {
+		return "", err
+	}
+	switch p.pr.Status {
+	case models.StatusMerged:
+		return "", repository.ErrAlreadyMerged
+	case models.StatusClosed:
+		return "", repository.ErrAlreadyClosed
+	}
+	if expectedVersion != nil && *expectedVersion != p.pr.Version {
+		return "", repository.ErrVersionConflict
+	}
+	review := p.review(oldReviewerID)
+	if review == nil {
+		return "", repository.ErrNotAssigned
+	}
+
+	if newReviewerID == "" {
+		candidates := s.candidates(p, 1)
+		if len(candidates) == 0 {
+			return "", repository.ErrNoCandidate
+		}
+		newReviewerID = candidates[0]
+	} else {
+		user, ok := s.users[newReviewerID]
+		if !ok {
+			return "", repository.ErrNotFound
+		}
+		if p.review(newReviewerID) != nil {
+			return "", repository.ErrAlreadyAssigned
+		}
+		if !user.active || newReviewerID == p.pr.AuthorID || !slices.Contains(s.teams[p.team], newReviewerID) {
+			return "", repository.ErrCandidateNotEligible
+		}
+	}
+
+	*review = memReview{userID: newReviewerID}
+	p.pr.Version++
+	return newReviewerID, nil
+}
+
+// GetUserReviewStats возвращает число назначений каждого пользователя, включая пользователей без ревью
+func (s *memStore) GetUserReviewStats(context.Context) ([]models.UserReviewStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]models.UserReviewStats, 0, len(s.users))
+	for id, user := range s.users {
+		count := 0
+		for _, p := range s.prs {
+			if p.review(id) != nil {
+				count++
+			}
+		}
+		stats = append(stats, models.UserReviewStats{UserID: id, Username: user.name, ReviewCount: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ReviewCount != stats[j].ReviewCount {
+			return stats[i].ReviewCount > stats[j].ReviewCount
+		}
+		return stats[i].Username < stats[j].Username
+	})
+	return stats, nil
+}
+
+// find ищет PR по ссылке ref; без репозитория ID из нескольких репозиториев дает ErrAmbiguousPR
+func (s *memStore) find(ref models.PRRef) (*memPR, error) {
+	var found *memPR
+	for _, p := range s.prs {
+		if p.pr.PullRequestID != ref.ID || ref.Repository != nil && p.pr.Repository != *ref.Repository {
+			continue
+		}
+		if found != nil {
+			return nil, repository.ErrAmbiguousPR
+		}
+		found = p
+	}
+	if found == nil {
+		return nil, repository.ErrNotFound
+	}
+	return found, nil
+}
+
+// candidates возвращает до n кандидатов в ревьюеры PR из его команды: активных, не на паузе и не в отпуске,
+// кроме автора и текущих ревьюеров, по возрастанию числа открытых ревью, затем по ID
+func (s *memStore) candidates(p *memPR, n int) []string {
+	now := time.Now()
+	load := map[string]int{}
+	var candidates []string
+	for _, id := range s.teams[p.team] {
+		user := s.users[id]
+		if id == p.pr.AuthorID || p.review(id) != nil || !user.active || user.paused || onVacation(user, now) {
+			continue
+		}
+		for _, other := range s.prs {
+			if other.pr.Status == models.StatusOpen && other.review(id) != nil {
+				load[id]++
+			}
+		}
+		candidates = append(candidates, id)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if load[candidates[i]] != load[candidates[j]] {
+			return load[candidates[i]] < load[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// onVacation сообщает, в отпуске ли пользователь в момент now
+func onVacation(user *memUser, now time.Time) bool {
+	for _, v := range user.vacations {
+		if !v.From.After(now) && v.To.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// view возвращает копию PR с назначенными ревьюерами
+func (s *memStore) view(p *memPR) *models.PullRequest {
+	pr := p.pr
+	pr.AssignedReviewers = []models.AssignedReviewer{}
+	pr.AssignedReviewerIDs = []string{}
+	for _, review := range p.reviewers {
+		user := s.users[review.userID]
+		pr.AssignedReviewers = append(pr.AssignedReviewers, models.AssignedReviewer{
+			UserID:     review.userID,
+			Username:   user.name,
+			IsActive:   user.active,
+			Approved:   review.approvedAt != nil,
+			ApprovedAt: review.approvedAt,
+			Source:     models.ReviewerSourceTeam,
+		})
+		pr.AssignedReviewerIDs = append(pr.AssignedReviewerIDs, review.userID)
+	}
+	return &pr
+}
+
+// review возвращает назначение пользователя userID на PR или nil
+func (p *memPR) review(userID string) *memReview {
+	for _, review := range p.reviewers {
+		if review.userID == userID {
+			return review
+		}
+	}
+	return nil
+}
+
+// assign назначает на PR ревьюеров userIDs
+func (p *memPR) assign(userIDs []string) {
+	for _, id := range userIDs {
+		p.reviewers = append(p.reviewers, &memReview{userID: id})
+	}
+}
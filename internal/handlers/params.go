@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// parsePagination разбирает параметры limit/offset из query-строки.
+// Если limit не передан, используется defaultPageLimit; больше maxPageLimit нельзя.
+func parsePagination(c echo.Context) (int, int, error) {
+	limit := defaultPageLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 || v > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxPageLimit)
+		}
+		limit = v
+	}
+
+	offset := 0
+	if raw := c.QueryParam("offset"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = v
+	}
+
+	return limit, offset, nil
+}
+
+// parseBoolParam разбирает необязательный булев параметр query-строки (по умолчанию false)
+func parseBoolParam(c echo.Context, name string) (bool, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return v, nil
+}
+
+// parseStatusParam разбирает необязательный фильтр по статусу PR (пустая строка — любой статус)
+func parseStatusParam(c echo.Context) (string, error) {
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.StatusOpen, models.StatusMerged, models.StatusClosed:
+		return status, nil
+	}
+	return "", errors.New("status must be one of OPEN, MERGED, CLOSED")
+}
+
+// parseLabelParam разбирает необязательный фильтр по метке PR и нормализует его (пустая строка — любые метки)
+func parseLabelParam(c echo.Context) (string, error) {
+	label := NormalizeLabel(c.QueryParam("label"))
+	if utf8.RuneCountInString(label) > maxPRLabelLength {
+		return "", fmt.Errorf("label must be at most %d characters", maxPRLabelLength)
+	}
+	return label, nil
+}
+
+// parseDateRange разбирает параметры from и to (YYYY-MM-DD).
+// По умолчанию to — сегодня, from — за defaultHistoryDays дней до to.
+func parseDateRange(c echo.Context) (from, to time.Time, err error) {
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if raw := c.QueryParam("to"); raw != "" {
+		to, err = time.Parse(dateLayout, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	from = to.AddDate(0, 0, -(defaultHistoryDays - 1))
+	if raw := c.QueryParam("from"); raw != "" {
+		from, err = time.Parse(dateLayout, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+
+	return from, to, nil
+}
+
+// parseTimeParam разбирает необязательный параметр query-строки со временем в RFC3339 (nil — не задан)
+func parseTimeParam(c echo.Context, name string) (*time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// parsePRListRange разбирает общие для списков PR параметры периода (created_after, created_before,
+// merged_after, merged_before), сортировки (sort, order) и курсора страницы (cursor) в filter.
+// Ошибка называет неверный параметр.
+func parsePRListRange(c echo.Context, filter *repository.PRListFilter) error {
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"merged_after", &filter.MergedAfter},
+		{"merged_before", &filter.MergedBefore},
+	} {
+		t, err := parseTimeParam(c, p.name)
+		if err != nil {
+			return err
+		}
+		*p.dst = t
+	}
+
+	filter.Sort = c.QueryParam("sort")
+	if !repository.ValidPRSort(filter.Sort) {
+		return fmt.Errorf("sort must be one of %s, %s", repository.PRSortCreatedAt, repository.PRSortMergedAt)
+	}
+	filter.Order = c.QueryParam("order")
+	if !repository.ValidSortOrder(filter.Order) {
+		return fmt.Errorf("order must be one of %s, %s", repository.SortOrderAsc, repository.SortOrderDesc)
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		if c.QueryParam("offset") != "" {
+			return errors.New("cursor and offset are mutually exclusive")
+		}
+		if filter.Sort != "" && filter.Sort != repository.PRSortCreatedAt {
+			return fmt.Errorf("cursor is supported only with sort=%s", repository.PRSortCreatedAt)
+		}
+		cursor, err := repository.DecodePRCursor(raw)
+		if err != nil {
+			return errors.New("cursor is invalid")
+		}
+		filter.Cursor = cursor
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxPRDescriptionLength — максимальная длина описания PR в символах
+	maxPRDescriptionLength = 10000
+	// maxPRLabels — максимальное число меток у PR
+	maxPRLabels = 20
+	// maxPRLabelLength — максимальная длина метки в символах
+	maxPRLabelLength = 64
+)
+
+// NormalizeLabel приводит метку к виду, в котором она хранится: без пробелов по краям, в нижнем регистре
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// normalizeLabels нормализует метки, убирает пустые и повторы и возвращает найденные проблемы
+func normalizeLabels(labels []string) ([]string, []string) {
+	var problems []string
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		if label == "" {
+			continue
+		}
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+		if utf8.RuneCountInString(label) > maxPRLabelLength {
+			problems = append(problems, fmt.Sprintf("label %q must be at most %d characters", label, maxPRLabelLength))
+		}
+		normalized = append(normalized, label)
+	}
+	if len(normalized) > maxPRLabels {
+		problems = append(problems, fmt.Sprintf("at most %d labels are allowed per PR", maxPRLabels))
+	}
+	return normalized, problems
+}
+
+// ValidatePRMetadata проверяет заданные сведения о PR, нормализует метки на месте и возвращает найденные проблемы.
+// nil-поля и пустые строки (очистка поля) не проверяются. Используется и gRPC API.
+func ValidatePRMetadata(description, url *string, labels *[]string) []string {
+	var problems []string
+	if labels != nil && *labels != nil {
+		var labelProblems []string
+		*labels, labelProblems = normalizeLabels(*labels)
+		problems = append(problems, labelProblems...)
+	}
+	if description != nil && utf8.RuneCountInString(*description) > maxPRDescriptionLength {
+		problems = append(problems, fmt.Sprintf("description must be at most %d characters", maxPRDescriptionLength))
+	}
+	if url != nil && *url != "" {
+		if err := validateHTTPURL(*url); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// UpdatePullRequest изменяет сведения о PR (описание, ветки, ссылку, метки); статус и ревьюеры не меняются
+func (h *Handler) UpdatePullRequest(c echo.Context) error {
+	h.log(c).Info("UpdatePullRequest: начало обработки запроса")
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		models.PRMetadataUpdate
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("UpdatePullRequest: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	if problems := ValidatePRMetadata(req.Description, req.URL, req.Labels); len(problems) > 0 {
+		h.log(c).Warn("UpdatePullRequest: сведения о PR не прошли валидацию", zap.Strings("problems", problems))
+		resp := newErrorResponse(c, ErrCodeValidation, "pull request metadata is invalid")
+		resp.Error.Details = problems
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	h.log(c).Info("UpdatePullRequest: изменение сведений о PR", zap.String("pr_id", req.PullRequestID))
+
+	pr, err := h.repo.UpdatePRMetadata(c.Request().Context(), req.PullRequestID, req.PRMetadataUpdate)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("UpdatePullRequest: PR не найден", zap.String("pr_id", req.PullRequestID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "PR not found"))
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			h.log(c).Warn("UpdatePullRequest: ID PR есть в нескольких репозиториях", zap.String("pr_id", req.PullRequestID))
+			return ambiguousPR(c)
+		}
+		h.log(c).Error("UpdatePullRequest: ошибка изменения PR", zap.Error(err), zap.String("pr_id", req.PullRequestID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update PR"))
+	}
+
+	h.log(c).Info("UpdatePullRequest: сведения о PR изменены", zap.String("pr_id", pr.PullRequestID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// errRepositoryTooLong — ошибка валидации слишком длинного имени репозитория
+var errRepositoryTooLong = fmt.Errorf("repository must be at most %d characters", models.MaxRepositoryLength)
+
+// parseRepositoryParam разбирает необязательный параметр repository query-строки.
+// Отсутствующий параметр — nil: PR ищется по одному ID; repository= (пустое значение) — репозиторий по умолчанию.
+func parseRepositoryParam(c echo.Context) (*string, error) {
+	if !c.QueryParams().Has("repository") {
+		return nil, nil
+	}
+	repository := c.QueryParam("repository")
+	if !models.ValidRepository(&repository) {
+		return nil, errRepositoryTooLong
+	}
+	return &repository, nil
+}
+
+// prRef собирает ссылку на PR из внешнего ID и необязательного репозитория
+func prRef(repository *string, prID string) models.PRRef {
+	return models.PRRef{Repository: repository, ID: prID}
+}
+
+// ambiguousPR отвечает 409 AMBIGUOUS_PR: PR указан без репозитория, а его ID есть в нескольких репозиториях
+func ambiguousPR(c echo.Context) error {
+	return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAmbiguousPR,
+		"PR id exists in several repositories, specify repository"))
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// minPRSearchQueryLength — минимальная длина поискового запроса в символах: по одному символу
+	// совпадает почти каждый PR, а триграммный индекс такие запросы не ускоряет
+	minPRSearchQueryLength = 2
+	// maxPRSearchQueryLength — максимальная длина поискового запроса, равна длине названия PR в БД
+	maxPRSearchQueryLength = 500
+)
+
+// SearchPullRequests ищет PR по подстроке названия без учета регистра
+func (h *Handler) SearchPullRequests(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("SearchPullRequests: поиск PR по названию",
+		zap.String("q", query), zap.String("team_name", teamName))
+
+	if query == "" {
+		h.log(c).Warn("SearchPullRequests: параметр q отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "q parameter is required"))
+	}
+	if n := utf8.RuneCountInString(query); n < minPRSearchQueryLength || n > maxPRSearchQueryLength {
+		h.log(c).Warn("SearchPullRequests: недопустимая длина запроса", zap.Int("length", n))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam,
+			fmt.Sprintf("q must be between %d and %d characters", minPRSearchQueryLength, maxPRSearchQueryLength)))
+	}
+
+	status, err := parseStatusParam(c)
+	if err != nil {
+		h.log(c).Warn("SearchPullRequests: некорректный статус", zap.String("status", c.QueryParam("status")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("SearchPullRequests: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	prs, total, err := h.repo.SearchPRs(c.Request().Context(), query, teamName, status, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("SearchPullRequests: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("SearchPullRequests: ошибка поиска PR", zap.Error(err), zap.String("q", query))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to search PRs"))
+	}
+
+	h.log(c).Info("SearchPullRequests: поиск выполнен",
+		zap.String("q", query),
+		zap.Int("prs_count", len(prs)),
+		zap.Int("total", total))
+
+	response := map[string]interface{}{
+		"q":             query,
+		"pull_requests": prs,
+		"total":         total,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ifMatchHeader — заголовок с ожидаемой версией PR, альтернатива полю expected_version
+const ifMatchHeader = "If-Match"
+
+var (
+	errInvalidExpectedVersion = errors.New("expected_version must be a positive integer")
+	errInvalidIfMatch         = errors.New(`If-Match must be a PR version, e.g. "3"`)
+)
+
+// VersionConflictResponse — ответ 409 VERSION_CONFLICT: ошибка и текущее состояние PR,
+// по которому клиент решает, повторять ли изменение
+type VersionConflictResponse struct {
+	ErrorResponse
+	PR *models.PullRequest `json:"pr,omitempty"`
+}
+
+// expectedVersion возвращает ожидаемую версию PR из поля expected_version тела или заголовка If-Match
+// (поле тела важнее заголовка). nil — версия не передана или If-Match: *, изменение выполняется без проверки.
+// If-Match принимает версию в виде ETag ("3", W/"3") или числом.
+func expectedVersion(c echo.Context, fromBody *int64) (*int64, error) {
+	if fromBody != nil {
+		if *fromBody <= 0 {
+			return nil, errInvalidExpectedVersion
+		}
+		return fromBody, nil
+	}
+
+	raw := strings.TrimSpace(c.Request().Header.Get(ifMatchHeader))
+	if raw == "" || raw == "*" {
+		return nil, nil
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || version <= 0 {
+		return nil, errInvalidIfMatch
+	}
+	return &version, nil
+}
+
+// versionConflict отвечает 409 VERSION_CONFLICT с текущим состоянием PR. Если PR прочитать не удалось
+// (например, его уже удалили), ответ содержит только ошибку. PR читается из основного пула,
+// чтобы вернуть версию, с которой произошел конфликт.
+func (h *Handler) versionConflict(c echo.Context, op string, ref models.PRRef) error {
+	resp := VersionConflictResponse{
+		ErrorResponse: newErrorResponse(c, ErrCodeVersionConflict, "PR was modified by another request, expected_version is stale"),
+	}
+	pr, err := h.repo.GetPR(repository.WithPrimary(c.Request().Context()), ref)
+	if err != nil {
+		h.log(c).Warn(op+": не удалось прочитать текущее состояние PR", zap.Error(err), zap.String("pr_id", ref.ID))
+	} else {
+		resp.PR = pr
+	}
+	return c.JSON(http.StatusConflict, resp)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxPreviewReviewers ограничивает параметр count предпросмотра назначения
+const maxPreviewReviewers = 20
+
+// PreviewReviewers показывает, кто был бы назначен ревьюером на новый PR автора, ничего не создавая
+func (h *Handler) PreviewReviewers(c echo.Context) error {
+	authorID := h.normalizeID(c.QueryParam("author_id"))
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("PreviewReviewers: предпросмотр назначения",
+		zap.String("author_id", authorID),
+		zap.String("team_name", teamName))
+
+	if authorID == "" {
+		h.log(c).Warn("PreviewReviewers: параметр author_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "author_id parameter is required"))
+	}
+
+	// Без count — столько ревьюеров, сколько назначается на PR команды
+	count := 0
+	if raw := c.QueryParam("count"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 || v > maxPreviewReviewers {
+			h.log(c).Warn("PreviewReviewers: некорректный count", zap.String("count", raw))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam,
+				fmt.Sprintf("count must be an integer between 1 and %d", maxPreviewReviewers)))
+		}
+		count = v
+	}
+
+	preview, err := h.repo.PreviewReviewers(c.Request().Context(), authorID, teamName, count)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("PreviewReviewers: автор или команда не найдены", zap.String("author_id", authorID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "author or team not found"))
+		}
+		if errors.Is(err, repository.ErrAuthorNotInTeam) {
+			h.log(c).Warn("PreviewReviewers: автор не состоит в команде",
+				zap.String("author_id", authorID),
+				zap.String("team_name", teamName))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAuthorNotInTeam, "author is not a member of the team"))
+		}
+		var ambiguous *repository.AmbiguousTeamError
+		if errors.As(err, &ambiguous) {
+			h.log(c).Warn("PreviewReviewers: автор состоит в нескольких командах",
+				zap.String("author_id", authorID),
+				zap.Strings("teams", ambiguous.Teams))
+			resp := newErrorResponse(c, ErrCodeAmbiguousTeam, "author belongs to several teams, specify team_name")
+			resp.Error.Details = ambiguous.Teams
+			return c.JSON(http.StatusConflict, resp)
+		}
+		h.log(c).Error("PreviewReviewers: ошибка предпросмотра назначения", zap.Error(err), zap.String("author_id", authorID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to preview reviewers"))
+	}
+
+	h.log(c).Info("PreviewReviewers: кандидаты подобраны",
+		zap.String("author_id", authorID),
+		zap.String("team_name", preview.TeamName),
+		zap.Int("candidates_count", len(preview.Candidates)))
+
+	// Кто исключен приостановкой автоназначения, видят только администраторы
+	if !h.isAdmin(c) {
+		preview.PausedMembers = nil
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
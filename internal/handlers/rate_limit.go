@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// HeaderAPIKey — ключ клиента; известный ключ получает свою корзину, а не корзину IP
+	HeaderAPIKey = "X-API-Key"
+
+	// rateLimitIdleTTL — через сколько простоя корзина клиента удаляется из памяти
+	rateLimitIdleTTL = 3 * time.Minute
+)
+
+// rateLimitExempt — маршруты проверок здоровья и метрик, которые не ограничиваются
+var rateLimitExempt = map[string]bool{
+	"/live":    true,
+	"/ready":   true,
+	"/health":  true,
+	"/metrics": true,
+}
+
+// RateLimit возвращает middleware, ограничивающее частоту запросов каждого клиента
+// корзиной токенов: rps запросов в секунду с запасом burst.
+// Клиент определяется по заголовку X-API-Key, если ключ входит в apiKeys, иначе — по IP:
+// случайный ключ на каждый запрос не дает обойти лимит.
+// Превышение отклоняется с 429 RATE_LIMITED и заголовком Retry-After.
+func RateLimit(rps float64, burst int, apiKeys []string, logger *zap.Logger) echo.MiddlewareFunc {
+	known := make(map[string]bool, len(apiKeys))
+	for _, key := range apiKeys {
+		known[key] = true
+	}
+	// knownKey возвращает ключ запроса, если он известен
+	knownKey := func(c echo.Context) (string, bool) {
+		key := c.Request().Header.Get(HeaderAPIKey)
+		return key, key != "" && known[key]
+	}
+
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(rps),
+		Burst:     burst,
+		ExpiresIn: rateLimitIdleTTL,
+	})
+	// Через столько секунд у клиента гарантированно появится новый токен
+	retryAfter := strconv.Itoa(int(math.Ceil(1 / rps)))
+
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Skipper: func(c echo.Context) bool {
+			return rateLimitExempt[c.Path()]
+		},
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			if key, ok := knownKey(c); ok {
+				return "key:" + key, nil
+			}
+			return "ip:" + c.RealIP(), nil
+		},
+		Store: store,
+		DenyHandler: func(c echo.Context, _ string, _ error) error {
+			// Сам ключ в лог не пишется
+			_, byKey := knownKey(c)
+			RequestLogger(c, logger).Warn("RateLimit: превышен лимит запросов",
+				zap.String("client_ip", c.RealIP()),
+				zap.Bool("by_api_key", byKey))
+			c.Response().Header().Set(echo.HeaderRetryAfter, retryAfter)
+			return c.JSON(http.StatusTooManyRequests, newErrorResponse(c, ErrCodeRateLimited, "rate limit exceeded"))
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+)
+
+// newRateLimitedServer собирает Echo с ограничением частоты и маршрутами /ping и /health
+func newRateLimitedServer(rps float64, burst int, apiKeys ...string) *echo.Echo {
+	e := echo.New()
+	e.Use(handlers.RateLimit(rps, burst, apiKeys, zap.NewNop()))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/ping", ok)
+	e.GET("/health", ok)
+	return e
+}
+
+// hit выполняет GET-запрос с адреса ip и, если key не пуст, с заголовком X-API-Key
+func hit(e *echo.Echo, target, ip, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = ip + ":40000"
+	if key != "" {
+		req.Header.Set(handlers.HeaderAPIKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitBurstAndRefill(t *testing.T) {
+	const burst = 3
+	// 10 запросов в секунду: новый токен появляется каждые 100ms
+	e := newRateLimitedServer(10, burst)
+
+	for i := range burst {
+		require.Equal(t, http.StatusOK, hit(e, "/ping", "192.0.2.1", "").Code, "request %d within burst", i+1)
+	}
+
+	rec := hit(e, "/ping", "192.0.2.1", "")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, "request above burst")
+	assert.Equal(t, "1", rec.Header().Get(echo.HeaderRetryAfter))
+	var resp handlers.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, handlers.ErrCodeRateLimited, resp.Error.Code)
+
+	// Через интервал пополнения проходит ровно один запрос
+	time.Sleep(120 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, hit(e, "/ping", "192.0.2.1", "").Code, "request after refill")
+	assert.Equal(t, http.StatusTooManyRequests, hit(e, "/ping", "192.0.2.1", "").Code, "second request after one refill")
+
+	// У другого клиента своя полная корзина
+	assert.Equal(t, http.StatusOK, hit(e, "/ping", "192.0.2.2", "").Code)
+}
+
+func TestRateLimitClientIdentity(t *testing.T) {
+	// Пополнение раз в 100 секунд не влияет на ход теста
+	e := newRateLimitedServer(0.01, 1, "known-a", "known-b")
+
+	t.Run("unknown keys share the IP bucket", func(t *testing.T) {
+		ip := "198.51.100.1"
+		assert.Equal(t, http.StatusOK, hit(e, "/ping", ip, "random-1").Code)
+		for i := 2; i <= 5; i++ {
+			assert.Equal(t, http.StatusTooManyRequests, hit(e, "/ping", ip, "random-"+strconv.Itoa(i)).Code,
+				"a fresh unknown key must not bypass the limit")
+		}
+		assert.Equal(t, http.StatusTooManyRequests, hit(e, "/ping", ip, "").Code)
+	})
+
+	t.Run("known key has its own bucket", func(t *testing.T) {
+		ip := "198.51.100.2"
+		assert.Equal(t, http.StatusOK, hit(e, "/ping", ip, "").Code)
+		assert.Equal(t, http.StatusOK, hit(e, "/ping", ip, "known-a").Code)
+		assert.Equal(t, http.StatusTooManyRequests, hit(e, "/ping", ip, "known-a").Code)
+		// Корзина ключа общая для всех IP
+		assert.Equal(t, http.StatusTooManyRequests, hit(e, "/ping", "198.51.100.3", "known-a").Code)
+		assert.Equal(t, http.StatusOK, hit(e, "/ping", "198.51.100.3", "known-b").Code)
+	})
+
+	t.Run("health checks are exempt", func(t *testing.T) {
+		ip := "198.51.100.4"
+		assert.Equal(t, http.StatusOK, hit(e, "/ping", ip, "").Code)
+		for range 3 {
+			assert.Equal(t, http.StatusOK, hit(e, "/health", ip, "").Code)
+		}
+	})
+}
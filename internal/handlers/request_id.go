@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// requestLoggerKey — ключ логгера запроса в echo.Context
+const requestLoggerKey = "request_logger"
+
+type requestIDKey struct{}
+
+// RequestID возвращает ID запроса из контекста (пустая строка вне запроса)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// BindRequestID возвращает RequestIDHandler для middleware.RequestIDWithConfig:
+// сохраняет ID в контексте запроса и создает логгер запроса с полями request_id, trace_id и span_id.
+// Middleware трассировки должен стоять раньше, чтобы спан запроса уже был в контексте.
+func BindRequestID(base *zap.Logger) func(echo.Context, string) {
+	return func(c echo.Context, id string) {
+		req := c.Request()
+		ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+		c.SetRequest(req.WithContext(ctx))
+
+		fields := append([]zap.Field{zap.String("request_id", id)}, traceFields(ctx)...)
+		c.Set(requestLoggerKey, base.With(fields...))
+	}
+}
+
+// RequestLogger возвращает логгер запроса, а если он не создан (запрос прошел мимо BindRequestID) — fallback
+func RequestLogger(c echo.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := c.Get(requestLoggerKey).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// log возвращает логгер текущего запроса; обработчики пишут в лог только через него
+func (h *Handler) log(c echo.Context) *zap.Logger {
+	return RequestLogger(c, h.logger)
+}
+
+// traceFields возвращает поля лога с идентификаторами текущей трассы и спана (пусто вне трассы)
+func traceFields(ctx context.Context) []zap.Field {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", spanCtx.TraceID().String()),
+		zap.String("span_id", spanCtx.SpanID().String()),
+	}
+}
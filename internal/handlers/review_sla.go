@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetOverduePullRequests возвращает просроченные по SLA назначения ревьюеров в открытых PR команды
+func (h *Handler) GetOverduePullRequests(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("GetOverduePullRequests: получение просроченных ревью", zap.String("team_name", teamName))
+
+	if teamName == "" {
+		h.log(c).Warn("GetOverduePullRequests: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("GetOverduePullRequests: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	reviews, err := h.repo.GetOverdueReviews(c.Request().Context(), teamName, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetOverduePullRequests: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetOverduePullRequests: ошибка получения просроченных ревью", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get overdue reviews"))
+	}
+
+	h.log(c).Info("GetOverduePullRequests: просроченные ревью получены",
+		zap.String("team_name", teamName),
+		zap.Int("reviews_count", len(reviews)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"team_name": teamName,
+		"overdue":   reviews,
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// SetReviewerEligibility включает или исключает пользователя из пула автоматического назначения ревьюеров.
+// При исключении с reassign_reviews открытые ревью пользователя переназначаются, как при деактивации.
+func (h *Handler) SetReviewerEligibility(c echo.Context) error {
+	h.log(c).Info("SetReviewerEligibility: начало обработки запроса")
+
+	var req struct {
+		UserID          string `json:"user_id"`
+		IsReviewer      *bool  `json:"is_reviewer"`
+		ReassignReviews bool   `json:"reassign_reviews"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("SetReviewerEligibility: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+
+	if req.UserID == "" || req.IsReviewer == nil {
+		h.log(c).Warn("SetReviewerEligibility: user_id или is_reviewer отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "user_id and is_reviewer are required"))
+	}
+
+	h.log(c).Info("SetReviewerEligibility: обновление участия в назначении ревьюеров",
+		zap.String("user_id", req.UserID),
+		zap.Bool("is_reviewer", *req.IsReviewer),
+		zap.Bool("reassign_reviews", req.ReassignReviews))
+
+	reassignment, err := h.repo.SetReviewerEligibility(c.Request().Context(), req.UserID, *req.IsReviewer, req.ReassignReviews)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("SetReviewerEligibility: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("SetReviewerEligibility: ошибка обновления", zap.Error(err), zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update reviewer eligibility"))
+	}
+
+	user, err := h.repo.GetUser(repository.WithPrimary(c.Request().Context()), req.UserID)
+	if err != nil {
+		h.log(c).Error("SetReviewerEligibility: ошибка получения обновленного пользователя", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get updated user"))
+	}
+
+	h.log(c).Info("SetReviewerEligibility: участие в назначении ревьюеров обновлено", zap.String("user_id", req.UserID))
+
+	response := map[string]interface{}{"user": h.userView(c, user)}
+	if reassignment != nil {
+		h.log(c).Info("SetReviewerEligibility: открытые ревью переназначены",
+			zap.String("user_id", req.UserID),
+			zap.Int("reassigned_count", len(reassignment.Reassigned)),
+			zap.Int("not_reassigned_count", len(reassignment.NotReassigned)),
+			zap.Int("skipped_recent_count", len(reassignment.SkippedRecent)))
+		h.metrics.ReviewersReassigned.Add(float64(len(reassignment.Reassigned)))
+		response["reassignment"] = reassignment
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,67 @@
+//go:build postgres
+
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/labstack/echo/v4"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/migrations"
+)
+
+// newScenarioRepository создает в базе SCENARIO_DATABASE_URL отдельную схему, применяет в ней миграции
+// и возвращает репозиторий поверх нее. Схема удаляется по завершении теста, поэтому тест можно
+// повторять на одной базе, не трогая ее данные.
+func newScenarioRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+	dsn := os.Getenv("SCENARIO_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("SCENARIO_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	schema := fmt.Sprintf("scenario_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		require.NoError(t, err)
+	})
+
+	// public остается в search_path ради расширений вроде pg_trgm, установленных в базе раньше
+	cfg, err := pgxpool.ParseConfig(dsn)
+	require.NoError(t, err)
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	db := stdlib.OpenDBFromPool(pool)
+	t.Cleanup(func() { _ = db.Close() })
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS())
+	require.NoError(t, err)
+	_, err = provider.Up(ctx)
+	require.NoError(t, err)
+
+	return repository.New(pool, repository.Options{AssignmentStrategy: repository.StrategyLeastLoaded})
+}
+
+func TestBusinessRuleScenariosPostgres(t *testing.T) {
+	// Сценарии выполняются по очереди на одной базе: их ID не пересекаются
+	e := newTestServer(newScenarioRepository(t), scenarioConfig)
+	runScenarios(t, func() *echo.Echo { return e }, businessRuleScenarios())
+}
@@ -0,0 +1,441 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// scenarioAdminKey — ключ администратора сервера сценариев
+const scenarioAdminKey = "scenario-admin-key"
+
+// scenarioConfig — настройки обработчиков в сценариях: значения по умолчанию и ключ администратора
+var scenarioConfig = handlers.Config{AdminAPIKeys: []string{scenarioAdminKey}}
+
+// scenarioStep — запрос сценария и ожидаемый ответ
+type scenarioStep struct {
+	name   string
+	method string
+	target string
+	body   string
+	// admin передает ключ администратора scenarioAdminKey
+	admin  bool
+	status int
+	// code — ожидаемый код ошибки, пустой — ответ без ошибки
+	code string
+	// check проверяет тело ответа
+	check func(t *testing.T, body []byte)
+}
+
+// scenario — последовательность вызовов API, проверяющая бизнес-правило. Шаги выполняются по порядку,
+// и сценарий останавливается на первом расхождении. ID команд, пользователей и PR у сценариев
+// не пересекаются, поэтому их можно выполнять на одной базе.
+type scenario struct {
+	name  string
+	steps []scenarioStep
+}
+
+// runScenarios выполняет сценарии; server возвращает сервер для очередного сценария
+func runScenarios(t *testing.T, server func() *echo.Echo, scenarios []scenario) {
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			e := server()
+			for i, step := range sc.steps {
+				var header map[string]string
+				if step.admin {
+					header = map[string]string{"X-API-Key": scenarioAdminKey}
+				}
+				rec := serve(e, step.method, step.target, step.body, header)
+
+				msg := fmt.Sprintf("step %d %q: %s", i+1, step.name, rec.Body.String())
+				require.Equal(t, step.status, rec.Code, msg)
+				if step.code != "" {
+					var resp handlers.ErrorResponse
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), msg)
+					require.Equal(t, step.code, resp.Error.Code, msg)
+				}
+				if step.check != nil {
+					step.check(t, rec.Body.Bytes())
+				}
+			}
+		})
+	}
+}
+
+// stepAddTeam — шаг создания команды team из участников ids; участники из inactive создаются неактивными
+func stepAddTeam(team string, ids []string, inactive ...string) scenarioStep {
+	members := make([]models.TeamMember, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, models.TeamMember{UserID: id, Username: "User " + id, IsActive: !slices.Contains(inactive, id)})
+	}
+	body, _ := json.Marshal(models.Team{TeamName: team, Members: members})
+	return scenarioStep{name: "create team " + team, method: http.MethodPost, target: "/team/add", body: string(body), status: http.StatusCreated}
+}
+
+// stepCreatePR — шаг создания PR prID автора authorID; check проверяет созданный PR
+func stepCreatePR(prID, authorID string, check func(t *testing.T, pr models.PullRequest)) scenarioStep {
+	return scenarioStep{
+		name:   "create " + prID,
+		method: http.MethodPost,
+		target: "/pullRequest/create",
+		body:   fmt.Sprintf(`{"pull_request_id":%q,"pull_request_name":"Scenario","author_id":%q}`, prID, authorID),
+		status: http.StatusCreated,
+		check:  checkPR(check),
+	}
+}
+
+// stepPost — шаг POST-запроса к target с телом body
+func stepPost(name, target, body string, status int, code string) scenarioStep {
+	return scenarioStep{name: name, method: http.MethodPost, target: target, body: body, status: status, code: code}
+}
+
+// checkPR превращает проверку PR из поля pr ответа в проверку тела ответа
+func checkPR(check func(t *testing.T, pr models.PullRequest)) func(t *testing.T, body []byte) {
+	if check == nil {
+		return nil
+	}
+	return func(t *testing.T, body []byte) {
+		var resp struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		require.NoError(t, json.Unmarshal(body, &resp))
+		check(t, resp.PR)
+	}
+}
+
+// reviewers — проверка, что на PR назначены ровно ids в любом порядке
+func reviewers(ids ...string) func(t *testing.T, pr models.PullRequest) {
+	return func(t *testing.T, pr models.PullRequest) {
+		assert.ElementsMatch(t, ids, pr.AssignedReviewerIDs, "assigned reviewers of %s", pr.PullRequestID)
+	}
+}
+
+// reviewIDs — проверка, что GET /users/getReview вернул ровно PR ids
+func reviewIDs(ids ...string) func(t *testing.T, body []byte) {
+	return func(t *testing.T, body []byte) {
+		var resp struct {
+			PullRequests []models.PullRequestShort `json:"pull_requests"`
+		}
+		require.NoError(t, json.Unmarshal(body, &resp))
+		got := []string{}
+		for _, pr := range resp.PullRequests {
+			got = append(got, pr.PullRequestID)
+		}
+		assert.ElementsMatch(t, ids, got)
+	}
+}
+
+// businessRuleScenarios возвращает сценарии бизнес-правил назначения ревьюеров и жизненного цикла PR
+// (те же правила, что в tests/e2e/11_business_rules.http)
+func businessRuleScenarios() []scenario {
+	vacation := fmt.Sprintf(`{"user_id":"s16b","from":%q,"to":%q}`,
+		time.Now().Add(-time.Minute).UTC().Format(time.RFC3339), time.Now().Add(24*time.Hour).UTC().Format(time.RFC3339))
+
+	return []scenario{
+		{
+			name: "author is never assigned, up to two active reviewers are",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-1", []string{"s1a", "s1b", "s1c", "s1d"}),
+				stepCreatePR("pr-s1", "s1a", func(t *testing.T, pr models.PullRequest) {
+					assert.Len(t, pr.AssignedReviewerIDs, 2)
+					assert.NotContains(t, pr.AssignedReviewerIDs, "s1a")
+					assert.Equal(t, models.StatusOpen, pr.Status)
+				}),
+			},
+		},
+		{
+			name: "inactive members are not assigned",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-2", []string{"s2a", "s2b", "s2c"}, "s2c"),
+				stepCreatePR("pr-s2", "s2a", reviewers("s2b")),
+			},
+		},
+		{
+			name: "PR without candidates is created with a warning",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-3", []string{"s3a", "s3b"}, "s3b"),
+				{
+					name: "create pr-s3", method: http.MethodPost, target: "/pullRequest/create",
+					body:   `{"pull_request_id":"pr-s3","pull_request_name":"Scenario","author_id":"s3a"}`,
+					status: http.StatusCreated,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							PR       models.PullRequest `json:"pr"`
+							Warnings []string           `json:"warnings"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						assert.Empty(t, resp.PR.AssignedReviewerIDs)
+						assert.Equal(t, []string{handlers.WarnNoReviewersAssigned}, resp.Warnings)
+					},
+				},
+			},
+		},
+		{
+			name: "PR ID is unique within a repository",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-4", []string{"s4a", "s4b"}),
+				stepCreatePR("pr-s4", "s4a", nil),
+				stepPost("create pr-s4 again", "/pullRequest/create",
+					`{"pull_request_id":"pr-s4","pull_request_name":"Again","author_id":"s4a"}`, http.StatusConflict, handlers.ErrCodePRExists),
+				stepPost("create pr-s4 in another repository", "/pullRequest/create",
+					`{"pull_request_id":"pr-s4","pull_request_name":"Other","author_id":"s4a","repository":"acme/s4"}`, http.StatusCreated, ""),
+			},
+		},
+		{
+			name: "PR of an unknown author is not created",
+			steps: []scenarioStep{
+				stepPost("create pr-s5", "/pullRequest/create",
+					`{"pull_request_id":"pr-s5","pull_request_name":"Scenario","author_id":"s5-no-such-user"}`, http.StatusNotFound, handlers.ErrCodeNotFound),
+			},
+		},
+		{
+			name: "deactivating the only reviewer with reassign keeps the review without a candidate",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-6", []string{"s6a", "s6b", "s6c", "s6d"}),
+				stepPost("deactivate s6c", "/users/setIsActive", `{"user_id":"s6c","is_active":false}`, http.StatusOK, ""),
+				stepPost("deactivate s6d", "/users/setIsActive", `{"user_id":"s6d","is_active":false}`, http.StatusOK, ""),
+				stepCreatePR("pr-s6", "s6a", reviewers("s6b")),
+				{
+					name: "deactivate s6b with reassign", method: http.MethodPost, target: "/users/setIsActive",
+					body:   `{"user_id":"s6b","is_active":false,"reassign_reviews":true}`,
+					status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							User         models.User               `json:"user"`
+							Reassignment models.ReassignmentResult `json:"reassignment"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						assert.False(t, resp.User.IsActive)
+						assert.Empty(t, resp.Reassignment.Reassigned)
+						assert.Equal(t, []string{"pr-s6"}, resp.Reassignment.NotReassigned)
+					},
+				},
+				{name: "s6b still reviews pr-s6", method: http.MethodGet, target: "/users/getReview?user_id=s6b", status: http.StatusOK, check: reviewIDs("pr-s6")},
+			},
+		},
+		{
+			name: "deactivating a reviewer with reassign moves the review to a free member",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-7", []string{"s7a", "s7b", "s7c"}),
+				stepCreatePR("pr-s7", "s7a", reviewers("s7b", "s7c")),
+				stepPost("add s7d", "/team/addMember", `{"team_name":"scenario-7","user_id":"s7d","username":"User s7d","is_active":true}`, http.StatusOK, ""),
+				{
+					name: "deactivate s7b with reassign", method: http.MethodPost, target: "/users/setIsActive",
+					body:   `{"user_id":"s7b","is_active":false,"reassign_reviews":true}`,
+					status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							Reassignment models.ReassignmentResult `json:"reassignment"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						assert.Equal(t, []models.ReviewReassignment{{PullRequestID: "pr-s7", NewReviewerID: "s7d"}}, resp.Reassignment.Reassigned)
+						assert.Empty(t, resp.Reassignment.NotReassigned)
+					},
+				},
+				{
+					name: "get pr-s7", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-s7", status: http.StatusOK,
+					check: checkPR(reviewers("s7c", "s7d")),
+				},
+				{name: "s7b reviews nothing", method: http.MethodGet, target: "/users/getReview?user_id=s7b", status: http.StatusOK, check: reviewIDs()},
+			},
+		},
+		{
+			name: "reassign without free candidates fails",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-8", []string{"s8a", "s8b"}),
+				stepCreatePR("pr-s8", "s8a", reviewers("s8b")),
+				stepPost("reassign s8b", "/pullRequest/reassign", `{"pull_request_id":"pr-s8","old_user_id":"s8b"}`, http.StatusConflict, handlers.ErrCodeNoCandidate),
+			},
+		},
+		{
+			name: "only an assigned reviewer can be reassigned",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-9", []string{"s9a", "s9b", "s9c"}),
+				stepCreatePR("pr-s9", "s9a", nil),
+				stepPost("reassign the author", "/pullRequest/reassign", `{"pull_request_id":"pr-s9","old_user_id":"s9a"}`, http.StatusConflict, handlers.ErrCodeNotAssigned),
+			},
+		},
+		{
+			name: "reassign picks a member of the PR team who is neither the author nor a reviewer",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-10", []string{"s10a", "s10b", "s10c"}),
+				stepCreatePR("pr-s10", "s10a", reviewers("s10b", "s10c")),
+				stepPost("add s10d", "/team/addMember", `{"team_name":"scenario-10","user_id":"s10d","username":"User s10d","is_active":true}`, http.StatusOK, ""),
+				{
+					name: "reassign s10b", method: http.MethodPost, target: "/pullRequest/reassign",
+					body:   `{"pull_request_id":"pr-s10","old_user_id":"s10b"}`,
+					status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							PR         models.PullRequest `json:"pr"`
+							ReplacedBy string             `json:"replaced_by"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						assert.Equal(t, "s10d", resp.ReplacedBy)
+						assert.ElementsMatch(t, []string{"s10c", "s10d"}, resp.PR.AssignedReviewerIDs)
+					},
+				},
+			},
+		},
+		{
+			name: "merge is idempotent and freezes reviewers",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-11", []string{"s11a", "s11b", "s11c"}),
+				stepCreatePR("pr-s11", "s11a", nil),
+				{
+					name: "merge pr-s11", method: http.MethodPost, target: "/pullRequest/merge", body: `{"pull_request_id":"pr-s11"}`, status: http.StatusOK,
+					check: checkPR(func(t *testing.T, pr models.PullRequest) {
+						assert.Equal(t, models.StatusMerged, pr.Status)
+						assert.NotNil(t, pr.MergedAt)
+					}),
+				},
+				{
+					name: "merge pr-s11 again", method: http.MethodPost, target: "/pullRequest/merge", body: `{"pull_request_id":"pr-s11"}`, status: http.StatusOK,
+					check: checkPR(func(t *testing.T, pr models.PullRequest) { assert.Equal(t, models.StatusMerged, pr.Status) }),
+				},
+				stepPost("reassign on merged", "/pullRequest/reassign", `{"pull_request_id":"pr-s11","old_user_id":"s11b"}`, http.StatusConflict, handlers.ErrCodePRMerged),
+				stepPost("close merged", "/pullRequest/close", `{"pull_request_id":"pr-s11"}`, http.StatusConflict, handlers.ErrCodePRMerged),
+				stepPost("reopen merged", "/pullRequest/reopen", `{"pull_request_id":"pr-s11"}`, http.StatusConflict, handlers.ErrCodePRMerged),
+			},
+		},
+		{
+			name: "closed PR cannot be approved, reassigned or merged",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-12", []string{"s12a", "s12b", "s12c"}),
+				stepCreatePR("pr-s12", "s12a", nil),
+				{
+					name: "close pr-s12", method: http.MethodPost, target: "/pullRequest/close", body: `{"pull_request_id":"pr-s12"}`, status: http.StatusOK,
+					check: checkPR(func(t *testing.T, pr models.PullRequest) { assert.Equal(t, models.StatusClosed, pr.Status) }),
+				},
+				stepPost("approve closed", "/pullRequest/approve", `{"pull_request_id":"pr-s12","user_id":"s12b"}`, http.StatusConflict, handlers.ErrCodePRClosed),
+				stepPost("reassign on closed", "/pullRequest/reassign", `{"pull_request_id":"pr-s12","old_user_id":"s12b"}`, http.StatusConflict, handlers.ErrCodePRClosed),
+				stepPost("merge closed", "/pullRequest/merge", `{"pull_request_id":"pr-s12"}`, http.StatusConflict, handlers.ErrCodeInvalidTransition),
+			},
+		},
+		{
+			name: "reopen with reassign assigns reviewers to a PR left without them",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-13", []string{"s13a", "s13b"}, "s13b"),
+				stepCreatePR("pr-s13", "s13a", reviewers()),
+				stepPost("activate s13b", "/users/setIsActive", `{"user_id":"s13b","is_active":true}`, http.StatusOK, ""),
+				stepPost("close pr-s13", "/pullRequest/close", `{"pull_request_id":"pr-s13"}`, http.StatusOK, ""),
+				{
+					name: "reopen pr-s13 with reassign", method: http.MethodPost, target: "/pullRequest/reopen",
+					body: `{"pull_request_id":"pr-s13","reassign":true}`, status: http.StatusOK,
+					check: checkPR(func(t *testing.T, pr models.PullRequest) {
+						assert.Equal(t, models.StatusOpen, pr.Status)
+						assert.Nil(t, pr.ClosedAt)
+						assert.Equal(t, []string{"s13b"}, pr.AssignedReviewerIDs)
+					}),
+				},
+			},
+		},
+		{
+			name: "only an assigned reviewer approves, approving again keeps approved_at",
+			steps: func() []scenarioStep {
+				var approvedAt *time.Time
+				return []scenarioStep{
+					stepAddTeam("scenario-14", []string{"s14a", "s14b"}),
+					stepCreatePR("pr-s14", "s14a", reviewers("s14b")),
+					stepPost("approve by the author", "/pullRequest/approve", `{"pull_request_id":"pr-s14","user_id":"s14a"}`, http.StatusConflict, handlers.ErrCodeNotAssigned),
+					{
+						name: "approve by s14b", method: http.MethodPost, target: "/pullRequest/approve",
+						body: `{"pull_request_id":"pr-s14","user_id":"s14b"}`, status: http.StatusOK,
+						check: checkPR(func(t *testing.T, pr models.PullRequest) {
+							require.Len(t, pr.AssignedReviewers, 1)
+							assert.True(t, pr.AssignedReviewers[0].Approved)
+							require.NotNil(t, pr.AssignedReviewers[0].ApprovedAt)
+							approvedAt = pr.AssignedReviewers[0].ApprovedAt
+						}),
+					},
+					{
+						name: "approve by s14b again", method: http.MethodPost, target: "/pullRequest/approve",
+						body: `{"pull_request_id":"pr-s14","user_id":"s14b"}`, status: http.StatusOK,
+						check: checkPR(func(t *testing.T, pr models.PullRequest) {
+							require.Len(t, pr.AssignedReviewers, 1)
+							require.NotNil(t, pr.AssignedReviewers[0].ApprovedAt)
+							assert.True(t, approvedAt.Equal(*pr.AssignedReviewers[0].ApprovedAt), "approved_at does not change")
+						}),
+					},
+					{name: "s14b has nothing to approve", method: http.MethodGet, target: "/users/getReview?user_id=s14b&unapproved=true", status: http.StatusOK, check: reviewIDs()},
+					{name: "s14b still reviews pr-s14", method: http.MethodGet, target: "/users/getReview?user_id=s14b", status: http.StatusOK, check: reviewIDs("pr-s14")},
+				}
+			}(),
+		},
+		{
+			name: "paused member is not assigned and the pause is visible to admins only",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-15", []string{"s15a", "s15b", "s15c"}),
+				{
+					name: "pause s15b", method: http.MethodPost, target: "/admin/users/pauseAssignment",
+					body: `{"user_id":"s15b","paused":true}`, admin: true, status: http.StatusOK,
+				},
+				stepCreatePR("pr-s15", "s15a", reviewers("s15c")),
+				stepPost("pause without admin key", "/admin/users/pauseAssignment", `{"user_id":"s15c","paused":true}`, http.StatusUnauthorized, handlers.ErrCodeUnauthorized),
+				{
+					name: "get s15b without admin key", method: http.MethodGet, target: "/users/get?user_id=s15b", status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						assert.NotContains(t, string(body), "assignment_paused")
+					},
+				},
+				{
+					name: "get s15b with admin key", method: http.MethodGet, target: "/users/get?user_id=s15b", admin: true, status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							User models.User `json:"user"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						require.NotNil(t, resp.User.AssignmentPaused)
+						assert.True(t, *resp.User.AssignmentPaused)
+					},
+				},
+			},
+		},
+		{
+			name: "member on vacation is not assigned",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-16", []string{"s16a", "s16b", "s16c"}),
+				stepPost("send s16b on vacation", "/users/vacation", vacation, http.StatusCreated, ""),
+				stepCreatePR("pr-s16", "s16a", reviewers("s16c")),
+			},
+		},
+		{
+			name: "stats include users without reviews",
+			steps: []scenarioStep{
+				stepAddTeam("scenario-17", []string{"s17a", "s17b"}),
+				stepCreatePR("pr-s17", "s17a", reviewers("s17b")),
+				{
+					name: "get stats", method: http.MethodGet, target: "/stats", status: http.StatusOK,
+					check: func(t *testing.T, body []byte) {
+						var resp struct {
+							Stats []models.UserReviewStats `json:"stats"`
+						}
+						require.NoError(t, json.Unmarshal(body, &resp))
+						counts := map[string]int{}
+						for _, s := range resp.Stats {
+							counts[s.UserID] = s.ReviewCount
+						}
+						require.Contains(t, counts, "s17a", "users without reviews are listed")
+						assert.Equal(t, 0, counts["s17a"])
+						assert.Equal(t, 1, counts["s17b"])
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBusinessRuleScenarios(t *testing.T) {
+	// Каждый сценарий выполняется на собственном пустом хранилище в памяти
+	runScenarios(t, func() *echo.Echo { return newTestServer(newMemStore(), scenarioConfig) }, businessRuleScenarios())
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// Store — операции хранилища, которые обработчики вызывают напрямую.
+// Включает service.Store, чтобы один мок подходил и обработчикам, и сервисному слою.
+// Реализуется *repository.Repository.
+type Store interface {
+	service.Store
+
+	// Команды
+	GetTeamPage(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error)
+	ListTeams(ctx context.Context, limit, offset int) ([]models.TeamSummary, int, error)
+	DeleteTeam(ctx context.Context, teamName string, force bool) error
+	AddTeamMember(ctx context.Context, teamName string, member models.TeamMember) (*models.Team, error)
+	RemoveTeamMember(ctx context.Context, teamName, userID string) (*models.Team, error)
+	UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error)
+	GetTeamAssignment(ctx context.Context, teamName string) (*models.AssignmentSettings, error)
+	GetPRTeamSettings(ctx context.Context, pullRequestID string) (*models.TeamSettings, error)
+
+	// Пользователи
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	UpdateUserStatus(ctx context.Context, userID string, isActive bool) error
+	SetUsersActiveBatch(ctx context.Context, updates []models.UserActivityUpdate) ([]models.User, []string, error)
+	DeactivateAndReassign(ctx context.Context, userID string) (*models.ReassignmentResult, error)
+	GetPRsByReviewer(ctx context.Context, reviewerID string, filter repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error)
+	AddVacation(ctx context.Context, userID string, from, to time.Time) (*models.Vacation, error)
+	DeleteVacation(ctx context.Context, userID string, vacationID int64) error
+	SetAssignmentPaused(ctx context.Context, userID string, paused bool, until *time.Time) error
+	SetUserCapacity(ctx context.Context, userID string, maxOpenReviews *int) error
+	SetReviewerEligibility(ctx context.Context, userID string, isReviewer, reassign bool) (*models.ReassignmentResult, error)
+	AddExclusion(ctx context.Context, authorID, reviewerID, reason string) (*models.AssignmentExclusion, error)
+	RemoveExclusion(ctx context.Context, authorID, reviewerID string) error
+	ListExclusions(ctx context.Context, userID string) ([]models.AssignmentExclusion, error)
+	LinkExternalAccount(ctx context.Context, account models.ExternalAccount) error
+	AddExternalAccount(ctx context.Context, account models.ExternalAccount) error
+	UnlinkExternalAccount(ctx context.Context, provider, accountID string) (*models.ExternalAccount, error)
+	GetUserByExternalAccount(ctx context.Context, provider, accountID string) (string, error)
+
+	// Pull Requests
+	GetPRsBatch(ctx context.Context, repository *string, pullRequestIDs []string) (map[string]*models.PullRequest, []string, error)
+	GetUnassignedPRs(ctx context.Context, teamName string, limit, offset int) ([]models.UnassignedPullRequest, error)
+	PreviewReviewers(ctx context.Context, authorID, teamName string, count int) (*models.ReviewerPreview, error)
+	GetOverdueReviews(ctx context.Context, teamName string, limit, offset int) ([]models.OverdueReview, error)
+	ListTeamPRs(ctx context.Context, teamName string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error)
+	ListAuthorPRs(ctx context.Context, authorID string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error)
+	SearchPRs(ctx context.Context, query, teamName, status string, limit, offset int) ([]models.PullRequestSearchResult, int, error)
+	ClosePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, error)
+	ReopenPR(ctx context.Context, ref models.PRRef, reassign bool) (*models.PullRequest, error)
+	ApprovePR(ctx context.Context, ref models.PRRef, userID string) (*models.PullRequest, error)
+	UpdatePRMetadata(ctx context.Context, pullRequestID string, update models.PRMetadataUpdate) (*models.PullRequest, error)
+	GetAssignmentHistory(ctx context.Context, pullRequestID string) ([]models.AssignmentEvent, error)
+
+	// Статистика
+	GetUserReviewStats(ctx context.Context) ([]models.UserReviewStats, error)
+	GetUserLoadHistory(ctx context.Context, userID string, from, to time.Time) ([]models.LoadHistorySeries, error)
+	GetTeamLoadHistory(ctx context.Context, teamName string, from, to time.Time) ([]models.LoadHistorySeries, error)
+	GetTeamStats(ctx context.Context, teamName string, since time.Time) (*models.TeamStats, error)
+	GetFairnessReport(ctx context.Context, teamName string, since time.Time) (*models.FairnessReport, error)
+
+	// Администрирование
+	Bootstrap(ctx context.Context, doc models.BootstrapDocument) (*models.BootstrapResult, error)
+	SweepOrphanedReviews(ctx context.Context, inactiveBefore time.Time, limit int) (*models.OrphanSweepResult, error)
+
+	// Вебхуки
+	ReserveWebhookDelivery(ctx context.Context, provider, deliveryID string, ttl time.Duration) (bool, error)
+	ReleaseWebhookDelivery(ctx context.Context, provider, deliveryID string) error
+
+	// Подписки исходящих вебхуков
+	CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID int64) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhookID int64, update models.WebhookUpdate) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int64) error
+	ListWebhookAttempts(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookAttempt, int, error)
+}
+
+var _ Store = (*repository.Repository)(nil)
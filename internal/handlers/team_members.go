@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// AddTeamMember добавляет одного участника в команду
+func (h *Handler) AddTeamMember(c echo.Context) error {
+	h.log(c).Info("AddTeamMember: начало обработки запроса")
+
+	var req struct {
+		TeamName string `json:"team_name"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+		models.TeamMember
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("AddTeamMember: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+	req.UserID = h.normalizeID(req.UserID)
+
+	if req.TeamName == "" || req.UserID == "" {
+		h.log(c).Warn("AddTeamMember: team_name или user_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "team_name and user_id are required"))
+	}
+	if !models.ValidSlackUserID(req.SlackUserID) {
+		h.log(c).Warn("AddTeamMember: некорректный slack_user_id", zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "slack_user_id must be a Slack member ID"))
+	}
+	if !models.ValidMaxOpenReviews(req.MaxOpenReviews) {
+		h.log(c).Warn("AddTeamMember: некорректный max_open_reviews", zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "max_open_reviews must be a positive integer"))
+	}
+	if !models.ValidTeamRole(req.Role) {
+		h.log(c).Warn("AddTeamMember: некорректная роль участника", zap.String("user_id", req.UserID), zap.String("role", req.Role))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "role must be one of member, lead"))
+	}
+
+	h.log(c).Info("AddTeamMember: добавление участника",
+		zap.String("team_name", req.TeamName),
+		zap.String("user_id", req.UserID))
+
+	team, err := h.repo.AddTeamMember(c.Request().Context(), req.TeamName, req.TeamMember)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("AddTeamMember: команда не найдена", zap.String("team_name", req.TeamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		if errors.Is(err, repository.ErrAlreadyMember) {
+			h.log(c).Warn("AddTeamMember: пользователь уже в команде",
+				zap.String("team_name", req.TeamName),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeAlreadyMember, "user is already a team member"))
+		}
+		h.log(c).Error("AddTeamMember: ошибка добавления участника", zap.Error(err), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to add team member"))
+	}
+
+	h.log(c).Info("AddTeamMember: участник добавлен",
+		zap.String("team_name", req.TeamName),
+		zap.String("user_id", req.UserID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"team": h.teamView(c, team)})
+}
+
+// RemoveTeamMember удаляет участника из команды с переназначением его открытых ревью
+func (h *Handler) RemoveTeamMember(c echo.Context) error {
+	h.log(c).Info("RemoveTeamMember: начало обработки запроса")
+
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		// ActorID — инициатор запроса, важнее заголовка X-Actor-Id
+		ActorID string `json:"actor_id"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("RemoveTeamMember: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	bodyActor(c, req.ActorID)
+	req.UserID = h.normalizeID(req.UserID)
+
+	h.log(c).Info("RemoveTeamMember: удаление участника",
+		zap.String("team_name", req.TeamName),
+		zap.String("user_id", req.UserID))
+
+	team, err := h.repo.RemoveTeamMember(c.Request().Context(), req.TeamName, req.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("RemoveTeamMember: команда или пользователь не найдены",
+				zap.String("team_name", req.TeamName),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team or user not found"))
+		}
+		if errors.Is(err, repository.ErrNotMember) {
+			h.log(c).Warn("RemoveTeamMember: пользователь не состоит в команде",
+				zap.String("team_name", req.TeamName),
+				zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeNotMember, "user is not a team member"))
+		}
+		h.log(c).Error("RemoveTeamMember: ошибка удаления участника", zap.Error(err), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to remove team member"))
+	}
+
+	h.log(c).Info("RemoveTeamMember: участник удален",
+		zap.String("team_name", req.TeamName),
+		zap.String("user_id", req.UserID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"team": h.teamView(c, team)})
+}
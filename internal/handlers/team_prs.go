@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ListTeamPullRequests возвращает страницу PR, авторы которых состоят в команде
+func (h *Handler) ListTeamPullRequests(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("ListTeamPullRequests: получение PR команды", zap.String("team_name", teamName))
+
+	if teamName == "" {
+		h.log(c).Warn("ListTeamPullRequests: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
+	}
+
+	status, err := parseStatusParam(c)
+	if err != nil {
+		h.log(c).Warn("ListTeamPullRequests: некорректный статус", zap.String("status", c.QueryParam("status")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	label, err := parseLabelParam(c)
+	if err != nil {
+		h.log(c).Warn("ListTeamPullRequests: некорректная метка", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	repo, err := parseRepositoryParam(c)
+	if err != nil {
+		h.log(c).Warn("ListTeamPullRequests: некорректный параметр repository", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("ListTeamPullRequests: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	filter := repository.PRListFilter{Status: status, Label: label, Repository: repo, Limit: limit, Offset: offset}
+	if err := parsePRListRange(c, &filter); err != nil {
+		h.log(c).Warn("ListTeamPullRequests: некорректные параметры периода, сортировки или курсора", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	prs, page, err := h.repo.ListTeamPRs(c.Request().Context(), teamName, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("ListTeamPullRequests: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("ListTeamPullRequests: ошибка получения PR", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list team PRs"))
+	}
+
+	h.log(c).Info("ListTeamPullRequests: PR успешно получены",
+		zap.String("team_name", teamName),
+		zap.Int("prs_count", len(prs)),
+		zap.Int("total", page.Total))
+
+	response := map[string]interface{}{
+		"team_name":     teamName,
+		"pull_requests": prs,
+		"total":         page.Total,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ListAuthorPullRequests возвращает страницу PR автора
+func (h *Handler) ListAuthorPullRequests(c echo.Context) error {
+	authorID := h.normalizeID(c.QueryParam("author_id"))
+	h.log(c).Info("ListAuthorPullRequests: получение PR автора", zap.String("author_id", authorID))
+
+	if authorID == "" {
+		h.log(c).Warn("ListAuthorPullRequests: параметр author_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "author_id parameter is required"))
+	}
+
+	status, err := parseStatusParam(c)
+	if err != nil {
+		h.log(c).Warn("ListAuthorPullRequests: некорректный статус", zap.String("status", c.QueryParam("status")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	label, err := parseLabelParam(c)
+	if err != nil {
+		h.log(c).Warn("ListAuthorPullRequests: некорректная метка", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	repo, err := parseRepositoryParam(c)
+	if err != nil {
+		h.log(c).Warn("ListAuthorPullRequests: некорректный параметр repository", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("ListAuthorPullRequests: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	filter := repository.PRListFilter{Status: status, Label: label, Repository: repo, Limit: limit, Offset: offset}
+	if err := parsePRListRange(c, &filter); err != nil {
+		h.log(c).Warn("ListAuthorPullRequests: некорректные параметры периода, сортировки или курсора", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	prs, page, err := h.repo.ListAuthorPRs(c.Request().Context(), authorID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("ListAuthorPullRequests: автор не найден", zap.String("author_id", authorID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("ListAuthorPullRequests: ошибка получения PR", zap.Error(err), zap.String("author_id", authorID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list author PRs"))
+	}
+
+	h.log(c).Info("ListAuthorPullRequests: PR успешно получены",
+		zap.String("author_id", authorID),
+		zap.Int("prs_count", len(prs)),
+		zap.Int("total", page.Total))
+
+	response := map[string]interface{}{
+		"author_id":     authorID,
+		"pull_requests": prs,
+		"total":         page.Total,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/announcement"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxReviewSLAHours — максимальный SLA ревью в часах (30 суток)
+const maxReviewSLAHours = 720
+
+// UpdateTeamSettings обновляет настройки команды; не переданные поля остаются прежними
+func (h *Handler) UpdateTeamSettings(c echo.Context) error {
+	h.log(c).Info("UpdateTeamSettings: начало обработки запроса")
+
+	var req models.TeamSettings
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("UpdateTeamSettings: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	if req.TeamName == "" {
+		h.log(c).Warn("UpdateTeamSettings: team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "team_name is required"))
+	}
+
+	// Пустой шаблон означает возврат к шаблону по умолчанию
+	if req.AnnouncementTemplate != nil && *req.AnnouncementTemplate != "" {
+		if err := announcement.Validate(*req.AnnouncementTemplate); err != nil {
+			h.log(c).Warn("UpdateTeamSettings: некорректный шаблон анонса", zap.Error(err), zap.String("team_name", req.TeamName))
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, err.Error()))
+		}
+	}
+
+	if problem := h.validateAssignmentSettings(req); problem != "" {
+		h.log(c).Warn("UpdateTeamSettings: некорректные правила назначения", zap.String("problem", problem), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, problem))
+	}
+
+	// Нулевой SLA означает отключение SLA команды
+	if req.ReviewSLAHours != nil && (*req.ReviewSLAHours < 0 || *req.ReviewSLAHours > maxReviewSLAHours) {
+		h.log(c).Warn("UpdateTeamSettings: некорректный SLA ревью", zap.Int("review_sla_hours", *req.ReviewSLAHours), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody,
+			fmt.Sprintf("review_sla_hours must be between 0 and %d", maxReviewSLAHours)))
+	}
+
+	settings, err := h.repo.UpdateTeamSettings(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("UpdateTeamSettings: команда не найдена", zap.String("team_name", req.TeamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("UpdateTeamSettings: ошибка сохранения настроек", zap.Error(err), zap.String("team_name", req.TeamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update team settings"))
+	}
+
+	h.log(c).Info("UpdateTeamSettings: настройки команды обновлены", zap.String("team_name", req.TeamName))
+	return c.JSON(http.StatusOK, map[string]interface{}{"settings": settings})
+}
+
+// validateAssignmentSettings проверяет правила назначения команды и возвращает описание проблемы
+// или пустую строку. Ноль и пустая стратегия означают возврат к значениям по умолчанию.
+func (h *Handler) validateAssignmentSettings(req models.TeamSettings) string {
+	if req.ReviewersPerPR != nil {
+		n := *req.ReviewersPerPR
+		if n < 0 || (h.cfg.MaxReviewers > 0 && n > h.cfg.MaxReviewers) {
+			return fmt.Sprintf("reviewers_per_pr must be between 0 and %d", h.cfg.MaxReviewers)
+		}
+	}
+	if req.Strategy != nil {
+		switch *req.Strategy {
+		case "", repository.StrategyRandom, repository.StrategyLeastLoaded, repository.StrategyRoundRobin:
+		default:
+			return fmt.Sprintf("strategy must be one of %s, %s, %s",
+				repository.StrategyLeastLoaded, repository.StrategyRandom, repository.StrategyRoundRobin)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetTeamStats возвращает статистику пропускной способности PR команды
+func (h *Handler) GetTeamStats(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("GetTeamStats: получение статистики команды", zap.String("team_name", teamName))
+
+	if teamName == "" {
+		h.log(c).Warn("GetTeamStats: параметр team_name отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "team_name parameter is required"))
+	}
+
+	since, err := parseSinceParam(c)
+	if err != nil {
+		h.log(c).Warn("GetTeamStats: некорректный параметр since", zap.String("since", c.QueryParam("since")))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	stats, err := h.repo.GetTeamStats(c.Request().Context(), teamName, since)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetTeamStats: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetTeamStats: ошибка получения статистики", zap.Error(err), zap.String("team_name", teamName))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get team stats"))
+	}
+
+	h.log(c).Info("GetTeamStats: статистика команды успешно получена",
+		zap.String("team_name", teamName),
+		zap.Int("open_prs", stats.OpenPRs),
+		zap.Int("merged_prs", stats.MergedPRs))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"stats": stats})
+}
+
+// parseSinceParam разбирает начало окна статистики из параметра since (YYYY-MM-DD).
+// По умолчанию окно — последние defaultHistoryDays дней.
+func parseSinceParam(c echo.Context) (time.Time, error) {
+	raw := c.QueryParam("since")
+	if raw == "" {
+		return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -defaultHistoryDays), nil
+	}
+	since, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, errors.New("since must be a date in YYYY-MM-DD format")
+	}
+	return since, nil
+}
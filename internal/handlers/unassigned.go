@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// GetUnassignedPullRequests возвращает открытые PR без ревьюеров, от самых старых к новым
+func (h *Handler) GetUnassignedPullRequests(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+	h.log(c).Info("GetUnassignedPullRequests: получение PR без ревьюеров", zap.String("team_name", teamName))
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("GetUnassignedPullRequests: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	prs, err := h.repo.GetUnassignedPRs(c.Request().Context(), teamName, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetUnassignedPullRequests: команда не найдена", zap.String("team_name", teamName))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "team not found"))
+		}
+		h.log(c).Error("GetUnassignedPullRequests: ошибка получения PR", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get unassigned PRs"))
+	}
+
+	h.log(c).Info("GetUnassignedPullRequests: PR успешно получены", zap.Int("prs_count", len(prs)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"pull_requests": prs})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// maxActivityBatchSize ограничивает количество пользователей в пакетном изменении активности
+const maxActivityBatchSize = 1000
+
+// SetUsersIsActiveBatch меняет статус активности нескольких пользователей одним запросом.
+// Ненайденные пользователи возвращаются в not_found и не прерывают обновление остальных.
+func (h *Handler) SetUsersIsActiveBatch(c echo.Context) error {
+	h.log(c).Info("SetUsersIsActiveBatch: начало обработки запроса")
+
+	var req []struct {
+		UserID   string `json:"user_id"`
+		IsActive *bool  `json:"is_active"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("SetUsersIsActiveBatch: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	if len(req) == 0 {
+		h.log(c).Warn("SetUsersIsActiveBatch: пустой список пользователей")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "batch must not be empty"))
+	}
+	if len(req) > maxActivityBatchSize {
+		h.log(c).Warn("SetUsersIsActiveBatch: превышен размер batch", zap.Int("users_count", len(req)))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody,
+			fmt.Sprintf("too many users, max is %d", maxActivityBatchSize)))
+	}
+
+	updates := make([]models.UserActivityUpdate, 0, len(req))
+	for i, item := range req {
+		userID := h.normalizeID(item.UserID)
+		if userID == "" {
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam,
+				fmt.Sprintf("user_id is required (item %d)", i)))
+		}
+		if item.IsActive == nil {
+			return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam,
+				fmt.Sprintf("is_active is required (item %d)", i)))
+		}
+		updates = append(updates, models.UserActivityUpdate{UserID: userID, IsActive: *item.IsActive})
+	}
+
+	users, notFound, err := h.repo.SetUsersActiveBatch(c.Request().Context(), updates)
+	if err != nil {
+		h.log(c).Error("SetUsersIsActiveBatch: ошибка обновления статусов", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update users status"))
+	}
+
+	h.log(c).Info("SetUsersIsActiveBatch: статусы пользователей обновлены",
+		zap.Int("updated_count", len(users)),
+		zap.Int("not_found_count", len(notFound)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users":     users,
+		"not_found": notFound,
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// SetUserCapacity задает лимит открытых ревью пользователя.
+// max_open_reviews: null (или отсутствие поля) снимает ограничение.
+func (h *Handler) SetUserCapacity(c echo.Context) error {
+	h.log(c).Info("SetUserCapacity: начало обработки запроса")
+
+	var req struct {
+		UserID         string `json:"user_id"`
+		MaxOpenReviews *int   `json:"max_open_reviews"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("SetUserCapacity: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+
+	if req.UserID == "" {
+		h.log(c).Warn("SetUserCapacity: user_id отсутствует")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "user_id is required"))
+	}
+	if !models.ValidMaxOpenReviews(req.MaxOpenReviews) {
+		h.log(c).Warn("SetUserCapacity: некорректный max_open_reviews", zap.Int("max_open_reviews", *req.MaxOpenReviews))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "max_open_reviews must be a positive integer or null"))
+	}
+
+	h.log(c).Info("SetUserCapacity: обновление лимита открытых ревью",
+		zap.String("user_id", req.UserID),
+		zap.Intp("max_open_reviews", req.MaxOpenReviews))
+
+	if err := h.repo.SetUserCapacity(c.Request().Context(), req.UserID, req.MaxOpenReviews); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("SetUserCapacity: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		h.log(c).Error("SetUserCapacity: ошибка обновления лимита", zap.Error(err), zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update user capacity"))
+	}
+
+	h.log(c).Info("SetUserCapacity: лимит открытых ревью обновлен", zap.String("user_id", req.UserID))
+
+	response := map[string]interface{}{
+		"user_id":          req.UserID,
+		"max_open_reviews": req.MaxOpenReviews,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// AddUserVacation добавляет отпуск пользователю: на интервале [from, to) он не назначается ревьюером
+func (h *Handler) AddUserVacation(c echo.Context) error {
+	h.log(c).Info("AddUserVacation: начало обработки запроса")
+
+	var req struct {
+		UserID string    `json:"user_id"`
+		From   time.Time `json:"from"`
+		To     time.Time `json:"to"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("AddUserVacation: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+	req.UserID = h.normalizeID(req.UserID)
+
+	if req.UserID == "" || req.From.IsZero() || req.To.IsZero() {
+		h.log(c).Warn("AddUserVacation: не заполнены обязательные поля")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "user_id, from and to are required"))
+	}
+	if !req.To.After(req.From) {
+		h.log(c).Warn("AddUserVacation: to не позже from", zap.Time("from", req.From), zap.Time("to", req.To))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "to must be after from"))
+	}
+
+	h.log(c).Info("AddUserVacation: добавление отпуска",
+		zap.String("user_id", req.UserID),
+		zap.Time("from", req.From),
+		zap.Time("to", req.To))
+
+	vacation, err := h.repo.AddVacation(c.Request().Context(), req.UserID, req.From, req.To)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("AddUserVacation: пользователь не найден", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "user not found"))
+		}
+		if errors.Is(err, repository.ErrVacationOverlap) {
+			h.log(c).Warn("AddUserVacation: отпуск пересекается с существующим", zap.String("user_id", req.UserID))
+			return c.JSON(http.StatusConflict, newErrorResponse(c, ErrCodeVacationOverlap, "vacation overlaps an existing one"))
+		}
+		h.log(c).Error("AddUserVacation: ошибка добавления отпуска", zap.Error(err), zap.String("user_id", req.UserID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to add vacation"))
+	}
+
+	user, err := h.repo.GetUser(repository.WithPrimary(c.Request().Context()), req.UserID)
+	if err != nil {
+		h.log(c).Error("AddUserVacation: ошибка получения пользователя", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get updated user"))
+	}
+
+	h.log(c).Info("AddUserVacation: отпуск добавлен",
+		zap.String("user_id", req.UserID),
+		zap.Int64("vacation_id", vacation.VacationID))
+
+	response := map[string]interface{}{
+		"vacation": vacation,
+		"user":     h.userView(c, user),
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// DeleteUserVacation удаляет отпуск пользователя
+func (h *Handler) DeleteUserVacation(c echo.Context) error {
+	userID := h.normalizeID(c.QueryParam("user_id"))
+	rawVacationID := c.QueryParam("vacation_id")
+	h.log(c).Info("DeleteUserVacation: удаление отпуска",
+		zap.String("user_id", userID),
+		zap.String("vacation_id", rawVacationID))
+
+	if userID == "" || rawVacationID == "" {
+		h.log(c).Warn("DeleteUserVacation: параметры user_id или vacation_id отсутствуют")
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeMissingParam, "user_id and vacation_id parameters are required"))
+	}
+
+	vacationID, err := strconv.ParseInt(rawVacationID, 10, 64)
+	if err != nil {
+		h.log(c).Warn("DeleteUserVacation: некорректный vacation_id", zap.String("vacation_id", rawVacationID))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, "vacation_id must be an integer"))
+	}
+
+	if err := h.repo.DeleteVacation(c.Request().Context(), userID, vacationID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("DeleteUserVacation: отпуск не найден",
+				zap.String("user_id", userID),
+				zap.Int64("vacation_id", vacationID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "vacation not found"))
+		}
+		h.log(c).Error("DeleteUserVacation: ошибка удаления отпуска", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to delete vacation"))
+	}
+
+	user, err := h.repo.GetUser(repository.WithPrimary(c.Request().Context()), userID)
+	if err != nil {
+		h.log(c).Error("DeleteUserVacation: ошибка получения пользователя", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get updated user"))
+	}
+
+	h.log(c).Info("DeleteUserVacation: отпуск удален",
+		zap.String("user_id", userID),
+		zap.Int64("vacation_id", vacationID))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"user": h.userView(c, user)})
+}
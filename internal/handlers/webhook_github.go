@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// Заголовки вебхуков GitHub
+const (
+	headerGitHubEvent     = "X-GitHub-Event"
+	headerGitHubDelivery  = "X-GitHub-Delivery"
+	headerGitHubSignature = "X-Hub-Signature-256"
+)
+
+// githubPullRequestEvent — поля события pull_request, которые использует сервис
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	// RequestedReviewer — у кого запрошено ревью (действие review_requested); для запроса у команды пуст
+	RequestedReviewer struct {
+		Login string `json:"login"`
+	} `json:"requested_reviewer"`
+}
+
+// GitHubWebhook принимает события pull_request от GitHub: opened создает PR, closed — сливает
+// или закрывает его, review_requested назначает ревьюера. ID PR — "owner/repo#номер" в репозитории
+// "owner/repo", автор и ревьюер ищутся по логину в user_external_accounts.
+// Остальные события и действия подтверждаются 202 и игнорируются.
+func (h *Handler) GitHubWebhook(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		h.log(c).Error("GitHubWebhook: ошибка чтения тела запроса", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+
+	if !validGitHubSignature(h.cfg.GitHubWebhookSecret, body, c.Request().Header.Get(headerGitHubSignature)) {
+		h.log(c).Warn("GitHubWebhook: неверная подпись")
+		return c.JSON(http.StatusUnauthorized, newErrorResponse(c, ErrCodeUnauthorized, "invalid webhook signature"))
+	}
+
+	event := c.Request().Header.Get(headerGitHubEvent)
+	deliveryID := c.Request().Header.Get(headerGitHubDelivery)
+	if event != "pull_request" {
+		h.log(c).Info("GitHubWebhook: событие пропущено", zap.String("event", event), zap.String("delivery_id", deliveryID))
+		return c.JSON(http.StatusAccepted, webhookIgnored("event type is not supported").body)
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		h.log(c).Error("GitHubWebhook: ошибка парсинга события", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+
+	ev := prEvent{
+		PullRequestID: fmt.Sprintf("%s#%d", payload.Repository.FullName, payload.Number),
+		Repository:    eventRepository(payload.Repository.FullName),
+		Title:         payload.PullRequest.Title,
+		AuthorAccount: normalizeAccountID(models.ProviderGitHub, payload.PullRequest.User.Login),
+	}
+	switch {
+	case payload.Action == "opened":
+		ev.Action = prEventOpened
+	case payload.Action == "closed" && payload.PullRequest.Merged:
+		ev.Action = prEventMerged
+	case payload.Action == "closed":
+		ev.Action = prEventClosed
+	case payload.Action == "review_requested" && payload.RequestedReviewer.Login != "":
+		ev.Action = prEventReviewRequested
+		ev.ReviewerAccount = normalizeAccountID(models.ProviderGitHub, payload.RequestedReviewer.Login)
+	default:
+		h.log(c).Info("GitHubWebhook: действие пропущено", zap.String("action", payload.Action), zap.String("delivery_id", deliveryID))
+		return c.JSON(http.StatusAccepted, webhookIgnored("action is not supported").body)
+	}
+
+	return h.handleWebhookDelivery(c, models.ProviderGitHub, deliveryID, ev)
+}
+
+// validGitHubSignature проверяет заголовок X-Hub-Signature-256: "sha256=" и HMAC-SHA256 тела на секрете
+func validGitHubSignature(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// Заголовки вебхуков GitLab
+const (
+	headerGitLabToken     = "X-Gitlab-Token"
+	headerGitLabEventUUID = "X-Gitlab-Event-UUID"
+)
+
+// gitlabMergeRequestEvent — поля события merge_request, которые использует сервис
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	Project    struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int64  `json:"iid"`
+		Title    string `json:"title"`
+		Action   string `json:"action"`
+		AuthorID int64  `json:"author_id"`
+	} `json:"object_attributes"`
+}
+
+// gitlabActions сопоставляет действия merge_request с действиями над PR; остальные игнорируются
+var gitlabActions = map[string]string{
+	"open":  prEventOpened,
+	"merge": prEventMerged,
+	"close": prEventClosed,
+}
+
+// GitLabWebhook принимает события merge_request от GitLab: open создает PR, merge сливает, close закрывает.
+// ID PR — "group/project!iid" в репозитории "group/project", автор ищется по ID пользователя GitLab в user_external_accounts.
+// Остальные события и действия подтверждаются 202 и игнорируются.
+func (h *Handler) GitLabWebhook(c echo.Context) error {
+	token := c.Request().Header.Get(headerGitLabToken)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.GitLabWebhookSecret)) != 1 {
+		h.log(c).Warn("GitLabWebhook: неверный токен")
+		return c.JSON(http.StatusUnauthorized, newErrorResponse(c, ErrCodeUnauthorized, "invalid webhook token"))
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		h.log(c).Error("GitLabWebhook: ошибка чтения тела запроса", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+
+	var payload gitlabMergeRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		h.log(c).Error("GitLabWebhook: ошибка парсинга события", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidBody, "invalid request body"))
+	}
+
+	// Idempotency-Key GitLab сохраняет при повторных попытках, X-Gitlab-Event-UUID — запасной вариант для старых версий
+	deliveryID := c.Request().Header.Get(HeaderIdempotencyKey)
+	if deliveryID == "" {
+		deliveryID = c.Request().Header.Get(headerGitLabEventUUID)
+	}
+
+	if payload.ObjectKind != "merge_request" {
+		h.log(c).Info("GitLabWebhook: событие пропущено", zap.String("object_kind", payload.ObjectKind), zap.String("delivery_id", deliveryID))
+		return c.JSON(http.StatusAccepted, webhookIgnored("event type is not supported").body)
+	}
+
+	action, ok := gitlabActions[payload.ObjectAttributes.Action]
+	if !ok {
+		h.log(c).Info("GitLabWebhook: действие пропущено", zap.String("action", payload.ObjectAttributes.Action), zap.String("delivery_id", deliveryID))
+		return c.JSON(http.StatusAccepted, webhookIgnored("action is not supported").body)
+	}
+
+	ev := prEvent{
+		Action:        action,
+		PullRequestID: fmt.Sprintf("%s!%d", payload.Project.PathWithNamespace, payload.ObjectAttributes.IID),
+		Repository:    eventRepository(payload.Project.PathWithNamespace),
+		Title:         payload.ObjectAttributes.Title,
+		AuthorAccount: strconv.FormatInt(payload.ObjectAttributes.AuthorID, 10),
+	}
+
+	return h.handleWebhookDelivery(c, models.ProviderGitLab, deliveryID, ev)
+}
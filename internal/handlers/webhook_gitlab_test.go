@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// gitlabToken — секрет X-Gitlab-Token вебхуков GitLab в тестах
+const gitlabToken = "gitlab-token"
+
+// gitlabFixture читает событие GitLab из tests/e2e/fixtures/gitlab
+func gitlabFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "tests", "e2e", "fixtures", "gitlab", name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// gitlabStore возвращает хранилище, которое записывает в calls обращения вебхука: привязана только
+// учетная запись GitLab 4101 (пользователь u1), доставки не повторяются
+func gitlabStore(calls *[]string) *mocks.Store {
+	record := func(format string, args ...any) {
+		*calls = append(*calls, fmt.Sprintf(format, args...))
+	}
+	pr := func(id string, status string) *models.PullRequest {
+		return &models.PullRequest{PullRequestID: id, Repository: "acme/api", Status: status}
+	}
+	return &mocks.Store{
+		ReserveWebhookDeliveryFunc: func(_ context.Context, provider, deliveryID string, _ time.Duration) (bool, error) {
+			record("reserve %s %s", provider, deliveryID)
+			return true, nil
+		},
+		GetUserByExternalAccountFunc: func(_ context.Context, provider, accountID string) (string, error) {
+			record("account %s %s", provider, accountID)
+			if accountID != "4101" {
+				return "", repository.ErrNotFound
+			}
+			return "u1", nil
+		},
+		CreatePRFunc: func(_ context.Context, repo, id, name, authorID, _ string, _ models.PRMetadata) (*models.PullRequest, error) {
+			record("create %s %s %q %s", repo, id, name, authorID)
+			return pr(id, models.StatusOpen), nil
+		},
+		MergePRFunc: func(_ context.Context, ref models.PRRef, _ *int64) (*models.PullRequest, bool, error) {
+			record("merge %s %s", *ref.Repository, ref.ID)
+			return pr(ref.ID, models.StatusMerged), true, nil
+		},
+		ClosePRFunc: func(_ context.Context, ref models.PRRef, _ *int64) (*models.PullRequest, error) {
+			record("close %s %s", *ref.Repository, ref.ID)
+			return pr(ref.ID, models.StatusClosed), nil
+		},
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	delivery := map[string]string{"X-Gitlab-Token": gitlabToken, "X-Gitlab-Event-UUID": "uuid-1"}
+
+	cases := []struct {
+		name    string
+		fixture string
+		body    string
+		header  map[string]string
+		status  int
+		// result — поле status ответа (processed или ignored), reason — причина пропуска
+		result string
+		reason string
+		// prStatus — статус PR в ответе на примененное событие
+		prStatus string
+		calls    []string
+	}{
+		{
+			name: "open", fixture: "merge_request_open.json", header: delivery,
+			status: http.StatusOK, result: "processed", prStatus: models.StatusOpen,
+			calls: []string{
+				"reserve gitlab uuid-1",
+				"account gitlab 4101",
+				`create acme/api acme/api!11 "Add GitLab support" u1`,
+			},
+		},
+		{
+			name: "open second merge request", fixture: "merge_request_open_second.json", header: delivery,
+			status: http.StatusOK, result: "processed", prStatus: models.StatusOpen,
+			calls: []string{
+				"reserve gitlab uuid-1",
+				"account gitlab 4101",
+				`create acme/api acme/api!12 "Experimental branch" u1`,
+			},
+		},
+		{
+			name: "merge", fixture: "merge_request_merge.json", header: delivery,
+			status: http.StatusOK, result: "processed", prStatus: models.StatusMerged,
+			calls: []string{"reserve gitlab uuid-1", "merge acme/api acme/api!11"},
+		},
+		{
+			name: "close", fixture: "merge_request_close.json", header: delivery,
+			status: http.StatusOK, result: "processed", prStatus: models.StatusClosed,
+			calls: []string{"reserve gitlab uuid-1", "close acme/api acme/api!12"},
+		},
+		{
+			name: "unknown action is ignored", fixture: "merge_request_update.json", header: delivery,
+			status: http.StatusAccepted, result: "ignored", reason: "action is not supported",
+		},
+		{
+			name: "unknown author is not linked", fixture: "merge_request_unknown_author.json", header: delivery,
+			status: http.StatusAccepted, result: "ignored", reason: "author account is not linked",
+			calls: []string{"reserve gitlab uuid-1", "account gitlab 4999"},
+		},
+		{
+			name: "other event kind is ignored", body: `{"object_kind":"push","ref":"refs/heads/main"}`, header: delivery,
+			status: http.StatusAccepted, result: "ignored", reason: "event type is not supported",
+		},
+		{
+			name:    "Idempotency-Key wins over event UUID",
+			fixture: "merge_request_merge.json",
+			header:  map[string]string{"X-Gitlab-Token": gitlabToken, "X-Gitlab-Event-UUID": "uuid-1", handlers.HeaderIdempotencyKey: "key-1"},
+			status:  http.StatusOK, result: "processed", prStatus: models.StatusMerged,
+			calls: []string{"reserve gitlab key-1", "merge acme/api acme/api!11"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := tc.body
+			if tc.fixture != "" {
+				body = gitlabFixture(t, tc.fixture)
+			}
+			var calls []string
+			e := newTestServer(gitlabStore(&calls), handlers.Config{GitLabWebhookSecret: gitlabToken})
+
+			rec := serve(e, http.MethodPost, "/webhooks/gitlab", body, tc.header)
+
+			require.Equal(t, tc.status, rec.Code, rec.Body.String())
+			var resp struct {
+				Status   string              `json:"status"`
+				Reason   string              `json:"reason"`
+				Warnings []string            `json:"warnings"`
+				PR       *models.PullRequest `json:"pr"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.result, resp.Status)
+			assert.Equal(t, tc.reason, resp.Reason)
+			if tc.prStatus != "" {
+				require.NotNil(t, resp.PR)
+				assert.Equal(t, tc.prStatus, resp.PR.Status)
+			} else {
+				assert.Nil(t, resp.PR)
+			}
+			if tc.reason == "author account is not linked" {
+				assert.Equal(t, []string{handlers.WarnAccountNotLinked}, resp.Warnings)
+			}
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestGitLabWebhookRejectsBadToken(t *testing.T) {
+	body := gitlabFixture(t, "merge_request_open.json")
+
+	for _, tc := range []struct {
+		name   string
+		header map[string]string
+	}{
+		{name: "missing token"},
+		{name: "wrong token", header: map[string]string{"X-Gitlab-Token": "not-" + gitlabToken}},
+		{name: "token prefix", header: map[string]string{"X-Gitlab-Token": gitlabToken[:4]}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			e := newTestServer(gitlabStore(&calls), handlers.Config{GitLabWebhookSecret: gitlabToken})
+
+			rec := serve(e, http.MethodPost, "/webhooks/gitlab", body, tc.header)
+
+			require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+			var resp handlers.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, handlers.ErrCodeUnauthorized, resp.Error.Code)
+			assert.Empty(t, calls, "rejected delivery must not reach the store")
+		})
+	}
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		var calls []string
+		e := newTestServer(gitlabStore(&calls), handlers.Config{GitLabWebhookSecret: gitlabToken})
+
+		rec := serve(e, http.MethodPost, "/webhooks/gitlab", `{"object_kind":`, map[string]string{"X-Gitlab-Token": gitlabToken})
+
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		assert.Empty(t, calls)
+	})
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// webhookEventTypes — события, на которые можно подписаться
+var webhookEventTypes = []string{
+	models.EventPRCreated,
+	models.EventReviewerAssigned,
+	models.EventReviewerReassigned,
+	models.EventPRMerged,
+	models.EventReviewReminder,
+}
+
+// validateHTTPURL проверяет адрес подписчика или ссылку на PR: абсолютный http(s) URL
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// validateWebhookEventTypes проверяет список событий подписки и убирает повторы
+func validateWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, errors.New("event_types must not be empty")
+	}
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return nil, fmt.Errorf("unknown event type %q", eventType)
+		}
+		if !slices.Contains(unique, eventType) {
+			unique = append(unique, eventType)
+		}
+	}
+	return unique, nil
+}
+
+// parseWebhookID разбирает обязательный параметр webhook_id
+func parseWebhookID(c echo.Context) (int64, *ErrorResponse) {
+	raw := c.QueryParam("webhook_id")
+	if raw == "" {
+		resp := newErrorResponse(c, ErrCodeMissingParam, "webhook_id parameter is required")
+		return 0, &resp
+	}
+	webhookID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		resp := newErrorResponse(c, ErrCodeInvalidParam, "webhook_id must be an integer")
+		return 0, &resp
+	}
+	return webhookID, nil
+}
+
+// CreateWebhook создает подписку на события исходящих вебхуков
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	h.log(c).Info("CreateWebhook: начало обработки запроса")
+
+	var req struct {
+		URL        string   `json:"url"`
+		Secret     string   `json:"secret"`
+		EventTypes []string `json:"event_types"`
+		Enabled    *bool    `json:"enabled"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("CreateWebhook: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	var problems []string
+	if err := validateHTTPURL(req.URL); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if req.Secret == "" {
+		problems = append(problems, "secret is required")
+	}
+	eventTypes, err := validateWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		h.log(c).Warn("CreateWebhook: подписка не прошла валидацию", zap.Strings("problems", problems))
+		resp := newErrorResponse(c, ErrCodeValidation, "webhook is invalid")
+		resp.Error.Details = problems
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	hook := models.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: eventTypes,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	created, err := h.repo.CreateWebhook(c.Request().Context(), hook)
+	if err != nil {
+		h.log(c).Error("CreateWebhook: ошибка создания подписки", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to create webhook"))
+	}
+
+	h.log(c).Info("CreateWebhook: подписка создана",
+		zap.Int64("webhook_id", created.WebhookID),
+		zap.Strings("event_types", created.EventTypes))
+	return c.JSON(http.StatusCreated, map[string]interface{}{"webhook": created})
+}
+
+// ListWebhooks возвращает все подписки
+func (h *Handler) ListWebhooks(c echo.Context) error {
+	h.log(c).Info("ListWebhooks: получение списка подписок")
+
+	hooks, err := h.repo.ListWebhooks(c.Request().Context())
+	if err != nil {
+		h.log(c).Error("ListWebhooks: ошибка получения подписок", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list webhooks"))
+	}
+
+	h.log(c).Info("ListWebhooks: подписки получены", zap.Int("webhooks_count", len(hooks)))
+	return c.JSON(http.StatusOK, map[string]interface{}{"webhooks": hooks})
+}
+
+// GetWebhook возвращает подписку по ID
+func (h *Handler) GetWebhook(c echo.Context) error {
+	webhookID, errResp := parseWebhookID(c)
+	if errResp != nil {
+		h.log(c).Warn("GetWebhook: некорректный webhook_id", zap.String("webhook_id", c.QueryParam("webhook_id")))
+		return c.JSON(http.StatusBadRequest, errResp)
+	}
+
+	h.log(c).Info("GetWebhook: получение подписки", zap.Int64("webhook_id", webhookID))
+
+	hook, err := h.repo.GetWebhook(c.Request().Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("GetWebhook: подписка не найдена", zap.Int64("webhook_id", webhookID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "webhook not found"))
+		}
+		h.log(c).Error("GetWebhook: ошибка получения подписки", zap.Error(err), zap.Int64("webhook_id", webhookID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to get webhook"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"webhook": hook})
+}
+
+// UpdateWebhook частично изменяет подписку: переданные поля заменяются, остальные остаются прежними
+func (h *Handler) UpdateWebhook(c echo.Context) error {
+	h.log(c).Info("UpdateWebhook: начало обработки запроса")
+
+	var req struct {
+		WebhookID  int64    `json:"webhook_id"`
+		URL        *string  `json:"url"`
+		Secret     *string  `json:"secret"`
+		EventTypes []string `json:"event_types"`
+		Enabled    *bool    `json:"enabled"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		h.log(c).Error("UpdateWebhook: ошибка парсинга тела запроса", zap.Error(err))
+		return bindFailed(c, err)
+	}
+
+	var problems []string
+	if req.WebhookID <= 0 {
+		problems = append(problems, "webhook_id is required")
+	}
+	if req.URL != nil {
+		if err := validateHTTPURL(*req.URL); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if req.Secret != nil && *req.Secret == "" {
+		problems = append(problems, "secret must not be empty")
+	}
+	update := models.WebhookUpdate{URL: req.URL, Secret: req.Secret, Enabled: req.Enabled}
+	if req.EventTypes != nil {
+		eventTypes, err := validateWebhookEventTypes(req.EventTypes)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		update.EventTypes = eventTypes
+	}
+	if len(problems) > 0 {
+		h.log(c).Warn("UpdateWebhook: изменение не прошло валидацию", zap.Strings("problems", problems))
+		resp := newErrorResponse(c, ErrCodeValidation, "webhook update is invalid")
+		resp.Error.Details = problems
+		return c.JSON(http.StatusBadRequest, resp)
+	}
+
+	h.log(c).Info("UpdateWebhook: изменение подписки", zap.Int64("webhook_id", req.WebhookID))
+
+	hook, err := h.repo.UpdateWebhook(c.Request().Context(), req.WebhookID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("UpdateWebhook: подписка не найдена", zap.Int64("webhook_id", req.WebhookID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "webhook not found"))
+		}
+		h.log(c).Error("UpdateWebhook: ошибка изменения подписки", zap.Error(err), zap.Int64("webhook_id", req.WebhookID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to update webhook"))
+	}
+
+	h.log(c).Info("UpdateWebhook: подписка изменена", zap.Int64("webhook_id", hook.WebhookID), zap.Bool("enabled", hook.Enabled))
+	return c.JSON(http.StatusOK, map[string]interface{}{"webhook": hook})
+}
+
+// DeleteWebhook удаляет подписку вместе с историей доставок
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	webhookID, errResp := parseWebhookID(c)
+	if errResp != nil {
+		h.log(c).Warn("DeleteWebhook: некорректный webhook_id", zap.String("webhook_id", c.QueryParam("webhook_id")))
+		return c.JSON(http.StatusBadRequest, errResp)
+	}
+
+	h.log(c).Info("DeleteWebhook: удаление подписки", zap.Int64("webhook_id", webhookID))
+
+	if err := h.repo.DeleteWebhook(c.Request().Context(), webhookID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("DeleteWebhook: подписка не найдена", zap.Int64("webhook_id", webhookID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "webhook not found"))
+		}
+		h.log(c).Error("DeleteWebhook: ошибка удаления подписки", zap.Error(err), zap.Int64("webhook_id", webhookID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to delete webhook"))
+	}
+
+	h.log(c).Info("DeleteWebhook: подписка удалена", zap.Int64("webhook_id", webhookID))
+	return c.JSON(http.StatusOK, map[string]interface{}{"webhook_id": webhookID})
+}
+
+// ListWebhookDeliveries возвращает историю попыток доставки подписки, новые первыми
+func (h *Handler) ListWebhookDeliveries(c echo.Context) error {
+	webhookID, errResp := parseWebhookID(c)
+	if errResp != nil {
+		h.log(c).Warn("ListWebhookDeliveries: некорректный webhook_id", zap.String("webhook_id", c.QueryParam("webhook_id")))
+		return c.JSON(http.StatusBadRequest, errResp)
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.log(c).Warn("ListWebhookDeliveries: некорректные параметры пагинации", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, newErrorResponse(c, ErrCodeInvalidParam, err.Error()))
+	}
+
+	h.log(c).Info("ListWebhookDeliveries: получение истории доставок",
+		zap.Int64("webhook_id", webhookID),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset))
+
+	attempts, total, err := h.repo.ListWebhookAttempts(c.Request().Context(), webhookID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log(c).Warn("ListWebhookDeliveries: подписка не найдена", zap.Int64("webhook_id", webhookID))
+			return c.JSON(http.StatusNotFound, newErrorResponse(c, ErrCodeNotFound, "webhook not found"))
+		}
+		h.log(c).Error("ListWebhookDeliveries: ошибка получения истории", zap.Error(err), zap.Int64("webhook_id", webhookID))
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to list webhook deliveries"))
+	}
+
+	h.log(c).Info("ListWebhookDeliveries: история получена",
+		zap.Int64("webhook_id", webhookID),
+		zap.Int("attempts_count", len(attempts)),
+		zap.Int("total", total))
+
+	response := map[string]interface{}{
+		"attempts": attempts,
+		"total":    total,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
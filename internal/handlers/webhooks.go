@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Действия над PR из событий внешних систем
+const (
+	prEventOpened = "opened"
+	prEventMerged = "merged"
+	prEventClosed = "closed"
+	// prEventReviewRequested — во внешней системе запрошено ревью у пользователя: он назначается ревьюером
+	prEventReviewRequested = "review_requested"
+)
+
+// prEvent — событие PR внешней системы, приведенное к общему для всех провайдеров виду
+type prEvent struct {
+	Action        string
+	PullRequestID string
+	Title         string
+	// Repository — репозиторий PR во внешней системе. nil — не указан: PR создается в репозитории
+	// по умолчанию, а остальные действия ищут его по одному ID
+	Repository *string
+	// AuthorAccount — учетная запись автора во внешней системе (см. user_external_accounts)
+	AuthorAccount string
+	// ReviewerAccount — учетная запись ревьюера во внешней системе для prEventReviewRequested
+	ReviewerAccount string
+}
+
+// webhookResult — ответ на доставку вебхука
+type webhookResult struct {
+	status int
+	body   map[string]interface{}
+}
+
+// webhookProcessed — событие применено, в ответе актуальное состояние PR
+func webhookProcessed(pr *models.PullRequest) webhookResult {
+	return webhookResult{status: http.StatusOK, body: map[string]interface{}{"status": "processed", "pr": pr}}
+}
+
+// webhookIgnored — событие принято, но ничего не изменило. Внешняя система не должна его повторять,
+// поэтому вместо 4xx отвечаем 202.
+func webhookIgnored(reason string) webhookResult {
+	return webhookResult{status: http.StatusAccepted, body: map[string]interface{}{"status": "ignored", "reason": reason}}
+}
+
+// webhookAccountNotLinked — событие принято, но не применено: учетная запись автора или ревьюера (role)
+// не привязана к пользователю. Предупреждение ACCOUNT_NOT_LINKED и учетная запись в ответе подсказывают,
+// что привязать через POST /users/linkAccount.
+func webhookAccountNotLinked(role, provider, accountID string) webhookResult {
+	result := webhookIgnored(role + " account is not linked")
+	result.body["warnings"] = []string{WarnAccountNotLinked}
+	result.body["account"] = map[string]string{"provider": provider, "account_id": accountID}
+	return result
+}
+
+// eventRepository возвращает репозиторий события внешней системы: пустое имя — репозиторий не указан
+func eventRepository(name string) *string {
+	if name == "" {
+		return nil
+	}
+	return &name
+}
+
+// normalizeAccountID приводит учетную запись внешней системы к виду, в котором она хранится:
+// логины GitHub регистронезависимы
+func normalizeAccountID(provider, accountID string) string {
+	accountID = strings.TrimSpace(accountID)
+	if provider == models.ProviderGitHub {
+		return strings.ToLower(accountID)
+	}
+	return accountID
+}
+
+// handleWebhookDelivery применяет событие не более одного раза на ID доставки:
+// повтор уже принятой доставки подтверждается без повторного применения.
+// Если применить событие не удалось из-за внутренней ошибки, отметка доставки снимается,
+// чтобы повтор от внешней системы был обработан заново.
+func (h *Handler) handleWebhookDelivery(c echo.Context, provider, deliveryID string, ev prEvent) error {
+	ctx := c.Request().Context()
+	log := h.log(c).With(zap.String("provider", provider), zap.String("delivery_id", deliveryID),
+		zap.String("action", ev.Action), zap.String("pr_id", ev.PullRequestID), zap.Stringp("repository", ev.Repository))
+
+	if deliveryID != "" {
+		reserved, err := h.repo.ReserveWebhookDelivery(ctx, provider, deliveryID, h.cfg.WebhookDeliveryTTL)
+		if err != nil {
+			log.Error("Webhook: ошибка отметки доставки", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to process webhook"))
+		}
+		if !reserved {
+			log.Info("Webhook: повтор доставки пропущен")
+			return c.JSON(http.StatusOK, map[string]interface{}{"status": "duplicate"})
+		}
+	}
+
+	result, err := h.applyPREvent(c, log, provider, ev)
+	if err != nil {
+		log.Error("Webhook: ошибка применения события", zap.Error(err))
+		if deliveryID != "" {
+			if err := h.repo.ReleaseWebhookDelivery(context.WithoutCancel(ctx), provider, deliveryID); err != nil {
+				log.Error("Webhook: ошибка снятия отметки доставки", zap.Error(err))
+			}
+		}
+		return c.JSON(http.StatusInternalServerError, newErrorResponse(c, ErrCodeInternal, "failed to process webhook"))
+	}
+
+	return c.JSON(result.status, result.body)
+}
+
+// applyPREvent применяет событие PR. Ошибки предметной области (неизвестный автор, PR уже существует,
+// PR не найден) дают webhookIgnored, ошибка возвращается только для внутренних сбоев.
+func (h *Handler) applyPREvent(c echo.Context, log *zap.Logger, provider string, ev prEvent) (webhookResult, error) {
+	// Изменения ревьюеров из вебхука записываются в журнал назначений от имени внешней системы
+	ctx := repository.WithActor(c.Request().Context(), "webhook:"+provider)
+
+	switch ev.Action {
+	case prEventOpened:
+		authorID, err := h.repo.GetUserByExternalAccount(ctx, provider, ev.AuthorAccount)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("Webhook: учетная запись автора не привязана", zap.String("account_id", ev.AuthorAccount))
+			return webhookAccountNotLinked("author", provider, ev.AuthorAccount), nil
+		}
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		var repo string
+		if ev.Repository != nil {
+			repo = *ev.Repository
+		}
+		pr, err := h.services.PRs.Create(ctx, repo, ev.PullRequestID, ev.Title, authorID, "", models.PRMetadata{})
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			log.Warn("Webhook: PR уже существует")
+			return webhookIgnored("PR already exists"), nil
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("Webhook: автор или команда не найдены", zap.String("author_id", authorID))
+			return webhookIgnored("author or team not found"), nil
+		}
+		if errors.Is(err, repository.ErrAmbiguousTeam) {
+			log.Warn("Webhook: автор состоит в нескольких командах", zap.String("author_id", authorID))
+			return webhookIgnored("author belongs to several teams"), nil
+		}
+		if errors.Is(err, repository.ErrNoReviewers) {
+			log.Warn("Webhook: нет кандидатов в ревьюеры", zap.String("author_id", authorID))
+			return webhookIgnored("no reviewer candidates"), nil
+		}
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		h.metrics.PRsCreated.Inc()
+		if len(pr.AssignedReviewers) == 0 {
+			h.metrics.ZeroReviewerAssignments.Inc()
+		}
+		log.Info("Webhook: PR создан", zap.Int("reviewers_count", len(pr.AssignedReviewers)))
+		return webhookProcessed(pr), nil
+
+	case prEventMerged:
+		pr, merged, err := h.services.PRs.Merge(ctx, prRef(ev.Repository, ev.PullRequestID), nil)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("Webhook: PR не найден")
+			return webhookIgnored("PR not found"), nil
+		}
+		var notApproved *repository.NotApprovedError
+		if errors.As(err, &notApproved) {
+			// PR уже слит во внешней системе, но правило REQUIRE_APPROVALS не выполнено: оставляем его открытым
+			log.Warn("Webhook: недостаточно одобрений для слияния",
+				zap.Int("approvals", notApproved.Approvals),
+				zap.Int("required", notApproved.Required))
+			return webhookIgnored(notApproved.Error()), nil
+		}
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			log.Warn("Webhook: недопустимый переход статуса", zap.Error(err))
+			return webhookIgnored(err.Error()), nil
+		}
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		if merged {
+			h.metrics.PRsMerged.Inc()
+		}
+		log.Info("Webhook: PR слит", zap.Bool("merged", merged))
+		return webhookProcessed(pr), nil
+
+	case prEventClosed:
+		pr, err := h.repo.ClosePR(ctx, prRef(ev.Repository, ev.PullRequestID), nil)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("Webhook: PR не найден")
+			return webhookIgnored("PR not found"), nil
+		}
+		if errors.Is(err, repository.ErrAlreadyMerged) {
+			log.Warn("Webhook: попытка закрыть смерженный PR")
+			return webhookIgnored("PR already merged"), nil
+		}
+		if errors.Is(err, repository.ErrAmbiguousPR) {
+			log.Warn("Webhook: ID PR есть в нескольких репозиториях")
+			return webhookIgnored("PR id exists in several repositories"), nil
+		}
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		log.Info("Webhook: PR закрыт")
+		return webhookProcessed(pr), nil
+
+	case prEventReviewRequested:
+		reviewerID, err := h.repo.GetUserByExternalAccount(ctx, provider, ev.ReviewerAccount)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("Webhook: учетная запись ревьюера не привязана", zap.String("account_id", ev.ReviewerAccount))
+			return webhookAccountNotLinked("reviewer", provider, ev.ReviewerAccount), nil
+		}
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		pr, _, err := h.services.PRs.AddReviewer(ctx, prRef(ev.Repository, ev.PullRequestID), reviewerID, nil)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			log.Warn("Webhook: PR или ревьюер не найден", zap.String("reviewer_id", reviewerID))
+			return webhookIgnored("PR or reviewer not found"), nil
+		case errors.Is(err, repository.ErrAlreadyAssigned):
+			log.Info("Webhook: ревьюер уже назначен", zap.String("reviewer_id", reviewerID))
+			return webhookIgnored("reviewer is already assigned"), nil
+		case errors.Is(err, repository.ErrCandidateNotEligible):
+			log.Warn("Webhook: пользователь не может ревьюить PR", zap.String("reviewer_id", reviewerID))
+			return webhookIgnored("reviewer must be an active member of the PR team and not the author"), nil
+		case errors.Is(err, repository.ErrMaxReviewers):
+			log.Warn("Webhook: у PR уже максимум ревьюеров", zap.String("reviewer_id", reviewerID))
+			return webhookIgnored("PR already has the maximum number of reviewers"), nil
+		case errors.Is(err, repository.ErrAlreadyMerged), errors.Is(err, repository.ErrAlreadyClosed):
+			log.Warn("Webhook: PR уже не открыт")
+			return webhookIgnored("PR is not open"), nil
+		case errors.Is(err, repository.ErrAmbiguousPR):
+			log.Warn("Webhook: ID PR есть в нескольких репозиториях")
+			return webhookIgnored("PR id exists in several repositories"), nil
+		case err != nil:
+			return webhookResult{}, err
+		}
+
+		log.Info("Webhook: ревьюер назначен", zap.String("reviewer_id", reviewerID))
+		return webhookProcessed(pr), nil
+	}
+
+	return webhookIgnored("action is not supported"), nil
+}
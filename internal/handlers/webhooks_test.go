@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// githubSecret — секрет подписи вебхуков GitHub в тестах
+const githubSecret = "secret"
+
+// githubHeader возвращает заголовки доставки GitHub события pull_request с подписью тела body
+func githubHeader(deliveryID, body string) map[string]string {
+	mac := hmac.New(sha256.New, []byte(githubSecret))
+	mac.Write([]byte(body))
+	return map[string]string{
+		"X-GitHub-Event":      "pull_request",
+		"X-GitHub-Delivery":   deliveryID,
+		"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+	}
+}
+
+func TestGitHubWebhookRepository(t *testing.T) {
+	const repo = "acme/api"
+	pr := &models.PullRequest{PullRequestID: repo + "#7", Repository: repo, Status: models.StatusOpen}
+
+	// got — репозиторий и ID PR, с которыми вебхук обратился к хранилищу
+	var got models.PRRef
+	record := func(repository *string, id string) {
+		got = models.PRRef{Repository: repository, ID: id}
+	}
+	st := &mocks.Store{
+		ReserveWebhookDeliveryFunc: func(context.Context, string, string, time.Duration) (bool, error) { return true, nil },
+		GetUserByExternalAccountFunc: func(context.Context, string, string) (string, error) {
+			return "u1", nil
+		},
+		CreatePRFunc: func(_ context.Context, repository, id, _, _, _ string, _ models.PRMetadata) (*models.PullRequest, error) {
+			record(&repository, id)
+			return pr, nil
+		},
+		MergePRFunc: func(_ context.Context, ref models.PRRef, _ *int64) (*models.PullRequest, bool, error) {
+			record(ref.Repository, ref.ID)
+			return pr, true, nil
+		},
+		ClosePRFunc: func(_ context.Context, ref models.PRRef, _ *int64) (*models.PullRequest, error) {
+			record(ref.Repository, ref.ID)
+			return pr, nil
+		},
+		AddReviewerFunc: func(_ context.Context, ref models.PRRef, _ string, _ *int64) (*models.PullRequest, string, error) {
+			record(ref.Repository, ref.ID)
+			return pr, "u1", nil
+		},
+	}
+	e := newTestServer(st, handlers.Config{GitHubWebhookSecret: githubSecret})
+
+	for _, tc := range []struct {
+		name string
+		body string
+	}{
+		{name: "opened", body: `{"action":"opened","number":7,"pull_request":{"title":"Fix","user":{"login":"alice"}},"repository":{"full_name":"acme/api"}}`},
+		{name: "merged", body: `{"action":"closed","number":7,"pull_request":{"merged":true,"user":{"login":"alice"}},"repository":{"full_name":"acme/api"}}`},
+		{name: "closed", body: `{"action":"closed","number":7,"pull_request":{"user":{"login":"alice"}},"repository":{"full_name":"acme/api"}}`},
+		{name: "review requested", body: `{"action":"review_requested","number":7,"pull_request":{"user":{"login":"alice"}},"repository":{"full_name":"acme/api"},"requested_reviewer":{"login":"bob"}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got = models.PRRef{}
+
+			rec := serve(e, http.MethodPost, "/webhooks/github", tc.body, githubHeader("delivery-"+tc.name, tc.body))
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, repo+"#7", got.ID)
+			require.NotNil(t, got.Repository, "the PR is looked up in the event repository")
+			assert.Equal(t, repo, *got.Repository)
+		})
+	}
+}
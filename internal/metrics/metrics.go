@@ -0,0 +1,151 @@
+// Package metrics содержит метрики Prometheus: HTTP-метрики и бизнес-счетчики сервиса.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics хранит метрики сервиса и реестр, в котором они зарегистрированы.
+// Реестр передается снаружи, поэтому в тестах можно использовать отдельный prometheus.NewRegistry().
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+
+	// PRsCreated — созданные PR
+	PRsCreated prometheus.Counter
+	// PRsMerged — PR, слитые из статуса OPEN; повторный merge уже слитого PR не считается
+	PRsMerged prometheus.Counter
+	// ReviewersReassigned — переназначенные ревью (вручную и при деактивации)
+	ReviewersReassigned prometheus.Counter
+	// ZeroReviewerAssignments — PR, созданные без единого ревьюера
+	ZeroReviewerAssignments prometheus.Counter
+	// CapacityLimitedAssignments — PR, получившие меньше ревьюеров из-за лимита открытых ревью участников
+	CapacityLimitedAssignments prometheus.Counter
+	// OrphanReviewsSwept — ревью давно деактивированных пользователей, обработанные очисткой, по результату
+	OrphanReviewsSwept *prometheus.CounterVec
+	// WebhookDeliveries — итоги доставки событий исходящих вебхуков по результату
+	WebhookDeliveries *prometheus.CounterVec
+	// DBRetries — повторы операций с БД после временных ошибок Postgres по операции репозитория
+	DBRetries *prometheus.CounterVec
+}
+
+// New создает метрики и регистрирует их в registry вместе с метриками процесса и Go runtime
+func New(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Количество HTTP-запросов по маршруту, методу и статусу.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Длительность обработки HTTP-запросов.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Количество обрабатываемых HTTP-запросов.",
+		}),
+		PRsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prs_created_total",
+			Help: "Количество созданных PR.",
+		}),
+		PRsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prs_merged_total",
+			Help: "Количество PR, переведенных из OPEN в MERGED.",
+		}),
+		ReviewersReassigned: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "reviewers_reassigned_total",
+			Help: "Количество переназначенных ревью.",
+		}),
+		ZeroReviewerAssignments: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "assignments_with_zero_reviewers_total",
+			Help: "Количество PR, созданных без ревьюеров.",
+		}),
+		CapacityLimitedAssignments: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "assignments_capacity_limited_total",
+			Help: "Количество назначений, в которых PR получил меньше ревьюеров из-за лимита открытых ревью (max_open_reviews).",
+		}),
+		OrphanReviewsSwept: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "orphan_reviews_swept_total",
+			Help: "Количество ревью давно деактивированных пользователей, обработанных очисткой: reassigned — переданы другому ревьюеру, unassigned — сняты без замены.",
+		}, []string{"result"}),
+		WebhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Количество событий исходящих вебхуков по результату: delivered, failed, dropped.",
+		}, []string{"result"}),
+		DBRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_retries_total",
+			Help: "Количество повторов операций с БД после временных ошибок Postgres (обрыв соединения, конфликт сериализации, дедлок).",
+		}, []string{"operation"}),
+	}
+
+	registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		m.requests, m.duration, m.inFlight,
+		m.PRsCreated, m.PRsMerged, m.ReviewersReassigned, m.ZeroReviewerAssignments,
+		m.CapacityLimitedAssignments, m.OrphanReviewsSwept,
+		m.WebhookDeliveries, m.DBRetries,
+	)
+
+	return m
+}
+
+// Middleware записывает число, длительность и количество одновременных HTTP-запросов.
+// Маршрут берется из шаблона роута, чтобы ID в query и пути не раздували число серий.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			start := time.Now()
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				// Ошибка еще не записана в ответ: статус определит HTTPErrorHandler
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			labels := prometheus.Labels{
+				"route":  route,
+				"method": c.Request().Method,
+				"status": strconv.Itoa(status),
+			}
+			m.requests.With(labels).Inc()
+			m.duration.With(labels).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// ObserveOrphanSweep учитывает итог запуска очистки ревью давно деактивированных пользователей
+func (m *Metrics) ObserveOrphanSweep(reassigned, unassigned int) {
+	m.OrphanReviewsSwept.WithLabelValues("reassigned").Add(float64(reassigned))
+	m.OrphanReviewsSwept.WithLabelValues("unassigned").Add(float64(unassigned))
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики реестра в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
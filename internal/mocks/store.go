@@ -0,0 +1,488 @@
+// Package mocks содержит ручные моки интерфейсов для модульных тестов без PostgreSQL.
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/handlers"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// ErrNotConfigured возвращается методом мока, для которого не задана функция
+var ErrNotConfigured = errors.New("mock method is not configured")
+
+// Store — мок handlers.Store (и service.Store). Поведение каждого метода задается полем <Метод>Func;
+// незаданные методы возвращают нулевые значения и ErrNotConfigured.
+type Store struct {
+	CreateTeamFunc             func(ctx context.Context, teamData models.Team) (*models.Team, error)
+	CreatePRFunc               func(ctx context.Context, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error)
+	GetPRFunc                  func(ctx context.Context, ref models.PRRef) (*models.PullRequest, error)
+	ReassignReviewerFunc       func(ctx context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (string, error)
+	AddReviewerFunc            func(ctx context.Context, ref models.PRRef, userID string, expectedVersion *int64) (*models.PullRequest, string, error)
+	GetTeamPageFunc            func(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error)
+	ListTeamsFunc              func(ctx context.Context, limit, offset int) ([]models.TeamSummary, int, error)
+	DeleteTeamFunc             func(ctx context.Context, teamName string, force bool) error
+	AddTeamMemberFunc          func(ctx context.Context, teamName string, member models.TeamMember) (*models.Team, error)
+	RemoveTeamMemberFunc       func(ctx context.Context, teamName, userID string) (*models.Team, error)
+	UpdateTeamSettingsFunc     func(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error)
+	GetTeamAssignmentFunc      func(ctx context.Context, teamName string) (*models.AssignmentSettings, error)
+	GetPRTeamSettingsFunc      func(ctx context.Context, pullRequestID string) (*models.TeamSettings, error)
+	GetUserFunc                func(ctx context.Context, userID string) (*models.User, error)
+	UpdateUserStatusFunc       func(ctx context.Context, userID string, isActive bool) error
+	SetUsersActiveBatchFunc    func(ctx context.Context, updates []models.UserActivityUpdate) ([]models.User, []string, error)
+	DeactivateAndReassignFunc  func(ctx context.Context, userID string) (*models.ReassignmentResult, error)
+	GetPRsByReviewerFunc       func(ctx context.Context, reviewerID string, filter repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error)
+	AddVacationFunc            func(ctx context.Context, userID string, from, to time.Time) (*models.Vacation, error)
+	DeleteVacationFunc         func(ctx context.Context, userID string, vacationID int64) error
+	SetAssignmentPausedFunc    func(ctx context.Context, userID string, paused bool, until *time.Time) error
+	SetUserCapacityFunc        func(ctx context.Context, userID string, maxOpenReviews *int) error
+	SetReviewerEligibilityFunc func(ctx context.Context, userID string, isReviewer, reassign bool) (*models.ReassignmentResult, error)
+	AddExclusionFunc           func(ctx context.Context, authorID, reviewerID, reason string) (*models.AssignmentExclusion, error)
+	RemoveExclusionFunc        func(ctx context.Context, authorID, reviewerID string) error
+	ListExclusionsFunc         func(ctx context.Context, userID string) ([]models.AssignmentExclusion, error)
+	GetPRsBatchFunc            func(ctx context.Context, repository *string, pullRequestIDs []string) (map[string]*models.PullRequest, []string, error)
+	GetUnassignedPRsFunc       func(ctx context.Context, teamName string, limit, offset int) ([]models.UnassignedPullRequest, error)
+	PreviewReviewersFunc       func(ctx context.Context, authorID, teamName string, count int) (*models.ReviewerPreview, error)
+	GetOverdueReviewsFunc      func(ctx context.Context, teamName string, limit, offset int) ([]models.OverdueReview, error)
+	ListTeamPRsFunc            func(ctx context.Context, teamName string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error)
+	ListAuthorPRsFunc          func(ctx context.Context, authorID string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error)
+	SearchPRsFunc              func(ctx context.Context, query, teamName, status string, limit, offset int) ([]models.PullRequestSearchResult, int, error)
+	MergePRFunc                func(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, bool, error)
+	ClosePRFunc                func(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, error)
+	ReopenPRFunc               func(ctx context.Context, ref models.PRRef, reassign bool) (*models.PullRequest, error)
+	ApprovePRFunc              func(ctx context.Context, ref models.PRRef, userID string) (*models.PullRequest, error)
+	UpdatePRMetadataFunc       func(ctx context.Context, pullRequestID string, update models.PRMetadataUpdate) (*models.PullRequest, error)
+	GetAssignmentHistoryFunc   func(ctx context.Context, pullRequestID string) ([]models.AssignmentEvent, error)
+	GetUserReviewStatsFunc     func(ctx context.Context) ([]models.UserReviewStats, error)
+	GetUserLoadHistoryFunc     func(ctx context.Context, userID string, from, to time.Time) ([]models.LoadHistorySeries, error)
+	GetTeamLoadHistoryFunc     func(ctx context.Context, teamName string, from, to time.Time) ([]models.LoadHistorySeries, error)
+	GetTeamStatsFunc           func(ctx context.Context, teamName string, since time.Time) (*models.TeamStats, error)
+	GetFairnessReportFunc      func(ctx context.Context, teamName string, since time.Time) (*models.FairnessReport, error)
+	BootstrapFunc              func(ctx context.Context, doc models.BootstrapDocument) (*models.BootstrapResult, error)
+	SweepOrphanedReviewsFunc   func(ctx context.Context, inactiveBefore time.Time, limit int) (*models.OrphanSweepResult, error)
+
+	LinkExternalAccountFunc      func(ctx context.Context, account models.ExternalAccount) error
+	AddExternalAccountFunc       func(ctx context.Context, account models.ExternalAccount) error
+	UnlinkExternalAccountFunc    func(ctx context.Context, provider, accountID string) (*models.ExternalAccount, error)
+	GetUserByExternalAccountFunc func(ctx context.Context, provider, accountID string) (string, error)
+	ReserveWebhookDeliveryFunc   func(ctx context.Context, provider, deliveryID string, ttl time.Duration) (bool, error)
+	ReleaseWebhookDeliveryFunc   func(ctx context.Context, provider, deliveryID string) error
+
+	CreateWebhookFunc       func(ctx context.Context, hook models.Webhook) (*models.Webhook, error)
+	GetWebhookFunc          func(ctx context.Context, webhookID int64) (*models.Webhook, error)
+	ListWebhooksFunc        func(ctx context.Context) ([]models.Webhook, error)
+	UpdateWebhookFunc       func(ctx context.Context, webhookID int64, update models.WebhookUpdate) (*models.Webhook, error)
+	DeleteWebhookFunc       func(ctx context.Context, webhookID int64) error
+	ListWebhookAttemptsFunc func(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookAttempt, int, error)
+}
+
+var _ handlers.Store = (*Store)(nil)
+
+func (m *Store) CreateTeam(ctx context.Context, teamData models.Team) (*models.Team, error) {
+	if m.CreateTeamFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.CreateTeamFunc(ctx, teamData)
+}
+
+func (m *Store) CreatePR(ctx context.Context, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error) {
+	if m.CreatePRFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.CreatePRFunc(ctx, repository, pullRequestID, pullRequestName, authorID, teamName, meta)
+}
+
+func (m *Store) GetPR(ctx context.Context, ref models.PRRef) (*models.PullRequest, error) {
+	if m.GetPRFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetPRFunc(ctx, ref)
+}
+
+func (m *Store) ReassignReviewer(ctx context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (string, error) {
+	if m.ReassignReviewerFunc == nil {
+		return "", ErrNotConfigured
+	}
+	return m.ReassignReviewerFunc(ctx, ref, oldReviewerID, newReviewerID, expectedVersion)
+}
+
+func (m *Store) AddReviewer(ctx context.Context, ref models.PRRef, userID string, expectedVersion *int64) (*models.PullRequest, string, error) {
+	if m.AddReviewerFunc == nil {
+		return nil, "", ErrNotConfigured
+	}
+	return m.AddReviewerFunc(ctx, ref, userID, expectedVersion)
+}
+
+func (m *Store) GetTeamPage(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error) {
+	if m.GetTeamPageFunc == nil {
+		return nil, 0, ErrNotConfigured
+	}
+	return m.GetTeamPageFunc(ctx, teamName, limit, offset)
+}
+
+func (m *Store) ListTeams(ctx context.Context, limit, offset int) ([]models.TeamSummary, int, error) {
+	if m.ListTeamsFunc == nil {
+		return nil, 0, ErrNotConfigured
+	}
+	return m.ListTeamsFunc(ctx, limit, offset)
+}
+
+func (m *Store) DeleteTeam(ctx context.Context, teamName string, force bool) error {
+	if m.DeleteTeamFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.DeleteTeamFunc(ctx, teamName, force)
+}
+
+func (m *Store) AddTeamMember(ctx context.Context, teamName string, member models.TeamMember) (*models.Team, error) {
+	if m.AddTeamMemberFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.AddTeamMemberFunc(ctx, teamName, member)
+}
+
+func (m *Store) RemoveTeamMember(ctx context.Context, teamName, userID string) (*models.Team, error) {
+	if m.RemoveTeamMemberFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.RemoveTeamMemberFunc(ctx, teamName, userID)
+}
+
+func (m *Store) UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error) {
+	if m.UpdateTeamSettingsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.UpdateTeamSettingsFunc(ctx, settings)
+}
+
+func (m *Store) GetTeamAssignment(ctx context.Context, teamName string) (*models.AssignmentSettings, error) {
+	if m.GetTeamAssignmentFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetTeamAssignmentFunc(ctx, teamName)
+}
+
+func (m *Store) GetPRTeamSettings(ctx context.Context, pullRequestID string) (*models.TeamSettings, error) {
+	if m.GetPRTeamSettingsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetPRTeamSettingsFunc(ctx, pullRequestID)
+}
+
+func (m *Store) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if m.GetUserFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetUserFunc(ctx, userID)
+}
+
+func (m *Store) UpdateUserStatus(ctx context.Context, userID string, isActive bool) error {
+	if m.UpdateUserStatusFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.UpdateUserStatusFunc(ctx, userID, isActive)
+}
+
+func (m *Store) SetUsersActiveBatch(ctx context.Context, updates []models.UserActivityUpdate) ([]models.User, []string, error) {
+	if m.SetUsersActiveBatchFunc == nil {
+		return nil, nil, ErrNotConfigured
+	}
+	return m.SetUsersActiveBatchFunc(ctx, updates)
+}
+
+func (m *Store) DeactivateAndReassign(ctx context.Context, userID string) (*models.ReassignmentResult, error) {
+	if m.DeactivateAndReassignFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.DeactivateAndReassignFunc(ctx, userID)
+}
+
+func (m *Store) GetPRsByReviewer(ctx context.Context, reviewerID string, filter repository.ReviewFilter) ([]models.PullRequestShort, repository.PageInfo, error) {
+	if m.GetPRsByReviewerFunc == nil {
+		return nil, repository.PageInfo{}, ErrNotConfigured
+	}
+	return m.GetPRsByReviewerFunc(ctx, reviewerID, filter)
+}
+
+func (m *Store) AddVacation(ctx context.Context, userID string, from, to time.Time) (*models.Vacation, error) {
+	if m.AddVacationFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.AddVacationFunc(ctx, userID, from, to)
+}
+
+func (m *Store) DeleteVacation(ctx context.Context, userID string, vacationID int64) error {
+	if m.DeleteVacationFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.DeleteVacationFunc(ctx, userID, vacationID)
+}
+
+func (m *Store) SetAssignmentPaused(ctx context.Context, userID string, paused bool, until *time.Time) error {
+	if m.SetAssignmentPausedFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.SetAssignmentPausedFunc(ctx, userID, paused, until)
+}
+
+func (m *Store) SetUserCapacity(ctx context.Context, userID string, maxOpenReviews *int) error {
+	if m.SetUserCapacityFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.SetUserCapacityFunc(ctx, userID, maxOpenReviews)
+}
+
+func (m *Store) SetReviewerEligibility(ctx context.Context, userID string, isReviewer, reassign bool) (*models.ReassignmentResult, error) {
+	if m.SetReviewerEligibilityFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.SetReviewerEligibilityFunc(ctx, userID, isReviewer, reassign)
+}
+
+func (m *Store) AddExclusion(ctx context.Context, authorID, reviewerID, reason string) (*models.AssignmentExclusion, error) {
+	if m.AddExclusionFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.AddExclusionFunc(ctx, authorID, reviewerID, reason)
+}
+
+func (m *Store) RemoveExclusion(ctx context.Context, authorID, reviewerID string) error {
+	if m.RemoveExclusionFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.RemoveExclusionFunc(ctx, authorID, reviewerID)
+}
+
+func (m *Store) ListExclusions(ctx context.Context, userID string) ([]models.AssignmentExclusion, error) {
+	if m.ListExclusionsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.ListExclusionsFunc(ctx, userID)
+}
+
+func (m *Store) GetPRsBatch(ctx context.Context, repository *string, pullRequestIDs []string) (map[string]*models.PullRequest, []string, error) {
+	if m.GetPRsBatchFunc == nil {
+		return nil, nil, ErrNotConfigured
+	}
+	return m.GetPRsBatchFunc(ctx, repository, pullRequestIDs)
+}
+
+func (m *Store) GetUnassignedPRs(ctx context.Context, teamName string, limit, offset int) ([]models.UnassignedPullRequest, error) {
+	if m.GetUnassignedPRsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetUnassignedPRsFunc(ctx, teamName, limit, offset)
+}
+
+func (m *Store) PreviewReviewers(ctx context.Context, authorID, teamName string, count int) (*models.ReviewerPreview, error) {
+	if m.PreviewReviewersFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.PreviewReviewersFunc(ctx, authorID, teamName, count)
+}
+
+func (m *Store) GetOverdueReviews(ctx context.Context, teamName string, limit, offset int) ([]models.OverdueReview, error) {
+	if m.GetOverdueReviewsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetOverdueReviewsFunc(ctx, teamName, limit, offset)
+}
+
+func (m *Store) ListTeamPRs(ctx context.Context, teamName string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error) {
+	if m.ListTeamPRsFunc == nil {
+		return nil, repository.PageInfo{}, ErrNotConfigured
+	}
+	return m.ListTeamPRsFunc(ctx, teamName, filter)
+}
+
+func (m *Store) ListAuthorPRs(ctx context.Context, authorID string, filter repository.PRListFilter) ([]models.TeamPullRequest, repository.PageInfo, error) {
+	if m.ListAuthorPRsFunc == nil {
+		return nil, repository.PageInfo{}, ErrNotConfigured
+	}
+	return m.ListAuthorPRsFunc(ctx, authorID, filter)
+}
+
+func (m *Store) SearchPRs(ctx context.Context, query, teamName, status string, limit, offset int) ([]models.PullRequestSearchResult, int, error) {
+	if m.SearchPRsFunc == nil {
+		return nil, 0, ErrNotConfigured
+	}
+	return m.SearchPRsFunc(ctx, query, teamName, status, limit, offset)
+}
+
+func (m *Store) MergePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, bool, error) {
+	if m.MergePRFunc == nil {
+		return nil, false, ErrNotConfigured
+	}
+	return m.MergePRFunc(ctx, ref, expectedVersion)
+}
+
+func (m *Store) ClosePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, error) {
+	if m.ClosePRFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.ClosePRFunc(ctx, ref, expectedVersion)
+}
+
+func (m *Store) ReopenPR(ctx context.Context, ref models.PRRef, reassign bool) (*models.PullRequest, error) {
+	if m.ReopenPRFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.ReopenPRFunc(ctx, ref, reassign)
+}
+
+func (m *Store) ApprovePR(ctx context.Context, ref models.PRRef, userID string) (*models.PullRequest, error) {
+	if m.ApprovePRFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.ApprovePRFunc(ctx, ref, userID)
+}
+
+func (m *Store) UpdatePRMetadata(ctx context.Context, pullRequestID string, update models.PRMetadataUpdate) (*models.PullRequest, error) {
+	if m.UpdatePRMetadataFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.UpdatePRMetadataFunc(ctx, pullRequestID, update)
+}
+
+func (m *Store) GetAssignmentHistory(ctx context.Context, pullRequestID string) ([]models.AssignmentEvent, error) {
+	if m.GetAssignmentHistoryFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetAssignmentHistoryFunc(ctx, pullRequestID)
+}
+
+func (m *Store) GetUserReviewStats(ctx context.Context) ([]models.UserReviewStats, error) {
+	if m.GetUserReviewStatsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetUserReviewStatsFunc(ctx)
+}
+
+func (m *Store) GetUserLoadHistory(ctx context.Context, userID string, from, to time.Time) ([]models.LoadHistorySeries, error) {
+	if m.GetUserLoadHistoryFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetUserLoadHistoryFunc(ctx, userID, from, to)
+}
+
+func (m *Store) GetTeamLoadHistory(ctx context.Context, teamName string, from, to time.Time) ([]models.LoadHistorySeries, error) {
+	if m.GetTeamLoadHistoryFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetTeamLoadHistoryFunc(ctx, teamName, from, to)
+}
+
+func (m *Store) GetTeamStats(ctx context.Context, teamName string, since time.Time) (*models.TeamStats, error) {
+	if m.GetTeamStatsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetTeamStatsFunc(ctx, teamName, since)
+}
+
+func (m *Store) GetFairnessReport(ctx context.Context, teamName string, since time.Time) (*models.FairnessReport, error) {
+	if m.GetFairnessReportFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetFairnessReportFunc(ctx, teamName, since)
+}
+
+func (m *Store) Bootstrap(ctx context.Context, doc models.BootstrapDocument) (*models.BootstrapResult, error) {
+	if m.BootstrapFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.BootstrapFunc(ctx, doc)
+}
+
+func (m *Store) SweepOrphanedReviews(ctx context.Context, inactiveBefore time.Time, limit int) (*models.OrphanSweepResult, error) {
+	if m.SweepOrphanedReviewsFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.SweepOrphanedReviewsFunc(ctx, inactiveBefore, limit)
+}
+
+func (m *Store) LinkExternalAccount(ctx context.Context, account models.ExternalAccount) error {
+	if m.LinkExternalAccountFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.LinkExternalAccountFunc(ctx, account)
+}
+
+func (m *Store) AddExternalAccount(ctx context.Context, account models.ExternalAccount) error {
+	if m.AddExternalAccountFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.AddExternalAccountFunc(ctx, account)
+}
+
+func (m *Store) UnlinkExternalAccount(ctx context.Context, provider, accountID string) (*models.ExternalAccount, error) {
+	if m.UnlinkExternalAccountFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.UnlinkExternalAccountFunc(ctx, provider, accountID)
+}
+
+func (m *Store) GetUserByExternalAccount(ctx context.Context, provider, accountID string) (string, error) {
+	if m.GetUserByExternalAccountFunc == nil {
+		return "", ErrNotConfigured
+	}
+	return m.GetUserByExternalAccountFunc(ctx, provider, accountID)
+}
+
+func (m *Store) ReserveWebhookDelivery(ctx context.Context, provider, deliveryID string, ttl time.Duration) (bool, error) {
+	if m.ReserveWebhookDeliveryFunc == nil {
+		return false, ErrNotConfigured
+	}
+	return m.ReserveWebhookDeliveryFunc(ctx, provider, deliveryID, ttl)
+}
+
+func (m *Store) ReleaseWebhookDelivery(ctx context.Context, provider, deliveryID string) error {
+	if m.ReleaseWebhookDeliveryFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.ReleaseWebhookDeliveryFunc(ctx, provider, deliveryID)
+}
+
+func (m *Store) CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error) {
+	if m.CreateWebhookFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.CreateWebhookFunc(ctx, hook)
+}
+
+func (m *Store) GetWebhook(ctx context.Context, webhookID int64) (*models.Webhook, error) {
+	if m.GetWebhookFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.GetWebhookFunc(ctx, webhookID)
+}
+
+func (m *Store) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	if m.ListWebhooksFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.ListWebhooksFunc(ctx)
+}
+
+func (m *Store) UpdateWebhook(ctx context.Context, webhookID int64, update models.WebhookUpdate) (*models.Webhook, error) {
+	if m.UpdateWebhookFunc == nil {
+		return nil, ErrNotConfigured
+	}
+	return m.UpdateWebhookFunc(ctx, webhookID, update)
+}
+
+func (m *Store) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	if m.DeleteWebhookFunc == nil {
+		return ErrNotConfigured
+	}
+	return m.DeleteWebhookFunc(ctx, webhookID)
+}
+
+func (m *Store) ListWebhookAttempts(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookAttempt, int, error) {
+	if m.ListWebhookAttemptsFunc == nil {
+		return nil, 0, ErrNotConfigured
+	}
+	return m.ListWebhookAttemptsFunc(ctx, webhookID, limit, offset)
+}
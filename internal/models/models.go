@@ -1,46 +1,383 @@
 // models/models.go
 package models
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 // TeamMember представляет участника команды
 type TeamMember struct {
-    UserID   string `json:"user_id" db:"user_id"`
-    Username string `json:"username" db:"username"`
-    IsActive bool   `json:"is_active" db:"is_active"`
+	UserID   string `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	IsActive bool   `json:"is_active" db:"is_active"`
+	// SlackUserID — ID участника Slack (U…/W…) для упоминаний; пустой при сохранении не стирает прежний
+	SlackUserID string `json:"slack_user_id,omitempty" db:"slack_user_id"`
+	// MaxOpenReviews — сколько открытых ревью можно назначить участнику одновременно (nil — без ограничения);
+	// nil при сохранении не стирает прежний лимит
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty" db:"max_open_reviews"`
+	// IsReviewer — участвует ли участник в автоматическом назначении ревьюеров; nil при сохранении
+	// нового пользователя означает true, существующего — прежнее значение. В ответах заполнен всегда.
+	IsReviewer *bool `json:"is_reviewer,omitempty" db:"is_reviewer"`
+	// Role — роль участника в команде (TeamRoleMember или TeamRoleLead); пустая при сохранении означает
+	// TeamRoleMember. В ответах заполнена всегда.
+	Role string `json:"role,omitempty" db:"role"`
+	// AssignmentPaused — действует ли приостановка автоназначения участника (POST /admin/users/pauseAssignment);
+	// заполнен только в ответах администратору
+	AssignmentPaused *bool `json:"assignment_paused,omitempty" db:"-"`
+	// AssignmentPausedUntil — когда истекает действующая приостановка (nil — бессрочно или не приостановлен)
+	AssignmentPausedUntil *time.Time `json:"assignment_paused_until,omitempty" db:"-"`
+}
+
+// Роли участника команды
+const (
+	TeamRoleMember = "member"
+	TeamRoleLead   = "lead"
+)
+
+// ValidTeamRole сообщает, допустима ли роль участника команды; пустая роль означает TeamRoleMember
+func ValidTeamRole(role string) bool {
+	return role == "" || role == TeamRoleMember || role == TeamRoleLead
+}
+
+// slackUserIDPattern — формат ID участника Slack
+var slackUserIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// ValidSlackUserID сообщает, похож ли id на ID участника Slack; пустой ID допустим
+func ValidSlackUserID(id string) bool {
+	return id == "" || slackUserIDPattern.MatchString(id)
+}
+
+// ValidMaxOpenReviews сообщает, допустим ли лимит открытых ревью: положительное число или nil
+func ValidMaxOpenReviews(limit *int) bool {
+	return limit == nil || *limit > 0
 }
 
 // Team представляет команду с участниками
 type Team struct {
-    TeamName string       `json:"team_name" db:"team_name"`
-    Members  []TeamMember `json:"members" db:"-"`
+	TeamName string       `json:"team_name" db:"team_name"`
+	Members  []TeamMember `json:"members" db:"-"`
+}
+
+// TeamSummary представляет краткую информацию о команде для списков
+type TeamSummary struct {
+	TeamName     string    `json:"team_name" db:"name"`
+	MembersCount int       `json:"members_count" db:"members_count"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// TeamSettings представляет настройки команды
+type TeamSettings struct {
+	TeamName             string  `json:"team_name"`
+	AnnouncementTemplate *string `json:"announcement_template,omitempty"`
+	// ReviewSLAHours — за сколько часов ревьюер должен взяться за PR; nil — SLA не задан
+	ReviewSLAHours *int `json:"review_sla_hours,omitempty"`
+	// ReviewersPerPR — сколько ревьюеров назначается на новый PR команды; nil — значение по умолчанию
+	ReviewersPerPR *int `json:"reviewers_per_pr,omitempty"`
+	// Strategy — стратегия выбора ревьюеров команды; nil — ASSIGNMENT_STRATEGY
+	Strategy *string `json:"strategy,omitempty"`
+	// AlwaysIncludeLead — назначать ли лидера команды одним из ревьюеров каждого нового PR; nil — нет
+	AlwaysIncludeLead *bool `json:"always_include_lead,omitempty"`
+}
+
+// AssignmentSettings — действующие правила назначения ревьюеров команды с учетом значений по умолчанию
+type AssignmentSettings struct {
+	ReviewersPerPR    int    `json:"reviewers_per_pr"`
+	Strategy          string `json:"strategy"`
+	AlwaysIncludeLead bool   `json:"always_include_lead"`
+}
+
+// ReviewerCandidate — кандидат в ревьюеры из предпросмотра назначения
+type ReviewerCandidate struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	// Source — откуда был бы назначен: ReviewerSourceTeam или ReviewerSourceFallback
+	Source string `json:"source"`
+	// OpenReviews — в скольких открытых PR кандидат уже назначен ревьюером
+	OpenReviews int `json:"open_reviews"`
+}
+
+// ReviewerPreview — кто был бы назначен ревьюером на новый PR автора, без создания PR
+type ReviewerPreview struct {
+	AuthorID string `json:"author_id"`
+	TeamName string `json:"team_name"`
+	Strategy string `json:"strategy"`
+	// Count — сколько ревьюеров запрошено (по умолчанию reviewers_per_pr команды)
+	Count      int                 `json:"count"`
+	Candidates []ReviewerCandidate `json:"candidates"`
+	// PausedMembers — участники команды, исключенные из выбора действующей приостановкой автоназначения;
+	// заполнен только в ответах администратору
+	PausedMembers []string `json:"paused_members,omitempty"`
 }
 
 // User представляет пользователя с принадлежностью к команде
 type User struct {
-    UserID   string `json:"user_id" db:"user_id"`
-    Username string `json:"username" db:"username"`
-    TeamName string `json:"team_name" db:"team_name"`
-    IsActive bool   `json:"is_active" db:"is_active"`
+	UserID   string `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	TeamName string `json:"team_name" db:"team_name"`
+	IsActive bool   `json:"is_active" db:"is_active"`
+	// IsReviewer — участвует ли пользователь в автоматическом назначении ревьюеров
+	IsReviewer bool       `json:"is_reviewer" db:"is_reviewer"`
+	Vacations  []Vacation `json:"vacations" db:"-"`
+	// ExternalAccounts — учетные записи пользователя во внешних системах (GitHub, GitLab)
+	ExternalAccounts []ExternalAccount `json:"external_accounts" db:"-"`
+	// AssignmentPaused — действует ли приостановка автоназначения пользователя; заполнен только в ответах администратору
+	AssignmentPaused *bool `json:"assignment_paused,omitempty" db:"-"`
+	// AssignmentPausedUntil — когда истекает действующая приостановка (nil — бессрочно или не приостановлен)
+	AssignmentPausedUntil *time.Time `json:"assignment_paused_until,omitempty" db:"-"`
+}
+
+// UserActivityUpdate — элемент пакетного изменения статуса активности пользователей
+type UserActivityUpdate struct {
+	UserID   string `json:"user_id"`
+	IsActive bool   `json:"is_active"`
+}
+
+// Vacation представляет отпуск пользователя: на интервале [From, To) он не назначается ревьюером
+type Vacation struct {
+	VacationID int64     `json:"vacation_id" db:"id"`
+	From       time.Time `json:"from" db:"starts_at"`
+	To         time.Time `json:"to" db:"ends_at"`
+}
+
+// AssignmentExclusion — запрет назначать ReviewerID ревьюером PR автора AuthorID (в обратную сторону не действует)
+type AssignmentExclusion struct {
+	AuthorID   string    `json:"author_id" db:"author_id"`
+	ReviewerID string    `json:"reviewer_id" db:"reviewer_id"`
+	Reason     string    `json:"reason" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
 // PullRequest представляет PR с полной информацией
 type PullRequest struct {
-    PullRequestID      string    `json:"pull_request_id" db:"pull_request_id"`
-    PullRequestName    string    `json:"pull_request_name" db:"pull_request_name"`
-    AuthorID           string    `json:"author_id" db:"author_id"`
-    Status             string    `json:"status" db:"status"`
-    AssignedReviewers  []string  `json:"assigned_reviewers" db:"-"`
-    CreatedAt          *time.Time `json:"createdAt,omitempty" db:"created_at"`
-    MergedAt           *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	PullRequestID string `json:"pull_request_id" db:"pull_request_id"`
+	// Repository — репозиторий (проект) PR; внешний ID PR уникален в его пределах, пустой — репозиторий не задан
+	Repository        string             `json:"repository" db:"repository"`
+	PullRequestName   string             `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID          string             `json:"author_id" db:"author_id"`
+	Status            string             `json:"status" db:"status"`
+	AssignedReviewers []AssignedReviewer `json:"assigned_reviewers" db:"-"`
+	// AssignedReviewerIDs — плоский список user_id ревьюеров для клиентов старого формата
+	AssignedReviewerIDs []string   `json:"assigned_reviewer_ids" db:"-"`
+	CreatedAt           *time.Time `json:"createdAt,omitempty" db:"created_at"`
+	MergedAt            *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	ClosedAt            *time.Time `json:"closedAt,omitempty" db:"closed_at"`
+	// CreatedBy, MergedBy и ClosedBy — инициаторы создания, слияния и закрытия PR (X-Actor-Id или actor_id,
+	// без них — api); не заданы у PR, измененных до появления этих полей
+	CreatedBy *string `json:"createdBy,omitempty" db:"created_by"`
+	MergedBy  *string `json:"mergedBy,omitempty" db:"merged_by"`
+	ClosedBy  *string `json:"closedBy,omitempty" db:"closed_by"`
+	// Version растет при каждом изменении PR и его ревьюеров; передается в expected_version или If-Match,
+	// чтобы изменение не затерло чужое. Сравнивается только на равенство: шаг роста не гарантируется
+	Version int64 `json:"version" db:"version"`
+	PRMetadata
+}
+
+// MaxRepositoryLength — максимальная длина имени репозитория PR в символах
+const MaxRepositoryLength = 255
+
+// ValidRepository сообщает, допустимо ли имя репозитория PR: не длиннее MaxRepositoryLength символов или nil
+func ValidRepository(repository *string) bool {
+	return repository == nil || utf8.RuneCountInString(*repository) <= MaxRepositoryLength
+}
+
+// PRRef ссылается на PR по внешнему ID. Repository == nil — репозиторий не указан: PR ищется
+// только по ID, и ссылка однозначна, пока ID есть лишь в одном репозитории.
+type PRRef struct {
+	Repository *string
+	ID         string
+}
+
+// PRMetadata — необязательные сведения о PR из внешней системы; пустые поля не возвращаются
+type PRMetadata struct {
+	Description  string `json:"description,omitempty" db:"description"`
+	SourceBranch string `json:"source_branch,omitempty" db:"source_branch"`
+	TargetBranch string `json:"target_branch,omitempty" db:"target_branch"`
+	URL          string `json:"url,omitempty" db:"url"`
+	// Labels — метки PR в нижнем регистре, без повторов
+	Labels []string `json:"labels,omitempty" db:"labels"`
+}
+
+// PRMetadataUpdate — частичное изменение сведений о PR: nil-поля остаются прежними, пустая строка очищает поле
+type PRMetadataUpdate struct {
+	Description  *string `json:"description"`
+	SourceBranch *string `json:"source_branch"`
+	TargetBranch *string `json:"target_branch"`
+	URL          *string `json:"url"`
+	// Labels заменяет метки PR целиком; пустой массив удаляет все метки
+	Labels *[]string `json:"labels"`
+}
+
+// AssignedReviewer представляет назначенного на PR ревьюера, его имя, активность и одобрение
+type AssignedReviewer struct {
+	UserID     string     `json:"user_id" db:"user_id"`
+	Username   string     `json:"username" db:"username"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	Approved   bool       `json:"approved" db:"approved"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty" db:"approved_at"`
+	// Source — откуда назначен ревьюер: ReviewerSourceTeam или ReviewerSourceFallback
+	Source string `json:"source" db:"source"`
+	// ReviewDueAt — срок ревью по SLA команды PR; nil, если у команды нет SLA
+	ReviewDueAt *time.Time `json:"review_due_at,omitempty" db:"review_due_at"`
+}
+
+// ReviewReassignment описывает переназначение ревью в PR на нового ревьюера
+type ReviewReassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+// ReassignmentResult описывает итог массового переназначения ревью пользователя
+type ReassignmentResult struct {
+	Reassigned    []ReviewReassignment `json:"reassigned"`
+	NotReassigned []string             `json:"not_reassigned"`
+	// SkippedRecent — PR, где пользователь назначен недавно (моложе MIN_ASSIGNMENT_AGE_HOURS) и остался ревьюером
+	SkippedRecent []string `json:"skipped_recent"`
+}
+
+// SweptReview — ревью давно деактивированного пользователя, обработанное очисткой
+type SweptReview struct {
+	PullRequestID string `json:"pull_request_id"`
+	Repository    string `json:"repository"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	// NewReviewerID — назначенная замена; пустой, если кандидата не нашлось и ревьюер просто снят
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+// OrphanSweepResult описывает итог одного запуска очистки ревью давно деактивированных пользователей
+type OrphanSweepResult struct {
+	// Reassigned — ревью, переданные другим ревьюерам
+	Reassigned []SweptReview `json:"reassigned"`
+	// Unassigned — ревьюеры сняты без замены: подходящих кандидатов нет
+	Unassigned []SweptReview `json:"unassigned"`
+	// SkippedRecent — ревью, назначенные недавно (моложе MIN_ASSIGNMENT_AGE_HOURS); остаются до следующих запусков
+	SkippedRecent []SweptReview `json:"skipped_recent"`
+}
+
+// IdempotencyKey идентифицирует запрос с заголовком Idempotency-Key: ключ действует в пределах метода и пути
+type IdempotencyKey struct {
+	Key    string
+	Method string
+	// Path — путь без префикса версии API
+	Path string
+}
+
+// IdempotencyRecord — сохраненный результат запроса по ключу идемпотентности.
+// StatusCode == 0 означает, что первый запрос с этим ключом еще выполняется.
+type IdempotencyRecord struct {
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+}
+
+// BootstrapPullRequest описывает PR в документе начального заполнения
+type BootstrapPullRequest struct {
+	PullRequestID   string `json:"pull_request_id"`
+	Repository      string `json:"repository"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+}
+
+// BootstrapDocument описывает команды и PR, создаваемые одной транзакцией
+type BootstrapDocument struct {
+	Teams        []Team                 `json:"teams"`
+	PullRequests []BootstrapPullRequest `json:"pull_requests"`
+}
+
+// BootstrapResult содержит созданные при начальном заполнении сущности
+type BootstrapResult struct {
+	Teams        []Team        `json:"teams"`
+	PullRequests []PullRequest `json:"pull_requests"`
 }
 
 // PullRequestShort представляет краткую информацию о PR
 type PullRequestShort struct {
-    PullRequestID   string `json:"pull_request_id" db:"pull_request_id"`
-    PullRequestName string `json:"pull_request_name" db:"pull_request_name"`
-    AuthorID        string `json:"author_id" db:"author_id"`
-    Status          string `json:"status" db:"status"`
+	PullRequestID   string `json:"pull_request_id" db:"pull_request_id"`
+	Repository      string `json:"repository" db:"repository"`
+	PullRequestName string `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID        string `json:"author_id" db:"author_id"`
+	Status          string `json:"status" db:"status"`
+}
+
+// PullRequestSearchResult представляет PR, найденный поиском по названию
+type PullRequestSearchResult struct {
+	PullRequestShort
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TeamPullRequest представляет PR из списка PR команды или автора с текущими ревьюерами
+type TeamPullRequest struct {
+	PullRequestID       string   `json:"pull_request_id" db:"pull_request_id"`
+	Repository          string   `json:"repository" db:"repository"`
+	PullRequestName     string   `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID            string   `json:"author_id" db:"author_id"`
+	Status              string   `json:"status" db:"status"`
+	AssignedReviewerIDs []string `json:"assigned_reviewer_ids" db:"assigned_reviewer_ids"`
+	// AssignedReviewers — ревьюеры с именами, активностью и одобрениями, в порядке assigned_reviewer_ids
+	AssignedReviewers []AssignedReviewer `json:"assigned_reviewers" db:"-"`
+	Labels            []string           `json:"labels" db:"labels"`
+	CreatedAt         time.Time          `json:"createdAt" db:"created_at"`
+	MergedAt          *time.Time         `json:"mergedAt,omitempty" db:"merged_at"`
+}
+
+// UnassignedPullRequest представляет открытый PR без назначенных ревьюеров
+type UnassignedPullRequest struct {
+	PullRequestID   string    `json:"pull_request_id" db:"pull_request_id"`
+	Repository      string    `json:"repository" db:"repository"`
+	PullRequestName string    `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID        string    `json:"author_id" db:"author_id"`
+	AuthorName      string    `json:"author_name" db:"author_name"`
+	TeamName        string    `json:"team_name" db:"team_name"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+	// AgeSeconds — сколько секунд PR открыт
+	AgeSeconds int64 `json:"age_seconds" db:"age_seconds"`
+}
+
+// OverdueReview представляет назначение ревьюера в открытом PR, просроченное по SLA команды
+type OverdueReview struct {
+	PullRequestID   string    `json:"pull_request_id" db:"pull_request_id"`
+	Repository      string    `json:"repository" db:"repository"`
+	PullRequestName string    `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID        string    `json:"author_id" db:"author_id"`
+	ReviewerID      string    `json:"reviewer_id" db:"reviewer_id"`
+	ReviewerName    string    `json:"reviewer_name" db:"reviewer_name"`
+	AssignedAt      time.Time `json:"assigned_at" db:"assigned_at"`
+	ReviewDueAt     time.Time `json:"review_due_at" db:"review_due_at"`
+	// OverdueHours — сколько полных часов прошло после review_due_at
+	OverdueHours int64 `json:"overdue_hours" db:"overdue_hours"`
+}
+
+// Причины изменения ревьюеров PR в журнале назначений
+const (
+	// AssignmentReasonAutoInitial — автоматическое назначение при создании или переоткрытии PR
+	AssignmentReasonAutoInitial = "AUTO_INITIAL"
+	// AssignmentReasonAutoReassign — автоматическая замена через /pullRequest/reassign
+	AssignmentReasonAutoReassign = "AUTO_REASSIGN"
+	// AssignmentReasonManual — ручной выбор ревьюера (reassign с new_user_id, addReviewer)
+	AssignmentReasonManual = "MANUAL"
+	// AssignmentReasonDeactivation — замена ревьюера при деактивации или исключении из команды
+	AssignmentReasonDeactivation = "DEACTIVATION"
+	// AssignmentReasonDeactivationSweep — замена ревьюера, давно деактивированного без переназначения ревью,
+	// фоновой очисткой или POST /admin/sweepOrphans
+	AssignmentReasonDeactivationSweep = "DEACTIVATION_SWEEP"
+)
+
+// AssignmentEvent — запись журнала изменений ревьюеров PR с внешними ID пользователей
+type AssignmentEvent struct {
+	EventID int64  `json:"event_id" db:"event_id"`
+	Actor   string `json:"actor" db:"actor"`
+	// OldReviewerID — снятый ревьюер; nil при назначении без замены
+	OldReviewerID *string `json:"old_reviewer_id" db:"old_reviewer_id"`
+	// NewReviewerID — назначенный ревьюер; nil, если замену найти не удалось
+	NewReviewerID *string   `json:"new_reviewer_id" db:"new_reviewer_id"`
+	Reason        string    `json:"reason" db:"reason"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
 
 // UserReviewStats представляет статистику по назначениям ревью.
@@ -50,8 +387,190 @@ type UserReviewStats struct {
 	ReviewCount int    `json:"review_count" db:"review_count"`
 }
 
+// TeamStats представляет агрегированную статистику PR команды (авторы — участники команды)
+type TeamStats struct {
+	TeamName string `json:"team_name"`
+	// Since — начало окна для merged_prs, времени до слияния и до первого одобрения и среднего числа ревьюеров
+	Since     string `json:"since"`
+	OpenPRs   int    `json:"open_prs"`
+	MergedPRs int    `json:"merged_prs"`
+	// AvgTimeToMerge и P90TimeToMerge — от created_at до merged_at; nil, если в окне нет слитых PR
+	AvgTimeToMerge *DurationStat `json:"avg_time_to_merge"`
+	P90TimeToMerge *DurationStat `json:"p90_time_to_merge"`
+	// AvgTimeToFirstReview — от created_at до первого одобрения у PR, созданных в окне; nil, если одобрений нет
+	AvgTimeToFirstReview *DurationStat `json:"avg_time_to_first_review"`
+	// AvgReviewersPerPR — среднее число текущих ревьюеров у PR, созданных в окне
+	AvgReviewersPerPR float64 `json:"avg_reviewers_per_pr"`
+}
+
+// DurationStat представляет длительность в секундах и в читаемом виде (например, "1d 2h 5m")
+type DurationStat struct {
+	Seconds int64  `json:"seconds"`
+	Human   string `json:"human"`
+}
+
+// NewDurationStat создает DurationStat из числа секунд, округляя до секунды
+func NewDurationStat(seconds float64) *DurationStat {
+	total := int64(math.Round(seconds))
+	if total < 60 {
+		return &DurationStat{Seconds: total, Human: fmt.Sprintf("%ds", total)}
+	}
+
+	days, rest := total/86400, total%86400
+	hours, minutes := rest/3600, rest%3600/60
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return &DurationStat{Seconds: total, Human: strings.Join(parts, " ")}
+}
+
+// FairnessReport представляет распределение назначений ревьюеров между активными участниками команды
+type FairnessReport struct {
+	TeamName string `json:"team_name"`
+	// Since — начало окна, в котором считаются назначения
+	Since            string `json:"since"`
+	TotalAssignments int    `json:"total_assignments"`
+	// Gini — коэффициент Джини числа назначений: 0 — поровну, ближе к 1 — назначения достаются немногим;
+	// nil, если в окне не было назначений
+	Gini    *float64                `json:"gini"`
+	Members []MemberAssignmentShare `json:"members"`
+}
+
+// MemberAssignmentShare представляет число назначений участника команды в окне и его долю от всех назначений
+type MemberAssignmentShare struct {
+	UserID      string  `json:"user_id"`
+	Username    string  `json:"username"`
+	Assignments int     `json:"assignments"`
+	Share       float64 `json:"share"`
+}
+
+// LoadHistoryPoint представляет число открытых ревью пользователя на дату снимка
+type LoadHistoryPoint struct {
+	Date        string `json:"date"`
+	OpenReviews int    `json:"open_reviews"`
+}
+
+// LoadHistorySeries представляет временной ряд загрузки одного ревьюера
+type LoadHistorySeries struct {
+	UserID   string             `json:"user_id"`
+	Username string             `json:"username"`
+	Points   []LoadHistoryPoint `json:"points"`
+}
+
+// ExternalAccount связывает пользователя с его учетной записью во внешней системе
+type ExternalAccount struct {
+	UserID    string `json:"user_id" db:"user_id"`
+	Provider  string `json:"provider" db:"provider"`
+	AccountID string `json:"account_id" db:"account_id"`
+}
+
+// Внешние системы, присылающие события PR
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Типы событий исходящих вебхуков
+const (
+	EventPRCreated          = "pr.created"
+	EventReviewerAssigned   = "reviewer.assigned"
+	EventReviewerReassigned = "reviewer.reassigned"
+	EventPRMerged           = "pr.merged"
+	EventReviewReminder     = "review.reminder"
+)
+
+// Webhook — подписка внешней системы на события сервиса.
+// Секрет подписи в ответах API не возвращается.
+type Webhook struct {
+	WebhookID  int64     `json:"webhook_id" db:"id"`
+	URL        string    `json:"url" db:"url"`
+	Secret     string    `json:"-" db:"secret"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	Enabled    bool      `json:"enabled" db:"enabled"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookUpdate — частичное изменение подписки: nil-поля остаются прежними
+type WebhookUpdate struct {
+	URL        *string
+	Secret     *string
+	EventTypes []string
+	Enabled    *bool
+}
+
+// WebhookEvent — событие, отправляемое подписчикам исходящих вебхуков
+type WebhookEvent struct {
+	EventID    string           `json:"event_id"`
+	Type       string           `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       WebhookEventData `json:"data"`
+}
+
+// WebhookEventData — содержимое события; заполняются только поля, относящиеся к его типу
+type WebhookEventData struct {
+	PullRequest   *PullRequest `json:"pull_request"`
+	ReviewerID    string       `json:"reviewer_id,omitempty"`
+	OldReviewerID string       `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string       `json:"new_reviewer_id,omitempty"`
+	// AssignedAt — когда ревьюер назначен на PR (review.reminder)
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+}
+
+// ReviewReminder — назначение ревьюера, о котором пора напомнить
+type ReviewReminder struct {
+	PullRequestID string    `db:"pull_request_id"`
+	Repository    string    `db:"repository"`
+	ReviewerID    string    `db:"reviewer_id"`
+	AssignedAt    time.Time `db:"assigned_at"`
+}
+
+// WebhookAttempt — попытка доставки события подписчику
+type WebhookAttempt struct {
+	AttemptID int64  `json:"attempt_id" db:"id"`
+	WebhookID int64  `json:"webhook_id" db:"webhook_id"`
+	EventID   string `json:"event_id" db:"event_id"`
+	EventType string `json:"event_type" db:"event_type"`
+	// Attempt — номер попытки доставки события, начиная с 1
+	Attempt int `json:"attempt" db:"attempt"`
+	// StatusCode — HTTP-статус ответа подписчика; отсутствует, если ответа не было
+	StatusCode *int      `json:"status_code,omitempty" db:"status_code"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMs int64     `json:"duration_ms" db:"duration_ms"`
+	Success    bool      `json:"success" db:"success"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
 // Константы статусов PR
 const (
-    StatusOpen   = "OPEN"
-    StatusMerged = "MERGED"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// statusTransitions — допустимые переходы статуса PR; все остальные переходы запрещены.
+// Новый статус добавляется сюда вместе с его переходами.
+var statusTransitions = map[string][]string{
+	StatusOpen:   {StatusMerged, StatusClosed},
+	StatusClosed: {StatusOpen},
+}
+
+// CanTransition сообщает, можно ли перевести PR из статуса from в статус to
+func CanTransition(from, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// Источники назначения ревьюера
+const (
+	// ReviewerSourceTeam — ревьюер из команды PR
+	ReviewerSourceTeam = "team"
+	// ReviewerSourceFallback — ревьюер из резервной команды, добранный при нехватке кандидатов
+	ReviewerSourceFallback = "fallback"
 )
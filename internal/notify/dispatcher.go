@@ -0,0 +1,260 @@
+// Package notify доставляет события сервиса подписчикам исходящих вебхуков.
+// События ставятся в ограниченную очередь и отправляются фоновыми воркерами с повторами,
+// поэтому медленный или недоступный подписчик не влияет на обработку запросов API.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// Заголовки запросов к подписчикам
+const (
+	// HeaderSignature — HMAC-SHA256 тела запроса на секрете подписки в виде "sha256=<hex>"
+	HeaderSignature = "X-Webhook-Signature-256"
+	// HeaderEvent — тип события
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderEventID — ID события, одинаковый во всех попытках доставки
+	HeaderEventID = "X-Webhook-Event-Id"
+	// HeaderAttempt — номер попытки доставки, начиная с 1
+	HeaderAttempt = "X-Webhook-Attempt"
+)
+
+const (
+	// maxResponseBytes ограничивает, сколько байт ответа подписчика читается перед закрытием соединения
+	maxResponseBytes = 64 << 10
+
+	// recordTimeout ограничивает запись попытки доставки в историю
+	recordTimeout = 5 * time.Second
+)
+
+// Store — операции хранилища, которые использует диспетчер. Реализуется *repository.Repository.
+type Store interface {
+	ListEnabledWebhooks(ctx context.Context, eventType string) ([]models.Webhook, error)
+	RecordWebhookAttempt(ctx context.Context, attempt models.WebhookAttempt) error
+}
+
+// Config задает параметры доставки
+type Config struct {
+	// QueueSize — сколько событий может ждать отправки; при заполненной очереди новые события отбрасываются
+	QueueSize int
+	// Workers — число горутин, отправляющих события
+	Workers int
+	// MaxAttempts — сколько раз пытаться доставить событие одному подписчику
+	MaxAttempts int
+	// InitialBackoff — пауза перед второй попыткой; каждая следующая пауза вдвое длиннее
+	InitialBackoff time.Duration
+	// Timeout — таймаут одного HTTP-запроса к подписчику
+	Timeout time.Duration
+}
+
+// Dispatcher принимает события и асинхронно доставляет их подписчикам
+type Dispatcher struct {
+	store   Store
+	cfg     Config
+	client  *http.Client
+	queue   chan models.WebhookEvent
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+}
+
+// New создает диспетчер. Доставка начинается после вызова Run.
+func New(store Store, cfg Config, m *metrics.Metrics, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store: store,
+		cfg:   cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// Перенаправления не выполняются: ответ 3xx считается неуспешной доставкой
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queue:   make(chan models.WebhookEvent, cfg.QueueSize),
+		metrics: m,
+		logger:  logger,
+	}
+}
+
+// Publish ставит событие в очередь, проставляя ID и время, если они не заданы.
+// Никогда не блокирует вызывающего: при заполненной очереди событие отбрасывается с записью в лог.
+func (d *Dispatcher) Publish(event models.WebhookEvent) {
+	if event.EventID == "" {
+		event.EventID = uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		d.metrics.WebhookDeliveries.WithLabelValues("dropped").Inc()
+		d.logger.Warn("Dispatcher: очередь событий заполнена, событие отброшено",
+			zap.String("event_id", event.EventID),
+			zap.String("event_type", event.Type))
+	}
+}
+
+// Run запускает воркеры доставки и ждет их завершения после отмены ctx.
+// События, оставшиеся в очереди при остановке, не доставляются.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.logger.Info("Dispatcher: запуск",
+		zap.Int("workers", d.cfg.Workers),
+		zap.Int("queue_size", d.cfg.QueueSize),
+		zap.Int("max_attempts", d.cfg.MaxAttempts))
+
+	var wg sync.WaitGroup
+	for range d.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-d.queue:
+					d.dispatch(ctx, event)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	d.logger.Info("Dispatcher: остановка", zap.Int("pending_events", len(d.queue)))
+}
+
+// dispatch отправляет событие всем включенным подписчикам на его тип
+func (d *Dispatcher) dispatch(ctx context.Context, event models.WebhookEvent) {
+	log := d.logger.With(zap.String("event_id", event.EventID), zap.String("event_type", event.Type))
+
+	hooks, err := d.store.ListEnabledWebhooks(ctx, event.Type)
+	if err != nil {
+		log.Error("Dispatcher: ошибка получения подписок", zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Dispatcher: ошибка сериализации события", zap.Error(err))
+		return
+	}
+
+	for _, hook := range hooks {
+		d.deliver(ctx, log.With(zap.Int64("webhook_id", hook.WebhookID)), hook, event, body)
+	}
+}
+
+// deliver отправляет событие одному подписчику с повторами и экспоненциальной паузой между ними
+func (d *Dispatcher) deliver(ctx context.Context, log *zap.Logger, hook models.Webhook, event models.WebhookEvent, body []byte) {
+	backoff := d.cfg.InitialBackoff
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		record, retry := d.send(ctx, hook, event, body, attempt)
+		d.record(ctx, log, record)
+
+		if record.Success {
+			d.metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+			log.Info("Dispatcher: событие доставлено", zap.Int("attempt", attempt))
+			return
+		}
+		if !retry || attempt == d.cfg.MaxAttempts {
+			break
+		}
+
+		log.Warn("Dispatcher: ошибка доставки, повтор",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.String("error", record.Error))
+
+		select {
+		case <-ctx.Done():
+			log.Warn("Dispatcher: доставка прервана остановкой сервиса", zap.Int("attempt", attempt))
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	d.metrics.WebhookDeliveries.WithLabelValues("failed").Inc()
+	log.Error("Dispatcher: событие не доставлено")
+}
+
+// send выполняет одну попытку доставки. Возвращает запись о попытке и признак того,
+// что неуспешную попытку имеет смысл повторить.
+func (d *Dispatcher) send(ctx context.Context, hook models.Webhook, event models.WebhookEvent, body []byte, attempt int) (models.WebhookAttempt, bool) {
+	record := models.WebhookAttempt{
+		WebhookID: hook.WebhookID,
+		EventID:   event.EventID,
+		EventType: event.Type,
+		Attempt:   attempt,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderEventID, event.EventID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	record.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record, true
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	resp.Body.Close()
+
+	status := resp.StatusCode
+	record.StatusCode = &status
+	if status >= 200 && status < 300 {
+		record.Success = true
+		return record, false
+	}
+
+	record.Error = fmt.Sprintf("unexpected status %d", status)
+	// Остальные ответы 4xx означают, что подписчик отверг событие, и повтор его не изменит
+	retry := status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+	return record, retry
+}
+
+// record сохраняет попытку в историю; ошибка записи только логируется
+func (d *Dispatcher) record(ctx context.Context, log *zap.Logger, attempt models.WebhookAttempt) {
+	// Попытка, прерванная остановкой сервиса, тоже должна попасть в историю
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if err := d.store.RecordWebhookAttempt(ctx, attempt); err != nil {
+		log.Error("Dispatcher: ошибка записи попытки доставки", zap.Error(err))
+	}
+}
+
+// Sign возвращает значение заголовка X-Webhook-Signature-256 для тела body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// assignmentPausedSQL — условие действующей приостановки автоназначения пользователя u:
+// пауза без срока или со сроком в будущем. Истекшая пауза не действует без отдельной очистки.
+const assignmentPausedSQL = `(u.assignment_paused AND (u.assignment_paused_until IS NULL OR u.assignment_paused_until > NOW()))`
+
+// assignmentPausedUntilSQL — срок действующей приостановки пользователя u; NULL, если пауза бессрочная или не действует
+const assignmentPausedUntilSQL = `CASE WHEN ` + assignmentPausedSQL + ` THEN u.assignment_paused_until END`
+
+// SetAssignmentPaused приостанавливает или возобновляет автоназначение пользователя ревьюером.
+// Пауза без until действует бессрочно, с until — истекает автоматически.
+func (r *Repository) SetAssignmentPaused(ctx context.Context, userID string, paused bool, until *time.Time) error {
+	if !paused {
+		until = nil
+	}
+	if until != nil {
+		// Колонка хранит время без зоны в UTC
+		utc := until.UTC()
+		until = &utc
+	}
+
+	query := `
+		UPDATE users
+		SET assignment_paused = $1, assignment_paused_until = $2, updated_at = NOW()
+		WHERE ` + r.userIDMatch("external_id", "$3")
+
+	tag, err := r.pool.Exec(ctx, query, paused, until, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update assignment pause: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	r.invalidateCache()
+	return nil
+}
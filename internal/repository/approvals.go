@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// NotApprovedError сообщает, что у открытого PR меньше одобрений, чем требуется для слияния
+type NotApprovedError struct {
+	Approvals int
+	Required  int
+}
+
+func (e *NotApprovedError) Error() string {
+	return fmt.Sprintf("PR has %d of %d required approvals", e.Approvals, e.Required)
+}
+
+func (e *NotApprovedError) Unwrap() error {
+	return ErrNotApproved
+}
+
+// setReviewers заполняет ревьюеров PR и совместимый плоский список их внешних ID
+func setReviewers(pr *models.PullRequest, reviewers []models.AssignedReviewer) {
+	if reviewers == nil {
+		reviewers = []models.AssignedReviewer{}
+	}
+	pr.AssignedReviewers = reviewers
+	pr.AssignedReviewerIDs = make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		pr.AssignedReviewerIDs = append(pr.AssignedReviewerIDs, reviewer.UserID)
+	}
+}
+
+// ApprovePR отмечает одобрение PR ревьюером (идемпотентно: повторное одобрение не меняет approved_at).
+// Одобрить можно только открытый PR, на который пользователь назначен ревьюером.
+// Без репозитория в ref ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) ApprovePR(ctx context.Context, ref models.PRRef, userID string) (*models.PullRequest, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	prInternalID, _, status, err := r.lockPRStatus(ctx, tx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case models.StatusMerged:
+		return nil, ErrAlreadyMerged
+	case models.StatusClosed:
+		return nil, ErrAlreadyClosed
+	}
+
+	var reviewerID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM users WHERE `+r.userIDMatch("external_id", "$1"), userID).Scan(&reviewerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer by external id: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE pr_reviewers
+		SET approved = true, approved_at = COALESCE(approved_at, NOW())
+		WHERE pr_id = $1 AND reviewer_id = $2
+	`, prInternalID, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve PR: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotAssigned
+	}
+	if err := bumpPRVersion(ctx, tx, prInternalID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.getPRByID(ctx, prInternalID)
+}
+
+// checkApprovals блокирует PR до конца транзакции и проверяет, что у открытого PR
+// не меньше RequireApprovals одобрений. Отсутствующий и не открытый PR не проверяются.
+func (r *Repository) checkApprovals(ctx context.Context, tx pgx.Tx, prID int64) error {
+	var status string
+	var approvals int
+	err := tx.QueryRow(ctx, `
+		SELECT pr.status,
+			(SELECT COUNT(*) FROM pr_reviewers prr WHERE prr.pr_id = pr.id AND prr.approved)
+		FROM pull_requests pr
+		WHERE pr.id = $1
+		FOR UPDATE
+	`, prID).Scan(&status, &approvals)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to count approvals: %w", err)
+	}
+
+	if status == models.StatusOpen && approvals < r.opts.RequireApprovals {
+		return &NotApprovedError{Approvals: approvals, Required: r.opts.RequireApprovals}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// errReviewerConflict — выбранный кандидат уже назначен на PR (нарушение блокировки строки PR)
+var errReviewerConflict = errors.New("replacement reviewer is already assigned")
+
+// replaceReviewer снимает ревьюера с PR и назначает вместо него активного участника команды PR
+// (или резервной команды, см. selectWithFallback), который не является автором и еще не назначен на PR. Должен вызываться внутри транзакции,
+// заблокировавшей строку PR (SELECT ... FOR UPDATE).
+// Если кандидата нет: при allowEmpty ревьюер просто снимается и возвращается 0,
+// иначе PR не меняется и возвращается ErrNoCandidate. reason записывается в журнал назначений.
+func (r *Repository) replaceReviewer(ctx context.Context, tx pgx.Tx, prID, authorID, oldReviewerID int64, allowEmpty bool, reason string) (int64, error) {
+	replacement, err := r.findReplacement(ctx, tx, prID, authorID)
+	if err != nil && !errors.Is(err, ErrNoCandidate) {
+		return 0, err
+	}
+	if errors.Is(err, ErrNoCandidate) && !allowEmpty {
+		return 0, ErrNoCandidate
+	}
+
+	return r.swapReviewer(ctx, tx, prID, oldReviewerID, replacement, reason)
+}
+
+// replaceReviewerWithUser снимает ревьюера с PR и назначает вместо него пользователя с внешним ID userID,
+// проверив его через checkEligible. Должен вызываться внутри транзакции, заблокировавшей строку PR.
+func (r *Repository) replaceReviewerWithUser(ctx context.Context, tx pgx.Tx, prID, authorID, oldReviewerID int64, userID string) (int64, error) {
+	replacement, err := r.checkEligible(ctx, tx, prID, authorID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.swapReviewer(ctx, tx, prID, oldReviewerID, replacement, models.AssignmentReasonManual)
+}
+
+// swapReviewer снимает ревьюера с PR и назначает replacement (при нулевом userID только снимает),
+// записывая замену в журнал назначений с причиной reason. Общая часть автоматического и ручного переназначения.
+// Версию PR увеличивает вызывающий (bumpPRVersion), один раз на операцию.
+func (r *Repository) swapReviewer(ctx context.Context, tx pgx.Tx, prID, oldReviewerID int64, replacement candidate, reason string) (int64, error) {
+	// Снимаем старого ревьюера
+	_, err := tx.Exec(ctx,
+		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2`,
+		prID, oldReviewerID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove old reviewer: %w", err)
+	}
+
+	if replacement.userID == 0 {
+		return 0, r.recordAssignmentEvent(ctx, tx, prID, oldReviewerID, 0, reason)
+	}
+
+	// Назначаем нового ревьюера; срок ревью по SLA отсчитывается заново от момента переназначения.
+	// Пара (pr_id, reviewer_id) — первичный ключ, поэтому дубль невозможен; конфликт означает,
+	// что кандидат уже ревьюер, и замена не выполняется.
+	tag, err := tx.Exec(ctx,
+		insertReviewerQuery+` ON CONFLICT (pr_id, reviewer_id) DO NOTHING`,
+		prID, replacement.userID, replacement.source,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add new reviewer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, fmt.Errorf("failed to add new reviewer: %w", errReviewerConflict)
+	}
+
+	if err := r.recordAssignmentEvent(ctx, tx, prID, oldReviewerID, replacement.userID, reason); err != nil {
+		return 0, err
+	}
+
+	return replacement.userID, nil
+}
+
+// checkEligible проверяет, что пользователя с внешним ID userID можно назначить ревьюером PR вручную:
+// он активен, не автор, еще не назначен на PR, ему не запрещено ревьюить PR автора (assignment_exclusions)
+// и он состоит в команде PR (source team) или в резервной команде (source fallback). Пауза автоназначения, отпуск, max_open_reviews и исключение
+// из пула ревьюеров (is_reviewer) ручной выбор не ограничивают.
+// Возвращает ErrNotFound, ErrAlreadyAssigned или ErrCandidateNotEligible.
+func (r *Repository) checkEligible(ctx context.Context, tx pgx.Tx, prID, authorID int64, userID string) (candidate, error) {
+	var (
+		id       int64
+		active   bool
+		assigned bool
+		inTeam   bool
+		inPool   bool
+		excluded bool
+	)
+	teamID, err := r.prTeamID(ctx, tx, prID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return candidate{}, err
+	}
+
+	query := `
+		SELECT u.id, u.is_active,
+			EXISTS(SELECT 1 FROM pr_reviewers WHERE pr_id = $2 AND reviewer_id = u.id),
+			EXISTS(SELECT 1 FROM team_users WHERE team_id = $3 AND user_id = u.id),
+			EXISTS(
+				SELECT 1 FROM team_users tu JOIN teams t ON t.id = tu.team_id
+				WHERE t.name = $4 AND tu.user_id = u.id
+			),
+			EXISTS(
+				SELECT 1 FROM assignment_exclusions
+				WHERE author_user_id = $5 AND reviewer_user_id = u.id
+			)
+		FROM users u
+		WHERE ` + r.userIDMatch("u.external_id", "$1")
+	err = tx.QueryRow(ctx, query, userID, prID, teamID, r.opts.FallbackTeam, authorID).
+		Scan(&id, &active, &assigned, &inTeam, &inPool, &excluded)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return candidate{}, ErrNotFound
+	}
+	if err != nil {
+		return candidate{}, fmt.Errorf("failed to check reviewer candidate: %w", err)
+	}
+
+	switch {
+	case assigned:
+		return candidate{}, ErrAlreadyAssigned
+	case !active || id == authorID || excluded:
+		return candidate{}, ErrCandidateNotEligible
+	case inTeam:
+		return candidate{userID: id, source: models.ReviewerSourceTeam}, nil
+	case inPool:
+		return candidate{userID: id, source: models.ReviewerSourceFallback}, nil
+	default:
+		return candidate{}, ErrCandidateNotEligible
+	}
+}
+
+// assignIfUnreviewed назначает ревьюеров из команды PR по ее правилам назначения, если у PR нет ни одного.
+// Если команды у PR нет, он остается без ревьюеров. Должен вызываться внутри транзакции.
+func (r *Repository) assignIfUnreviewed(ctx context.Context, tx pgx.Tx, prID, authorID int64) error {
+	var hasReviewers bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pr_id = $1)`, prID).Scan(&hasReviewers)
+	if err != nil {
+		return fmt.Errorf("failed to check PR reviewers: %w", err)
+	}
+	if hasReviewers {
+		return nil
+	}
+
+	teamID, err := r.prTeamID(ctx, tx, prID)
+	if errors.Is(err, ErrNotFound) {
+		return nil // команда удалена, а автор больше ни в одной не состоит
+	}
+	if err != nil {
+		return err
+	}
+
+	reviewers, _, err := r.pickReviewers(ctx, tx, teamID, authorID, 0, false)
+	if err != nil {
+		return err
+	}
+
+	for _, reviewer := range reviewers {
+		if _, err := tx.Exec(ctx,
+			insertReviewerQuery,
+			prID, reviewer.userID, reviewer.source,
+		); err != nil {
+			return fmt.Errorf("failed to assign reviewer: %w", err)
+		}
+		if err := r.recordAssignmentEvent(ctx, tx, prID, 0, reviewer.userID, models.AssignmentReasonAutoInitial); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findReplacement ищет кандидата на замену ревьюера: активный член команды PR (или резервной команды),
+// не автор и не один из текущих ревьюеров PR
+func (r *Repository) findReplacement(ctx context.Context, tx pgx.Tx, prID, authorID int64) (candidate, error) {
+	// Получаем команду, из которой назначались ревьюеры PR
+	teamID, err := r.prTeamID(ctx, tx, prID)
+	if errors.Is(err, ErrNotFound) {
+		return candidate{}, ErrNoCandidate // команда удалена, а автор больше ни в одной не состоит
+	}
+	if err != nil {
+		return candidate{}, err
+	}
+
+	// Исключаем всех текущих ревьюеров PR (автор исключается в selectCandidates)
+	var exclude []int64
+	rows, err := tx.Query(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1`, prID)
+	if err != nil {
+		return candidate{}, fmt.Errorf("failed to get current reviewers: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return candidate{}, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		exclude = append(exclude, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return candidate{}, fmt.Errorf("failed to iterate current reviewers: %w", err)
+	}
+
+	candidates, err := r.selectWithFallback(ctx, tx, candidateRequest{
+		teamID:   teamID,
+		authorID: authorID,
+		exclude:  exclude,
+		limit:    1,
+	})
+	if err != nil {
+		return candidate{}, err
+	}
+	if len(candidates) == 0 {
+		return candidate{}, ErrNoCandidate
+	}
+
+	return candidates[0], nil
+}
+
+// DeactivateAndReassign деактивирует пользователя и в той же транзакции переназначает
+// все его ревью в открытых PR. PR без подходящего кандидата остаются с меньшим числом ревьюеров.
+func (r *Repository) DeactivateAndReassign(ctx context.Context, userID string) (_ *models.ReassignmentResult, err error) {
+	ctx, span := startSpan(ctx, "DeactivateAndReassign")
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var uID int64
+	deactivateQuery := `UPDATE users SET is_active = false, updated_at = NOW() WHERE ` +
+		r.userIDMatch("external_id", "$1") + ` RETURNING id`
+	err = tx.QueryRow(ctx, deactivateQuery, userID).Scan(&uID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	result, err := r.reassignOpenReviews(ctx, tx, uID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return result, nil
+}
+
+// reassignOpenReviews переназначает все ревью пользователя в открытых PR внутри транзакции.
+// Назначения моложе MinAssignmentAge не трогаются и попадают в SkippedRecent.
+func (r *Repository) reassignOpenReviews(ctx context.Context, tx pgx.Tx, reviewerID int64) (*models.ReassignmentResult, error) {
+	openReviewsQuery := `
+		SELECT pr.id, pr.external_id, pr.author_id,
+			prr.created_at > NOW()::timestamp - make_interval(secs => $3) AS recent
+		FROM pull_requests pr
+		JOIN pr_reviewers prr ON prr.pr_id = pr.id
+		WHERE prr.reviewer_id = $1
+		  AND pr.status = $2
+		ORDER BY pr.id
+		FOR UPDATE OF pr
+	`
+	rows, err := tx.Query(ctx, openReviewsQuery, reviewerID, models.StatusOpen, r.opts.MinAssignmentAge.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reviews: %w", err)
+	}
+
+	type openReview struct {
+		prID       int64
+		externalID string
+		authorID   int64
+		recent     bool
+	}
+	var reviews []openReview
+	for rows.Next() {
+		var rv openReview
+		if err := rows.Scan(&rv.prID, &rv.externalID, &rv.authorID, &rv.recent); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan open review: %w", err)
+		}
+		reviews = append(reviews, rv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate open reviews: %w", err)
+	}
+
+	result := &models.ReassignmentResult{
+		Reassigned:    make([]models.ReviewReassignment, 0, len(reviews)),
+		NotReassigned: make([]string, 0),
+		SkippedRecent: make([]string, 0),
+	}
+	for _, rv := range reviews {
+		if rv.recent {
+			result.SkippedRecent = append(result.SkippedRecent, rv.externalID)
+			continue
+		}
+
+		newReviewerID, err := r.replaceReviewer(ctx, tx, rv.prID, rv.authorID, reviewerID, true, models.AssignmentReasonDeactivation)
+		if err != nil {
+			return nil, err
+		}
+		if err := bumpPRVersion(ctx, tx, rv.prID, nil); err != nil {
+			return nil, err
+		}
+		if newReviewerID == 0 {
+			result.NotReassigned = append(result.NotReassigned, rv.externalID)
+			continue
+		}
+
+		var newReviewerExternalID string
+		err = tx.QueryRow(ctx, `SELECT external_id FROM users WHERE id = $1`, newReviewerID).Scan(&newReviewerExternalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get new reviewer external id: %w", err)
+		}
+		result.Reassigned = append(result.Reassigned, models.ReviewReassignment{
+			PullRequestID: rv.externalID,
+			NewReviewerID: newReviewerExternalID,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// ActorSystem — инициатор изменений, сделанных без запроса к API (воркеры, миграции данных)
+const ActorSystem = "system"
+
+type actorKey struct{}
+
+// WithActor сохраняет в контексте инициатора изменений для журнала назначений
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFromContext возвращает инициатора изменений из контекста, по умолчанию ActorSystem
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
+
+// recordAssignmentEvent записывает изменение ревьюеров PR в журнал в транзакции изменения.
+// Нулевой oldReviewerID означает назначение без замены, нулевой newReviewerID — снятие без замены.
+func (r *Repository) recordAssignmentEvent(ctx context.Context, tx pgx.Tx, prID, oldReviewerID, newReviewerID int64, reason string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO assignment_events (pr_id, actor, old_reviewer_id, new_reviewer_id, reason)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5)
+	`, prID, actorFromContext(ctx), oldReviewerID, newReviewerID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record assignment event: %w", err)
+	}
+	return nil
+}
+
+// GetAssignmentHistory возвращает журнал изменений ревьюеров PR от старых событий к новым.
+// ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) GetAssignmentHistory(ctx context.Context, pullRequestID string) ([]models.AssignmentEvent, error) {
+	prID, err := r.lookupPR(ctx, r.pool, prRef(pullRequestID), false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ae.id AS event_id,
+			ae.actor,
+			ou.external_id AS old_reviewer_id,
+			nu.external_id AS new_reviewer_id,
+			ae.reason,
+			ae.created_at
+		FROM assignment_events ae
+		LEFT JOIN users ou ON ou.id = ae.old_reviewer_id
+		LEFT JOIN users nu ON nu.id = ae.new_reviewer_id
+		WHERE ae.pr_id = $1
+		ORDER BY ae.created_at, ae.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.AssignmentEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect assignment history: %w", err)
+	}
+
+	return events, nil
+}
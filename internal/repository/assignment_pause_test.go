@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pauseTestNow — момент «сейчас» для NOW() в запросах pauseDB
+var pauseTestNow = time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+
+// pausedUser — строка users с колонками приостановки автоназначения
+type pausedUser struct {
+	id         int64
+	externalID string
+	paused     bool
+	until      *time.Time
+}
+
+// activePause повторяет assignmentPausedSQL для pauseTestNow
+func (u pausedUser) activePause() bool {
+	return u.paused && (u.until == nil || u.until.After(pauseTestNow))
+}
+
+// pauseDB отдает участников команды на запросы выбора кандидатов и списка приостановленных.
+// Условие приостановки применяется, только если запрос содержит assignmentPausedSQL.
+func pauseDB(users []pausedUser) *fakeDB {
+	db := &fakeDB{
+		query: func(sql string, _ []any) (*fakeRows, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			filtered := strings.Contains(sql, assignmentPausedSQL)
+			switch {
+			case strings.Contains(sql, "SELECT tu.user_id, load.open_reviews"):
+				rows := newFakeRows([]string{"user_id", "open_reviews"})
+				for _, u := range users {
+					if !filtered || !u.activePause() {
+						rows.data = append(rows.data, []any{u.id, 0})
+					}
+				}
+				return rows, nil
+			case strings.Contains(sql, "SELECT u.external_id FROM team_users"):
+				rows := newFakeRows([]string{"external_id"})
+				for _, u := range users {
+					if filtered && u.activePause() {
+						rows.data = append(rows.data, []any{u.externalID})
+					}
+				}
+				return rows, nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+	db.withTx(&fakeTx{})
+	return db
+}
+
+func TestSelectCandidatesSkipsPausedUsers(t *testing.T) {
+	past := pauseTestNow.Add(-time.Hour)
+	future := pauseTestNow.Add(time.Hour)
+	users := []pausedUser{
+		{id: 2, externalID: "u2"},
+		{id: 3, externalID: "u3", paused: true},
+		{id: 4, externalID: "u4", paused: true, until: &future},
+		{id: 5, externalID: "u5", paused: true, until: &past},
+	}
+	db := pauseDB(users)
+	r := New(db, Options{AssignmentStrategy: StrategyLeastLoaded})
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+
+	candidates, err := r.selectCandidates(context.Background(), tx, candidateRequest{teamID: 1, authorID: 1, limit: 10})
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, c := range candidates {
+		ids = append(ids, c.userID)
+	}
+	assert.Equal(t, []int64{2, 5}, ids, "indefinite and unexpired pauses are skipped, an expired pause is not")
+	assert.Contains(t, db.queries()[0], "AND NOT "+assignmentPausedSQL)
+
+	paused, err := r.pausedTeamMembers(context.Background(), tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, paused, "explain output lists only members whose pause is in effect")
+}
+
+func TestAssignmentPausedSQLExpires(t *testing.T) {
+	assert.Contains(t, assignmentPausedSQL, "u.assignment_paused_until IS NULL",
+		"a pause without until never expires")
+	assert.Contains(t, assignmentPausedSQL, "u.assignment_paused_until > NOW()",
+		"a pause with until stops applying once until has passed")
+	assert.True(t, strings.HasPrefix(assignmentPausedUntilSQL, "CASE WHEN "+assignmentPausedSQL),
+		"until is reported only while the pause is in effect")
+}
+
+func TestGetUserAssignmentPause(t *testing.T) {
+	until := pauseTestNow.Add(time.Hour)
+	cases := []struct {
+		name   string
+		paused bool
+		until  any
+	}{
+		{name: "not paused", paused: false, until: nil},
+		{name: "paused indefinitely", paused: true, until: nil},
+		{name: "paused until", paused: true, until: until},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := &fakeDB{
+				query: func(sql string, _ []any) (*fakeRows, error) {
+					sql = strings.Join(strings.Fields(sql), " ")
+					switch {
+					case strings.Contains(sql, "FROM users u LEFT JOIN team_users"):
+						require.Contains(t, sql, assignmentPausedSQL)
+						return newFakeRows(
+							[]string{"external_id", "name", "team_name", "is_active", "is_reviewer", "paused", "until"},
+							[]any{"u1", "Alice", "backend", true, true, tc.paused, tc.until},
+						), nil
+					case strings.Contains(sql, "FROM user_vacations"), strings.Contains(sql, "FROM user_external_accounts"):
+						return newFakeRows(nil), nil
+					}
+					return nil, errFakeUnexpected
+				},
+			}
+			r := New(db, Options{})
+
+			user, err := r.GetUser(context.Background(), "u1")
+			require.NoError(t, err)
+			require.NotNil(t, user.AssignmentPaused)
+			assert.Equal(t, tc.paused, *user.AssignmentPaused)
+			if tc.until == nil {
+				assert.Nil(t, user.AssignmentPausedUntil)
+			} else {
+				require.NotNil(t, user.AssignmentPausedUntil)
+				assert.Equal(t, until, *user.AssignmentPausedUntil)
+			}
+		})
+	}
+}
+
+func TestSetAssignmentPausedInvalidatesCache(t *testing.T) {
+	var args []any
+	reads := 0
+	db := &fakeDB{
+		query: func(sql string, _ []any) (*fakeRows, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			switch {
+			case strings.Contains(sql, "FROM users u LEFT JOIN team_users"):
+				reads++
+				return newFakeRows(
+					[]string{"external_id", "name", "team_name", "is_active", "is_reviewer", "paused", "until"},
+					[]any{"u1", "Alice", "backend", true, true, false, nil},
+				), nil
+			case strings.Contains(sql, "FROM user_vacations"), strings.Contains(sql, "FROM user_external_accounts"):
+				return newFakeRows(nil), nil
+			}
+			return nil, errFakeUnexpected
+		},
+		exec: func(_ string, a []any) (pgconn.CommandTag, error) {
+			args = a
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}
+	r := New(db, Options{CacheTTL: time.Minute})
+
+	_, err := r.GetUser(context.Background(), "u1")
+	require.NoError(t, err)
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	until := time.Date(2025, 12, 1, 3, 0, 0, 0, moscow)
+	require.NoError(t, r.SetAssignmentPaused(context.Background(), "u1", true, &until))
+	require.Len(t, args, 3)
+	assert.Equal(t, true, args[0])
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), *args[1].(*time.Time), "until is stored in UTC")
+
+	_, err = r.GetUser(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, reads, "the cached user is dropped after the pause changes")
+
+	require.NoError(t, r.SetAssignmentPaused(context.Background(), "u1", false, &until))
+	assert.Nil(t, args[1], "resuming clears until")
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Bootstrap создает команды и PR документа одной транзакцией: сначала команды с участниками,
+// затем PR с автоназначением ревьюеров, после чего PR со статусом MERGED сливаются.
+// Любая ошибка откатывает весь документ; ошибка содержит ID сущности, на которой она произошла.
+func (r *Repository) Bootstrap(ctx context.Context, doc models.BootstrapDocument) (_ *models.BootstrapResult, err error) {
+	ctx, span := startSpan(ctx, "Bootstrap",
+		attribute.Int("bootstrap.teams", len(doc.Teams)),
+		attribute.Int("bootstrap.pull_requests", len(doc.PullRequests)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &models.BootstrapResult{
+		Teams:        make([]models.Team, 0, len(doc.Teams)),
+		PullRequests: make([]models.PullRequest, 0, len(doc.PullRequests)),
+	}
+
+	for _, team := range doc.Teams {
+		if err := r.upsertTeam(ctx, tx, team); err != nil {
+			return nil, fmt.Errorf("team %q: %w", team.TeamName, err)
+		}
+		result.Teams = append(result.Teams, team)
+	}
+
+	// Ревьюеры PR назначаются из команды автора, описанной в документе, даже если в базе
+	// автор состоит и в других командах
+	authorTeams := make(map[string]string)
+	for _, team := range doc.Teams {
+		for _, member := range team.Members {
+			authorTeams[member.UserID] = team.TeamName
+		}
+	}
+
+	for _, item := range doc.PullRequests {
+		pr, err := r.createPR(ctx, tx, item.Repository, item.PullRequestID, item.PullRequestName, item.AuthorID, authorTeams[item.AuthorID], models.PRMetadata{})
+		if err != nil {
+			return nil, fmt.Errorf("pull request %q: %w", item.PullRequestID, err)
+		}
+
+		if item.Status == models.StatusMerged {
+			err := tx.QueryRow(ctx, `
+				UPDATE pull_requests
+				SET status = $1, merged_at = NOW(), merged_by = $4
+				WHERE repository = $2 AND external_id = $3
+				RETURNING status, merged_at, merged_by
+			`, models.StatusMerged, item.Repository, item.PullRequestID, actorFromContext(ctx)).Scan(&pr.Status, &pr.MergedAt, &pr.MergedBy)
+			if err != nil {
+				return nil, fmt.Errorf("pull request %q: failed to merge PR: %w", item.PullRequestID, err)
+			}
+		}
+
+		result.PullRequests = append(result.PullRequests, *pr)
+	}
+
+	if err := tx.commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"slices"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/cache"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// cacheMaxEntries — сколько страниц команд и сколько пользователей хранит кэш чтения
+const cacheMaxEntries = 1000
+
+// teamPageKey — ключ страницы участников команды в кэше
+type teamPageKey struct {
+	teamName      string
+	limit, offset int
+}
+
+// teamPage — закэшированная страница участников команды и их общее число
+type teamPage struct {
+	team  models.Team
+	total int
+}
+
+// readCache — кэш горячих чтений команд и пользователей в памяти процесса.
+// Любое изменение команд, участников или пользователей через репозиторий очищает его целиком,
+// изменения из других экземпляров сервиса видны не позже чем через TTL.
+type readCache struct {
+	teams *cache.LRU[teamPageKey, teamPage]
+	users *cache.LRU[string, models.User]
+}
+
+// newReadCache создает кэш чтения; при ttl <= 0 кэширование выключено и возвращается nil
+func newReadCache(ttl time.Duration) *readCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &readCache{
+		teams: cache.New[teamPageKey, teamPage](cacheMaxEntries, ttl),
+		users: cache.New[string, models.User](cacheMaxEntries, ttl),
+	}
+}
+
+// invalidateCache очищает кэш чтения после изменения команд, участников или пользователей.
+// Вызывается сразу после фиксации изменения, до чтения обновленных данных.
+func (r *Repository) invalidateCache() {
+	if r.cache == nil {
+		return
+	}
+	r.cache.teams.Purge()
+	r.cache.users.Purge()
+}
+
+// cloneTeam копирует команду, чтобы вызывающий код не мог изменить закэшированное значение
+func cloneTeam(team models.Team) *models.Team {
+	team.Members = slices.Clone(team.Members)
+	return &team
+}
+
+// cloneUser копирует пользователя, чтобы вызывающий код не мог изменить закэшированное значение
+func cloneUser(user models.User) *models.User {
+	user.Vacations = slices.Clone(user.Vacations)
+	user.ExternalAccounts = slices.Clone(user.ExternalAccounts)
+	return &user
+}
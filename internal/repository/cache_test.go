@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// memberDB — основная БД fakeCluster, которая также принимает добавление участника через AddTeamMember
+func memberDB(cluster *fakeCluster) *fakeDB {
+	db := cluster.db(true)
+	query, exec := db.query, db.exec
+	db.query = func(sql string, args []any) (*fakeRows, error) {
+		if strings.Contains(sql, "INSERT INTO users") {
+			return newFakeRows([]string{"id"}, []any{int64(2)}), nil
+		}
+		return query(sql, args)
+	}
+	db.exec = func(sql string, args []any) (pgconn.CommandTag, error) {
+		if strings.Contains(sql, "INSERT INTO team_users") {
+			cluster.addMember("u2")
+			return pgconn.NewCommandTag("INSERT 0 1"), nil
+		}
+		return exec(sql, args)
+	}
+	db.withTx(&fakeTx{})
+	return db
+}
+
+func TestCachedReadsAfterMutation(t *testing.T) {
+	ctx := context.Background()
+	db := memberDB(newFakeCluster())
+	r := New(db, Options{CacheTTL: time.Minute})
+
+	user, err := r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	require.True(t, user.IsActive)
+	team, err := r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+
+	// Повторные чтения обслуживаются кэшем
+	queries := len(db.queries())
+	_, err = r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	_, err = r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, db.queries(), queries, "hits must not reach the database")
+
+	require.NoError(t, r.UpdateUserStatus(ctx, "u1", false))
+	user, err = r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive, "no stale user after a status change")
+
+	_, err = r.AddTeamMember(ctx, "backend", models.TeamMember{UserID: "u2", Username: "User u2", IsActive: true})
+	require.NoError(t, err)
+	team, err = r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2, "no stale team after a member is added")
+}
+
+func TestCachedValuesAreCopies(t *testing.T) {
+	ctx := context.Background()
+	r := New(newFakeCluster().db(true), Options{CacheTTL: time.Minute})
+
+	team, err := r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	team.Members[0].UserID = "changed"
+	team.Members = append(team.Members, models.TeamMember{UserID: "extra"})
+
+	team, err = r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "u1", team.Members[0].UserID, "callers must not modify the cached team")
+}
+
+func BenchmarkGetTeam(b *testing.B) {
+	ctx := context.Background()
+	for _, bc := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{name: "cached", ttl: time.Minute},
+		{name: "uncached"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			cluster := newFakeCluster()
+			for i := range 49 {
+				cluster.addMember(fmt.Sprintf("m%02d", i))
+			}
+			r := New(cluster.db(true), Options{CacheTTL: bc.ttl})
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := r.GetTeam(ctx, "backend"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
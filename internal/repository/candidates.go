@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// Стратегии выбора ревьюеров
+const (
+	StrategyRandom      = "random"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+)
+
+// reviewersPerPR — сколько ревьюеров назначается на новый PR, если команда не задала reviewers_per_pr
+const reviewersPerPR = 2
+
+// candidateRequest описывает параметры выбора ревьюеров
+type candidateRequest struct {
+	teamID int64
+	// authorID — автор PR: всегда исключается и используется для cooldown
+	authorID int64
+	// exclude — дополнительно исключаемые пользователи (например, текущие ревьюеры)
+	exclude []int64
+	limit   int
+	// strategy — стратегия выбора; пустая — стратегия команды teamID
+	strategy string
+	// dryRun — только посмотреть, кто был бы выбран: указатель ротации не блокируется и не сдвигается
+	dryRun bool
+	// leadsOnly — выбирать только лидеров команды (role = lead)
+	leadsOnly bool
+}
+
+// teamAssignment возвращает действующие правила назначения команды: reviewers_per_pr и стратегию
+// из team_settings, а для незаданных — reviewersPerPR и AssignmentStrategy.
+// Должен вызываться внутри транзакции.
+func (r *Repository) teamAssignment(ctx context.Context, tx pgx.Tx, teamID int64) (models.AssignmentSettings, error) {
+	var perPR *int
+	var strategy *string
+	var includeLead *bool
+	err := tx.QueryRow(ctx,
+		`SELECT reviewers_per_pr, assignment_strategy, always_include_lead FROM team_settings WHERE team_id = $1`,
+		teamID,
+	).Scan(&perPR, &strategy, &includeLead)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.AssignmentSettings{}, fmt.Errorf("failed to get team assignment settings: %w", err)
+	}
+
+	return r.effectiveAssignment(perPR, strategy, includeLead), nil
+}
+
+// effectiveAssignment подставляет значения по умолчанию вместо незаданных настроек команды
+func (r *Repository) effectiveAssignment(perPR *int, strategy *string, includeLead *bool) models.AssignmentSettings {
+	settings := models.AssignmentSettings{ReviewersPerPR: reviewersPerPR, Strategy: r.defaultStrategy()}
+	if perPR != nil {
+		settings.ReviewersPerPR = *perPR
+	}
+	if strategy != nil {
+		settings.Strategy = *strategy
+	}
+	if includeLead != nil {
+		settings.AlwaysIncludeLead = *includeLead
+	}
+	return settings
+}
+
+// defaultStrategy возвращает глобальную стратегию назначения (least_loaded, если не задана)
+func (r *Repository) defaultStrategy() string {
+	if r.opts.AssignmentStrategy == "" {
+		return StrategyLeastLoaded
+	}
+	return r.opts.AssignmentStrategy
+}
+
+// selectCandidates выбирает до req.limit активных участников команды, исключая автора, пользователей
+// из req.exclude, исключенных из пула ревьюеров (is_reviewer = false), тех, у кого автоназначение
+// приостановлено, тех, кто сейчас в отпуске, тех, у кого открытых ревью уже max_open_reviews,
+// и тех, кому запрещено ревьюить PR автора (assignment_exclusions).
+// При включенном cooldown участники, назначенные на последние CooldownPRs PR автора, идут в конце
+// очереди, но не исключаются. Дальше порядок определяется стратегией назначения: least_loaded
+// предпочитает участников с наименьшим числом открытых ревью, случайно разбивая ничьи; round_robin
+// берет следующих по кругу после указателя ротации команды и сдвигает указатель (кроме req.dryRun).
+// Источник у возвращаемых кандидатов не заполняется.
+// Должен вызываться внутри транзакции.
+func (r *Repository) selectCandidates(ctx context.Context, tx pgx.Tx, req candidateRequest) ([]candidate, error) {
+	strategy := req.strategy
+	if strategy == "" {
+		strategy = r.defaultStrategy()
+	}
+
+	exclude := append([]int64{req.authorID}, req.exclude...)
+	args := []any{req.teamID, exclude, req.limit, models.StatusOpen}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	author := arg(req.authorID)
+
+	roleFilter := ""
+	if req.leadsOnly {
+		roleFilter = `
+		  AND tu.role = ` + arg(models.TeamRoleLead)
+	}
+
+	var orderBy []string
+	cooldownJoin := ""
+	if r.opts.CooldownPRs > 0 {
+		// Был ли участник ревьюером одного из последних K PR автора
+		cooldownJoin = `
+		CROSS JOIN LATERAL (
+			SELECT EXISTS(
+				SELECT 1
+				FROM pr_reviewers rp
+				WHERE rp.reviewer_id = tu.user_id
+				  AND rp.pr_id IN (
+					SELECT id FROM pull_requests
+					WHERE author_id = ` + author + `
+					ORDER BY created_at DESC, id DESC
+					LIMIT ` + arg(r.opts.CooldownPRs) + `
+				  )
+			) AS recent
+		) cooldown`
+		orderBy = append(orderBy, `cooldown.recent`)
+	}
+
+	switch strategy {
+	case StrategyRandom:
+		orderBy = append(orderBy, `RANDOM()`)
+	case StrategyRoundRobin:
+		lock := r.lockRotation
+		if req.dryRun {
+			lock = r.peekRotation
+		}
+		pointer, err := lock(ctx, tx, req.teamID)
+		if err != nil {
+			return nil, err
+		}
+		// Сначала участники после указателя, затем с начала круга
+		p := arg(pointer)
+		orderBy = append(orderBy, `(tu.user_id <= `+p+`)`, `tu.user_id`)
+	default:
+		orderBy = append(orderBy, `load.open_reviews`, `RANDOM()`)
+	}
+
+	query := `
+		SELECT tu.user_id, load.open_reviews
+		FROM team_users tu
+		JOIN users u ON tu.user_id = u.id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS open_reviews
+			FROM pr_reviewers prr
+			JOIN pull_requests p ON p.id = prr.pr_id
+			WHERE prr.reviewer_id = tu.user_id
+			  AND p.status = $4
+		) load` + cooldownJoin + `
+		WHERE tu.team_id = $1
+		  AND u.is_active = true
+		  AND u.is_reviewer = true
+		  AND NOT ` + assignmentPausedSQL + `
+		  AND (u.max_open_reviews IS NULL OR load.open_reviews < u.max_open_reviews)
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_vacations v
+			WHERE v.user_id = tu.user_id AND v.starts_at <= NOW() AND v.ends_at > NOW()
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM assignment_exclusions ex
+			WHERE ex.author_user_id = ` + author + ` AND ex.reviewer_user_id = tu.user_id
+		  )
+		  AND tu.user_id != ALL($2)` + roleFilter + `
+		ORDER BY ` + strings.Join(orderBy, ", ") + `
+		LIMIT $3
+	`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.userID, &c.openReviews); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate candidates: %w", err)
+	}
+
+	if strategy == StrategyRoundRobin && !req.dryRun && len(candidates) > 0 {
+		if err := r.advanceRotation(ctx, tx, req.teamID, candidates[len(candidates)-1].userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return candidates, nil
+}
+
+// candidate — выбранный ревьюер и источник его назначения
+type candidate struct {
+	userID int64
+	// source — models.ReviewerSourceTeam или models.ReviewerSourceFallback
+	source string
+	// openReviews — число открытых PR, где кандидат уже ревьюер, на момент выбора
+	openReviews int
+}
+
+// selectWithFallback выбирает ревьюеров как selectCandidates, а если в команде не нашлось req.limit
+// кандидатов и задана FallbackTeam, добирает недостающих из резервной команды по тем же правилам
+// (без автора, req.exclude и уже выбранных). Несуществующая резервная команда пропускается.
+// Без req.strategy используется стратегия команды PR, в том числе при доборе из резервной.
+// При req.dryRun ничего не пишет. Должен вызываться внутри транзакции.
+func (r *Repository) selectWithFallback(ctx context.Context, tx pgx.Tx, req candidateRequest) ([]candidate, error) {
+	if req.strategy == "" {
+		settings, err := r.teamAssignment(ctx, tx, req.teamID)
+		if err != nil {
+			return nil, err
+		}
+		req.strategy = settings.Strategy
+	}
+
+	teamCandidates, err := r.selectCandidates(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]candidate, 0, req.limit)
+	exclude := append([]int64{}, req.exclude...)
+	for _, c := range teamCandidates {
+		c.source = models.ReviewerSourceTeam
+		selected = append(selected, c)
+		exclude = append(exclude, c.userID)
+	}
+	if len(selected) >= req.limit || r.opts.FallbackTeam == "" {
+		return selected, nil
+	}
+
+	var fallbackTeamID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM teams WHERE name = $1`, r.opts.FallbackTeam).Scan(&fallbackTeamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return selected, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fallback team: %w", err)
+	}
+	if fallbackTeamID == req.teamID {
+		return selected, nil
+	}
+
+	fallbackCandidates, err := r.selectCandidates(ctx, tx, candidateRequest{
+		teamID:   fallbackTeamID,
+		authorID: req.authorID,
+		exclude:  exclude,
+		limit:    req.limit - len(selected),
+		strategy: req.strategy,
+		dryRun:   req.dryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range fallbackCandidates {
+		c.source = models.ReviewerSourceFallback
+		selected = append(selected, c)
+	}
+
+	return selected, nil
+}
+
+// pickReviewers выбирает ревьюеров для нового PR автора authorID по правилам назначения команды teamID:
+// limit кандидатов (0 — reviewers_per_pr команды) по стратегии команды, с добором из резервной команды.
+// При always_include_lead первым назначается лидер команды (см. pickLead), остальные места заполняются
+// как обычно. Если кандидатов не хватило из-за max_open_reviews, PR получает меньше ревьюеров, а после
+// фиксации транзакции вызывается Options.OnCapacityLimited.
+// Общий выбор для создания PR, назначения при переоткрытии и предпросмотра назначения.
+// Должен вызываться внутри транзакции.
+func (r *Repository) pickReviewers(ctx context.Context, tx pgx.Tx, teamID, authorID int64, limit int, dryRun bool) ([]candidate, models.AssignmentSettings, error) {
+	settings, err := r.teamAssignment(ctx, tx, teamID)
+	if err != nil {
+		return nil, models.AssignmentSettings{}, err
+	}
+	if limit == 0 {
+		limit = settings.ReviewersPerPR
+	}
+
+	var reviewers []candidate
+	var exclude []int64
+	if settings.AlwaysIncludeLead && limit > 0 {
+		lead, err := r.pickLead(ctx, tx, teamID, authorID, dryRun)
+		if err != nil {
+			return nil, models.AssignmentSettings{}, err
+		}
+		if lead != nil {
+			reviewers = append(reviewers, *lead)
+			exclude = append(exclude, lead.userID)
+		}
+	}
+
+	if len(reviewers) < limit {
+		rest, err := r.selectWithFallback(ctx, tx, candidateRequest{
+			teamID:   teamID,
+			authorID: authorID,
+			exclude:  exclude,
+			limit:    limit - len(reviewers),
+			strategy: settings.Strategy,
+			dryRun:   dryRun,
+		})
+		if err != nil {
+			return nil, models.AssignmentSettings{}, err
+		}
+		reviewers = append(reviewers, rest...)
+	}
+
+	if !dryRun && len(reviewers) < limit {
+		if err := r.reportCapacityShortfall(ctx, tx, teamID, authorID, limit, len(reviewers)); err != nil {
+			return nil, models.AssignmentSettings{}, err
+		}
+	}
+	return reviewers, settings, nil
+}
+
+// pickLead выбирает лидера команды teamID в ревьюеры нового PR автора authorID. Лидер проходит те же
+// проверки, что и остальные кандидаты (активен, не в отпуске, не превысил max_open_reviews и т. д.);
+// из нескольких лидеров берется наименее загруженный, указатель ротации не сдвигается.
+// Если автор сам лидер команды или подходящего лидера нет, возвращается nil — ревьюеры выбираются как обычно.
+// Должен вызываться внутри транзакции.
+func (r *Repository) pickLead(ctx context.Context, tx pgx.Tx, teamID, authorID int64, dryRun bool) (*candidate, error) {
+	var authorIsLead bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM team_users WHERE team_id = $1 AND user_id = $2 AND role = $3)`,
+		teamID, authorID, models.TeamRoleLead,
+	).Scan(&authorIsLead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check author role: %w", err)
+	}
+	if authorIsLead {
+		return nil, nil
+	}
+
+	leads, err := r.selectCandidates(ctx, tx, candidateRequest{
+		teamID:    teamID,
+		authorID:  authorID,
+		limit:     1,
+		strategy:  StrategyLeastLoaded,
+		dryRun:    dryRun,
+		leadsOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(leads) == 0 {
+		return nil, nil
+	}
+	lead := leads[0]
+	lead.source = models.ReviewerSourceTeam
+	return &lead, nil
+}
+
+// checkLeadReplaceable возвращает ErrLeadRequired, если ревьюер reviewerID — лидер команды PR, а команда
+// назначает лидера на каждый PR (always_include_lead): автоматическая замена убрала бы его с PR.
+// Замена на явно указанного пользователя и снятие неактивных ревьюеров этой проверкой не ограничиваются.
+// Должен вызываться внутри транзакции.
+func (r *Repository) checkLeadReplaceable(ctx context.Context, tx pgx.Tx, prID, reviewerID int64) error {
+	teamID, err := r.prTeamID(ctx, tx, prID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var required bool
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(ts.always_include_lead, false)
+			AND EXISTS(SELECT 1 FROM team_users WHERE team_id = $1 AND user_id = $2 AND role = $3)
+		FROM teams t
+		LEFT JOIN team_settings ts ON ts.team_id = t.id
+		WHERE t.id = $1
+	`, teamID, reviewerID, models.TeamRoleLead).Scan(&required)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check team lead: %w", err)
+	}
+	if required {
+		return ErrLeadRequired
+	}
+	return nil
+}
+
+// lockRotation блокирует указатель ротации команды до конца транзакции и возвращает
+// внутренний ID последнего назначенного участника (0, если назначений еще не было).
+// Блокировка сериализует параллельные назначения в одной команде.
+func (r *Repository) lockRotation(ctx context.Context, tx pgx.Tx, teamID int64) (int64, error) {
+	_, err := tx.Exec(ctx, `INSERT INTO team_rotation (team_id) VALUES ($1) ON CONFLICT (team_id) DO NOTHING`, teamID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to init team rotation: %w", err)
+	}
+
+	var pointer int64
+	err = tx.QueryRow(ctx, `SELECT last_user_id FROM team_rotation WHERE team_id = $1 FOR UPDATE`, teamID).Scan(&pointer)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock team rotation: %w", err)
+	}
+	return pointer, nil
+}
+
+// peekRotation возвращает указатель ротации команды без блокировки и без создания записи (0, если ее нет).
+// Используется при предпросмотре назначения, который ничего не пишет.
+func (r *Repository) peekRotation(ctx context.Context, tx pgx.Tx, teamID int64) (int64, error) {
+	var pointer int64
+	err := tx.QueryRow(ctx, `SELECT last_user_id FROM team_rotation WHERE team_id = $1`, teamID).Scan(&pointer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team rotation: %w", err)
+	}
+	return pointer, nil
+}
+
+// advanceRotation сдвигает указатель ротации команды на последнего назначенного участника
+func (r *Repository) advanceRotation(ctx context.Context, tx pgx.Tx, teamID, userID int64) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE team_rotation SET last_user_id = $2, updated_at = NOW() WHERE team_id = $1`,
+		teamID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to advance team rotation: %w", err)
+	}
+	return nil
+}
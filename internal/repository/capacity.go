@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// SetUserCapacity задает, сколько открытых ревью можно назначить пользователю одновременно.
+// maxOpenReviews == nil снимает ограничение. Уже назначенные ревью сверх лимита не снимаются.
+func (r *Repository) SetUserCapacity(ctx context.Context, userID string, maxOpenReviews *int) error {
+	query := `
+		UPDATE users
+		SET max_open_reviews = $1, updated_at = NOW()
+		WHERE ` + r.userIDMatch("external_id", "$2")
+
+	tag, err := r.pool.Exec(ctx, query, maxOpenReviews, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user capacity: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	r.invalidateCache()
+	return nil
+}
+
+// reportCapacityShortfall сообщает через Options.OnCapacityLimited, что PR назначено меньше
+// ревьюеров, чем нужно, и в команде teamID есть активные участники, упершиеся в max_open_reviews.
+// Если таких участников нет (команда просто мала), ничего не сообщает.
+// Должен вызываться внутри транзакции: сообщение откладывается до ее фиксации (см. afterCommit),
+// поэтому повтор транзакции после временной ошибки не дает повторного сообщения.
+func (r *Repository) reportCapacityShortfall(ctx context.Context, tx pgx.Tx, teamID, authorID int64, requested, assigned int) error {
+	if r.opts.OnCapacityLimited == nil {
+		return nil
+	}
+
+	var teamName string
+	var capped int
+	err := tx.QueryRow(ctx, `
+		SELECT t.name, (
+			SELECT COUNT(*)
+			FROM team_users tu
+			JOIN users u ON u.id = tu.user_id
+			WHERE tu.team_id = t.id
+			  AND tu.user_id != $2
+			  AND u.is_active = true
+			  AND u.is_reviewer = true
+			  AND u.max_open_reviews IS NOT NULL
+			  AND (
+				SELECT COUNT(*)
+				FROM pr_reviewers prr
+				JOIN pull_requests p ON p.id = prr.pr_id
+				WHERE prr.reviewer_id = tu.user_id AND p.status = $3
+			  ) >= u.max_open_reviews
+		)
+		FROM teams t
+		WHERE t.id = $1
+	`, teamID, authorID, models.StatusOpen).Scan(&teamName, &capped)
+	if err != nil {
+		return fmt.Errorf("failed to count reviewers at capacity: %w", err)
+	}
+
+	if capped > 0 {
+		afterCommit(tx, func() { r.opts.OnCapacityLimited(teamName, requested, assigned, capped) })
+	}
+	return nil
+}
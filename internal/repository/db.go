@@ -12,4 +12,29 @@ type DB interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 	Begin(ctx context.Context) (pgx.Tx, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// primaryKey — ключ контекста, требующий читать из основного пула
+type primaryKey struct{}
+
+// WithPrimary возвращает контекст, в котором методы чтения репозитория идут в основной пул
+// в обход пула чтения и кэша. Нужен для чтения сразу после записи: реплика может отставать.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// primaryRequired сообщает, требует ли контекст чтения из основного пула
+func primaryRequired(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// reader возвращает пул для чистого чтения: пул чтения, если он задан и контекст не требует основного пула.
+// Транзакции и запись всегда идут через r.pool.
+func (r *Repository) reader(ctx context.Context) DB {
+	if primaryRequired(ctx) {
+		return r.pool
+	}
+	return r.read
 }
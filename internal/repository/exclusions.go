@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// AddExclusion запрещает назначать reviewerID ревьюером PR автора authorID.
+// Повторный вызов для той же пары обновляет причину. Если кого-то из пользователей нет, возвращает ErrNotFound;
+// запрет на самого себя — ErrInvalidInput.
+func (r *Repository) AddExclusion(ctx context.Context, authorID, reviewerID, reason string) (*models.AssignmentExclusion, error) {
+	var aID, rID int64
+	usersQuery := `
+		SELECT
+			(SELECT id FROM users WHERE ` + r.userIDMatch("external_id", "$1") + `),
+			(SELECT id FROM users WHERE ` + r.userIDMatch("external_id", "$2") + `)
+	`
+	var authorRow, reviewerRow *int64
+	if err := r.pool.QueryRow(ctx, usersQuery, authorID, reviewerID).Scan(&authorRow, &reviewerRow); err != nil {
+		return nil, fmt.Errorf("failed to get users by external id: %w", err)
+	}
+	if authorRow == nil || reviewerRow == nil {
+		return nil, ErrNotFound
+	}
+	aID, rID = *authorRow, *reviewerRow
+	if aID == rID {
+		return nil, fmt.Errorf("%w: user cannot be excluded from reviewing own PRs", ErrInvalidInput)
+	}
+
+	query := `
+		WITH upserted AS (
+			INSERT INTO assignment_exclusions (author_user_id, reviewer_user_id, reason)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (author_user_id, reviewer_user_id) DO UPDATE SET reason = excluded.reason
+			RETURNING author_user_id, reviewer_user_id, reason, created_at
+		)
+		SELECT a.external_id AS author_id, rv.external_id AS reviewer_id, up.reason, up.created_at
+		FROM upserted up
+		JOIN users a ON a.id = up.author_user_id
+		JOIN users rv ON rv.id = up.reviewer_user_id
+	`
+	rows, err := r.pool.Query(ctx, query, aID, rID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add assignment exclusion: %w", err)
+	}
+	exclusion, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.AssignmentExclusion])
+	if err != nil {
+		return nil, fmt.Errorf("failed to add assignment exclusion: %w", err)
+	}
+	return &exclusion, nil
+}
+
+// RemoveExclusion снимает запрет назначать reviewerID ревьюером PR автора authorID.
+// Если такого запрета нет, возвращает ErrNotFound.
+func (r *Repository) RemoveExclusion(ctx context.Context, authorID, reviewerID string) error {
+	query := `
+		DELETE FROM assignment_exclusions ex
+		USING users a, users rv
+		WHERE ex.author_user_id = a.id
+		  AND ex.reviewer_user_id = rv.id
+		  AND ` + r.userIDMatch("a.external_id", "$1") + `
+		  AND ` + r.userIDMatch("rv.external_id", "$2")
+
+	tag, err := r.pool.Exec(ctx, query, authorID, reviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to remove assignment exclusion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListExclusions возвращает запреты, в которых пользователь userID — автор или ревьюер,
+// от новых к старым. Если пользователя нет, возвращает ErrNotFound.
+func (r *Repository) ListExclusions(ctx context.Context, userID string) ([]models.AssignmentExclusion, error) {
+	var uID int64
+	err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE `+r.userIDMatch("external_id", "$1"), userID).Scan(&uID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by external id: %w", err)
+	}
+
+	query := `
+		SELECT a.external_id AS author_id, rv.external_id AS reviewer_id, ex.reason, ex.created_at
+		FROM assignment_exclusions ex
+		JOIN users a ON a.id = ex.author_user_id
+		JOIN users rv ON rv.id = ex.reviewer_user_id
+		WHERE ex.author_user_id = $1 OR ex.reviewer_user_id = $1
+		ORDER BY ex.created_at DESC, a.external_id, rv.external_id
+	`
+	rows, err := r.pool.Query(ctx, query, uID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignment exclusions: %w", err)
+	}
+	exclusions, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.AssignmentExclusion])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect assignment exclusions: %w", err)
+	}
+	return exclusions, nil
+}
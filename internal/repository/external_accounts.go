@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// LinkExternalAccount привязывает учетную запись внешней системы к пользователю.
+// Уже привязанная к другому пользователю учетная запись перепривязывается.
+func (r *Repository) LinkExternalAccount(ctx context.Context, account models.ExternalAccount) error {
+	query := `
+		INSERT INTO user_external_accounts (provider, account_id, user_id)
+		SELECT $1, $2, u.id FROM users u WHERE ` + r.userIDMatch("u.external_id", "$3") + `
+		ON CONFLICT (provider, account_id) DO UPDATE SET user_id = EXCLUDED.user_id
+	`
+	tag, err := r.pool.Exec(ctx, query, account.Provider, account.AccountID, account.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to link external account: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	r.invalidateCache()
+	return nil
+}
+
+// AddExternalAccount привязывает учетную запись внешней системы к пользователю, не перепривязывая чужие:
+// учетная запись другого пользователя дает ErrAccountLinked, повторная привязка к тому же пользователю
+// ничего не меняет. Неизвестный пользователь — ErrNotFound.
+func (r *Repository) AddExternalAccount(ctx context.Context, account models.ExternalAccount) error {
+	// Подзапрос linked видит состояние до вставки: при конфликте в нем владелец учетной записи
+	query := `
+		WITH target AS (
+			SELECT u.id FROM users u WHERE ` + r.userIDMatch("u.external_id", "$3") + `
+		), inserted AS (
+			INSERT INTO user_external_accounts (provider, account_id, user_id)
+			SELECT $1, $2, id FROM target
+			ON CONFLICT (provider, account_id) DO NOTHING
+			RETURNING user_id
+		)
+		SELECT
+			(SELECT id FROM target),
+			(SELECT user_id FROM inserted),
+			(SELECT user_id FROM user_external_accounts WHERE provider = $1 AND account_id = $2)
+	`
+	var userID, insertedID, linkedID *int64
+	err := r.pool.QueryRow(ctx, query, account.Provider, account.AccountID, account.UserID).Scan(&userID, &insertedID, &linkedID)
+	if err != nil {
+		return fmt.Errorf("failed to add external account: %w", err)
+	}
+	switch {
+	case userID == nil:
+		return ErrNotFound
+	case insertedID != nil:
+		r.invalidateCache()
+		return nil
+	case linkedID != nil && *linkedID == *userID:
+		return nil
+	}
+	return ErrAccountLinked
+}
+
+// UnlinkExternalAccount отвязывает учетную запись внешней системы и возвращает ее вместе с внешним ID
+// пользователя, к которому она была привязана. Непривязанная учетная запись — ErrNotFound.
+func (r *Repository) UnlinkExternalAccount(ctx context.Context, provider, accountID string) (*models.ExternalAccount, error) {
+	account := &models.ExternalAccount{Provider: provider, AccountID: accountID}
+	err := r.pool.QueryRow(ctx, `
+		DELETE FROM user_external_accounts a
+		USING users u
+		WHERE u.id = a.user_id AND a.provider = $1 AND a.account_id = $2
+		RETURNING u.external_id
+	`, provider, accountID).Scan(&account.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlink external account: %w", err)
+	}
+	r.invalidateCache()
+	return account, nil
+}
+
+// getExternalAccounts возвращает из db учетные записи внешних систем пользователя
+func (r *Repository) getExternalAccounts(ctx context.Context, db DB, userID string) ([]models.ExternalAccount, error) {
+	query := `
+		SELECT u.external_id AS user_id, a.provider, a.account_id
+		FROM user_external_accounts a
+		JOIN users u ON u.id = a.user_id
+		WHERE ` + r.userIDMatch("u.external_id", "$1") + `
+		ORDER BY a.provider, a.account_id
+	`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get external accounts: %w", err)
+	}
+
+	accounts, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ExternalAccount])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect external accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetUserByExternalAccount возвращает внешний ID пользователя, привязанного к учетной записи внешней системы
+func (r *Repository) GetUserByExternalAccount(ctx context.Context, provider, accountID string) (string, error) {
+	var userID string
+	err := r.pool.QueryRow(ctx, `
+		SELECT u.external_id
+		FROM user_external_accounts a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.provider = $1 AND a.account_id = $2
+	`, provider, accountID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user by external account: %w", err)
+	}
+	return userID, nil
+}
+
+// ReserveWebhookDelivery отмечает доставку вебхука как принятую на ttl.
+// Возвращает false, если доставка с этим ID уже принималась и отметка еще не истекла.
+func (r *Repository) ReserveWebhookDelivery(ctx context.Context, provider, deliveryID string, ttl time.Duration) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (provider, delivery_id, expires_at)
+		VALUES ($1, $2, NOW()::timestamp + make_interval(secs => $3))
+		ON CONFLICT (provider, delivery_id) DO UPDATE
+		SET received_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE webhook_deliveries.expires_at <= NOW()
+	`, provider, deliveryID, ttl.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve webhook delivery: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseWebhookDelivery снимает отметку доставки, чтобы повтор был обработан заново
+func (r *Repository) ReleaseWebhookDelivery(ctx context.Context, provider, deliveryID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM webhook_deliveries WHERE provider = $1 AND delivery_id = $2`,
+		provider, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to release webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// PruneWebhookDeliveries удаляет истекшие отметки доставок вебхуков
+func (r *Repository) PruneWebhookDeliveries(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_deliveries WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
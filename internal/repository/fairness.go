@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// GetFairnessReport считает, сколько назначений ревьюером на PR команды получил с момента since каждый
+// активный участник команды, его долю от всех назначений и коэффициент Джини распределения.
+// Назначения берутся из журнала assignment_events: первичные, переназначения и ручные. Участники,
+// добавленные в середине окна, просто получают меньше назначений. Числа считаются в SQL, коэффициент — в Go.
+func (r *Repository) GetFairnessReport(ctx context.Context, teamName string, since time.Time) (*models.FairnessReport, error) {
+	var teamID int64
+	err := r.reader(ctx).QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team by name: %w", err)
+	}
+
+	query := `
+		SELECT u.external_id, u.username, COUNT(ae.id)
+		FROM team_users tu
+		JOIN users u ON u.id = tu.user_id
+		LEFT JOIN assignment_events ae ON ae.new_reviewer_id = u.id
+			AND ae.created_at >= $2
+			AND EXISTS (SELECT 1 FROM pull_requests pr WHERE pr.id = ae.pr_id AND pr.team_id = $1)
+		WHERE tu.team_id = $1 AND u.is_active = true
+		GROUP BY u.id, u.external_id, u.username
+		ORDER BY COUNT(ae.id) DESC, u.external_id
+	`
+	rows, err := r.reader(ctx).Query(ctx, query, teamID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count assignments: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.FairnessReport{
+		TeamName: teamName,
+		Since:    since.Format(time.DateOnly),
+		Members:  []models.MemberAssignmentShare{},
+	}
+	for rows.Next() {
+		var m models.MemberAssignmentShare
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Assignments); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment count: %w", err)
+		}
+		report.TotalAssignments += m.Assignments
+		report.Members = append(report.Members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate assignment counts: %w", err)
+	}
+
+	if report.TotalAssignments == 0 {
+		return report, nil
+	}
+	counts := make([]int, len(report.Members))
+	for i := range report.Members {
+		report.Members[i].Share = roundTo(float64(report.Members[i].Assignments)/float64(report.TotalAssignments), 4)
+		counts[i] = report.Members[i].Assignments
+	}
+	gini := roundTo(giniCoefficient(counts), 4)
+	report.Gini = &gini
+
+	return report, nil
+}
+
+// giniCoefficient возвращает коэффициент Джини неотрицательных значений: 0 при равном распределении,
+// (n-1)/n, если все приходится на одно значение. Для пустого набора и нулевой суммы возвращает 0.
+func giniCoefficient(values []int) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := make([]int, n)
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	// По упорядоченным значениям: G = Σ (2i - n - 1) * x_i / (n * Σ x_i), i = 1..n
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += float64(v)
+		weighted += float64(2*(i+1)-n-1) * float64(v)
+	}
+	if sum == 0 {
+		return 0
+	}
+	return weighted / (float64(n) * sum)
+}
+
+// roundTo округляет x до digits знаков после запятой
+func roundTo(x float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(x*p) / p
+}
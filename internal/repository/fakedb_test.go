@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// errFakeUnexpected возвращается fakeDB на запрос, который тест не ожидает
+var errFakeUnexpected = errors.New("fakeDB: unexpected query")
+
+// fakeDB — DB для модульных тестов без PostgreSQL. Запросы отдаются функциям query и exec,
+// которые по тексту SQL решают, что вернуть; транзакции — через begin (см. withTx).
+type fakeDB struct {
+	mu    sync.Mutex
+	calls []string
+
+	query func(sql string, args []any) (*fakeRows, error)
+	exec  func(sql string, args []any) (pgconn.CommandTag, error)
+	begin func() (pgx.Tx, error)
+}
+
+var _ DB = (*fakeDB)(nil)
+
+// record запоминает текст запроса без лишних пробелов
+func (f *fakeDB) record(sql string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, strings.Join(strings.Fields(sql), " "))
+}
+
+// queries возвращает выполненные запросы
+func (f *fakeDB) queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// run записывает запрос и получает его результат от f.query
+func (f *fakeDB) run(sql string, args []any) (*fakeRows, error) {
+	f.record(sql)
+	if f.query == nil {
+		return nil, fmt.Errorf("%w: %s", errFakeUnexpected, sql)
+	}
+	return f.query(sql, args)
+}
+
+func (f *fakeDB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := f.run(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	rows, err := f.run(sql, args)
+	return fakeRow{rows: rows, err: err}
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	f.record(sql)
+	if f.exec == nil {
+		return pgconn.CommandTag{}, fmt.Errorf("%w: %s", errFakeUnexpected, sql)
+	}
+	return f.exec(sql, args)
+}
+
+func (f *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	if f.begin == nil {
+		return nil, fmt.Errorf("%w: BEGIN", errFakeUnexpected)
+	}
+	return f.begin()
+}
+
+func (f *fakeDB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &fakeBatchResults{ctx: ctx, db: f, queued: b.QueuedQueries}
+}
+
+// fakeTx — транзакция поверх fakeDB: запросы уходят в ту же DB, Commit и Rollback считаются
+type fakeTx struct {
+	*fakeDB
+	commitErr error
+	commits   int
+	rollbacks int
+}
+
+var _ pgx.Tx = (*fakeTx)(nil)
+
+// withTx настраивает f.begin так, чтобы каждая транзакция была tx
+func (f *fakeDB) withTx(tx *fakeTx) {
+	tx.fakeDB = f
+	f.begin = func() (pgx.Tx, error) { return tx, nil }
+}
+
+func (t *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	return nil, errors.New("fakeTx: nested transactions")
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	t.commits++
+	return t.commitErr
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	t.rollbacks++
+	return nil
+}
+
+// CopyFrom читает все строки источника и сообщает, что они записаны
+func (t *fakeTx) CopyFrom(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+	var n int64
+	for src.Next() {
+		if _, err := src.Values(); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, src.Err()
+}
+
+func (t *fakeTx) LargeObjects() pgx.LargeObjects { return pgx.LargeObjects{} }
+
+func (t *fakeTx) Prepare(context.Context, string, string) (*pgconn.StatementDescription, error) {
+	return nil, errors.New("fakeTx: Prepare is not supported")
+}
+
+func (t *fakeTx) Conn() *pgx.Conn { return nil }
+
+// fakeBatchResults выполняет запросы batch'а по очереди через fakeDB
+type fakeBatchResults struct {
+	ctx    context.Context
+	db     *fakeDB
+	queued []*pgx.QueuedQuery
+}
+
+func (b *fakeBatchResults) next() (*pgx.QueuedQuery, error) {
+	if len(b.queued) == 0 {
+		return nil, errors.New("fakeDB: no more queued queries")
+	}
+	q := b.queued[0]
+	b.queued = b.queued[1:]
+	return q, nil
+}
+
+func (b *fakeBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := b.next()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return b.db.Exec(b.ctx, q.SQL, q.Arguments...)
+}
+
+func (b *fakeBatchResults) Query() (pgx.Rows, error) {
+	q, err := b.next()
+	if err != nil {
+		return nil, err
+	}
+	return b.db.Query(b.ctx, q.SQL, q.Arguments...)
+}
+
+func (b *fakeBatchResults) QueryRow() pgx.Row {
+	q, err := b.next()
+	if err != nil {
+		return fakeRow{err: err}
+	}
+	return b.db.QueryRow(b.ctx, q.SQL, q.Arguments...)
+}
+
+func (b *fakeBatchResults) Close() error { return nil }
+
+// fakeRows — строки результата с именованными колонками. Scan присваивает значения через reflect:
+// nil обнуляет приемник, значение T записывается в *T и в **T.
+type fakeRows struct {
+	cols []string
+	data [][]any
+	pos  int
+}
+
+// newFakeRows создает результат с колонками cols и строками data
+func newFakeRows(cols []string, data ...[]any) *fakeRows {
+	return &fakeRows{cols: cols, data: data}
+}
+
+func (r *fakeRows) Close()                        {}
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.NewCommandTag("SELECT") }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.cols))
+	for i, col := range r.cols {
+		fields[i].Name = col
+	}
+	return fields
+}
+
+func (r *fakeRows) Next() bool {
+	if r.pos >= len(r.data) {
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	return r.data[r.pos-1], nil
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.data[r.pos-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("fakeDB: scan into %d values, row has %d", len(dest), len(row))
+	}
+	for i, d := range dest {
+		if err := assign(d, row[i]); err != nil {
+			return fmt.Errorf("fakeDB: column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// fakeRow — pgx.Row из первой строки результата
+type fakeRow struct {
+	rows *fakeRows
+	err  error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if !r.rows.Next() {
+		return pgx.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+// assign записывает value в указатель dest
+func assign(dest, value any) error {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("destination %T is not a pointer", dest)
+	}
+	target = target.Elem()
+	if value == nil {
+		target.SetZero()
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(target.Type()):
+		target.Set(v)
+	case target.Kind() == reflect.Pointer && v.Type().AssignableTo(target.Type().Elem()):
+		p := reflect.New(target.Type().Elem())
+		p.Elem().Set(v)
+		target.Set(p)
+	case v.Type().ConvertibleTo(target.Type()):
+		target.Set(v.Convert(target.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", value, target.Type())
+	}
+	return nil
+}
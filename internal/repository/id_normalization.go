@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// foldedIDIndex — нормализованный индекс внешних ID пользователей (миграция 0004)
+const foldedIDIndex = "idx_users_external_id_folded"
+
+// ErrFoldedIndexNotUnique — нормализованный индекс внешних ID не уникален или отсутствует,
+// поэтому режим ID_NORMALIZATION=fold работать не может
+var ErrFoldedIndexNotUnique = errors.New("folded external_id index is not unique")
+
+// IDCollision — пользователи, чьи внешние ID совпадают после нормализации
+type IDCollision struct {
+	NormalizedID string
+	ExternalIDs  []string
+}
+
+// IDCollisionError сообщает, что нормализованный индекс не уникален, и перечисляет коллизии,
+// из-за которых миграция 0004 создала его без UNIQUE. Коллизий может не быть, если их уже
+// разрешили, а индекс не пересоздали. errors.Is(err, ErrFoldedIndexNotUnique).
+type IDCollisionError struct {
+	Collisions []IDCollision
+}
+
+func (e *IDCollisionError) Error() string {
+	if len(e.Collisions) == 0 {
+		return fmt.Sprintf("%s: no collisions left, recreate %s as UNIQUE", ErrFoldedIndexNotUnique, foldedIDIndex)
+	}
+	collisions := make([]string, 0, len(e.Collisions))
+	for _, c := range e.Collisions {
+		collisions = append(collisions, c.NormalizedID+" <- "+strings.Join(c.ExternalIDs, ", "))
+	}
+	return fmt.Sprintf("%s: %d external_id collisions after normalization: %s",
+		ErrFoldedIndexNotUnique, len(e.Collisions), strings.Join(collisions, "; "))
+}
+
+func (e *IDCollisionError) Unwrap() error {
+	return ErrFoldedIndexNotUnique
+}
+
+// Report возвращает отчет о коллизиях для оператора: по строке на нормализованный ID с исходными ID
+// в кавычках (видны пробелы) и подсказку, как восстановить уникальный индекс
+func (e *IDCollisionError) Report() string {
+	var b strings.Builder
+	if len(e.Collisions) == 0 {
+		fmt.Fprintf(&b, "index %s is missing or not unique, but no external_id collisions are left.\n", foldedIDIndex)
+	} else {
+		fmt.Fprintf(&b, "external_id collisions after normalization (ID_NORMALIZATION=fold), %d:\n", len(e.Collisions))
+		for _, c := range e.Collisions {
+			quoted := make([]string, 0, len(c.ExternalIDs))
+			for _, id := range c.ExternalIDs {
+				quoted = append(quoted, fmt.Sprintf("%q", id))
+			}
+			fmt.Fprintf(&b, "  %s <- %s\n", c.NormalizedID, strings.Join(quoted, ", "))
+		}
+		b.WriteString("Resolve them (see the user_external_id_collisions view), then ")
+	}
+	fmt.Fprintf(&b, "recreate the index: DROP INDEX %[1]s; CREATE UNIQUE INDEX %[1]s ON users (lower(btrim(external_id)));\n", foldedIDIndex)
+	return b.String()
+}
+
+// CheckFoldedIDIndex проверяет, что нормализованный индекс внешних ID уникален. Без него режим fold
+// мог бы считать одним пользователем нескольких, поэтому сервис с ID_NORMALIZATION=fold не запускается.
+// Если индекс отсутствует или не уникален, возвращает *IDCollisionError с текущими коллизиями.
+func (r *Repository) CheckFoldedIDIndex(ctx context.Context) error {
+	var unique bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE((SELECT indisunique FROM pg_index WHERE indexrelid = to_regclass($1)), false)
+	`, foldedIDIndex).Scan(&unique)
+	if err != nil {
+		return fmt.Errorf("failed to check folded external_id index: %w", err)
+	}
+	if unique {
+		return nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT normalized_id, external_ids FROM user_external_id_collisions ORDER BY normalized_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to get external_id collisions: %w", err)
+	}
+	defer rows.Close()
+
+	collisionErr := &IDCollisionError{}
+	for rows.Next() {
+		var c IDCollision
+		if err := rows.Scan(&c.NormalizedID, &c.ExternalIDs); err != nil {
+			return fmt.Errorf("failed to scan external_id collision: %w", err)
+		}
+		collisionErr.Collisions = append(collisionErr.Collisions, c)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate external_id collisions: %w", err)
+	}
+	return collisionErr
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// fakeUser — строка таблицы users в fakeUsers
+type fakeUser struct {
+	id         int64
+	externalID string
+	name       string
+	active     bool
+}
+
+// fakeUsers — таблица users для проверки режимов ID_NORMALIZATION без PostgreSQL. Она выполняет
+// сравнение внешнего ID так, как его строит userIDMatch (как есть или lower(btrim(...))), и upsert
+// по обычному или нормализованному уникальному индексу.
+type fakeUsers struct {
+	rows []*fakeUser
+}
+
+// userIDPredicate — условие на внешний ID пользователя в запросе: нормализованное или строгое и номер параметра
+var userIDPredicate = regexp.MustCompile(`(lower\(btrim\()?(?:u\.)?external_id(?:\)\))? = \$(\d+)`)
+
+// foldID нормализует внешний ID так же, как lower(btrim(...)) в PostgreSQL
+func foldID(id string) string {
+	return strings.ToLower(strings.Trim(id, " "))
+}
+
+// matching возвращает пользователей, подходящих под условие на внешний ID в sql
+func (u *fakeUsers) matching(sql string, args []any) []*fakeUser {
+	m := userIDPredicate.FindStringSubmatch(sql)
+	if m == nil {
+		return nil
+	}
+	n, _ := strconv.Atoi(m[2])
+	want := args[n-1].(string)
+
+	var found []*fakeUser
+	for _, row := range u.rows {
+		if row.externalID == want || m[1] != "" && foldID(row.externalID) == want {
+			found = append(found, row)
+		}
+	}
+	return found
+}
+
+// upsert выполняет массовый INSERT ... ON CONFLICT из upsertTeam
+func (u *fakeUsers) upsert(sql string, args []any) *fakeRows {
+	key := func(id string) string { return id }
+	if strings.Contains(sql, "ON CONFLICT ((lower(btrim(external_id))))") {
+		key = foldID
+	}
+	rewriteID := strings.Contains(sql, "SET external_id = excluded.external_id")
+
+	ids, names, active := args[0].([]string), args[1].([]string), args[2].([]bool)
+	rows := newFakeRows([]string{"id", "external_id"})
+	for i, id := range ids {
+		var row *fakeUser
+		for _, existing := range u.rows {
+			if key(existing.externalID) == key(id) {
+				row = existing
+			}
+		}
+		if row == nil {
+			row = &fakeUser{id: int64(len(u.rows) + 1), externalID: id}
+			u.rows = append(u.rows, row)
+		} else if rewriteID {
+			row.externalID = id
+		}
+		row.name, row.active = names[i], active[i]
+		rows.data = append(rows.data, []any{row.id, row.externalID})
+	}
+	return rows
+}
+
+// db возвращает fakeDB поверх таблицы: запросы, не касающиеся users, возвращают пустой результат
+func (u *fakeUsers) db() *fakeDB {
+	db := &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			switch {
+			case strings.Contains(sql, "INSERT INTO teams"):
+				return newFakeRows([]string{"id"}, []any{int64(1)}), nil
+			case strings.Contains(sql, "INSERT INTO users"):
+				return u.upsert(sql, args), nil
+			case strings.Contains(sql, "as team_name"):
+				rows := newFakeRows([]string{"external_id", "name", "team_name", "is_active", "is_reviewer", "paused", "paused_until"})
+				for _, row := range u.matching(sql, args) {
+					rows.data = append(rows.data, []any{row.externalID, row.name, "backend", row.active, true, false, nil})
+				}
+				return rows, nil
+			}
+			return newFakeRows(nil), nil
+		},
+		exec: func(sql string, args []any) (pgconn.CommandTag, error) {
+			if !strings.HasPrefix(strings.TrimSpace(sql), "UPDATE users SET is_active") {
+				return pgconn.NewCommandTag("DELETE 0"), nil
+			}
+			found := u.matching(sql, args)
+			for _, row := range found {
+				row.active = args[0].(bool)
+			}
+			return pgconn.NewCommandTag("UPDATE " + strconv.Itoa(len(found))), nil
+		},
+	}
+	db.withTx(&fakeTx{})
+	return db
+}
+
+func TestFoldedUserIDsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	// Пользователь создан до включения нормализации, ID на входе приходит уже нормализованным
+	const legacyID, normalizedID = " Alice ", "alice"
+
+	t.Run("fold", func(t *testing.T) {
+		users := &fakeUsers{rows: []*fakeUser{{id: 1, externalID: legacyID, name: "Alice", active: true}}}
+		r := New(users.db(), Options{FoldUserIDs: true})
+
+		user, err := r.GetUser(ctx, normalizedID)
+		require.NoError(t, err)
+		assert.Equal(t, legacyID, user.UserID, "the legacy row is found by its normalized ID")
+
+		require.NoError(t, r.UpdateUserStatus(ctx, normalizedID, false))
+		assert.False(t, users.rows[0].active)
+
+		_, err = r.CreateTeam(ctx, models.Team{
+			TeamName: "backend",
+			Members:  []models.TeamMember{{UserID: normalizedID, Username: "Alice A.", IsActive: true}},
+		})
+		require.NoError(t, err)
+		require.Len(t, users.rows, 1, "the member is the existing user, not a new one")
+		assert.Equal(t, normalizedID, users.rows[0].externalID, "the legacy ID is rewritten to the normalized one")
+		assert.Equal(t, "Alice A.", users.rows[0].name)
+
+		user, err = r.GetUser(ctx, normalizedID)
+		require.NoError(t, err)
+		assert.Equal(t, normalizedID, user.UserID)
+		assert.True(t, user.IsActive)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		users := &fakeUsers{rows: []*fakeUser{{id: 1, externalID: legacyID, name: "Alice", active: true}}}
+		r := New(users.db(), Options{})
+
+		_, err := r.GetUser(ctx, normalizedID)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.ErrorIs(t, r.UpdateUserStatus(ctx, normalizedID, false), ErrNotFound)
+		assert.True(t, users.rows[0].active)
+
+		_, err = r.CreateTeam(ctx, models.Team{
+			TeamName: "backend",
+			Members:  []models.TeamMember{{UserID: normalizedID, Username: "Alice A.", IsActive: true}},
+		})
+		require.NoError(t, err)
+		require.Len(t, users.rows, 2, "IDs differing in case and spaces are different users")
+		assert.Equal(t, legacyID, users.rows[0].externalID)
+		assert.Equal(t, normalizedID, users.rows[1].externalID)
+
+		user, err := r.GetUser(ctx, legacyID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.Username)
+	})
+}
+
+// foldedIndexDB возвращает fakeDB, где нормализованный индекс уникален при unique, а представление
+// user_external_id_collisions содержит collisions
+func foldedIndexDB(unique bool, collisions ...IDCollision) *fakeDB {
+	return &fakeDB{
+		query: func(sql string, _ []any) (*fakeRows, error) {
+			switch {
+			case strings.Contains(sql, "FROM pg_index"):
+				return newFakeRows([]string{"indisunique"}, []any{unique}), nil
+			case strings.Contains(sql, "FROM user_external_id_collisions"):
+				rows := newFakeRows([]string{"normalized_id", "external_ids"})
+				for _, c := range collisions {
+					rows.data = append(rows.data, []any{c.NormalizedID, c.ExternalIDs})
+				}
+				return rows, nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+}
+
+func TestCheckFoldedIDIndex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unique index", func(t *testing.T) {
+		db := foldedIndexDB(true)
+		require.NoError(t, New(db, Options{FoldUserIDs: true}).CheckFoldedIDIndex(ctx))
+		assert.Len(t, db.queries(), 1, "collisions are not read when the index is unique")
+	})
+
+	t.Run("collisions", func(t *testing.T) {
+		collisions := []IDCollision{
+			{NormalizedID: "alice", ExternalIDs: []string{"Alice", "alice "}},
+			{NormalizedID: "bob", ExternalIDs: []string{"BOB", "bob"}},
+		}
+		err := New(foldedIndexDB(false, collisions...), Options{FoldUserIDs: true}).CheckFoldedIDIndex(ctx)
+
+		require.ErrorIs(t, err, ErrFoldedIndexNotUnique)
+		var collisionErr *IDCollisionError
+		require.ErrorAs(t, err, &collisionErr)
+		assert.Equal(t, collisions, collisionErr.Collisions)
+		assert.EqualError(t, err,
+			"folded external_id index is not unique: 2 external_id collisions after normalization: alice <- Alice, alice ; bob <- BOB, bob")
+
+		report := collisionErr.Report()
+		assert.Contains(t, report, "external_id collisions after normalization (ID_NORMALIZATION=fold), 2:\n")
+		assert.Contains(t, report, "  alice <- \"Alice\", \"alice \"\n", "trailing spaces are visible in the report")
+		assert.Contains(t, report, "  bob <- \"BOB\", \"bob\"\n")
+		assert.Contains(t, report, "user_external_id_collisions")
+		assert.Contains(t, report, "CREATE UNIQUE INDEX idx_users_external_id_folded ON users (lower(btrim(external_id)));")
+	})
+
+	t.Run("resolved collisions without unique index", func(t *testing.T) {
+		err := New(foldedIndexDB(false), Options{FoldUserIDs: true}).CheckFoldedIDIndex(ctx)
+
+		var collisionErr *IDCollisionError
+		require.ErrorAs(t, err, &collisionErr)
+		assert.Empty(t, collisionErr.Collisions)
+		assert.EqualError(t, err, "folded external_id index is not unique: no collisions left, recreate idx_users_external_id_folded as UNIQUE")
+		assert.Contains(t, collisionErr.Report(), "no external_id collisions are left")
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		errDown := errors.New("connection refused")
+		db := &fakeDB{query: func(string, []any) (*fakeRows, error) { return nil, errDown }}
+
+		err := New(db, Options{FoldUserIDs: true}).CheckFoldedIDIndex(ctx)
+
+		require.ErrorIs(t, err, errDown)
+		assert.NotErrorIs(t, err, ErrFoldedIndexNotUnique, "an unreachable database is not reported as collisions")
+	})
+}
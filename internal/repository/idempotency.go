@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// ReserveIdempotencyKey резервирует ключ за запросом с хэшем тела requestHash на срок lease.
+// Если ключ свободен (или истек), возвращает nil: запрос нужно выполнить и затем вызвать
+// CompleteIdempotencyKey или ReleaseIdempotencyKey. Иначе возвращает сохраненную запись.
+// Резерв, не завершенный за lease (например, процесс упал посреди запроса), истекает,
+// и ключ можно занять снова.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, key models.IdempotencyKey, requestHash string, lease time.Duration) (*models.IdempotencyRecord, error) {
+	var reserved bool
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (idempotency_key, method, path, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, NOW()::timestamp + make_interval(secs => $5))
+		ON CONFLICT (idempotency_key, method, path) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING true
+	`, key.Key, key.Method, key.Path, requestHash, lease.Seconds()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	// Ключ занят действующей записью
+	var record models.IdempotencyRecord
+	var statusCode *int
+	err = r.pool.QueryRow(ctx, `
+		SELECT request_hash, status_code, response_body
+		FROM idempotency_keys
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3
+	`, key.Key, key.Method, key.Path).Scan(&record.RequestHash, &statusCode, &record.ResponseBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Запись удалили между запросами (ReleaseIdempotencyKey или очистка) — пробуем еще раз
+		return r.ReserveIdempotencyKey(ctx, key, requestHash, lease)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ для зарезервированного ключа и продлевает запись на ttl
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, key models.IdempotencyKey, statusCode int, body []byte, ttl time.Duration) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $4, response_body = $5, expires_at = NOW()::timestamp + make_interval(secs => $6)
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3
+	`, key.Key, key.Method, key.Path, statusCode, body, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey снимает резерв ключа, чтобы повтор запроса выполнился заново
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, key models.IdempotencyKey) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3
+	`, key.Key, key.Method, key.Path)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PruneIdempotencyKeys удаляет истекшие ключи идемпотентности
+func (r *Repository) PruneIdempotencyKeys(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadDB — команда с открытыми ревью в памяти. Запрос кандидатов отдает участников команды без исключенных
+// в порядке его ORDER BY: по числу открытых ревью, если запрос сортирует по load.open_reviews,
+// а равные (или всех при ORDER BY RANDOM()) — в случайном порядке от rng.
+type loadDB struct {
+	*fakeDB
+	members []int64
+	load    map[int64]int
+}
+
+func newLoadDB(members []int64, rng *rand.Rand) *loadDB {
+	db := &loadDB{members: members, load: make(map[int64]int)}
+	db.fakeDB = &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			switch {
+			case strings.Contains(sql, "FROM team_settings"):
+				return newFakeRows([]string{"reviewers_per_pr", "assignment_strategy", "always_include_lead"}), nil
+			case strings.Contains(sql, "SELECT tu.user_id, load.open_reviews"):
+				exclude := args[1].([]int64)
+				limit := args[2].(int)
+				var ids []int64
+				for _, id := range db.members {
+					if !slices.Contains(exclude, id) {
+						ids = append(ids, id)
+					}
+				}
+				rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+				if strings.Contains(sql, "ORDER BY load.open_reviews,") {
+					sort.SliceStable(ids, func(i, j int) bool { return db.load[ids[i]] < db.load[ids[j]] })
+				}
+				rows := newFakeRows([]string{"user_id", "open_reviews"})
+				for _, id := range ids[:min(limit, len(ids))] {
+					rows.data = append(rows.data, []any{id, db.load[id]})
+				}
+				return rows, nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+	db.withTx(&fakeTx{})
+	return db
+}
+
+// createPRs назначает ревьюеров на n PR так же, как CreatePR (pickReviewers с настройками команды по умолчанию),
+// и засчитывает каждое назначение в открытые ревью. author возвращает автора i-го PR.
+func (db *loadDB) createPRs(t *testing.T, r *Repository, n int, author func(i int) int64) {
+	t.Helper()
+	for i := range n {
+		tx, err := db.Begin(context.Background())
+		require.NoError(t, err)
+		reviewers, _, err := r.pickReviewers(context.Background(), tx, 1, author(i), 0, false)
+		require.NoError(t, err)
+		require.Len(t, reviewers, reviewersPerPR)
+		for _, c := range reviewers {
+			require.NotEqual(t, author(i), c.userID, "the author never reviews own PR")
+			db.load[c.userID]++
+		}
+	}
+}
+
+// spread возвращает разницу между самым и наименее загруженным из ids
+func (db *loadDB) spread(ids []int64) int {
+	lo, hi := db.load[ids[0]], db.load[ids[0]]
+	for _, id := range ids {
+		lo, hi = min(lo, db.load[id]), max(hi, db.load[id])
+	}
+	return hi - lo
+}
+
+func TestLeastLoadedSpread(t *testing.T) {
+	team := []int64{1, 2, 3, 4}
+
+	// Разрыв не должен зависеть от того, как разрешились ничьи
+	for seed := range uint64(50) {
+		t.Run("author outside the reviewers", func(t *testing.T) {
+			db := newLoadDB(team, rand.New(rand.NewPCG(seed, 1)))
+			r := New(db, Options{AssignmentStrategy: StrategyLeastLoaded})
+
+			db.createPRs(t, r, 10, func(int) int64 { return 100 })
+
+			assert.LessOrEqual(t, db.spread(team), 1, "seed %d, load %v", seed, db.load)
+		})
+
+		t.Run("authors from the team", func(t *testing.T) {
+			db := newLoadDB(team, rand.New(rand.NewPCG(seed, 2)))
+			r := New(db, Options{AssignmentStrategy: StrategyLeastLoaded})
+
+			db.createPRs(t, r, 10, func(i int) int64 { return team[i%len(team)] })
+
+			assert.LessOrEqual(t, db.spread(team), 2, "seed %d, load %v", seed, db.load)
+		})
+	}
+}
+
+func TestLeastLoadedIsDefaultStrategy(t *testing.T) {
+	db := newLoadDB([]int64{1, 2, 3, 4}, rand.New(rand.NewPCG(1, 1)))
+	r := New(db, Options{})
+	db.load = map[int64]int{1: 3, 2: 0, 3: 5, 4: 1}
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	reviewers, settings, err := r.pickReviewers(context.Background(), tx, 1, 100, 0, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, StrategyLeastLoaded, settings.Strategy)
+	require.Len(t, reviewers, 2)
+	assert.Equal(t, int64(2), reviewers[0].userID)
+	assert.Equal(t, int64(4), reviewers[1].userID)
+	assert.Equal(t, 0, reviewers[0].openReviews)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// SnapshotReviewerLoad сохраняет число открытых ревью каждого активного пользователя на текущую дату.
+// Снимок идемпотентен в пределах дня: повторный вызов перезаписывает значения того же дня.
+func (r *Repository) SnapshotReviewerLoad(ctx context.Context) (int64, error) {
+	query := `
+		INSERT INTO reviewer_load_history (snapshot_date, user_id, open_reviews)
+		SELECT CURRENT_DATE, u.id, COUNT(pr.id)
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON prr.reviewer_id = u.id
+		LEFT JOIN pull_requests pr ON pr.id = prr.pr_id AND pr.status = $1
+		WHERE u.is_active = true
+		GROUP BY u.id
+		ON CONFLICT (snapshot_date, user_id) DO UPDATE
+		SET open_reviews = excluded.open_reviews, created_at = NOW()
+	`
+	tag, err := r.pool.Exec(ctx, query, models.StatusOpen)
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot reviewer load: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneReviewerLoadHistory удаляет снимки старше указанной даты
+func (r *Repository) PruneReviewerLoadHistory(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM reviewer_load_history WHERE snapshot_date < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune reviewer load history: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetUserLoadHistory возвращает ряд загрузки пользователя за период [from, to]
+func (r *Repository) GetUserLoadHistory(ctx context.Context, userID string, from, to time.Time) ([]models.LoadHistorySeries, error) {
+	var exists bool
+	existsQuery := `SELECT EXISTS(SELECT 1 FROM users WHERE ` + r.userIDMatch("external_id", "$1") + `)`
+	if err := r.reader(ctx).QueryRow(ctx, existsQuery, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	query := `
+		SELECT u.external_id, u.name, to_char(h.snapshot_date, 'YYYY-MM-DD'), h.open_reviews
+		FROM reviewer_load_history h
+		JOIN users u ON u.id = h.user_id
+		WHERE ` + r.userIDMatch("u.external_id", "$1") + `
+		  AND h.snapshot_date BETWEEN $2 AND $3
+		ORDER BY u.external_id, h.snapshot_date
+	`
+	return r.queryLoadHistory(ctx, query, userID, from, to)
+}
+
+// GetTeamLoadHistory возвращает ряды загрузки текущих участников команды за период [from, to]
+func (r *Repository) GetTeamLoadHistory(ctx context.Context, teamName string, from, to time.Time) ([]models.LoadHistorySeries, error) {
+	var exists bool
+	if err := r.reader(ctx).QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)`, teamName).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	query := `
+		SELECT u.external_id, u.name, to_char(h.snapshot_date, 'YYYY-MM-DD'), h.open_reviews
+		FROM reviewer_load_history h
+		JOIN users u ON u.id = h.user_id
+		JOIN team_users tu ON tu.user_id = h.user_id
+		JOIN teams t ON t.id = tu.team_id
+		WHERE t.name = $1
+		  AND h.snapshot_date BETWEEN $2 AND $3
+		ORDER BY u.external_id, h.snapshot_date
+	`
+	return r.queryLoadHistory(ctx, query, teamName, from, to)
+}
+
+// queryLoadHistory выполняет запрос истории загрузки и группирует точки по пользователям.
+// Строки запроса должны быть отсортированы по пользователю и дате.
+func (r *Repository) queryLoadHistory(ctx context.Context, query string, args ...any) ([]models.LoadHistorySeries, error) {
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer load history: %w", err)
+	}
+	defer rows.Close()
+
+	series := make([]models.LoadHistorySeries, 0)
+	for rows.Next() {
+		var userID, username string
+		var point models.LoadHistoryPoint
+		if err := rows.Scan(&userID, &username, &point.Date, &point.OpenReviews); err != nil {
+			return nil, fmt.Errorf("failed to scan load history point: %w", err)
+		}
+		if len(series) == 0 || series[len(series)-1].UserID != userID {
+			series = append(series, models.LoadHistorySeries{UserID: userID, Username: username})
+		}
+		last := &series[len(series)-1]
+		last.Points = append(last.Points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return series, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// loadKey — ключ reviewer_load_history
+type loadKey struct {
+	date time.Time
+	user string
+}
+
+// loadHistoryDB — reviewer_load_history для запросов load_history.go: снимок пишется так, как его
+// записал бы INSERT ... ON CONFLICT (snapshot_date, user_id) DO UPDATE, история выбирается по BETWEEN
+type loadHistoryDB struct {
+	t *testing.T
+	// today — CURRENT_DATE базы
+	today time.Time
+	// openReviews — текущее число открытых ревью активных пользователей
+	openReviews map[string]int
+	history     map[loadKey]int
+}
+
+func (l *loadHistoryDB) fake() *fakeDB {
+	return &fakeDB{
+		exec: func(sql string, args []any) (pgconn.CommandTag, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			require.Contains(l.t, sql, "INSERT INTO reviewer_load_history (snapshot_date, user_id, open_reviews) SELECT CURRENT_DATE")
+			require.Contains(l.t, sql, "ON CONFLICT (snapshot_date, user_id) DO UPDATE SET open_reviews = excluded.open_reviews")
+			require.Equal(l.t, []any{models.StatusOpen}, args)
+			for user, open := range l.openReviews {
+				l.history[loadKey{date: l.today, user: user}] = open
+			}
+			return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", len(l.openReviews))), nil
+		},
+		query: func(sql string, args []any) (*fakeRows, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			if strings.HasPrefix(sql, "SELECT EXISTS") {
+				return newFakeRows([]string{"exists"}, []any{true}), nil
+			}
+			require.Contains(l.t, sql, "AND h.snapshot_date BETWEEN $2 AND $3 ORDER BY u.external_id, h.snapshot_date")
+			from, to := args[1].(time.Time), args[2].(time.Time)
+			var keys []loadKey
+			for key := range l.history {
+				if strings.Contains(sql, "u.external_id = $1") && key.user != args[0] {
+					continue
+				}
+				if !key.date.Before(from) && !key.date.After(to) {
+					keys = append(keys, key)
+				}
+			}
+			slices.SortFunc(keys, func(a, b loadKey) int {
+				if c := strings.Compare(a.user, b.user); c != 0 {
+					return c
+				}
+				return a.date.Compare(b.date)
+			})
+			data := make([][]any, 0, len(keys))
+			for _, key := range keys {
+				data = append(data, []any{key.user, "name " + key.user, key.date.Format(time.DateOnly), l.history[key]})
+			}
+			return newFakeRows([]string{"external_id", "name", "to_char", "open_reviews"}, data...), nil
+		},
+	}
+}
+
+func TestSnapshotReviewerLoadIsIdempotentWithinDay(t *testing.T) {
+	day := time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC)
+	db := &loadHistoryDB{
+		t:           t,
+		today:       day,
+		openReviews: map[string]int{"u1": 2, "u2": 0, "u3": 5},
+		history:     map[loadKey]int{},
+	}
+	r := New(db.fake(), Options{})
+	ctx := context.Background()
+
+	n, err := r.SnapshotReviewerLoad(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	// Повторный снимок в тот же день (перезапуск воркера) перезаписывает значения дня, а не добавляет строки
+	db.openReviews["u1"] = 3
+	n, err = r.SnapshotReviewerLoad(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, map[loadKey]int{
+		{date: day, user: "u1"}: 3,
+		{date: day, user: "u2"}: 0,
+		{date: day, user: "u3"}: 5,
+	}, db.history)
+
+	// Снимок следующего дня добавляет новую точку каждому пользователю
+	db.today = day.AddDate(0, 0, 1)
+	_, err = r.SnapshotReviewerLoad(ctx)
+	require.NoError(t, err)
+	assert.Len(t, db.history, 6)
+
+	series, err := r.GetUserLoadHistory(ctx, "u1", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, []models.LoadHistoryPoint{
+		{Date: "2025-11-20", OpenReviews: 3},
+		{Date: "2025-11-21", OpenReviews: 3},
+	}, series[0].Points)
+}
+
+func TestLoadHistoryRange(t *testing.T) {
+	// История u1 и u2 за 1–10 ноября: в день d у u1 d открытых ревью, у u2 — 10*d
+	history := map[loadKey]int{}
+	date := func(d int) time.Time { return time.Date(2025, 11, d, 0, 0, 0, 0, time.UTC) }
+	for d := 1; d <= 10; d++ {
+		history[loadKey{date: date(d), user: "u1"}] = d
+		history[loadKey{date: date(d), user: "u2"}] = 10 * d
+	}
+	r := New((&loadHistoryDB{t: t, history: history}).fake(), Options{})
+	ctx := context.Background()
+
+	points := func(user string, days ...int) models.LoadHistorySeries {
+		s := models.LoadHistorySeries{UserID: user, Username: "name " + user}
+		for _, d := range days {
+			open := d
+			if user == "u2" {
+				open = 10 * d
+			}
+			s.Points = append(s.Points, models.LoadHistoryPoint{Date: date(d).Format(time.DateOnly), OpenReviews: open})
+		}
+		return s
+	}
+
+	cases := []struct {
+		name     string
+		from, to time.Time
+		user     []models.LoadHistorySeries
+		team     []models.LoadHistorySeries
+	}{
+		{
+			name: "both bounds inclusive", from: date(3), to: date(5),
+			user: []models.LoadHistorySeries{points("u1", 3, 4, 5)},
+			team: []models.LoadHistorySeries{points("u1", 3, 4, 5), points("u2", 3, 4, 5)},
+		},
+		{
+			name: "single day", from: date(7), to: date(7),
+			user: []models.LoadHistorySeries{points("u1", 7)},
+			team: []models.LoadHistorySeries{points("u1", 7), points("u2", 7)},
+		},
+		{
+			name: "range wider than history", from: date(9), to: date(30),
+			user: []models.LoadHistorySeries{points("u1", 9, 10)},
+			team: []models.LoadHistorySeries{points("u1", 9, 10), points("u2", 9, 10)},
+		},
+		{
+			name: "range before history", from: date(1).AddDate(0, 0, -7), to: date(1).AddDate(0, 0, -1),
+			user: []models.LoadHistorySeries{},
+			team: []models.LoadHistorySeries{},
+		},
+		{
+			name: "from after to", from: date(5), to: date(3),
+			user: []models.LoadHistorySeries{},
+			team: []models.LoadHistorySeries{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := r.GetUserLoadHistory(ctx, "u1", tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.user, user)
+
+			team, err := r.GetTeamLoadHistory(ctx, "backend", tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.team, team)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SweepOrphanedReviews переназначает до limit ревью в открытых PR, назначенных на пользователей,
+// деактивированных раньше inactiveBefore (ревью, оставшиеся после деактивации без reassign_reviews).
+// Замена выбирается как при автоматическом переназначении; если кандидата нет, ревьюер просто снимается.
+// Изменения записываются в журнал с причиной DEACTIVATION_SWEEP. Назначения моложе MinAssignmentAge
+// не трогаются и попадают в SkippedRecent (не больше limit).
+// PR блокируются с SKIP LOCKED, поэтому параллельные запуски на нескольких экземплярах сервиса
+// обрабатывают разные PR и не ждут друг друга.
+func (r *Repository) SweepOrphanedReviews(ctx context.Context, inactiveBefore time.Time, limit int) (_ *models.OrphanSweepResult, err error) {
+	ctx, span := startSpan(ctx, "SweepOrphanedReviews", attribute.Int("sweep.limit", limit))
+	defer func() { endSpan(span, err) }()
+
+	result := &models.OrphanSweepResult{
+		Reassigned:    make([]models.SweptReview, 0),
+		Unassigned:    make([]models.SweptReview, 0),
+		SkippedRecent: make([]models.SweptReview, 0),
+	}
+	minAge := r.opts.MinAssignmentAge.Seconds()
+	err = r.inTx(ctx, "SweepOrphanedReviews", func(tx pgx.Tx) error {
+		result.Reassigned, result.Unassigned = result.Reassigned[:0], result.Unassigned[:0]
+
+		rows, err := tx.Query(ctx, `
+			SELECT pr.id, pr.author_id, prr.reviewer_id, pr.external_id, pr.repository, u.external_id
+			FROM pr_reviewers prr
+			JOIN pull_requests pr ON pr.id = prr.pr_id
+			JOIN users u ON u.id = prr.reviewer_id
+			WHERE pr.status = $1
+			  AND NOT u.is_active
+			  AND u.deactivated_at < $2
+			  AND prr.created_at <= NOW()::timestamp - make_interval(secs => $4)
+			ORDER BY u.deactivated_at, pr.id, prr.reviewer_id
+			LIMIT $3
+			FOR UPDATE OF pr SKIP LOCKED
+		`, models.StatusOpen, inactiveBefore, limit, minAge)
+		if err != nil {
+			return fmt.Errorf("failed to get orphaned reviews: %w", err)
+		}
+
+		type orphanedReview struct {
+			prID, authorID, reviewerID int64
+			review                     models.SweptReview
+		}
+		var orphaned []orphanedReview
+		for rows.Next() {
+			var o orphanedReview
+			if err := rows.Scan(&o.prID, &o.authorID, &o.reviewerID,
+				&o.review.PullRequestID, &o.review.Repository, &o.review.OldReviewerID); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan orphaned review: %w", err)
+			}
+			orphaned = append(orphaned, o)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate orphaned reviews: %w", err)
+		}
+
+		for _, o := range orphaned {
+			newReviewerID, err := r.replaceReviewer(ctx, tx, o.prID, o.authorID, o.reviewerID, true, models.AssignmentReasonDeactivationSweep)
+			if err != nil {
+				return err
+			}
+			if err := bumpPRVersion(ctx, tx, o.prID, nil); err != nil {
+				return err
+			}
+			if newReviewerID == 0 {
+				result.Unassigned = append(result.Unassigned, o.review)
+				continue
+			}
+
+			err = tx.QueryRow(ctx, `SELECT external_id FROM users WHERE id = $1`, newReviewerID).Scan(&o.review.NewReviewerID)
+			if err != nil {
+				return fmt.Errorf("failed to get new reviewer external id: %w", err)
+			}
+			result.Reassigned = append(result.Reassigned, o.review)
+		}
+
+		if r.opts.MinAssignmentAge > 0 {
+			result.SkippedRecent, err = skippedRecentOrphans(ctx, tx, inactiveBefore, limit, minAge)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// skippedRecentOrphans возвращает до limit ревью давно деактивированных пользователей в открытых PR,
+// назначенных позже minAge секунд назад, — их очистка не трогает
+func skippedRecentOrphans(ctx context.Context, tx pgx.Tx, inactiveBefore time.Time, limit int, minAge float64) ([]models.SweptReview, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT pr.external_id, pr.repository, u.external_id
+		FROM pr_reviewers prr
+		JOIN pull_requests pr ON pr.id = prr.pr_id
+		JOIN users u ON u.id = prr.reviewer_id
+		WHERE pr.status = $1
+		  AND NOT u.is_active
+		  AND u.deactivated_at < $2
+		  AND prr.created_at > NOW()::timestamp - make_interval(secs => $4)
+		ORDER BY u.deactivated_at, pr.id, prr.reviewer_id
+		LIMIT $3
+	`, models.StatusOpen, inactiveBefore, limit, minAge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent orphaned reviews: %w", err)
+	}
+	defer rows.Close()
+
+	skipped := make([]models.SweptReview, 0)
+	for rows.Next() {
+		var review models.SweptReview
+		if err := rows.Scan(&review.PullRequestID, &review.Repository, &review.OldReviewerID); err != nil {
+			return nil, fmt.Errorf("failed to scan recent orphaned review: %w", err)
+		}
+		skipped = append(skipped, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate recent orphaned reviews: %w", err)
+	}
+	return skipped, nil
+}
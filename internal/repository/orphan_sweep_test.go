@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// sweepDB отвечает на запросы очистки: кандидатов на переназначение нет, а недавнее ревью — одно.
+// В ages записывается возраст назначения, переданный в каждый запрос.
+func sweepDB(ages *[]float64) *fakeDB {
+	db := &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			*ages = append(*ages, args[3].(float64))
+			switch {
+			case strings.Contains(sql, "prr.created_at <= NOW()"):
+				return newFakeRows([]string{"id", "author_id", "reviewer_id", "external_id", "repository", "external_id"}), nil
+			case strings.Contains(sql, "prr.created_at > NOW()"):
+				return newFakeRows([]string{"external_id", "repository", "external_id"},
+					[]any{"pr-1", "", "u5"}), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+	db.withTx(&fakeTx{})
+	return db
+}
+
+func TestSweepOrphanedReviewsHonoursMinAssignmentAge(t *testing.T) {
+	var ages []float64
+	r := New(sweepDB(&ages), Options{MinAssignmentAge: 2 * time.Hour})
+
+	result, err := r.SweepOrphanedReviews(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []float64{7200, 7200}, ages, "both the sweep and the skipped query filter by assignment age")
+	assert.Empty(t, result.Reassigned)
+	assert.Empty(t, result.Unassigned)
+	assert.Equal(t, []models.SweptReview{{PullRequestID: "pr-1", OldReviewerID: "u5"}}, result.SkippedRecent)
+}
+
+func TestSweepOrphanedReviewsWithoutMinAssignmentAge(t *testing.T) {
+	var ages []float64
+	r := New(sweepDB(&ages), Options{})
+
+	result, err := r.SweepOrphanedReviews(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []float64{0}, ages, "without MIN_ASSIGNMENT_AGE_HOURS recent reviews are not looked up")
+	assert.NotNil(t, result.SkippedRecent)
+	assert.Empty(t, result.SkippedRecent)
+}
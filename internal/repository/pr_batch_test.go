@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// batchDB возвращает fakeDB, который отдает каждый запрошенный PR с двумя ревьюерами:
+// PR — по списку внешних ID, ревьюеров — по списку внутренних ID
+func batchDB() *fakeDB {
+	created := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	return &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			switch {
+			case strings.Contains(sql, "FROM pull_requests pr"):
+				var data [][]any
+				for i, id := range args[0].([]string) {
+					data = append(data, []any{
+						int64(i + 1), id, "", "title " + id, "author", models.StatusOpen, created, nil, nil,
+						nil, nil, nil, int64(1), "", "", "", "", []string{},
+					})
+				}
+				return newFakeRows([]string{"id", "external_id", "repository", "title", "author", "status",
+					"created_at", "merged_at", "closed_at", "created_by", "merged_by", "closed_by", "version",
+					"description", "source_branch", "target_branch", "url", "labels"}, data...), nil
+			case strings.Contains(sql, "FROM pr_reviewers prr"):
+				var data [][]any
+				for _, id := range args[0].([]int64) {
+					for _, reviewer := range []string{"u1", "u2"} {
+						data = append(data, []any{id, reviewer, reviewer, true, false, nil, models.ReviewerSourceTeam, nil})
+					}
+				}
+				return newFakeRows([]string{"pr_id", "external_id", "name", "is_active", "approved",
+					"approved_at", "source", "review_due_at"}, data...), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+}
+
+func TestBatchReadsUseConstantQueries(t *testing.T) {
+	// Число запросов не зависит от числа PR: один запрос PR и один запрос ревьюеров на весь batch
+	for _, n := range []int{1, 10, 100, 1000} {
+		t.Run(fmt.Sprintf("%d PRs", n), func(t *testing.T) {
+			ids := make([]string, n)
+			internalIDs := make([]int64, n)
+			for i := range ids {
+				ids[i] = fmt.Sprintf("pr-%d", i+1)
+				internalIDs[i] = int64(i + 1)
+			}
+
+			db := batchDB()
+			r := New(db, Options{})
+			prs, missing, err := r.GetPRsBatch(context.Background(), nil, ids)
+			require.NoError(t, err)
+			assert.Empty(t, missing)
+			require.Len(t, prs, n)
+			for _, id := range ids {
+				assert.Len(t, prs[id].AssignedReviewers, 2, "reviewers of %s", id)
+			}
+			assert.Len(t, db.queries(), 2, "getPRsBatch must not query per PR")
+
+			db = batchDB()
+			r = New(db, Options{})
+			reviewers, err := r.getReviewersForPRs(context.Background(), internalIDs)
+			require.NoError(t, err)
+			assert.Len(t, reviewers, n)
+			assert.Len(t, db.queries(), 1, "getReviewersForPRs must not query per PR")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor — курсор страницы не разбирается
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PRCursor — позиция в списке PR, упорядоченном по (created_at, id): последний PR предыдущей страницы.
+// Клиенту передается непрозрачной строкой (см. Encode, DecodePRCursor).
+type PRCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// prCursorPayload — сериализованный вид курсора
+type prCursorPayload struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+}
+
+// Encode возвращает курсор в виде base64 (URL-safe, без выравнивания)
+func (c PRCursor) Encode() string {
+	data, _ := json.Marshal(prCursorPayload{CreatedAt: c.CreatedAt.UTC(), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePRCursor разбирает курсор, полученный от клиента
+func DecodePRCursor(s string) (*PRCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload prCursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.ID <= 0 || payload.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &PRCursor{CreatedAt: payload.CreatedAt, ID: payload.ID}, nil
+}
+
+// PageInfo — общее число PR по фильтрам и курсор следующей страницы
+type PageInfo struct {
+	Total int
+	// NextCursor — курсор следующей страницы; пустой, если страниц больше нет
+	// или список отсортирован не по created_at
+	NextCursor string
+}
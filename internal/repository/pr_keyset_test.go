@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// keysetRow — PR таблицы keysetDB
+type keysetRow struct {
+	id        int64
+	createdAt time.Time
+}
+
+// keysetDB — pull_requests одной команды для listPRs: страница выбирается так же, как ее выбрал бы
+// SQL с условием курсора, ORDER BY (created_at, id) и LIMIT/OFFSET
+type keysetDB struct {
+	t    *testing.T
+	rows []keysetRow
+}
+
+// insert добавляет n PR, созданных после всех существующих; два соседних PR делят время создания
+func (k *keysetDB) insert(n int) {
+	base := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	for range n {
+		id := int64(len(k.rows) + 1)
+		k.rows = append(k.rows, keysetRow{id: id, createdAt: base.Add(time.Duration(id/2) * time.Minute)})
+	}
+}
+
+func (k *keysetDB) fake() *fakeDB {
+	return &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			sql = strings.Join(strings.Fields(sql), " ")
+			switch {
+			case strings.Contains(sql, "FROM teams"):
+				return newFakeRows([]string{"id"}, []any{int64(7)}), nil
+			case strings.HasPrefix(sql, "SELECT COUNT(*)"):
+				return newFakeRows([]string{"count"}, []any{len(k.rows)}), nil
+			case strings.Contains(sql, "FROM pr_reviewers"):
+				return newFakeRows(nil), nil
+			case strings.HasPrefix(sql, "SELECT pr.id"):
+				return k.page(sql, args), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+}
+
+// page выбирает строки страницы по тексту и значениям запроса listPRs
+func (k *keysetDB) page(sql string, args []any) *fakeRows {
+	desc := strings.Contains(sql, "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC")
+	require.True(k.t, desc || strings.Contains(sql, "ORDER BY pr.created_at ASC NULLS LAST, pr.id ASC"), sql)
+	less := func(a, b keysetRow) bool {
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.Before(b.createdAt)
+		}
+		return a.id < b.id
+	}
+
+	rows := slices.Clone(k.rows)
+	if strings.Contains(sql, "(pr.created_at, pr.id)") {
+		cursor := keysetRow{createdAt: args[1].(time.Time), id: args[2].(int64)}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		require.Contains(k.t, sql, "(pr.created_at, pr.id) "+op+" ($2::timestamp, $3::bigint)")
+		rows = slices.DeleteFunc(rows, func(r keysetRow) bool {
+			if desc {
+				return !less(r, cursor)
+			}
+			return !less(cursor, r)
+		})
+	}
+	slices.SortFunc(rows, func(a, b keysetRow) int {
+		if less(a, b) == desc {
+			return 1
+		}
+		return -1
+	})
+	limit, offset := args[len(args)-2].(int), args[len(args)-1].(int)
+	rows = rows[min(offset, len(rows)):]
+	rows = rows[:min(limit, len(rows))]
+
+	data := make([][]any, 0, len(rows))
+	for _, r := range rows {
+		data = append(data, []any{r.id, fmt.Sprintf("pr-%d", r.id), "", "title", "author", models.StatusOpen, []string{}, r.createdAt, nil})
+	}
+	return newFakeRows([]string{"id", "external_id", "repository", "title", "author", "status", "labels", "created_at", "merged_at"}, data...)
+}
+
+// listAll проходит список команды страницами по 100, вызывая between после каждой страницы,
+// и возвращает ID прочитанных PR по порядку
+func listAll(t *testing.T, r *Repository, filter PRListFilter, useCursor bool, between func(page int)) []string {
+	t.Helper()
+	filter.Limit = 100
+	var ids []string
+	for page := 1; ; page++ {
+		require.Less(t, page, 10, "pagination does not terminate")
+		prs, info, err := r.ListTeamPRs(context.Background(), "backend", filter)
+		require.NoError(t, err)
+		for _, pr := range prs {
+			ids = append(ids, pr.PullRequestID)
+		}
+		if useCursor {
+			if info.NextCursor == "" {
+				return ids
+			}
+			filter.Cursor, err = DecodePRCursor(info.NextCursor)
+			require.NoError(t, err)
+		} else {
+			if len(prs) < filter.Limit {
+				return ids
+			}
+			filter.Offset += filter.Limit
+		}
+		between(page)
+	}
+}
+
+// prIDs возвращает внешние ID PR с from по to включительно, в порядке обхода
+func prIDs(from, to int64) []string {
+	var ids []string
+	step := int64(1)
+	if from > to {
+		step = -1
+	}
+	for id := from; id != to+step; id += step {
+		ids = append(ids, fmt.Sprintf("pr-%d", id))
+	}
+	return ids
+}
+
+func TestKeysetPaginationWithConcurrentInserts(t *testing.T) {
+	// После каждой страницы появляется 15 новых PR, новее всех прочитанных
+	insertBetweenPages := func(db *keysetDB) func(int) {
+		return func(int) { db.insert(15) }
+	}
+
+	t.Run("desc: new PRs stay ahead of the cursor", func(t *testing.T) {
+		db := &keysetDB{t: t}
+		db.insert(250)
+		ids := listAll(t, New(db.fake(), Options{}), PRListFilter{}, true, insertBetweenPages(db))
+		assert.Equal(t, prIDs(250, 1), ids, "every seeded PR exactly once, newest first")
+	})
+
+	t.Run("asc: new PRs are appended after the last page", func(t *testing.T) {
+		db := &keysetDB{t: t}
+		db.insert(250)
+		ids := listAll(t, New(db.fake(), Options{}), PRListFilter{Order: SortOrderAsc}, true, insertBetweenPages(db))
+		require.GreaterOrEqual(t, len(ids), 250)
+		assert.Equal(t, prIDs(1, 250), ids[:250], "seeded PRs in order without gaps")
+		assert.Equal(t, prIDs(251, int64(len(ids))), ids[250:], "inserted PRs follow without gaps")
+	})
+
+	t.Run("offset pagination repeats rows under the same inserts", func(t *testing.T) {
+		db := &keysetDB{t: t}
+		db.insert(250)
+		ids := listAll(t, New(db.fake(), Options{}), PRListFilter{}, false, insertBetweenPages(db))
+		assert.NotEqual(t, len(ids), len(slices.Compact(slices.Sorted(slices.Values(ids)))),
+			"offset pages shift when new PRs arrive; the cursor path exists to avoid this")
+	})
+}
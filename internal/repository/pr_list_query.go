@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Поля сортировки списков PR
+const (
+	PRSortCreatedAt = "created_at"
+	PRSortMergedAt  = "merged_at"
+)
+
+// Направления сортировки списков PR
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// prSortColumns и sortDirections — допустимые значения сортировки и их SQL.
+// В текст запроса попадают только значения из этих таблиц, но не ввод пользователя.
+var (
+	prSortColumns = map[string]string{
+		PRSortCreatedAt: "pr.created_at",
+		PRSortMergedAt:  "pr.merged_at",
+	}
+	sortDirections = map[string]string{
+		SortOrderAsc:  "ASC",
+		SortOrderDesc: "DESC",
+	}
+)
+
+// ValidPRSort сообщает, поддерживается ли поле сортировки списков PR (пустое — сортировка по умолчанию)
+func ValidPRSort(sort string) bool {
+	_, ok := prSortColumns[sort]
+	return ok || sort == ""
+}
+
+// ValidSortOrder сообщает, поддерживается ли направление сортировки (пустое — по умолчанию desc)
+func ValidSortOrder(order string) bool {
+	_, ok := sortDirections[order]
+	return ok || order == ""
+}
+
+// PRListFilter задает общие фильтры, сортировку и пагинацию списков PR ревьюера, команды и автора.
+// Нулевые значения означают отсутствие фильтра. Границы *After включаются в диапазон, *Before — нет.
+type PRListFilter struct {
+	// Status — статус PR, пустой означает любой статус
+	Status string
+	// Label оставляет только PR с этой меткой, пустая означает любые метки
+	Label string
+	// Repository оставляет только PR этого репозитория, nil означает любой репозиторий
+	Repository    *string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// MergedAfter и MergedBefore оставляют только слитые PR с временем слияния в диапазоне
+	MergedAfter  *time.Time
+	MergedBefore *time.Time
+	// Sort — поле сортировки (PRSortCreatedAt по умолчанию), Order — направление (SortOrderDesc по умолчанию)
+	Sort  string
+	Order string
+	Limit int
+	// Offset — смещение страницы; масштабируется хуже курсора и при вставке новых PR между страницами
+	// дает пропуски и повторы
+	Offset int
+	// Cursor — продолжить после этого PR (keyset-пагинация). Только при сортировке по created_at и без Offset
+	Cursor *PRCursor
+}
+
+// prListQuery собирает условия WHERE, ORDER BY и пагинацию списка PR. Значения передаются только
+// через плейсхолдеры $n в порядке добавления, текст условий задает код репозитория.
+type prListQuery struct {
+	conds []string
+	args  []any
+}
+
+// newPRListQuery создает построитель; args — значения плейсхолдеров, уже занятых условиями вызывающего ($1, $2, ...)
+func newPRListQuery(args ...any) *prListQuery {
+	return &prListQuery{args: args}
+}
+
+// arg добавляет значение и возвращает его плейсхолдер
+func (q *prListQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// where добавляет условие; условия объединяются через AND
+func (q *prListQuery) where(cond string) {
+	q.conds = append(q.conds, cond)
+}
+
+// applyFilter добавляет условия общих фильтров. Время приводится к UTC: колонки хранят время без часового пояса.
+func (q *prListQuery) applyFilter(f PRListFilter) {
+	if f.Status != "" {
+		q.where("pr.status = " + q.arg(f.Status))
+	}
+	if f.Label != "" {
+		q.where("pr.labels @> " + q.arg(labelFilter(f.Label)) + "::text[]")
+	}
+	if f.Repository != nil {
+		q.where("pr.repository = " + q.arg(*f.Repository))
+	}
+	q.timeRange("pr.created_at", f.CreatedAfter, f.CreatedBefore)
+	q.timeRange("pr.merged_at", f.MergedAfter, f.MergedBefore)
+}
+
+// timeRange добавляет условия полуинтервала [after, before) по колонке
+func (q *prListQuery) timeRange(column string, after, before *time.Time) {
+	if after != nil {
+		q.where(column + " >= " + q.arg(after.UTC()))
+	}
+	if before != nil {
+		q.where(column + " < " + q.arg(before.UTC()))
+	}
+}
+
+// applyCursor добавляет условие keyset-пагинации: PR строго после курсора в порядке (created_at, id).
+// Сравнение кортежей обслуживается индексом idx_pull_requests_created_at_id.
+// Вызывается после запроса общего числа: курсор на total не влияет.
+func (q *prListQuery) applyCursor(f PRListFilter) error {
+	if f.Cursor == nil {
+		return nil
+	}
+	if f.Sort != "" && f.Sort != PRSortCreatedAt {
+		return fmt.Errorf("%w: cursor requires sorting by %s", ErrInvalidInput, PRSortCreatedAt)
+	}
+	if f.Offset > 0 {
+		return fmt.Errorf("%w: cursor and offset are mutually exclusive", ErrInvalidInput)
+	}
+	op := "<"
+	if f.Order == SortOrderAsc {
+		op = ">"
+	}
+	q.where("(pr.created_at, pr.id) " + op + " (" + q.arg(f.Cursor.CreatedAt.UTC()) + "::timestamp, " + q.arg(f.Cursor.ID) + "::bigint)")
+	return nil
+}
+
+// snapshot возвращает WHERE и копию значений условий, добавленных к этому моменту
+func (q *prListQuery) snapshot() (string, []any) {
+	args := make([]any, len(q.args))
+	copy(args, q.args)
+	return q.whereSQL(), args
+}
+
+// nextPRCursor возвращает курсор следующей страницы по позициям прочитанных строк. Страница запрашивается
+// с запасом в одну строку (limit+1): если строк пришло больше limit, следующая страница есть и начинается
+// после последней строки в пределах limit. При сортировке не по created_at курсор не выдается.
+func nextPRCursor(f PRListFilter, positions []PRCursor) string {
+	if f.Limit <= 0 || len(positions) <= f.Limit || (f.Sort != "" && f.Sort != PRSortCreatedAt) {
+		return ""
+	}
+	return positions[f.Limit-1].Encode()
+}
+
+// whereSQL возвращает WHERE со всеми условиями или пустую строку
+func (q *prListQuery) whereSQL() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.conds, "\n\t\t  AND ")
+}
+
+// orderSQL возвращает ORDER BY по полю и направлению фильтра. PR без времени слияния при сортировке
+// по merged_at идут последними в обоих направлениях; при равенстве порядок задает pr.id.
+func (q *prListQuery) orderSQL(f PRListFilter) (string, error) {
+	sort, order := f.Sort, f.Order
+	if sort == "" {
+		sort = PRSortCreatedAt
+	}
+	if order == "" {
+		order = SortOrderDesc
+	}
+	column, ok := prSortColumns[sort]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown sort field %q", ErrInvalidInput, sort)
+	}
+	direction, ok := sortDirections[order]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown sort order %q", ErrInvalidInput, order)
+	}
+	return "ORDER BY " + column + " " + direction + " NULLS LAST, pr.id " + direction, nil
+}
+
+// page возвращает LIMIT/OFFSET и значения для запроса страницы; значения условий построителя не меняются
+func (q *prListQuery) page(limit, offset int) (string, []any) {
+	args := make([]any, len(q.args), len(q.args)+2)
+	copy(args, q.args)
+	n := len(args)
+	args = append(args, limit, offset)
+	return "LIMIT $" + strconv.Itoa(n+1) + " OFFSET $" + strconv.Itoa(n+2), args
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// placeholderRe находит плейсхолдеры $n в тексте запроса
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+// requirePlaceholders проверяет, что плейсхолдеры запроса — ровно $1..$n по числу значений
+func requirePlaceholders(t *testing.T, sql string, args []any) {
+	t.Helper()
+	used := map[int]bool{}
+	for _, m := range placeholderRe.FindAllStringSubmatch(sql, -1) {
+		n, err := strconv.Atoi(m[1])
+		require.NoError(t, err)
+		used[n] = true
+	}
+	for n := 1; n <= len(args); n++ {
+		assert.True(t, used[n], "placeholder $%d has a value but is not used in %q", n, sql)
+	}
+	assert.Len(t, used, len(args), "placeholders in %q do not match %d args", sql, len(args))
+}
+
+func TestPRListQuery(t *testing.T) {
+	after := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	before := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	repo := "backend"
+	cursor := &PRCursor{CreatedAt: time.Date(2025, 3, 15, 9, 30, 0, 0, time.UTC), ID: 42}
+
+	// Вызовы повторяют порядок GetPRsByReviewer и GetTeamPRs: условие вызывающего занимает $1
+	cases := []struct {
+		name      string
+		filter    PRListFilter
+		where     string
+		countArgs []any
+		order     string
+		limit     string
+		pageArgs  []any
+	}{
+		{
+			name:      "no filters",
+			filter:    PRListFilter{Limit: 20},
+			where:     "WHERE pr.scope = $1",
+			countArgs: []any{"scope"},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $2 OFFSET $3",
+			pageArgs:  []any{"scope", 21, 0},
+		},
+		{
+			name:      "status",
+			filter:    PRListFilter{Status: models.StatusOpen, Limit: 10, Offset: 30},
+			where:     "WHERE pr.scope = $1\n\t\t  AND pr.status = $2",
+			countArgs: []any{"scope", models.StatusOpen},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $3 OFFSET $4",
+			pageArgs:  []any{"scope", models.StatusOpen, 11, 30},
+		},
+		{
+			name:      "label and repository",
+			filter:    PRListFilter{Label: "bug", Repository: &repo, Limit: 5},
+			where:     "WHERE pr.scope = $1\n\t\t  AND pr.labels @> $2::text[]\n\t\t  AND pr.repository = $3",
+			countArgs: []any{"scope", []string{"bug"}, "backend"},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $4 OFFSET $5",
+			pageArgs:  []any{"scope", []string{"bug"}, "backend", 6, 0},
+		},
+		{
+			name:      "created range converted to UTC",
+			filter:    PRListFilter{CreatedAfter: &after, CreatedBefore: &before, Limit: 5},
+			where:     "WHERE pr.scope = $1\n\t\t  AND pr.created_at >= $2\n\t\t  AND pr.created_at < $3",
+			countArgs: []any{"scope", after.UTC(), before},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $4 OFFSET $5",
+			pageArgs:  []any{"scope", after.UTC(), before, 6, 0},
+		},
+		{
+			name:      "merged range sorted by merged_at asc",
+			filter:    PRListFilter{MergedAfter: &after, Sort: PRSortMergedAt, Order: SortOrderAsc, Limit: 5},
+			where:     "WHERE pr.scope = $1\n\t\t  AND pr.merged_at >= $2",
+			countArgs: []any{"scope", after.UTC()},
+			order:     "ORDER BY pr.merged_at ASC NULLS LAST, pr.id ASC",
+			limit:     "LIMIT $3 OFFSET $4",
+			pageArgs:  []any{"scope", after.UTC(), 6, 0},
+		},
+		{
+			name: "all filters",
+			filter: PRListFilter{
+				Status: models.StatusMerged, Label: "bug", Repository: &repo,
+				CreatedAfter: &after, CreatedBefore: &before, MergedAfter: &after, MergedBefore: &before,
+				Limit: 50, Offset: 100,
+			},
+			where: "WHERE pr.scope = $1\n\t\t  AND pr.status = $2\n\t\t  AND pr.labels @> $3::text[]\n\t\t  AND pr.repository = $4" +
+				"\n\t\t  AND pr.created_at >= $5\n\t\t  AND pr.created_at < $6\n\t\t  AND pr.merged_at >= $7\n\t\t  AND pr.merged_at < $8",
+			countArgs: []any{"scope", models.StatusMerged, []string{"bug"}, "backend", after.UTC(), before, after.UTC(), before},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $9 OFFSET $10",
+			pageArgs:  []any{"scope", models.StatusMerged, []string{"bug"}, "backend", after.UTC(), before, after.UTC(), before, 51, 100},
+		},
+		{
+			name:      "cursor desc",
+			filter:    PRListFilter{Cursor: cursor, Limit: 20},
+			where:     "WHERE pr.scope = $1",
+			countArgs: []any{"scope"},
+			order:     "ORDER BY pr.created_at DESC NULLS LAST, pr.id DESC",
+			limit:     "LIMIT $4 OFFSET $5",
+			pageArgs:  []any{"scope", cursor.CreatedAt, int64(42), 21, 0},
+		},
+		{
+			name:      "cursor asc after status filter",
+			filter:    PRListFilter{Status: models.StatusOpen, Cursor: cursor, Sort: PRSortCreatedAt, Order: SortOrderAsc, Limit: 20},
+			where:     "WHERE pr.scope = $1\n\t\t  AND pr.status = $2",
+			countArgs: []any{"scope", models.StatusOpen},
+			order:     "ORDER BY pr.created_at ASC NULLS LAST, pr.id ASC",
+			limit:     "LIMIT $5 OFFSET $6",
+			pageArgs:  []any{"scope", models.StatusOpen, cursor.CreatedAt, int64(42), 21, 0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newPRListQuery("scope")
+			q.where("pr.scope = $1")
+			q.applyFilter(tc.filter)
+			order, err := q.orderSQL(tc.filter)
+			require.NoError(t, err)
+			countWhere, countArgs := q.snapshot()
+			require.NoError(t, q.applyCursor(tc.filter))
+			limit, pageArgs := q.page(tc.filter.Limit+1, tc.filter.Offset)
+
+			assert.Equal(t, tc.where, countWhere)
+			assert.Equal(t, tc.countArgs, countArgs)
+			assert.Equal(t, tc.order, order)
+			assert.Equal(t, tc.limit, limit)
+			assert.Equal(t, tc.pageArgs, pageArgs)
+
+			// Запрос числа строк не видит курсора, запрос страницы — видит
+			requirePlaceholders(t, countWhere, countArgs)
+			pageWhere := q.whereSQL()
+			requirePlaceholders(t, pageWhere+" "+order+" "+limit, pageArgs)
+			if tc.filter.Cursor != nil {
+				n := len(countArgs)
+				op := "<"
+				if tc.filter.Order == SortOrderAsc {
+					op = ">"
+				}
+				assert.Equal(t, countWhere+"\n\t\t  AND (pr.created_at, pr.id) "+op+
+					" ($"+strconv.Itoa(n+1)+"::timestamp, $"+strconv.Itoa(n+2)+"::bigint)", pageWhere)
+			} else {
+				assert.Equal(t, countWhere, pageWhere)
+			}
+		})
+	}
+}
+
+func TestPRListQueryErrors(t *testing.T) {
+	cursor := &PRCursor{CreatedAt: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), ID: 1}
+
+	t.Run("unknown sort", func(t *testing.T) {
+		_, err := newPRListQuery().orderSQL(PRListFilter{Sort: "title"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+	t.Run("unknown order", func(t *testing.T) {
+		_, err := newPRListQuery().orderSQL(PRListFilter{Order: "sideways"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+	t.Run("cursor with merged_at sort", func(t *testing.T) {
+		q := newPRListQuery()
+		assert.ErrorIs(t, q.applyCursor(PRListFilter{Cursor: cursor, Sort: PRSortMergedAt}), ErrInvalidInput)
+		assert.Empty(t, q.args, "rejected cursor must not add args")
+	})
+	t.Run("cursor with offset", func(t *testing.T) {
+		q := newPRListQuery()
+		assert.ErrorIs(t, q.applyCursor(PRListFilter{Cursor: cursor, Offset: 10}), ErrInvalidInput)
+		assert.Empty(t, q.args, "rejected cursor must not add args")
+	})
+	t.Run("snapshot is not affected by later conditions", func(t *testing.T) {
+		q := newPRListQuery("scope")
+		where, args := q.snapshot()
+		q.where("pr.status = " + q.arg(models.StatusOpen))
+		assert.Empty(t, where)
+		assert.Equal(t, []any{"scope"}, args)
+	})
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// prMetadataColumns — сведения о PR в порядке полей models.PRMetadata (таблица pull_requests под псевдонимом pr)
+const prMetadataColumns = `COALESCE(pr.description, ''), COALESCE(pr.source_branch, ''), COALESCE(pr.target_branch, ''), COALESCE(pr.url, ''), pr.labels`
+
+// UpdatePRMetadata частично изменяет сведения о PR независимо от его статуса и возвращает обновленный PR.
+// Статус, название и ревьюеры не меняются. ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) UpdatePRMetadata(ctx context.Context, pullRequestID string, update models.PRMetadataUpdate) (*models.PullRequest, error) {
+	id, err := r.lookupPR(ctx, r.pool, prRef(pullRequestID), false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		UPDATE pull_requests
+		SET description = NULLIF(COALESCE($2, description), ''),
+		    source_branch = NULLIF(COALESCE($3, source_branch), ''),
+		    target_branch = NULLIF(COALESCE($4, target_branch), ''),
+		    url = NULLIF(COALESCE($5, url), ''),
+		    labels = COALESCE($6::text[], labels),
+		    version = version + 1,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING id
+	`, id, update.Description, update.SourceBranch, update.TargetBranch, update.URL, update.Labels).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update PR metadata: %w", err)
+	}
+
+	return r.getPRByID(ctx, id)
+}
+
+// labelFilter возвращает значение для условия `pr.labels @> $n::text[]`: пустой массив без метки
+// (условие выполняется для любого PR) или массив из одной метки. Проверка вхождения массива
+// обслуживается GIN-индексом idx_pull_requests_labels.
+func labelFilter(label string) []string {
+	if label == "" {
+		return []string{}
+	}
+	return []string{label}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// lookupPR возвращает внутренний ID PR по ссылке ref. Без репозитория PR ищется только по внешнему ID:
+// если такой ID есть в нескольких репозиториях, возвращается ErrAmbiguousPR. С forUpdate строка PR
+// блокируется до конца транзакции q. Если PR нет, возвращает ErrNotFound.
+func (r *Repository) lookupPR(ctx context.Context, q DB, ref models.PRRef, forUpdate bool) (int64, error) {
+	query := `
+		SELECT id FROM pull_requests
+		WHERE external_id = $1 AND ($2::text IS NULL OR repository = $2)
+		ORDER BY id
+		LIMIT 2`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := q.Query(ctx, query, ref.ID, ref.Repository)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get PR by external id: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("failed to get PR by external id: %w", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return 0, ErrNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, ErrAmbiguousPR
+	}
+}
+
+// prRef — ссылка на PR по внешнему ID без репозитория (для методов, которые принимают только ID)
+func prRef(pullRequestID string) models.PRRef {
+	return models.PRRef{ID: pullRequestID}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы подстрока искалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchPRs ищет PR по подстроке названия без учета регистра и возвращает страницу результатов и их общее число.
+// Пустые teamName и status означают отсутствие фильтра; teamName отбирает PR авторов команды.
+// PR упорядочены от новых к старым. При временных ошибках чтение повторяется.
+func (r *Repository) SearchPRs(ctx context.Context, query, teamName, status string, limit, offset int) (prs []models.PullRequestSearchResult, total int, err error) {
+	err = r.retry(ctx, "SearchPRs", func() (err error) {
+		prs, total, err = r.searchPRs(ctx, query, teamName, status, limit, offset)
+		return err
+	})
+	return prs, total, err
+}
+
+// searchPRs выполняет поиск без повторов
+func (r *Repository) searchPRs(ctx context.Context, query, teamName, status string, limit, offset int) ([]models.PullRequestSearchResult, int, error) {
+	var teamID *int64
+	if teamName != "" {
+		var id int64
+		err := r.pool.QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, ErrNotFound
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get team by name: %w", err)
+		}
+		teamID = &id
+	}
+
+	// Подстрока ищется через ILIKE: по шаблону '%...%' работает триграммный индекс idx_pull_requests_title_trgm
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+	filter := `
+		WHERE pr.title ILIKE $1
+		  AND ($2::bigint IS NULL OR EXISTS (
+			SELECT 1 FROM team_users tu WHERE tu.user_id = pr.author_id AND tu.team_id = $2))
+		  AND ($3::text = '' OR pr.status = $3)
+	`
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT pr.external_id, pr.repository, pr.title, u.external_id, pr.status, pr.created_at
+		FROM pull_requests pr
+		JOIN users u ON u.id = pr.author_id
+	`+filter+`
+		ORDER BY pr.created_at DESC, pr.id DESC
+		LIMIT $4 OFFSET $5
+	`, pattern, teamID, status, limit, offset)
+	batch.Queue(`
+		SELECT COUNT(*)
+		FROM pull_requests pr
+	`+filter, pattern, teamID, status)
+
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search PRs: %w", err)
+	}
+	prs := []models.PullRequestSearchResult{}
+	for rows.Next() {
+		var pr models.PullRequestSearchResult
+		if err := rows.Scan(&pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate PRs: %w", err)
+	}
+
+	var total int
+	if err := results.QueryRow().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count PRs: %w", err)
+	}
+
+	return prs, total, nil
+}
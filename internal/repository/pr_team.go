@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AmbiguousTeamError сообщает, что автор состоит в нескольких командах и команду PR нужно указать явно
+type AmbiguousTeamError struct {
+	Teams []string
+}
+
+func (e *AmbiguousTeamError) Error() string {
+	return "author belongs to several teams, team_name is required: " + strings.Join(e.Teams, ", ")
+}
+
+func (e *AmbiguousTeamError) Unwrap() error {
+	return ErrAmbiguousTeam
+}
+
+// resolveAuthorTeam возвращает внутренний ID команды, из которой назначаются ревьюеры PR.
+// Если teamName задан, автор должен в ней состоять (иначе ErrAuthorNotInTeam).
+// Без teamName для автора из нескольких команд при RejectAmbiguousTeam возвращается *AmbiguousTeamError,
+// иначе выбирается команда, созданная раньше остальных.
+func (r *Repository) resolveAuthorTeam(ctx context.Context, tx pgx.Tx, authorID int64, teamName string) (int64, error) {
+	if teamName != "" {
+		var teamID int64
+		var member bool
+		err := tx.QueryRow(ctx, `
+			SELECT t.id, EXISTS(SELECT 1 FROM team_users tu WHERE tu.team_id = t.id AND tu.user_id = $2)
+			FROM teams t
+			WHERE t.name = $1
+		`, teamName, authorID).Scan(&teamID, &member)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get team: %w", err)
+		}
+		if !member {
+			return 0, ErrAuthorNotInTeam
+		}
+		return teamID, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, t.name
+		FROM team_users tu
+		JOIN teams t ON t.id = tu.team_id
+		WHERE tu.user_id = $1
+		ORDER BY t.id
+	`, authorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get author's teams: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var names []string
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return 0, fmt.Errorf("failed to scan author's team: %w", err)
+		}
+		ids = append(ids, id)
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate author's teams: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, ErrNotFound
+	}
+	if len(ids) > 1 && r.opts.RejectAmbiguousTeam {
+		return 0, &AmbiguousTeamError{Teams: names}
+	}
+	return ids[0], nil
+}
+
+// prTeamID возвращает команду, из которой назначаются ревьюеры PR. Для PR без сохраненной команды
+// (команда удалена) берется команда автора, созданная раньше остальных.
+// Если команды нет, возвращает ErrNotFound.
+func (r *Repository) prTeamID(ctx context.Context, tx pgx.Tx, prID int64) (int64, error) {
+	var teamID *int64
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(
+			pr.team_id,
+			(SELECT MIN(tu.team_id) FROM team_users tu WHERE tu.user_id = pr.author_id)
+		)
+		FROM pull_requests pr
+		WHERE pr.id = $1
+	`, prID).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get PR team: %w", err)
+	}
+	if teamID == nil {
+		return 0, ErrNotFound
+	}
+	return *teamID, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrVersionConflict — версия PR не совпала с ожидаемой: PR успели изменить другим запросом
+var ErrVersionConflict = errors.New("PR version conflict")
+
+// prVersionMatch — условие оптимистической блокировки для WHERE изменения PR: без ожидаемой версии
+// (param IS NULL) изменение выполняется всегда, с ней — только если версия PR не изменилась
+func prVersionMatch(param string) string {
+	return "(" + param + "::bigint IS NULL OR version = " + param + ")"
+}
+
+// bumpPRVersion увеличивает версию PR с внутренним ID prID. С expected версия сравнивается в WHERE
+// того же UPDATE, и если PR уже изменили, возвращается ErrVersionConflict. Вызывается внутри транзакции,
+// изменяющей ревьюеров PR: сама строка PR в таких изменениях не обновляется.
+func bumpPRVersion(ctx context.Context, tx pgx.Tx, prID int64, expected *int64) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE pull_requests
+		SET version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND `+prVersionMatch("$2"),
+		prID, expected)
+	if err != nil {
+		return fmt.Errorf("failed to bump PR version: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return versionConflict(expected)
+	}
+	return nil
+}
+
+// versionConflict возвращает ErrVersionConflict с ожидаемой версией
+func versionConflict(expected *int64) error {
+	if expected == nil {
+		return ErrVersionConflict
+	}
+	return fmt.Errorf("%w: expected version %d", ErrVersionConflict, *expected)
+}
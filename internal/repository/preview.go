@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PreviewReviewers возвращает, кого получил бы новый PR автора authorID: тот же выбор, что и в CreatePR
+// (команда, стратегия, cooldown, отпуска, приостановка, резервная команда), но в транзакции только
+// для чтения — указатель ротации не сдвигается. count — сколько ревьюеров выбрать, 0 — reviewers_per_pr команды.
+// Для стратегий со случайным порядком результат предпросмотра и реальное назначение могут различаться.
+// PausedMembers перечисляет участников команды, исключенных действующей приостановкой автоназначения.
+// Ошибки команды и автора те же, что у CreatePR: ErrNotFound, ErrAuthorNotInTeam, *AmbiguousTeamError.
+func (r *Repository) PreviewReviewers(ctx context.Context, authorID, teamName string, count int) (_ *models.ReviewerPreview, err error) {
+	ctx, span := startSpan(ctx, "PreviewReviewers", attribute.String("user.id", authorID))
+	defer func() { endSpan(span, err) }()
+
+	var preview *models.ReviewerPreview
+	err = r.retry(ctx, "PreviewReviewers", func() error {
+		tx, err := r.pool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, `SET TRANSACTION READ ONLY`); err != nil {
+			return fmt.Errorf("failed to set read-only transaction: %w", err)
+		}
+
+		preview, err = r.previewReviewers(ctx, tx, authorID, teamName, count)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+func (r *Repository) previewReviewers(ctx context.Context, tx pgx.Tx, authorID, teamName string, count int) (*models.ReviewerPreview, error) {
+	var aID int64
+	var externalID string
+	err := tx.QueryRow(ctx, `SELECT id, external_id FROM users WHERE `+r.userIDMatch("external_id", "$1"), authorID).
+		Scan(&aID, &externalID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author by external id: %w", err)
+	}
+
+	teamID, err := r.resolveAuthorTeam(ctx, tx, aID, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow(ctx, `SELECT name FROM teams WHERE id = $1`, teamID).Scan(&teamName); err != nil {
+		return nil, fmt.Errorf("failed to get team name: %w", err)
+	}
+
+	reviewers, settings, err := r.pickReviewers(ctx, tx, teamID, aID, count, true)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		count = settings.ReviewersPerPR
+	}
+
+	ids := make([]int64, 0, len(reviewers))
+	for _, c := range reviewers {
+		ids = append(ids, c.userID)
+	}
+	rows, err := tx.Query(ctx, `SELECT id, external_id, name FROM users WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidates: %w", err)
+	}
+	defer rows.Close()
+
+	type userInfo struct{ externalID, name string }
+	users := make(map[int64]userInfo, len(ids))
+	for rows.Next() {
+		var id int64
+		var u userInfo
+		if err := rows.Scan(&id, &u.externalID, &u.name); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate: %w", err)
+		}
+		users[id] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate candidates: %w", err)
+	}
+
+	paused, err := r.pausedTeamMembers(ctx, tx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.ReviewerPreview{
+		AuthorID:      externalID,
+		TeamName:      teamName,
+		Strategy:      settings.Strategy,
+		Count:         count,
+		Candidates:    make([]models.ReviewerCandidate, 0, len(reviewers)),
+		PausedMembers: paused,
+	}
+	// Порядок кандидатов — порядок выбора
+	for _, c := range reviewers {
+		u := users[c.userID]
+		preview.Candidates = append(preview.Candidates, models.ReviewerCandidate{
+			UserID:      u.externalID,
+			Username:    u.name,
+			Source:      c.source,
+			OpenReviews: c.openReviews,
+		})
+	}
+	return preview, nil
+}
+
+// pausedTeamMembers возвращает внешние ID участников команды с действующей приостановкой автоназначения
+func (r *Repository) pausedTeamMembers(ctx context.Context, tx pgx.Tx, teamID int64) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT u.external_id
+		FROM team_users tu
+		JOIN users u ON u.id = tu.user_id
+		WHERE tu.team_id = $1 AND `+assignmentPausedSQL+`
+		ORDER BY u.external_id
+	`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paused team members: %w", err)
+	}
+	defer rows.Close()
+
+	var paused []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan paused team member: %w", err)
+		}
+		paused = append(paused, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate paused team members: %w", err)
+	}
+	return paused, nil
+}
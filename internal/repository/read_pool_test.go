@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster — состояние пользователя и команды в основной БД и в реплике.
+// Запись меняет только основную БД, реплика догоняет ее вызовом sync.
+type fakeCluster struct {
+	mu      sync.Mutex
+	primary clusterState
+	replica clusterState
+}
+
+type clusterState struct {
+	userActive bool
+	members    []string
+}
+
+// newFakeCluster создает кластер с активным пользователем u1 в команде backend из u1
+func newFakeCluster() *fakeCluster {
+	state := clusterState{userActive: true, members: []string{"u1"}}
+	return &fakeCluster{primary: state, replica: clusterState{userActive: true, members: []string{"u1"}}}
+}
+
+// sync доводит реплику до состояния основной БД
+func (c *fakeCluster) sync() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replica = clusterState{userActive: c.primary.userActive, members: append([]string(nil), c.primary.members...)}
+}
+
+// addMember добавляет участника в команду только в основной БД
+func (c *fakeCluster) addMember(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.primary.members = append(c.primary.members, userID)
+}
+
+// db возвращает DB, читающую состояние основной БД (primary) или реплики
+func (c *fakeCluster) db(primary bool) *fakeDB {
+	state := func() clusterState {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if primary {
+			return c.primary
+		}
+		return c.replica
+	}
+	db := &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			s := state()
+			switch {
+			case strings.Contains(sql, "SELECT id FROM teams WHERE name"):
+				return newFakeRows([]string{"id"}, []any{int64(1)}), nil
+			case strings.Contains(sql, "tu.role"):
+				rows := newFakeRows([]string{"external_id", "name", "is_active", "slack_user_id", "max_open_reviews", "is_reviewer", "role",
+					"assignment_paused", "assignment_paused_until"})
+				for _, id := range s.members {
+					rows.data = append(rows.data, []any{id, "User " + id, true, "", nil, true, "member", false, nil})
+				}
+				return rows, nil
+			case strings.Contains(sql, "SELECT COUNT(*) FROM team_users"):
+				return newFakeRows([]string{"count"}, []any{len(s.members)}), nil
+			case strings.Contains(sql, "LEFT JOIN team_users"):
+				return newFakeRows([]string{"external_id", "name", "team_name", "is_active", "is_reviewer", "assignment_paused", "assignment_paused_until"},
+					[]any{"u1", "User u1", "backend", s.userActive, true, false, nil}), nil
+			case strings.Contains(sql, "user_vacations"):
+				return newFakeRows([]string{"id", "starts_at", "ends_at"}), nil
+			case strings.Contains(sql, "user_external_accounts"):
+				return newFakeRows([]string{"user_id", "provider", "account_id"}), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+	if primary {
+		db.exec = func(sql string, args []any) (pgconn.CommandTag, error) {
+			if !strings.Contains(sql, "UPDATE users SET is_active") {
+				return pgconn.CommandTag{}, errFakeUnexpected
+			}
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.primary.userActive = args[0].(bool)
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		}
+	}
+	return db
+}
+
+func TestCachedReadAfterWriteIgnoresLaggingReplica(t *testing.T) {
+	ctx := context.Background()
+	cluster := newFakeCluster()
+	r := New(cluster.db(true), Options{CacheTTL: time.Minute, ReadPool: cluster.db(false)})
+
+	user, err := r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	require.True(t, user.IsActive)
+	team, err := r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+
+	// Запись попадает в основную БД, реплика отстает
+	require.NoError(t, r.UpdateUserStatus(ctx, "u1", false))
+	cluster.addMember("u2")
+	r.invalidateCache()
+
+	user, err = r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive, "cache must not be refilled from a lagging replica")
+
+	team, err = r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2, "cache must not be refilled from a lagging replica")
+}
+
+func TestReadsUseReplicaUnlessPrimaryRequired(t *testing.T) {
+	ctx := context.Background()
+	cluster := newFakeCluster()
+	primary, replica := cluster.db(true), cluster.db(false)
+	r := New(primary, Options{ReadPool: replica})
+
+	require.NoError(t, r.UpdateUserStatus(ctx, "u1", false))
+
+	user, err := r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, user.IsActive, "without WithPrimary the read goes to the lagging replica")
+
+	user, err = r.GetUser(WithPrimary(ctx), "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	cluster.sync()
+	user, err = r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+}
+
+func TestSinglePoolWithoutReadPool(t *testing.T) {
+	ctx := context.Background()
+	cluster := newFakeCluster()
+	primary := cluster.db(true)
+	r := New(primary, Options{})
+
+	require.NoError(t, r.UpdateUserStatus(ctx, "u1", false))
+	user, err := r.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	_, err = r.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, primary.queries(), 7, "the update and all reads go to the single pool")
+}
+
+// teamsDB возвращает fakeDB, отвечающий на запросы ListTeams одной командой
+func teamsDB() *fakeDB {
+	return &fakeDB{
+		query: func(sql string, _ []any) (*fakeRows, error) {
+			if strings.Contains(sql, "SELECT COUNT(*) FROM teams") {
+				return newFakeRows([]string{"count"}, []any{1}), nil
+			}
+			return newFakeRows([]string{"name", "members_count", "created_at"},
+				[]any{"backend", 2, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)}), nil
+		},
+	}
+}
+
+func TestBatchAndListReadsUseReplicaUnlessPrimaryRequired(t *testing.T) {
+	// answering отвечает на запросы операции; второй пул без ответов и не должен получить ни одного запроса
+	cases := []struct {
+		name      string
+		answering func(t *testing.T) *fakeDB
+		read      func(ctx context.Context, r *Repository) error
+	}{
+		{
+			name:      "GetPRsBatch",
+			answering: func(*testing.T) *fakeDB { return batchDB() },
+			read: func(ctx context.Context, r *Repository) error {
+				_, _, err := r.GetPRsBatch(ctx, nil, []string{"pr-1", "pr-2"})
+				return err
+			},
+		},
+		{
+			name:      "getReviewersForPRs",
+			answering: func(*testing.T) *fakeDB { return batchDB() },
+			read: func(ctx context.Context, r *Repository) error {
+				_, err := r.getReviewersForPRs(ctx, []int64{1, 2})
+				return err
+			},
+		},
+		{
+			name:      "ListTeams",
+			answering: func(*testing.T) *fakeDB { return teamsDB() },
+			read: func(ctx context.Context, r *Repository) error {
+				_, _, err := r.ListTeams(ctx, 10, 0)
+				return err
+			},
+		},
+		{
+			name: "ListTeamPRs",
+			answering: func(t *testing.T) *fakeDB {
+				db := &keysetDB{t: t}
+				db.insert(3)
+				return db.fake()
+			},
+			read: func(ctx context.Context, r *Repository) error {
+				_, _, err := r.ListTeamPRs(ctx, "backend", PRListFilter{Limit: 10})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name+" reads the replica", func(t *testing.T) {
+			primary, replica := &fakeDB{}, tc.answering(t)
+			r := New(primary, Options{ReadPool: replica})
+
+			require.NoError(t, tc.read(context.Background(), r))
+			assert.Empty(t, primary.queries())
+			assert.NotEmpty(t, replica.queries())
+		})
+		t.Run(tc.name+" reads the primary with WithPrimary", func(t *testing.T) {
+			primary, replica := tc.answering(t), &fakeDB{}
+			r := New(primary, Options{ReadPool: replica})
+
+			require.NoError(t, tc.read(WithPrimary(context.Background()), r))
+			assert.Empty(t, replica.queries())
+			assert.NotEmpty(t, primary.queries())
+		})
+	}
+}
@@ -6,35 +6,114 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyMerged = errors.New("PR already merged")
+	ErrAlreadyClosed = errors.New("PR already closed")
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrInvalidInput  = errors.New("invalid input")
+	ErrNotAssigned   = errors.New("reviewer is not assigned to PR")
+	ErrNoCandidate   = errors.New("no replacement candidate")
+	ErrNoReviewers   = errors.New("no reviewer candidates for PR")
+	ErrAmbiguousPR   = errors.New("pull request ID exists in several repositories, repository is required")
+	ErrAccountLinked = errors.New("external account is linked to another user")
+
+	ErrTeamHasOpenPRs = errors.New("team members are involved in open PRs")
+	ErrAlreadyMember  = errors.New("user is already a team member")
+	ErrNotMember      = errors.New("user is not a team member")
+
+	ErrVacationOverlap = errors.New("vacation overlaps an existing one")
+
+	ErrNotApproved = errors.New("not enough approvals")
+
+	ErrAuthorNotInTeam = errors.New("author is not a member of the team")
+	ErrAmbiguousTeam   = errors.New("author belongs to several teams")
+
+	ErrCandidateNotEligible = errors.New("user cannot review this PR")
+	ErrAlreadyAssigned      = errors.New("user is already assigned to PR")
+	ErrMaxReviewers         = errors.New("PR already has the maximum number of reviewers")
+	ErrLeadRequired         = errors.New("team lead is always included and is not replaced automatically")
 )
 
+// Options задает настройки поведения репозитория
+type Options struct {
+	// FoldUserIDs включает регистронезависимое сравнение внешних ID пользователей.
+	// Входные ID при этом должны быть уже нормализованы (trim + lower).
+	FoldUserIDs bool
+	// AssignmentStrategy задает стратегию выбора ревьюеров (StrategyRandom, StrategyLeastLoaded, StrategyRoundRobin)
+	AssignmentStrategy string
+	// CooldownPRs — по скольким последним PR автора понижать приоритет их ревьюеров (0 — выключено)
+	CooldownPRs int
+	// MinAssignmentAge — назначения моложе этого возраста не переназначаются автоматически (0 — выключено).
+	// Ручное переназначение через API не ограничивается.
+	MinAssignmentAge time.Duration
+	// RequireApprovals — минимальное число одобрений для слияния открытого PR (0 — без проверки)
+	RequireApprovals int
+	// RejectAmbiguousTeam запрещает создавать PR автора из нескольких команд без явного team_name
+	RejectAmbiguousTeam bool
+	// RejectZeroReviewers запрещает создавать PR, на который не нашлось ни одного ревьюера
+	RejectZeroReviewers bool
+	// FallbackTeam — команда, из которой добираются ревьюеры, если в команде PR не хватает кандидатов
+	// (пусто — выключено)
+	FallbackTeam string
+	// MaxReviewers — сколько ревьюеров можно назначить на PR через AddReviewer (0 — без ограничения)
+	MaxReviewers int
+	// CacheTTL — время жизни записей кэша GetTeamPage и GetUser (0 — кэш выключен)
+	CacheTTL time.Duration
+	// ReadPool — пул реплики для чистого чтения (GetTeam, GetPR, GetUser, GetPRsByReviewer, GetPRsBatch,
+	// ListTeams, списки PR команды и автора, статистика), nil — все запросы идут через основной пул
+	ReadPool DB
+	// OnRetry вызывается перед каждым повтором операции после временной ошибки Postgres
+	// (attempt — номер неудавшейся попытки). Используется для метрик и логов, может быть nil.
+	OnRetry func(op string, attempt int, err error)
+	// OnCapacityLimited вызывается, когда PR команды team при назначении получил меньше ревьюеров, чем requested,
+	// потому что capped участников уже набрали max_open_reviews открытых ревью. Вызывается один раз после
+	// фиксации транзакции назначения, даже если она повторялась; при откате не вызывается.
+	// Используется для метрик и логов, может быть nil.
+	OnCapacityLimited func(team string, requested, assigned, capped int)
+}
+
 type Repository struct {
 	pool DB
+	// read — пул для чистого чтения, совпадает с pool, если пул чтения не задан
+	read  DB
+	opts  Options
+	cache *readCache
 }
 
-func New(pool DB) *Repository {
-	return &Repository{pool: pool}
+func New(pool DB, opts Options) *Repository {
+	read := opts.ReadPool
+	if read == nil {
+		read = pool
+	}
+	return &Repository{pool: pool, read: read, opts: opts, cache: newReadCache(opts.CacheTTL)}
+}
+
+// userIDMatch возвращает SQL-условие сравнения колонки с внешним ID пользователя с параметром
+func (r *Repository) userIDMatch(column, param string) string {
+	if r.opts.FoldUserIDs {
+		return "lower(btrim(" + column + ")) = " + param
+	}
+	return column + " = " + param
 }
 
 // UpdateUserStatus обновляет статус активности пользователя по внешнему ID
 func (r *Repository) UpdateUserStatus(ctx context.Context, userID string, isActive bool) error {
-	query := `UPDATE users SET is_active = $1, updated_at = NOW() WHERE external_id = $2`
+	query := `UPDATE users SET is_active = $1, updated_at = NOW() WHERE ` + r.userIDMatch("external_id", "$2")
 	tag, err := r.pool.Exec(ctx, query, isActive, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update user status: %w", err)
 	}
+	r.invalidateCache()
 	if tag.RowsAffected() == 0 {
 		return ErrNotFound
 	}
@@ -42,13 +121,32 @@ func (r *Repository) UpdateUserStatus(ctx context.Context, userID string, isActi
 }
 
 // CreateTeam создает или обновляет команду и ее участников
-func (r *Repository) CreateTeam(ctx context.Context, teamData models.Team) (*models.Team, error) {
+func (r *Repository) CreateTeam(ctx context.Context, teamData models.Team) (_ *models.Team, err error) {
+	ctx, span := startSpan(ctx, "CreateTeam",
+		attribute.String("team.name", teamData.TeamName),
+		attribute.Int("team.members", len(teamData.Members)),
+	)
+	defer func() { endSpan(span, err) }()
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	if err := r.upsertTeam(ctx, tx, teamData); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return &teamData, nil
+}
+
+// upsertTeam создает или обновляет команду и заменяет ее состав внутри транзакции
+func (r *Repository) upsertTeam(ctx context.Context, tx pgx.Tx, teamData models.Team) error {
 	// Создаем или получаем ID существующей команды
 	var teamID int64
 	teamUpsertQuery := `
@@ -56,32 +154,56 @@ func (r *Repository) CreateTeam(ctx context.Context, teamData models.Team) (*mod
         ON CONFLICT (name) DO UPDATE SET updated_at = NOW()
         RETURNING id
     `
-	err = tx.QueryRow(ctx, teamUpsertQuery, teamData.TeamName).Scan(&teamID)
+	err := tx.QueryRow(ctx, teamUpsertQuery, teamData.TeamName).Scan(&teamID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upsert team: %w", err)
+		return fmt.Errorf("failed to upsert team: %w", err)
 	}
 
 	// Готовим данные для массового "upsert" пользователей
 	userExternalIDs := make([]string, len(teamData.Members))
 	userNames := make([]string, len(teamData.Members))
 	userIsActive := make([]bool, len(teamData.Members))
+	userSlackIDs := make([]string, len(teamData.Members))
+	userMaxOpenReviews := make([]*int, len(teamData.Members))
+	userIsReviewer := make([]*bool, len(teamData.Members))
 	for i, member := range teamData.Members {
 		userExternalIDs[i] = member.UserID
 		userNames[i] = member.Username
 		userIsActive[i] = member.IsActive
+		userSlackIDs[i] = member.SlackUserID
+		userMaxOpenReviews[i] = member.MaxOpenReviews
+		userIsReviewer[i] = member.IsReviewer
 	}
 
 	// Массово создаем или обновляем всех пользователей одним запросом
 	userUpsertQuery := `
-        INSERT INTO users (external_id, name, is_active)
-        SELECT * FROM unnest($1::varchar[], $2::varchar[], $3::boolean[])
+        INSERT INTO users (external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
+        SELECT external_id, name, is_active, NULLIF(slack_user_id, ''), max_open_reviews, COALESCE(is_reviewer, true)
+        FROM unnest($1::varchar[], $2::varchar[], $3::boolean[], $4::text[], $5::integer[], $6::boolean[])
+            AS t(external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
         ON CONFLICT (external_id) DO UPDATE
-        SET name = excluded.name, is_active = excluded.is_active, updated_at = NOW()
+        SET name = excluded.name, is_active = excluded.is_active,
+            slack_user_id = COALESCE(excluded.slack_user_id, users.slack_user_id),
+            max_open_reviews = COALESCE(excluded.max_open_reviews, users.max_open_reviews), updated_at = NOW()
+        RETURNING id, external_id
+    `
+	if r.opts.FoldUserIDs {
+		// Конфликт ищется по нормализованному индексу, старые записи приводятся к нормализованному ID
+		userUpsertQuery = `
+        INSERT INTO users (external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
+        SELECT external_id, name, is_active, NULLIF(slack_user_id, ''), max_open_reviews, COALESCE(is_reviewer, true)
+        FROM unnest($1::varchar[], $2::varchar[], $3::boolean[], $4::text[], $5::integer[], $6::boolean[])
+            AS t(external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
+        ON CONFLICT ((lower(btrim(external_id)))) DO UPDATE
+        SET external_id = excluded.external_id, name = excluded.name, is_active = excluded.is_active,
+            slack_user_id = COALESCE(excluded.slack_user_id, users.slack_user_id),
+            max_open_reviews = COALESCE(excluded.max_open_reviews, users.max_open_reviews), updated_at = NOW()
         RETURNING id, external_id
     `
-	rows, err := tx.Query(ctx, userUpsertQuery, userExternalIDs, userNames, userIsActive)
+	}
+	rows, err := tx.Query(ctx, userUpsertQuery, userExternalIDs, userNames, userIsActive, userSlackIDs, userMaxOpenReviews, userIsReviewer)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upsert users: %w", err)
+		return fmt.Errorf("failed to upsert users: %w", err)
 	}
 
 	// Собираем мапу "внешний ID" -> "внутренний ID" для дальнейшей работы
@@ -91,101 +213,282 @@ func (r *Repository) CreateTeam(ctx context.Context, teamData models.Team) (*mod
 		var externalID string
 		if err := rows.Scan(&internalID, &externalID); err != nil {
 			rows.Close()
-			return nil, fmt.Errorf("failed to scan upserted user: %w", err)
+			return fmt.Errorf("failed to scan upserted user: %w", err)
 		}
 		userInternalIDs[externalID] = internalID
 	}
 	rows.Close()
 
+	// Явно переданный is_reviewer применяется и к уже существующим пользователям:
+	// в ON CONFLICT нельзя отличить отсутствующий флаг от значения по умолчанию
+	reviewerIDs := make([]int64, 0, len(teamData.Members))
+	reviewerFlags := make([]bool, 0, len(teamData.Members))
+	for _, member := range teamData.Members {
+		if internalID, ok := userInternalIDs[member.UserID]; ok && member.IsReviewer != nil {
+			reviewerIDs = append(reviewerIDs, internalID)
+			reviewerFlags = append(reviewerFlags, *member.IsReviewer)
+		}
+	}
+	if len(reviewerIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE users u
+			SET is_reviewer = f.is_reviewer
+			FROM unnest($1::bigint[], $2::boolean[]) AS f(id, is_reviewer)
+			WHERE u.id = f.id
+		`, reviewerIDs, reviewerFlags)
+		if err != nil {
+			return fmt.Errorf("failed to update reviewer eligibility: %w", err)
+		}
+	}
+
 	// Очищаем старый состав команды
 	_, err = tx.Exec(ctx, "DELETE FROM team_users WHERE team_id = $1", teamID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clear old team members: %w", err)
+		return fmt.Errorf("failed to clear old team members: %w", err)
 	}
 
-	// Добавляем новый состав
+	// Добавляем новый состав; без роли участник получает роль member
 	newMembers := make([][]interface{}, 0, len(teamData.Members))
 	for _, member := range teamData.Members {
 		internalID, ok := userInternalIDs[member.UserID]
 		if ok {
-			newMembers = append(newMembers, []interface{}{teamID, internalID})
+			newMembers = append(newMembers, []interface{}{teamID, internalID, teamRole(member.Role)})
 		}
 	}
 
 	_, err = tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"team_users"},
-		[]string{"team_id", "user_id"},
+		[]string{"team_id", "user_id", "role"},
 		pgx.CopyFromRows(newMembers),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy new members: %w", err)
-	}
-
-	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to copy new members: %w", err)
 	}
 
-	return &teamData, nil
+	return nil
 }
 
 // GetTeam получает команду по ее имени со списком всех участников
 func (r *Repository) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	team, _, err := r.GetTeamPage(ctx, teamName, 0, 0)
+	return team, err
+}
+
+// GetTeamPage получает команду со страницей участников и общее число участников.
+// Участники упорядочены по (name, external_id), поэтому страницы стабильны при совпадающих именах.
+// limit <= 0 означает без ограничения. При включенном кэше страница читается из него, кроме контекста WithPrimary.
+// Кэш заполняется только из основного пула: иначе после сброса кэша записью отстающая реплика
+// вернула бы в кэш прежнюю команду на весь TTL.
+func (r *Repository) GetTeamPage(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error) {
+	if r.cache == nil || primaryRequired(ctx) {
+		return r.retryTeamPage(ctx, teamName, limit, offset)
+	}
+
+	page, err := r.cache.teams.Load(teamPageKey{teamName: teamName, limit: limit, offset: offset}, func() (teamPage, error) {
+		team, total, err := r.retryTeamPage(WithPrimary(ctx), teamName, limit, offset)
+		if err != nil {
+			return teamPage{}, err
+		}
+		return teamPage{team: *team, total: total}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return cloneTeam(page.team), page.total, nil
+}
+
+// retryTeamPage читает страницу команды из БД, повторяя чтение при временных ошибках
+func (r *Repository) retryTeamPage(ctx context.Context, teamName string, limit, offset int) (team *models.Team, total int, err error) {
+	err = r.retry(ctx, "GetTeam", func() (err error) {
+		team, total, err = r.loadTeamPage(ctx, teamName, limit, offset)
+		return err
+	})
+	return team, total, err
+}
+
+// loadTeamPage читает команду со страницей участников из БД в обход кэша
+func (r *Repository) loadTeamPage(ctx context.Context, teamName string, limit, offset int) (*models.Team, int, error) {
+	db := r.reader(ctx)
+
 	// Находим команду по имени
 	var teamID int64
-	err := r.pool.QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
+	err := db.QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, ErrNotFound
+		return nil, 0, ErrNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get team by name: %w", err)
+		return nil, 0, fmt.Errorf("failed to get team by name: %w", err)
 	}
 
-	// Получаем всех участников команды
-	query := `
-        SELECT u.external_id, u.name, u.is_active
+	var pageLimit *int
+	if limit > 0 {
+		pageLimit = &limit
+	}
+
+	// Страница участников и общее число участников одним batch'ем
+	batch := &pgx.Batch{}
+	batch.Queue(`
+        SELECT u.external_id, u.name, u.is_active, COALESCE(u.slack_user_id, ''), u.max_open_reviews, u.is_reviewer, tu.role,
+               `+assignmentPausedSQL+`, `+assignmentPausedUntilSQL+`
         FROM users u
         JOIN team_users tu ON u.id = tu.user_id
         WHERE tu.team_id = $1
-        ORDER BY u.name
-    `
-	rows, err := r.pool.Query(ctx, query, teamID)
+        ORDER BY u.name, u.external_id
+        LIMIT $2 OFFSET $3
+    `, teamID, pageLimit, offset)
+	batch.Queue(`SELECT COUNT(*) FROM team_users WHERE team_id = $1`, teamID)
+
+	results := db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %w", err)
+		return nil, 0, fmt.Errorf("failed to get team members: %w", err)
 	}
-	defer rows.Close()
 
 	var members []models.TeamMember
 	for rows.Next() {
 		var member models.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
-			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		var paused bool
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.SlackUserID, &member.MaxOpenReviews, &member.IsReviewer, &member.Role,
+			&paused, &member.AssignmentPausedUntil); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan team member: %w", err)
 		}
+		member.AssignmentPaused = &paused
 		members = append(members, member)
 	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate team members: %w", err)
+		return nil, 0, fmt.Errorf("failed to iterate team members: %w", err)
+	}
+
+	var total int
+	if err := results.QueryRow().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count team members: %w", err)
 	}
 
 	return &models.Team{
 		TeamName: teamName,
 		Members:  members,
-	}, nil
+	}, total, nil
 }
 
-// CreatePR создает новый PR и автоматически назначает до 2 ревьюеров из команды автора.
-// Метод идемпотентен: при повторном вызове с тем же pullRequestID вернет ошибку ErrAlreadyExists.
-func (r *Repository) CreatePR(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*models.PullRequest, error) {
+// DeleteTeam удаляет команду вместе с членством участников.
+// Пользователи и PR не удаляются. Без force удаление запрещено, если участники
+// команды являются авторами или ревьюерами открытых PR.
+func (r *Repository) DeleteTeam(ctx context.Context, teamName string, force bool) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	teamID, err := r.getTeamIDForUpdate(ctx, tx, teamName)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		var hasOpenPRs bool
+		openPRsQuery := `
+			SELECT EXISTS(
+				SELECT 1
+				FROM team_users tu
+				JOIN pull_requests pr ON pr.status = $2
+				LEFT JOIN pr_reviewers prr ON prr.pr_id = pr.id AND prr.reviewer_id = tu.user_id
+				WHERE tu.team_id = $1
+				  AND (pr.author_id = tu.user_id OR prr.reviewer_id IS NOT NULL)
+			)
+		`
+		if err := tx.QueryRow(ctx, openPRsQuery, teamID, models.StatusOpen).Scan(&hasOpenPRs); err != nil {
+			return fmt.Errorf("failed to check open PRs of team: %w", err)
+		}
+		if hasOpenPRs {
+			return ErrTeamHasOpenPRs
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM team_users WHERE team_id = $1`, teamID); err != nil {
+		return fmt.Errorf("failed to remove team members: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM teams WHERE id = $1`, teamID); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return nil
+}
+
+// ListTeams получает страницу команд с количеством участников и общее число команд.
+// Оба запроса отправляются одним batch'ем за один round trip.
+func (r *Repository) ListTeams(ctx context.Context, limit, offset int) ([]models.TeamSummary, int, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT t.name, COUNT(tu.user_id) AS members_count, t.created_at
+		FROM teams t
+		LEFT JOIN team_users tu ON tu.team_id = t.id
+		GROUP BY t.id, t.name, t.created_at
+		ORDER BY t.name
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	batch.Queue(`SELECT COUNT(*) FROM teams`)
+
+	results := r.reader(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list teams: %w", err)
+	}
+	teams, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.TeamSummary])
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to collect teams: %w", err)
+	}
+
+	var total int
+	if err := results.QueryRow().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count teams: %w", err)
+	}
+
+	return teams, total, nil
+}
+
+// CreatePR создает новый PR в репозитории repository (пустой — без репозитория) и автоматически назначает
+// до 2 ревьюеров из команды автора согласно стратегии назначения. Команда задается teamName или определяется
+// по автору (см. resolveAuthorTeam) и сохраняется в PR для последующих переназначений.
+// Метод идемпотентен: при повторном вызове с тем же pullRequestID в том же репозитории вернет ошибку ErrAlreadyExists.
+// При RejectZeroReviewers PR без единого кандидата в ревьюеры не создается и возвращается ErrNoReviewers.
+func (r *Repository) CreatePR(ctx context.Context, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (_ *models.PullRequest, err error) {
+	ctx, span := startSpan(ctx, "CreatePR", attribute.String("pull_request.id", pullRequestID))
+	defer func() { endSpan(span, err) }()
+
+	var pr *models.PullRequest
+	err = r.inTx(ctx, "CreatePR", func(tx pgx.Tx) (err error) {
+		pr, err = r.createPR(ctx, tx, repository, pullRequestID, pullRequestName, authorID, teamName, meta)
+		if err == nil && len(pr.AssignedReviewers) == 0 && r.opts.RejectZeroReviewers {
+			return ErrNoReviewers
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// createPR создает PR и назначает ревьюеров внутри транзакции
+func (r *Repository) createPR(ctx context.Context, tx pgx.Tx, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error) {
 	// Ищем пользователя по внешнему ID
 	var aID int64
-	authorQuery := `SELECT id FROM users WHERE external_id = $1`
-	err = tx.QueryRow(ctx, authorQuery, authorID).Scan(&aID)
+	authorQuery := `SELECT id FROM users WHERE ` + r.userIDMatch("external_id", "$1")
+	err := tx.QueryRow(ctx, authorQuery, authorID).Scan(&aID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound // автор с таким внешним ID не найден
 	}
@@ -193,10 +496,10 @@ func (r *Repository) CreatePR(ctx context.Context, pullRequestID, pullRequestNam
 		return nil, fmt.Errorf("failed to get author by external id: %w", err)
 	}
 
-	// Проверка на существование PR с таким внешним ID (для 409 Conflict)
+	// Проверка на существование PR с таким внешним ID в репозитории (для 409 Conflict)
 	var exists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE external_id = $1)`
-	err = tx.QueryRow(ctx, checkQuery, pullRequestID).Scan(&exists)
+	checkQuery := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE repository = $1 AND external_id = $2)`
+	err = tx.QueryRow(ctx, checkQuery, repository, pullRequestID).Scan(&exists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check PR existence: %w", err)
 	}
@@ -205,55 +508,30 @@ func (r *Repository) CreatePR(ctx context.Context, pullRequestID, pullRequestNam
 	}
 
 	// Определение команды автора для поиска ревьюеров
-	var teamID int64
-	teamQuery := `SELECT team_id FROM team_users WHERE user_id = $1 LIMIT 1`
-	err = tx.QueryRow(ctx, teamQuery, aID).Scan(&teamID)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, ErrNotFound
-	}
+	teamID, err := r.resolveAuthorTeam(ctx, tx, aID, teamName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get author's team: %w", err)
+		return nil, err
 	}
 
-	// Выбор до 2-х случайных активных ревьюеров из команды, исключая автора
-	candidatesQuery := `
-        SELECT tu.user_id
-        FROM team_users tu
-        JOIN users u ON tu.user_id = u.id
-        WHERE tu.team_id = $1
-          AND u.is_active = true
-          AND tu.user_id != $2
-        ORDER BY RANDOM()
-        LIMIT 2
-    `
-	rows, err := tx.Query(ctx, candidatesQuery, teamID, aID)
+	// Выбор активных ревьюеров из команды (с добором из резервной), исключая автора,
+	// по правилам назначения команды: число ревьюеров и стратегия
+	reviewers, _, err := r.pickReviewers(ctx, tx, teamID, aID, 0, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get reviewer candidates: %w", err)
-	}
-
-	var reviewerIDs []int64
-	for rows.Next() {
-		var rID int64
-		if err := rows.Scan(&rID); err != nil {
-			rows.Close()
-			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
-		}
-		reviewerIDs = append(reviewerIDs, rID)
-	}
-	rows.Close()
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate reviewers: %w", err)
+		return nil, err
 	}
 
 	// Создание основной записи о PR в базе данных
-	var internalID int64
+	var internalID, version int64
 	var createdAt time.Time
 	insertQuery := `
-        INSERT INTO pull_requests (external_id, title, author_id, status) 
-        VALUES ($1, $2, $3, $4) 
-        RETURNING id, created_at
+        INSERT INTO pull_requests (external_id, title, author_id, status, team_id,
+            description, source_branch, target_branch, url, labels, repository, created_by) 
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), COALESCE($10::text[], '{}'), $11, $12) 
+        RETURNING id, created_at, version
     `
-	err = tx.QueryRow(ctx, insertQuery, pullRequestID, pullRequestName, aID, models.StatusOpen).Scan(&internalID, &createdAt)
+	createdBy := actorFromContext(ctx)
+	err = tx.QueryRow(ctx, insertQuery, pullRequestID, pullRequestName, aID, models.StatusOpen, teamID,
+		meta.Description, meta.SourceBranch, meta.TargetBranch, meta.URL, meta.Labels, repository, createdBy).Scan(&internalID, &createdAt, &version)
 	if err != nil {
 		// Обработка возможного race condition
 		if pgxErr, ok := err.(*pgconn.PgError); ok && pgxErr.Code == "23505" {
@@ -263,102 +541,128 @@ func (r *Repository) CreatePR(ctx context.Context, pullRequestID, pullRequestNam
 	}
 
 	// Привязка найденных ревьюеров к созданному PR
-	assignedReviewers := make([]string, 0, len(reviewerIDs))
-	for _, rID := range reviewerIDs {
-		// сохраняем связь PR ↔ внутренний ID ревьюера
-		if _, err = tx.Exec(ctx,
-			`INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1, $2)`,
-			internalID, rID,
-		); err != nil {
+	assignedReviewers := make([]models.AssignedReviewer, 0, len(reviewers))
+	for _, candidate := range reviewers {
+		// сохраняем связь PR ↔ внутренний ID ревьюера вместе со сроком ревью по SLA
+		reviewer := models.AssignedReviewer{Source: candidate.source}
+		if err = tx.QueryRow(ctx,
+			insertReviewerQuery+` RETURNING review_due_at`,
+			internalID, candidate.userID, candidate.source,
+		).Scan(&reviewer.ReviewDueAt); err != nil {
 			return nil, fmt.Errorf("failed to assign reviewer: %w", err)
 		}
+		if err := r.recordAssignmentEvent(ctx, tx, internalID, 0, candidate.userID, models.AssignmentReasonAutoInitial); err != nil {
+			return nil, err
+		}
 
-		// получаем внешний ID ревьюера для ответа API
-		var reviewerExternalID string
+		// получаем внешний ID, имя и активность ревьюера для ответа API
 		if err := tx.QueryRow(
 			ctx,
-			`SELECT external_id FROM users WHERE id = $1`,
-			rID,
-		).Scan(&reviewerExternalID); err != nil {
+			`SELECT external_id, name, is_active FROM users WHERE id = $1`,
+			candidate.userID,
+		).Scan(&reviewer.UserID, &reviewer.Username, &reviewer.IsActive); err != nil {
 			return nil, fmt.Errorf("failed to get reviewer external id: %w", err)
 		}
 
-		assignedReviewers = append(assignedReviewers, reviewerExternalID)
-	}
-
-	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		assignedReviewers = append(assignedReviewers, reviewer)
 	}
 
 	pr := &models.PullRequest{
-		PullRequestID:     pullRequestID,
-		PullRequestName:   pullRequestName,
-		AuthorID:          authorID,
-		Status:            models.StatusOpen,
-		AssignedReviewers: assignedReviewers,
-		CreatedAt:         &createdAt,
-	}
+		PullRequestID:   pullRequestID,
+		Repository:      repository,
+		PullRequestName: pullRequestName,
+		AuthorID:        authorID,
+		Status:          models.StatusOpen,
+		CreatedAt:       &createdAt,
+		CreatedBy:       &createdBy,
+		Version:         version,
+		PRMetadata:      meta,
+	}
+	setReviewers(pr, assignedReviewers)
 
 	return pr, nil
 }
 
-// GetPR получает PR по внешнему ID с ревьюерами
-func (r *Repository) GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
-	pr := &models.PullRequest{
-		PullRequestID: pullRequestID,
-	}
+// GetPR получает PR по ссылке ref с ревьюерами. Без репозитория в ref внешний ID должен быть однозначным,
+// иначе возвращается ErrAmbiguousPR. При временных ошибках чтение повторяется.
+func (r *Repository) GetPR(ctx context.Context, ref models.PRRef) (pr *models.PullRequest, err error) {
+	err = r.retry(ctx, "GetPR", func() error {
+		db := r.reader(ctx)
+		prID, err := r.lookupPR(ctx, db, ref, false)
+		if err != nil {
+			return err
+		}
+		pr, err = r.getPR(ctx, db, prID)
+		return err
+	})
+	return pr, err
+}
+
+// getPRByID получает PR с ревьюерами по внутреннему ID, повторяя чтение при временных ошибках.
+// Используется после изменения PR, когда его внутренний ID уже известен.
+func (r *Repository) getPRByID(ctx context.Context, prID int64) (pr *models.PullRequest, err error) {
+	err = r.retry(ctx, "GetPR", func() (err error) {
+		pr, err = r.getPR(ctx, r.pool, prID)
+		return err
+	})
+	return pr, err
+}
+
+// getPR читает PR с ревьюерами по внутреннему ID из db без повторов
+func (r *Repository) getPR(ctx context.Context, db DB, prID int64) (*models.PullRequest, error) {
+	pr := &models.PullRequest{}
 
 	query := `
-        SELECT id, title, author_id, status, created_at, merged_at 
-        FROM pull_requests 
-        WHERE external_id = $1
+        SELECT pr.external_id, pr.repository, pr.title, u.external_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at,
+            pr.created_by, pr.merged_by, pr.closed_by, pr.version, ` + prMetadataColumns + `
+        FROM pull_requests pr
+        JOIN users u ON pr.author_id = u.id
+        WHERE pr.id = $1
     `
 
-	var internalID, authorID64 int64
-
-	err := r.pool.QueryRow(ctx, query, pullRequestID).Scan(
-		&internalID, &pr.PullRequestName, &authorID64, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
+	err := db.QueryRow(ctx, query, prID).Scan(
+		&pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.ClosedAt,
+		&pr.CreatedBy, &pr.MergedBy, &pr.ClosedBy, &pr.Version,
+		&pr.Description, &pr.SourceBranch, &pr.TargetBranch, &pr.URL, &pr.Labels,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get PR by external id: %w", err)
+		return nil, fmt.Errorf("failed to get PR: %w", err)
 	}
 
-	pr.AuthorID = strconv.FormatInt(authorID64, 10)
-
 	// Получаем ревьюеров по внутреннему ID
-	reviewers, err := r.getPRReviewers(ctx, internalID)
+	reviewers, err := r.getPRReviewers(ctx, db, prID)
 	if err != nil {
 		return nil, err
 	}
-	pr.AssignedReviewers = reviewers
+	setReviewers(pr, reviewers)
 
 	return pr, nil
 }
 
-// getPRReviewers получает список внешних ID ревьюеров для PR по внутреннему ID
-func (r *Repository) getPRReviewers(ctx context.Context, prID int64) ([]string, error) {
+// getPRReviewers получает из db ревьюеров PR (внешние ID, имена, активность и одобрения) по внутреннему ID
+func (r *Repository) getPRReviewers(ctx context.Context, db DB, prID int64) ([]models.AssignedReviewer, error) {
 	query := `
-		SELECT u.external_id
+		SELECT u.external_id, u.name, u.is_active, pr.approved, pr.approved_at, pr.source, pr.review_due_at
 		FROM pr_reviewers pr
 		JOIN users u ON pr.reviewer_id = u.id
 		WHERE pr.pr_id = $1
 	`
-	rows, err := r.pool.Query(ctx, query, prID)
+	rows, err := db.Query(ctx, query, prID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviewers: %w", err)
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	var reviewers []models.AssignedReviewer
 	for rows.Next() {
-		var reviewerExternalID string
-		if err := rows.Scan(&reviewerExternalID); err != nil {
-			return nil, fmt.Errorf("failed to scan reviewer external id: %w", err)
+		var reviewer models.AssignedReviewer
+		if err := rows.Scan(&reviewer.UserID, &reviewer.Username, &reviewer.IsActive, &reviewer.Approved, &reviewer.ApprovedAt, &reviewer.Source, &reviewer.ReviewDueAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
 		}
-		reviewers = append(reviewers, reviewerExternalID)
+		reviewers = append(reviewers, reviewer)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -368,89 +672,319 @@ func (r *Repository) getPRReviewers(ctx context.Context, prID int64) ([]string,
 	return reviewers, nil
 }
 
-// MergePR переводит PR в статус MERGED по внешнему ID (идемпотентно)
-func (r *Repository) MergePR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
-	pr := &models.PullRequest{
-		PullRequestID: pullRequestID,
-	}
+// GetPRsBatch получает несколько PR по внешним ID двумя запросами (PR и ревьюеры).
+// С repository ищутся PR только этого репозитория, без него внешние ID должны быть однозначными:
+// ID из нескольких репозиториев дают ErrAmbiguousPR со списком таких ID.
+// Возвращает найденные PR по внешнему ID и список ID, которых нет в базе. При временных ошибках чтение повторяется.
+func (r *Repository) GetPRsBatch(ctx context.Context, repository *string, pullRequestIDs []string) (prs map[string]*models.PullRequest, missing []string, err error) {
+	err = r.retry(ctx, "GetPRsBatch", func() (err error) {
+		prs, missing, err = r.getPRsBatch(ctx, repository, pullRequestIDs)
+		return err
+	})
+	return prs, missing, err
+}
 
+// getPRsBatch читает несколько PR без повторов
+func (r *Repository) getPRsBatch(ctx context.Context, repository *string, pullRequestIDs []string) (map[string]*models.PullRequest, []string, error) {
 	query := `
-        UPDATE pull_requests 
-        SET status = $1, merged_at = NOW() 
-        WHERE external_id = $2
-        RETURNING id, title, author_id, status, created_at, merged_at
-    `
+		SELECT pr.id, pr.external_id, pr.repository, pr.title, u.external_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at,
+			pr.created_by, pr.merged_by, pr.closed_by, pr.version, ` + prMetadataColumns + `
+		FROM pull_requests pr
+		JOIN users u ON pr.author_id = u.id
+		WHERE pr.external_id = ANY($1) AND ($2::text IS NULL OR pr.repository = $2)
+		ORDER BY pr.external_id, pr.repository
+	`
+	rows, err := r.reader(ctx).Query(ctx, query, pullRequestIDs, repository)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get PRs batch: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make(map[string]*models.PullRequest, len(pullRequestIDs))
+	byInternalID := make(map[int64]*models.PullRequest, len(pullRequestIDs))
+	internalIDs := make([]int64, 0, len(pullRequestIDs))
+	var ambiguous []string
+	for rows.Next() {
+		var internalID int64
+		pr := &models.PullRequest{}
+		setReviewers(pr, nil)
+		if err := rows.Scan(
+			&internalID, &pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.ClosedAt,
+			&pr.CreatedBy, &pr.MergedBy, &pr.ClosedBy, &pr.Version,
+			&pr.Description, &pr.SourceBranch, &pr.TargetBranch, &pr.URL, &pr.Labels,
+		); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		if prev, ok := prs[pr.PullRequestID]; ok {
+			// Строки упорядочены по внешнему ID, поэтому повтор идет сразу за первым вхождением
+			if len(ambiguous) == 0 || ambiguous[len(ambiguous)-1] != prev.PullRequestID {
+				ambiguous = append(ambiguous, prev.PullRequestID)
+			}
+			continue
+		}
+		prs[pr.PullRequestID] = pr
+		byInternalID[internalID] = pr
+		internalIDs = append(internalIDs, internalID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate PRs batch: %w", err)
+	}
+	if len(ambiguous) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrAmbiguousPR, strings.Join(ambiguous, ", "))
+	}
 
-	var internalID, authorID64 int64
+	reviewers, err := r.getReviewersForPRs(ctx, internalIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	for prID, prReviewers := range reviewers {
+		setReviewers(byInternalID[prID], prReviewers)
+	}
 
-	err := r.pool.QueryRow(ctx, query, models.StatusMerged, pullRequestID).Scan(
-		&internalID, &pr.PullRequestName, &authorID64, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
-	)
-	if errors.Is(err, pgx.ErrNoRows) {
-		// Если PR не найден, нужно проверить, не был ли он уже смержен
-		mergedPR, getErr := r.GetPR(ctx, pullRequestID)
-		if getErr != nil {
-			return nil, ErrNotFound // Не найден вообще
+	notFound := make([]string, 0)
+	seen := make(map[string]struct{}, len(pullRequestIDs))
+	for _, id := range pullRequestIDs {
+		if _, ok := seen[id]; ok {
+			continue
 		}
-		if mergedPR.Status == models.StatusMerged {
-			return mergedPR, nil // Уже смержен, возвращаем актуальное состояние
+		seen[id] = struct{}{}
+		if _, ok := prs[id]; !ok {
+			notFound = append(notFound, id)
 		}
-		return nil, ErrNotFound // Другая причина, почему не обновился
 	}
+
+	return prs, notFound, nil
+}
+
+// getReviewersForPRs получает ревьюеров сразу для нескольких PR одним запросом (вместо getPRReviewers
+// на каждый PR). Ревьюеры каждого PR упорядочены по внешнему ID.
+func (r *Repository) getReviewersForPRs(ctx context.Context, prIDs []int64) (map[int64][]models.AssignedReviewer, error) {
+	query := `
+		SELECT prr.pr_id, u.external_id, u.name, u.is_active, prr.approved, prr.approved_at, prr.source, prr.review_due_at
+		FROM pr_reviewers prr
+		JOIN users u ON prr.reviewer_id = u.id
+		WHERE prr.pr_id = ANY($1)
+		ORDER BY prr.pr_id, u.external_id
+	`
+	rows, err := r.reader(ctx).Query(ctx, query, prIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to merge PR: %w", err)
+		return nil, fmt.Errorf("failed to get reviewers batch: %w", err)
 	}
+	defer rows.Close()
 
-	pr.AuthorID = strconv.FormatInt(authorID64, 10)
+	reviewers := make(map[int64][]models.AssignedReviewer, len(prIDs))
+	for rows.Next() {
+		var prID int64
+		var reviewer models.AssignedReviewer
+		if err := rows.Scan(&prID, &reviewer.UserID, &reviewer.Username, &reviewer.IsActive, &reviewer.Approved, &reviewer.ApprovedAt, &reviewer.Source, &reviewer.ReviewDueAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		reviewers[prID] = append(reviewers[prID], reviewer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// MergePR переводит открытый PR в статус MERGED по ссылке ref (идемпотентно).
+// Повторный вызов для смерженного PR возвращает его без изменений, закрытый PR слить нельзя
+// (*TransitionError). Если задан RequireApprovals, открытый PR с недостаточным числом одобрений
+// не сливается: возвращается *NotApprovedError (errors.Is(err, ErrNotApproved)).
+// С expectedVersion PR сливается, только если его версия не изменилась, иначе возвращается ErrVersionConflict.
+// merged сообщает, что PR слит этим вызовом (переход OPEN -> MERGED), а не был смержен раньше.
+func (r *Repository) MergePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (_ *models.PullRequest, merged bool, err error) {
+	ctx, span := startSpan(ctx, "MergePR", attribute.String("pull_request.id", ref.ID))
+	defer func() { endSpan(span, err) }()
+
+	pr := &models.PullRequest{}
+
+	var internalID int64
+	var alreadyMerged bool
+	err = r.inTx(ctx, "MergePR", func(tx pgx.Tx) (err error) {
+		var status string
+		internalID, _, status, err = r.lockPRStatus(ctx, tx, ref)
+		if err != nil {
+			return err
+		}
+		if alreadyMerged = status == models.StatusMerged; alreadyMerged {
+			return nil
+		}
+		if err := checkTransition(status, models.StatusMerged); err != nil {
+			return err
+		}
+
+		if r.opts.RequireApprovals > 0 {
+			if err := r.checkApprovals(ctx, tx, internalID); err != nil {
+				return err
+			}
+		}
+
+		query := `
+            UPDATE pull_requests pr
+            SET status = $1, merged_at = NOW(), merged_by = $3, version = version + 1, updated_at = NOW()
+            WHERE id = $2 AND ` + prVersionMatch("$4") + `
+            RETURNING external_id, repository, title, (SELECT external_id FROM users WHERE id = author_id), status,
+                created_at, merged_at, closed_at, created_by, merged_by, closed_by, version, ` + prMetadataColumns + `
+        `
+		err = tx.QueryRow(ctx, query, models.StatusMerged, internalID, actorFromContext(ctx), expectedVersion).Scan(
+			&pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.ClosedAt,
+			&pr.CreatedBy, &pr.MergedBy, &pr.ClosedBy, &pr.Version,
+			&pr.Description, &pr.SourceBranch, &pr.TargetBranch, &pr.URL, &pr.Labels,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return versionConflict(expectedVersion)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to merge PR: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if alreadyMerged {
+		// Уже смержен: возвращаем состояние с исходным merged_at
+		pr, err := r.getPRByID(ctx, internalID)
+		return pr, false, err
+	}
 
 	// Получаем ревьюеров
-	reviewers, err := r.getPRReviewers(ctx, internalID)
+	reviewers, err := r.getPRReviewers(ctx, r.pool, internalID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	pr.AssignedReviewers = reviewers
+	setReviewers(pr, reviewers)
 
-	return pr, nil
+	return pr, true, nil
 }
 
-// ReassignReviewer переназначает ревьюера
-func (r *Repository) ReassignReviewerAuto(ctx context.Context, pullRequestID, oldReviewerID string) (string, error) {
-	// Получаем внутренний ID старого ревьюера
-	var rInternalID int64
-	usersQuery := `SELECT id FROM users WHERE external_id = $1`
-	err := r.pool.QueryRow(ctx, usersQuery, oldReviewerID).Scan(&rInternalID)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return "", ErrNotFound
+// ClosePR переводит открытый PR в статус CLOSED по ссылке ref (идемпотентно).
+// Смерженный PR закрыть нельзя: возвращается *TransitionError (errors.Is(err, ErrAlreadyMerged)).
+// С expectedVersion PR закрывается, только если его версия не изменилась, иначе возвращается ErrVersionConflict.
+// Без репозитория в ref ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) ClosePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (*models.PullRequest, error) {
+	var prID int64
+	err := r.inTx(ctx, "ClosePR", func(tx pgx.Tx) (err error) {
+		var status string
+		prID, _, status, err = r.lockPRStatus(ctx, tx, ref)
+		if err != nil {
+			return err
+		}
+		if status == models.StatusClosed {
+			return nil
+		}
+		if err := checkTransition(status, models.StatusClosed); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE pull_requests
+			SET status = $1, closed_at = NOW(), closed_by = $3, version = version + 1, updated_at = NOW()
+			WHERE id = $2 AND `+prVersionMatch("$4"),
+			models.StatusClosed, prID, actorFromContext(ctx), expectedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to close PR: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return versionConflict(expectedVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return r.getPRByID(ctx, prID)
+}
+
+// ReopenPR переводит закрытый PR обратно в статус OPEN (идемпотентно для открытых PR).
+// При reassign и отсутствии ревьюеров у переоткрытого PR заново назначает их из команды автора
+// в той же транзакции. Смерженный PR переоткрыть нельзя: возвращается *TransitionError
+// (errors.Is(err, ErrAlreadyMerged)). Без репозитория в ref ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) ReopenPR(ctx context.Context, ref models.PRRef, reassign bool) (*models.PullRequest, error) {
+	var prInternalID int64
+	err := r.inTx(ctx, "ReopenPR", func(tx pgx.Tx) (err error) {
+		var authorID int64
+		var status string
+		prInternalID, authorID, status, err = r.lockPRStatus(ctx, tx, ref)
+		if err != nil {
+			return err
+		}
+		if status == models.StatusOpen {
+			return nil
+		}
+		if err := checkTransition(status, models.StatusOpen); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE pull_requests
+			SET status = $1, closed_at = NULL, closed_by = NULL, version = version + 1, updated_at = NOW()
+			WHERE id = $2
+		`, models.StatusOpen, prInternalID)
+		if err != nil {
+			return fmt.Errorf("failed to reopen PR: %w", err)
+		}
+
+		if reassign {
+			return r.assignIfUnreviewed(ctx, tx, prInternalID, authorID)
+		}
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get old reviewer: %w", err)
+		return nil, err
 	}
 
+	return r.getPRByID(ctx, prInternalID)
+}
+
+// ReassignReviewer переназначает ревьюера PR. Без newReviewerID замена выбирается среди активных
+// участников команды PR согласно стратегии назначения. С newReviewerID назначается указанный пользователь:
+// он должен быть активным участником команды PR (или резервной команды), не автором и не ревьюером PR,
+// иначе возвращаются ErrCandidateNotEligible и ErrAlreadyAssigned.
+// Если параллельный запрос уже снял старого ревьюера, возвращается ErrNotAssigned.
+// Лидера команды с always_include_lead без newReviewerID не заменить: возвращается ErrLeadRequired.
+// С expectedVersion ревьюер переназначается, только если версия PR не изменилась, иначе возвращается ErrVersionConflict.
+func (r *Repository) ReassignReviewer(ctx context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (_ string, err error) {
+	ctx, span := startSpan(ctx, "ReassignReviewer", attribute.String("pull_request.id", ref.ID))
+	defer func() { endSpan(span, err) }()
+
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	fmt.Println("ReassignReviewer: ЭТАП 1")
-
-	// Находим внутренний ID PR и проверяем статус
-	var prInternalID int64
-	var status string
-	var authorID int64
-	checkQuery := `SELECT id, status, author_id FROM pull_requests WHERE external_id = $1`
-	err = tx.QueryRow(ctx, checkQuery, pullRequestID).Scan(&prInternalID, &status, &authorID)
+	// Получаем внутренний ID старого ревьюера
+	var rInternalID int64
+	usersQuery := `SELECT id FROM users WHERE ` + r.userIDMatch("external_id", "$1")
+	err = tx.QueryRow(ctx, usersQuery, oldReviewerID).Scan(&rInternalID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return "", ErrNotFound
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to check PR status: %w", err)
+		return "", fmt.Errorf("failed to get old reviewer: %w", err)
+	}
+
+	// Находим внутренний ID PR и проверяем статус.
+	// Строка PR блокируется до конца транзакции: параллельные переназначения на одном PR
+	// выполняются по очереди и видят состав ревьюеров после предыдущего.
+	prInternalID, authorID, status, err := r.lockPRStatus(ctx, tx, ref)
+	if err != nil {
+		return "", err
 	}
 	if status == models.StatusMerged {
 		return "", ErrAlreadyMerged
 	}
+	if status == models.StatusClosed {
+		return "", ErrAlreadyClosed
+	}
 
-	fmt.Println("ReassignReviewer: ЭТАП 2")
+	if err := bumpPRVersion(ctx, tx, prInternalID, expectedVersion); err != nil {
+		return "", err
+	}
 
 	// Проверяем, что старый ревьюер действительно назначен
 	var exists bool
@@ -461,108 +995,26 @@ func (r *Repository) ReassignReviewerAuto(ctx context.Context, pullRequestID, ol
 	}
 
 	if !exists {
-		return "", ErrNotFound // Ревьюер не назначен
-	}
-
-	fmt.Println("ReassignReviewer: ЭТАП 3")
-
-	// Получаем команду автора PR
-	var teamID int64
-	teamQuery := `SELECT team_id FROM team_users WHERE user_id = $1 LIMIT 1`
-	err = tx.QueryRow(ctx, teamQuery, authorID).Scan(&teamID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get author's team: %w", err)
+		return "", ErrNotAssigned
 	}
 
-	// Получаем текущих ревьюеров PR
-	currentReviewersQuery := `SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1`
-	rows, err := tx.Query(ctx, currentReviewersQuery, prInternalID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get current reviewers: %w", err)
-	}
-
-	var currentReviewers []int64
-	for rows.Next() {
-		var rID int64
-		if err := rows.Scan(&rID); err != nil {
-			rows.Close()
-			return "", fmt.Errorf("failed to scan reviewer: %w", err)
-		}
-		currentReviewers = append(currentReviewers, rID)
-	}
-	rows.Close()
-
-	fmt.Println("ReassignReviewer: ЭТАП 4")
-
-	// Ищем нового кандидата: активный член команды, не автор, не текущий ревьюер
-	candidateQuery := `
-		SELECT tu.user_id
-		FROM team_users tu
-		JOIN users u ON tu.user_id = u.id
-		WHERE tu.team_id = $1
-		AND u.is_active = true
-		AND tu.user_id != $2
-		AND tu.user_id != ALL($3)
-		ORDER BY RANDOM()
-		LIMIT 1
-	`
-
-	var newReviewerID int64
-	err = tx.QueryRow(ctx, candidateQuery, teamID, authorID, currentReviewers).Scan(&newReviewerID)
-
-	// Кандидата нет - просто оставляем PR без замены
-	if errors.Is(err, pgx.ErrNoRows) {
-		fmt.Println("ReassignReviewer: ЭТАП 5")
-
-		// Старого ревьюера всё равно снимаем
-		_, err = tx.Exec(ctx,
-			`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2`,
-			prInternalID, rInternalID,
-		)
-		if err != nil {
-			return "", fmt.Errorf("failed to remove old reviewer: %w", err)
-		}
-
-		fmt.Println("ReassignReviewer: ЭТАП 6")
-
-		if err = tx.Commit(ctx); err != nil {
-			return "", fmt.Errorf("failed to commit transaction (no replacement): %w", err)
+	var newInternalID int64
+	if newReviewerID == "" {
+		if err := r.checkLeadReplaceable(ctx, tx, prInternalID, rInternalID); err != nil {
+			return "", err
 		}
-
-		fmt.Println("ReassignReviewer: ЭТАП 7")
-
-		// Возвращаем пустую строку - замены нет, но операция успешна
-		return "", nil
-	}
-
-	fmt.Println("ReassignReviewer: ЭТАП 8")
-
-	if err != nil {
-		return "", fmt.Errorf("failed to find replacement candidate: %w", err)
-	}
-
-	// Удаляем старого ревьюера
-	_, err = tx.Exec(ctx,
-		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2`,
-		prInternalID, rInternalID,
-	)
-	if err != nil {
-		return "", fmt.Errorf("failed to remove old reviewer: %w", err)
+		newInternalID, err = r.replaceReviewer(ctx, tx, prInternalID, authorID, rInternalID, false, models.AssignmentReasonAutoReassign)
+	} else {
+		newInternalID, err = r.replaceReviewerWithUser(ctx, tx, prInternalID, authorID, rInternalID, newReviewerID)
 	}
-
-	// Добавляем нового ревьюера
-	_, err = tx.Exec(ctx,
-		`INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1, $2)`,
-		prInternalID, newReviewerID,
-	)
 	if err != nil {
-		return "", fmt.Errorf("failed to add new reviewer: %w", err)
+		return "", err
 	}
 
 	// Получаем внешний ID нового ревьюера
 	var newReviewerExternalID string
 	getExternalQuery := `SELECT external_id FROM users WHERE id = $1`
-	err = tx.QueryRow(ctx, getExternalQuery, newReviewerID).Scan(&newReviewerExternalID)
+	err = tx.QueryRow(ctx, getExternalQuery, newInternalID).Scan(&newReviewerExternalID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return "", ErrNotFound
 	}
@@ -577,20 +1029,55 @@ func (r *Repository) ReassignReviewerAuto(ctx context.Context, pullRequestID, ol
 	return newReviewerExternalID, nil
 }
 
-// GetUser получает пользователя по внешнему ID
+// GetUser получает пользователя по внешнему ID.
+// Для пользователя вне команды team_name пустой. При включенном кэше пользователь читается из него,
+// кроме контекста WithPrimary; как и у GetTeamPage, кэш заполняется только из основного пула.
 func (r *Repository) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if r.cache == nil || primaryRequired(ctx) {
+		return r.retryUser(ctx, userID)
+	}
+
+	user, err := r.cache.users.Load(userID, func() (models.User, error) {
+		user, err := r.retryUser(WithPrimary(ctx), userID)
+		if err != nil {
+			return models.User{}, err
+		}
+		return *user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneUser(user), nil
+}
+
+// retryUser читает пользователя из БД, повторяя чтение при временных ошибках
+func (r *Repository) retryUser(ctx context.Context, userID string) (user *models.User, err error) {
+	err = r.retry(ctx, "GetUser", func() (err error) {
+		user, err = r.loadUser(ctx, userID)
+		return err
+	})
+	return user, err
+}
+
+// loadUser читает пользователя из БД в обход кэша
+func (r *Repository) loadUser(ctx context.Context, userID string) (*models.User, error) {
 	query := `
-		SELECT u.external_id, u.name, t.name as team_name, u.is_active
+		SELECT u.external_id, u.name, COALESCE(t.name, '') as team_name, u.is_active, u.is_reviewer,
+		       ` + assignmentPausedSQL + `, ` + assignmentPausedUntilSQL + `
 		FROM users u
 		LEFT JOIN team_users tu ON u.id = tu.user_id
 		LEFT JOIN teams t ON tu.team_id = t.id
-		WHERE u.external_id = $1
+		WHERE ` + r.userIDMatch("u.external_id", "$1") + `
 		LIMIT 1
 	`
 
+	db := r.reader(ctx)
+
 	var user models.User
-	err := r.pool.QueryRow(ctx, query, userID).Scan(
-		&user.UserID, &user.Username, &user.TeamName, &user.IsActive,
+	var paused bool
+	err := db.QueryRow(ctx, query, userID).Scan(
+		&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.IsReviewer,
+		&paused, &user.AssignmentPausedUntil,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -600,6 +1087,19 @@ func (r *Repository) GetUser(ctx context.Context, userID string) (*models.User,
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	user.AssignmentPaused = &paused
+
+	vacations, err := r.getUpcomingVacations(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Vacations = vacations
+
+	accounts, err := r.getExternalAccounts(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.ExternalAccounts = accounts
 
 	return &user, nil
 }
@@ -630,56 +1130,103 @@ func (r *Repository) GetUserTeams(ctx context.Context, userID string) ([]string,
 	return teams, rows.Err()
 }
 
-// GetPRsByReviewer получает все PR для указанного ревьюера
-func (r *Repository) GetPRsByReviewer(ctx context.Context, reviewerID string) ([]models.PullRequestShort, error) {
-    var internalReviewerID int64
-    err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE external_id = $1`, reviewerID).
-        Scan(&internalReviewerID)
-    if errors.Is(err, pgx.ErrNoRows) {
-        return nil, ErrNotFound
-    }
-    if err != nil {
-        return nil, fmt.Errorf("failed to get reviewer by external id: %w", err)
-    }
-
-    query := `
-		SELECT pr.external_id,
-			pr.title,
-			u.external_id AS author_external_id,
-			pr.status,
-			pr.created_at
+// ReviewFilter задает фильтры, сортировку и пагинацию списка PR ревьюера
+type ReviewFilter struct {
+	PRListFilter
+	// UnapprovedOnly оставляет только PR, которые ревьюер еще не одобрил
+	UnapprovedOnly bool
+}
+
+// GetPRsByReviewer получает страницу PR указанного ревьюера, их общее число с учетом фильтров
+// и курсор следующей страницы. По умолчанию PR упорядочены от новых к старым.
+// При временных ошибках чтение повторяется.
+func (r *Repository) GetPRsByReviewer(ctx context.Context, reviewerID string, filter ReviewFilter) (prs []models.PullRequestShort, page PageInfo, err error) {
+	err = r.retry(ctx, "GetPRsByReviewer", func() (err error) {
+		prs, page, err = r.getPRsByReviewer(ctx, reviewerID, filter)
+		return err
+	})
+	return prs, page, err
+}
+
+// getPRsByReviewer читает страницу PR ревьюера без повторов
+func (r *Repository) getPRsByReviewer(ctx context.Context, reviewerID string, filter ReviewFilter) ([]models.PullRequestShort, PageInfo, error) {
+	db := r.reader(ctx)
+
+	var internalReviewerID int64
+	err := db.QueryRow(ctx, `SELECT id FROM users WHERE `+r.userIDMatch("external_id", "$1"), reviewerID).
+		Scan(&internalReviewerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, PageInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to get reviewer by external id: %w", err)
+	}
+
+	q := newPRListQuery(internalReviewerID)
+	q.where("prr.reviewer_id = $1")
+	if filter.UnapprovedOnly {
+		q.where("NOT prr.approved")
+	}
+	q.applyFilter(filter.PRListFilter)
+	orderBy, err := q.orderSQL(filter.PRListFilter)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	countWhere, countArgs := q.snapshot()
+	if err := q.applyCursor(filter.PRListFilter); err != nil {
+		return nil, PageInfo{}, err
+	}
+	// Строка сверх limit показывает, есть ли следующая страница
+	limit, pageArgs := q.page(filter.Limit+1, filter.Offset)
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT pr.id, pr.created_at, pr.external_id, pr.repository, pr.title, u.external_id, pr.status
 		FROM pull_requests pr
 		JOIN pr_reviewers prr ON pr.id = prr.pr_id
 		JOIN users u ON pr.author_id = u.id
-		WHERE prr.reviewer_id = $1
-		ORDER BY pr.created_at DESC
-	`
+		`+q.whereSQL()+`
+		`+orderBy+`
+		`+limit, pageArgs...)
+	batch.Queue(`
+		SELECT COUNT(*)
+		FROM pull_requests pr
+		JOIN pr_reviewers prr ON pr.id = prr.pr_id
+		`+countWhere, countArgs...)
+
+	results := db.SendBatch(ctx, batch)
+	defer results.Close()
 
-	rows, err := r.pool.Query(ctx, query, internalReviewerID)
+	rows, err := results.Query()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get PRs by reviewer: %w", err)
+		return nil, PageInfo{}, fmt.Errorf("failed to get PRs by reviewer: %w", err)
 	}
-	defer rows.Close()
-
-	var prs []models.PullRequestShort
+	prs := []models.PullRequestShort{}
+	var positions []PRCursor
 	for rows.Next() {
 		var pr models.PullRequestShort
-		var createdAt time.Time
-
-		if err := rows.Scan(
-			&pr.PullRequestID,
-			&pr.PullRequestName,
-			&pr.AuthorID,
-			&pr.Status,
-			&createdAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		var pos PRCursor
+		if err := rows.Scan(&pos.ID, &pos.CreatedAt, &pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+			rows.Close()
+			return nil, PageInfo{}, fmt.Errorf("failed to scan PR by reviewer: %w", err)
 		}
-
 		prs = append(prs, pr)
+		positions = append(positions, pos)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to iterate PRs by reviewer: %w", err)
+	}
+
+	page := PageInfo{NextCursor: nextPRCursor(filter.PRListFilter, positions)}
+	if err := results.QueryRow().Scan(&page.Total); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to count PRs by reviewer: %w", err)
+	}
+	if len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
 	}
 
-    return prs, rows.Err()
+	return prs, page, nil
 }
 
 // GetUserReviewStats возвращает статистику по количеству назначенных ревью для каждого пользователя.
@@ -699,7 +1246,7 @@ func (r *Repository) GetUserReviewStats(ctx context.Context) ([]models.UserRevie
 			review_count DESC, u.name ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.reader(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user review stats: %w", err)
 	}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// retryMaxAttempts — сколько раз всего выполняется операция при временных ошибках
+	retryMaxAttempts = 3
+	// retryBaseDelay — пауза перед первым повтором, дальше она удваивается
+	retryBaseDelay = 100 * time.Millisecond
+	// retryMaxDelay — верхняя граница паузы между попытками
+	retryMaxDelay = time.Second
+)
+
+// retryableCodes — коды ошибок Postgres, после которых операцию можно безопасно повторить:
+// сервер откатил транзакцию или соединение оборвалось при переключении мастера
+var retryableCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// isTransient сообщает, что ошибка вызвана временным сбоем Postgres и запрос стоит повторить
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Класс 08 — ошибки соединения
+		return retryableCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr *net.OpError
+	return pgconn.SafeToRetry(err) ||
+		errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// permanentError отмечает ошибку, после которой операцию повторять нельзя, даже если сбой временный
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryDelay возвращает паузу перед повтором после попытки attempt: экспонента со случайным разбросом в пределах половины
+func retryDelay(attempt int) time.Duration {
+	delay := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retry выполняет fn и повторяет ее при временных ошибках Postgres, не больше retryMaxAttempts раз.
+// fn должна быть идемпотентной: чтением или транзакцией целиком (см. inTx).
+// Отмена ctx прерывает ожидание следующей попытки.
+func (r *Repository) retry(ctx context.Context, op string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= retryMaxAttempts || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		trace.SpanFromContext(ctx).AddEvent("db.retry", trace.WithAttributes(
+			attribute.String("db.operation", op),
+			attribute.Int("db.retry.attempt", attempt),
+			attribute.String("error", err.Error()),
+		))
+		if r.opts.OnRetry != nil {
+			r.opts.OnRetry(op, attempt, err)
+		}
+
+		timer := time.NewTimer(retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// commitTx — транзакция, которая откладывает действия вне БД до успешного COMMIT (см. afterCommit)
+type commitTx struct {
+	pgx.Tx
+	hooks []func()
+}
+
+// afterCommit откладывает fn до успешного COMMIT транзакции tx, открытой через inTx или beginTx.
+// Если транзакция откатится или будет повторена, fn не вызовется. Вне такой транзакции fn вызывается сразу.
+func afterCommit(tx pgx.Tx, fn func()) {
+	if ct, ok := tx.(*commitTx); ok {
+		ct.hooks = append(ct.hooks, fn)
+		return
+	}
+	fn()
+}
+
+// beginTx открывает транзакцию, поддерживающую afterCommit. Отложенные действия выполняет commit.
+func (r *Repository) beginTx(ctx context.Context) (*commitTx, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &commitTx{Tx: tx}, nil
+}
+
+// commit фиксирует транзакцию и выполняет действия, отложенные через afterCommit
+func (t *commitTx) commit(ctx context.Context) error {
+	if err := t.Commit(ctx); err != nil {
+		return err
+	}
+	for _, fn := range t.hooks {
+		fn()
+	}
+	return nil
+}
+
+// inTx выполняет fn в транзакции и при временной ошибке повторяет транзакцию целиком с начала.
+// Сбой на COMMIT повторяется, только если сервер точно откатил транзакцию (конфликт сериализации,
+// дедлок): при обрыве соединения во время COMMIT неизвестно, применилась ли она.
+// fn не должна иметь побочных эффектов вне транзакции: их нужно откладывать через afterCommit,
+// тогда они выполнятся один раз, после попытки, которая зафиксировалась.
+func (r *Repository) inTx(ctx context.Context, op string, fn func(tx pgx.Tx) error) error {
+	return r.retry(ctx, op, func() error {
+		tx, err := r.beginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		if err := tx.commit(ctx); err != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", err)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01") {
+				return err
+			}
+			return &permanentError{err: err}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "crash shutdown", err: &pgconn.PgError{Code: "57P02"}, want: true},
+		{name: "cannot connect now", err: &pgconn.PgError{Code: "57P03"}, want: true},
+		{name: "connection failure class", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "connection exception class", err: &pgconn.PgError{Code: "08000"}, want: true},
+		{name: "wrapped serialization failure", err: fmt.Errorf("failed to create PR: %w", &pgconn.PgError{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "undefined table", err: &pgconn.PgError{Code: "42P01"}},
+		{name: "query canceled", err: &pgconn.PgError{Code: "57014"}},
+		{name: "pg error without code", err: &pgconn.PgError{}},
+		{name: "connect error", err: &pgconn.ConnectError{}, want: true},
+		{name: "network error", err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, want: true},
+		{name: "EOF", err: fmt.Errorf("failed to scan: %w", io.EOF), want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "context canceled", err: fmt.Errorf("failed to query: %w", context.Canceled)},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "not found", err: ErrNotFound},
+		{name: "plain error", err: errors.New("boom")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isTransient(tc.err))
+		})
+	}
+}
+
+func TestRetryDelayBounds(t *testing.T) {
+	for attempt := 1; attempt <= 8; attempt++ {
+		ceiling := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+		for range 200 {
+			delay := retryDelay(attempt)
+			require.GreaterOrEqual(t, delay, ceiling/2, "attempt %d", attempt)
+			require.LessOrEqual(t, delay, ceiling, "attempt %d", attempt)
+		}
+	}
+	assert.Equal(t, retryMaxDelay, min(retryBaseDelay<<7, retryMaxDelay), "the pause never exceeds retryMaxDelay")
+}
+
+func TestRetryAttempts(t *testing.T) {
+	serialization := &pgconn.PgError{Code: "40001"}
+	cases := []struct {
+		name     string
+		errs     []error
+		calls    int
+		retries  int
+		wantErr  error
+		wantNone bool
+	}{
+		{name: "success", errs: []error{nil}, calls: 1, wantNone: true},
+		{name: "transient then success", errs: []error{serialization, nil}, calls: 2, retries: 1, wantNone: true},
+		{name: "transient every time", errs: []error{serialization, serialization, serialization, serialization}, calls: retryMaxAttempts, retries: retryMaxAttempts - 1, wantErr: serialization},
+		{name: "non-transient", errs: []error{ErrNotFound, nil}, calls: 1, wantErr: ErrNotFound},
+		{name: "permanent transient", errs: []error{&permanentError{err: io.EOF}, nil}, calls: 1, wantErr: io.EOF},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var retried []int
+			r := New(&fakeDB{}, Options{OnRetry: func(op string, attempt int, err error) {
+				assert.Equal(t, "Op", op)
+				retried = append(retried, attempt)
+			}})
+
+			calls := 0
+			err := r.retry(context.Background(), "Op", func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, tc.calls, calls)
+			assert.Len(t, retried, tc.retries)
+			for i, attempt := range retried {
+				assert.Equal(t, i+1, attempt)
+			}
+			if tc.wantNone {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.wantErr)
+				var permanent *permanentError
+				assert.False(t, errors.As(err, &permanent), "permanentError is unwrapped")
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	r := New(&fakeDB{}, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	start := time.Now()
+	err := r.retry(ctx, "Op", func() error {
+		calls++
+		cancel()
+		return &pgconn.PgError{Code: "40001"}
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls, "no retry after the context is canceled")
+	assert.Less(t, time.Since(start), retryBaseDelay/2)
+}
+
+func TestInTxCommitFailures(t *testing.T) {
+	cases := []struct {
+		name      string
+		commitErr error
+		commits   int
+	}{
+		{name: "serialization failure on commit is retried", commitErr: &pgconn.PgError{Code: "40001"}, commits: retryMaxAttempts},
+		{name: "deadlock on commit is retried", commitErr: &pgconn.PgError{Code: "40P01"}, commits: retryMaxAttempts},
+		{name: "connection lost on commit is not retried", commitErr: io.ErrUnexpectedEOF, commits: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := &fakeDB{}
+			tx := &fakeTx{commitErr: tc.commitErr}
+			db.withTx(tx)
+			r := New(db, Options{})
+
+			err := r.inTx(context.Background(), "Op", func(pgx.Tx) error { return nil })
+
+			assert.ErrorIs(t, err, tc.commitErr)
+			assert.Contains(t, err.Error(), "failed to commit transaction")
+			assert.Equal(t, tc.commits, tx.commits)
+		})
+	}
+}
+
+// capacityDB отвечает на запрос reportCapacityShortfall: в команде backend capped участников на пределе
+func capacityDB(capped int) (*fakeDB, *fakeTx) {
+	db := &fakeDB{
+		query: func(sql string, _ []any) (*fakeRows, error) {
+			if strings.Contains(sql, "u.max_open_reviews IS NOT NULL") {
+				return newFakeRows([]string{"name", "capped"}, []any{"backend", capped}), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+	tx := &fakeTx{}
+	db.withTx(tx)
+	return db, tx
+}
+
+// capacityReport — один вызов Options.OnCapacityLimited
+type capacityReport struct {
+	team                        string
+	requested, assigned, capped int
+}
+
+func TestCapacityShortfallReportedOnceAfterCommit(t *testing.T) {
+	serialization := &pgconn.PgError{Code: "40001"}
+
+	t.Run("retried transaction", func(t *testing.T) {
+		db, tx := capacityDB(2)
+		var reports []capacityReport
+		r := New(db, Options{OnCapacityLimited: func(team string, requested, assigned, capped int) {
+			assert.Equal(t, 1, tx.commits, "reported only after the commit")
+			reports = append(reports, capacityReport{team, requested, assigned, capped})
+		}})
+
+		attempts := 0
+		err := r.inTx(context.Background(), "CreatePR", func(tx pgx.Tx) error {
+			attempts++
+			if err := r.reportCapacityShortfall(context.Background(), tx, 1, 10, 2, 0); err != nil {
+				return err
+			}
+			if attempts < retryMaxAttempts {
+				return serialization
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, retryMaxAttempts, attempts)
+		assert.Equal(t, []capacityReport{{"backend", 2, 0, 2}}, reports, "one report per committed assignment, not per attempt")
+	})
+
+	t.Run("rolled back transaction", func(t *testing.T) {
+		db, _ := capacityDB(2)
+		reports := 0
+		r := New(db, Options{OnCapacityLimited: func(string, int, int, int) { reports++ }})
+
+		err := r.inTx(context.Background(), "CreatePR", func(tx pgx.Tx) error {
+			if err := r.reportCapacityShortfall(context.Background(), tx, 1, 10, 2, 1); err != nil {
+				return err
+			}
+			return ErrNoReviewers
+		})
+
+		assert.ErrorIs(t, err, ErrNoReviewers)
+		assert.Zero(t, reports, "a rolled back assignment is not reported")
+	})
+
+	t.Run("failed commit", func(t *testing.T) {
+		db, tx := capacityDB(2)
+		tx.commitErr = io.ErrUnexpectedEOF
+		reports := 0
+		r := New(db, Options{OnCapacityLimited: func(string, int, int, int) { reports++ }})
+
+		err := r.inTx(context.Background(), "CreatePR", func(tx pgx.Tx) error {
+			return r.reportCapacityShortfall(context.Background(), tx, 1, 10, 2, 1)
+		})
+
+		require.Error(t, err)
+		assert.Zero(t, reports)
+	})
+
+	t.Run("no member at capacity", func(t *testing.T) {
+		db, _ := capacityDB(0)
+		reports := 0
+		r := New(db, Options{OnCapacityLimited: func(string, int, int, int) { reports++ }})
+
+		err := r.inTx(context.Background(), "CreatePR", func(tx pgx.Tx) error {
+			return r.reportCapacityShortfall(context.Background(), tx, 1, 10, 2, 1)
+		})
+
+		require.NoError(t, err)
+		assert.Zero(t, reports, "a small team is not a capacity shortfall")
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// ClaimReviewReminders выбирает до limit неодобренных назначений в открытых PR, сделанных раньше assignedBefore,
+// о которых не напоминали после remindedBefore, и в той же операции отмечает их last_reminded_at = now.
+// Отметка ставится до отправки напоминаний, поэтому перезапуск или параллельный экземпляр сервиса
+// не напомнит о том же назначении повторно в пределах окна.
+func (r *Repository) ClaimReviewReminders(ctx context.Context, assignedBefore, remindedBefore, now time.Time, limit int) ([]models.ReviewReminder, error) {
+	query := `
+		UPDATE pr_reviewers prr
+		SET last_reminded_at = $3
+		FROM (
+			SELECT s.pr_id, s.reviewer_id
+			FROM pr_reviewers s
+			JOIN pull_requests spr ON spr.id = s.pr_id
+			WHERE spr.status = $5
+			  AND NOT s.approved
+			  AND s.created_at < $1
+			  AND (s.last_reminded_at IS NULL OR s.last_reminded_at < $2)
+			ORDER BY s.created_at, s.pr_id, s.reviewer_id
+			LIMIT $4
+			FOR UPDATE OF s SKIP LOCKED
+		) due, pull_requests pr, users u
+		WHERE prr.pr_id = due.pr_id
+		  AND prr.reviewer_id = due.reviewer_id
+		  AND pr.id = prr.pr_id
+		  AND u.id = prr.reviewer_id
+		RETURNING pr.external_id AS pull_request_id, pr.repository, u.external_id AS reviewer_id, prr.created_at AS assigned_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, assignedBefore, remindedBefore, now, limit, models.StatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim review reminders: %w", err)
+	}
+	defer rows.Close()
+
+	reminders, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ReviewReminder])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect review reminders: %w", err)
+	}
+
+	return reminders, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+func TestClaimReviewRemindersMarksClaimed(t *testing.T) {
+	now := time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+	assignedBefore := now.Add(-4 * time.Hour)
+	remindedBefore := now.Add(-24 * time.Hour)
+
+	var query string
+	var args []any
+	db := &fakeDB{
+		query: func(sql string, a []any) (*fakeRows, error) {
+			query = strings.Join(strings.Fields(sql), " ")
+			args = a
+			return newFakeRows([]string{"pull_request_id", "repository", "reviewer_id", "assigned_at"},
+				[]any{"pr-1", "backend", "u2", assignedBefore.Add(-time.Hour)}), nil
+		},
+	}
+	r := New(db, Options{})
+
+	reminders, err := r.ClaimReviewReminders(context.Background(), assignedBefore, remindedBefore, now, 100)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, "u2", reminders[0].ReviewerID)
+
+	assert.Equal(t, []any{assignedBefore, remindedBefore, now, 100, models.StatusOpen}, args)
+	// Выбор и отметка — один UPDATE: повторный вызов в том же окне не вернет назначение
+	assert.True(t, strings.HasPrefix(query, "UPDATE pr_reviewers prr SET last_reminded_at = $3"))
+	assert.Contains(t, query, "AND (s.last_reminded_at IS NULL OR s.last_reminded_at < $2)")
+	assert.Contains(t, query, "AND s.created_at < $1")
+	assert.Contains(t, query, "AND NOT s.approved")
+	assert.Contains(t, query, "FOR UPDATE OF s SKIP LOCKED", "parallel instances do not claim the same rows")
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// insertReviewerQuery назначает ревьюера ($2, источник $3) на PR ($1) и проставляет срок ревью:
+// момент назначения плюс review_sla_hours команды PR (для PR без сохраненной команды — самой ранней
+// команды автора). Если у команды нет SLA, срок остается NULL.
+const insertReviewerQuery = `
+	INSERT INTO pr_reviewers (pr_id, reviewer_id, source, review_due_at)
+	VALUES ($1, $2, $3, (
+		SELECT NOW() + make_interval(hours => ts.review_sla_hours)
+		FROM pull_requests pr
+		JOIN team_settings ts ON ts.team_id = COALESCE(
+			pr.team_id,
+			(SELECT MIN(tu.team_id) FROM team_users tu WHERE tu.user_id = pr.author_id)
+		)
+		WHERE pr.id = $1
+	))`
+
+// GetOverdueReviews возвращает просроченные по SLA назначения в открытых PR команды, от самых просроченных.
+// Назначение просрочено, если ревьюер не одобрил PR до review_due_at.
+func (r *Repository) GetOverdueReviews(ctx context.Context, teamName string, limit, offset int) ([]models.OverdueReview, error) {
+	var teamID int64
+	err := r.pool.QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team by name: %w", err)
+	}
+
+	query := `
+		SELECT pr.external_id AS pull_request_id,
+			pr.repository,
+			pr.title AS pull_request_name,
+			a.external_id AS author_id,
+			ru.external_id AS reviewer_id,
+			ru.name AS reviewer_name,
+			prr.created_at AS assigned_at,
+			prr.review_due_at,
+			FLOOR(EXTRACT(EPOCH FROM (NOW()::timestamp - prr.review_due_at)) / 3600)::bigint AS overdue_hours
+		FROM pr_reviewers prr
+		JOIN pull_requests pr ON pr.id = prr.pr_id
+		JOIN users a ON a.id = pr.author_id
+		JOIN users ru ON ru.id = prr.reviewer_id
+		WHERE prr.review_due_at IS NOT NULL
+		  AND NOT prr.approved
+		  AND prr.review_due_at < NOW()
+		  AND pr.status = $2
+		  AND COALESCE(
+			pr.team_id,
+			(SELECT MIN(tu.team_id) FROM team_users tu WHERE tu.user_id = pr.author_id)
+		  ) = $1
+		ORDER BY prr.review_due_at, pr.id, ru.external_id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, teamID, models.StatusOpen, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.OverdueReview])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect overdue reviews: %w", err)
+	}
+
+	return reviews, nil
+}
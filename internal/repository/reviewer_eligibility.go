@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetReviewerEligibility включает или исключает пользователя из пула автоматического назначения ревьюеров.
+// При исключении с reassign открытые ревью пользователя переназначаются в той же транзакции так же,
+// как при деактивации (см. DeactivateAndReassign); иначе возвращаемый результат nil.
+func (r *Repository) SetReviewerEligibility(ctx context.Context, userID string, isReviewer, reassign bool) (_ *models.ReassignmentResult, err error) {
+	ctx, span := startSpan(ctx, "SetReviewerEligibility", attribute.String("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var uID int64
+	query := `UPDATE users SET is_reviewer = $1, updated_at = NOW() WHERE ` +
+		r.userIDMatch("external_id", "$2") + ` RETURNING id`
+	err = tx.QueryRow(ctx, query, isReviewer, userID).Scan(&uID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reviewer eligibility: %w", err)
+	}
+
+	var result *models.ReassignmentResult
+	if !isReviewer && reassign {
+		if result, err = r.reassignOpenReviews(ctx, tx, uID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return result, nil
+}
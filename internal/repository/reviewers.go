@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AddReviewer назначает на открытый PR дополнительного ревьюера и возвращает обновленный PR
+// и внешний ID назначенного. С userID назначается указанный пользователь (проверки как при ручном
+// переназначении, см. checkEligible), без него кандидат выбирается так же, как при создании PR,
+// исключая текущих ревьюеров. Если у PR уже MaxReviewers ревьюеров, возвращается ErrMaxReviewers.
+// С expectedVersion ревьюер добавляется, только если версия PR не изменилась, иначе возвращается ErrVersionConflict.
+// Без репозитория в ref ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) AddReviewer(ctx context.Context, ref models.PRRef, userID string, expectedVersion *int64) (_ *models.PullRequest, _ string, err error) {
+	ctx, span := startSpan(ctx, "AddReviewer", attribute.String("pull_request.id", ref.ID))
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Строка PR блокируется, чтобы параллельные добавления не превысили лимит ревьюеров
+	prID, authorID, status, err := r.lockPRStatus(ctx, tx, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	var reviewersCount int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM pr_reviewers WHERE pr_id = $1`, prID).Scan(&reviewersCount)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to count PR reviewers: %w", err)
+	}
+	if status == models.StatusMerged {
+		return nil, "", ErrAlreadyMerged
+	}
+	if status == models.StatusClosed {
+		return nil, "", ErrAlreadyClosed
+	}
+	if err := bumpPRVersion(ctx, tx, prID, expectedVersion); err != nil {
+		return nil, "", err
+	}
+	if r.opts.MaxReviewers > 0 && reviewersCount >= r.opts.MaxReviewers {
+		return nil, "", ErrMaxReviewers
+	}
+
+	var reviewer candidate
+	if userID == "" {
+		reviewer, err = r.findReplacement(ctx, tx, prID, authorID)
+	} else {
+		reviewer, err = r.checkEligible(ctx, tx, prID, authorID, userID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := tx.Exec(ctx,
+		insertReviewerQuery,
+		prID, reviewer.userID, reviewer.source,
+	); err != nil {
+		return nil, "", fmt.Errorf("failed to add reviewer: %w", err)
+	}
+	if err := r.recordAssignmentEvent(ctx, tx, prID, 0, reviewer.userID, models.AssignmentReasonManual); err != nil {
+		return nil, "", err
+	}
+
+	var reviewerExternalID string
+	err = tx.QueryRow(ctx, `SELECT external_id FROM users WHERE id = $1`, reviewer.userID).Scan(&reviewerExternalID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get reviewer external id: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	pr, err := r.getPRByID(ctx, prID)
+	if err != nil {
+		return nil, "", err
+	}
+	return pr, reviewerExternalID, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSlackUserID возвращает ID участника Slack пользователя; пустая строка — ID не задан
+func (r *Repository) GetSlackUserID(ctx context.Context, userID string) (string, error) {
+	var slackUserID string
+	query := `SELECT COALESCE(slack_user_id, '') FROM users WHERE ` + r.userIDMatch("external_id", "$1")
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&slackUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get slack user id: %w", err)
+	}
+	return slackUserID, nil
+}
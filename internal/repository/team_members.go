@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// AddTeamMember добавляет одного участника в команду с ролью member.Role, создавая или обновляя пользователя
+func (r *Repository) AddTeamMember(ctx context.Context, teamName string, member models.TeamMember) (*models.Team, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamID, err := r.getTeamIDForUpdate(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	userUpsertQuery := `
+		INSERT INTO users (external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, COALESCE($6, true))
+		ON CONFLICT (external_id) DO UPDATE
+		SET name = excluded.name, is_active = excluded.is_active,
+			slack_user_id = COALESCE(excluded.slack_user_id, users.slack_user_id),
+			max_open_reviews = COALESCE(excluded.max_open_reviews, users.max_open_reviews),
+			is_reviewer = COALESCE($6, users.is_reviewer), updated_at = NOW()
+		RETURNING id
+	`
+	if r.opts.FoldUserIDs {
+		userUpsertQuery = `
+		INSERT INTO users (external_id, name, is_active, slack_user_id, max_open_reviews, is_reviewer)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, COALESCE($6, true))
+		ON CONFLICT ((lower(btrim(external_id)))) DO UPDATE
+		SET external_id = excluded.external_id, name = excluded.name, is_active = excluded.is_active,
+			slack_user_id = COALESCE(excluded.slack_user_id, users.slack_user_id),
+			max_open_reviews = COALESCE(excluded.max_open_reviews, users.max_open_reviews),
+			is_reviewer = COALESCE($6, users.is_reviewer), updated_at = NOW()
+		RETURNING id
+	`
+	}
+
+	var userID int64
+	err = tx.QueryRow(ctx, userUpsertQuery, member.UserID, member.Username, member.IsActive, member.SlackUserID, member.MaxOpenReviews, member.IsReviewer).Scan(&userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert user: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO team_users (team_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		teamID, userID, teamRole(member.Role),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add team member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAlreadyMember
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return r.GetTeam(WithPrimary(ctx), teamName)
+}
+
+// RemoveTeamMember удаляет участника из команды. Открытые ревью, которые он вел в PR
+// авторов этой команды, переназначаются на других участников; если кандидата нет,
+// PR остается с меньшим числом ревьюеров.
+func (r *Repository) RemoveTeamMember(ctx context.Context, teamName, userID string) (*models.Team, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamID, err := r.getTeamIDForUpdate(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	var uID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM users WHERE `+r.userIDMatch("external_id", "$1"), userID).Scan(&uID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by external id: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM team_users WHERE team_id = $1 AND user_id = $2`, teamID, uID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotMember
+	}
+
+	// Открытые PR авторов команды, где удаляемый участник назначен ревьюером.
+	// Блокируем в порядке id, как и при деактивации, чтобы избежать взаимных блокировок.
+	openReviewsQuery := `
+		SELECT pr.id, pr.author_id
+		FROM pull_requests pr
+		JOIN pr_reviewers prr ON prr.pr_id = pr.id
+		JOIN team_users tu ON tu.user_id = pr.author_id AND tu.team_id = $2
+		WHERE prr.reviewer_id = $1
+		  AND pr.status = $3
+		ORDER BY pr.id
+		FOR UPDATE OF pr
+	`
+	rows, err := tx.Query(ctx, openReviewsQuery, uID, teamID, models.StatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reviews of member: %w", err)
+	}
+
+	type openReview struct {
+		prID     int64
+		authorID int64
+	}
+	var reviews []openReview
+	for rows.Next() {
+		var rv openReview
+		if err := rows.Scan(&rv.prID, &rv.authorID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan open review: %w", err)
+		}
+		reviews = append(reviews, rv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate open reviews: %w", err)
+	}
+
+	for _, rv := range reviews {
+		if _, err := r.replaceReviewer(ctx, tx, rv.prID, rv.authorID, uID, true, models.AssignmentReasonDeactivation); err != nil {
+			return nil, err
+		}
+		if err := bumpPRVersion(ctx, tx, rv.prID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return r.GetTeam(WithPrimary(ctx), teamName)
+}
+
+// getTeamIDForUpdate получает ID команды по имени и блокирует ее строку до конца транзакции
+func (r *Repository) getTeamIDForUpdate(ctx context.Context, tx pgx.Tx, teamName string) (int64, error) {
+	var teamID int64
+	err := tx.QueryRow(ctx, `SELECT id FROM teams WHERE name = $1 FOR UPDATE`, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team by name: %w", err)
+	}
+	return teamID, nil
+}
+
+// teamRole возвращает роль участника для записи в team_users: пустая роль означает member
+func teamRole(role string) string {
+	if role == "" {
+		return models.TeamRoleMember
+	}
+	return role
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// prListScope — отбор PR для listPRs: дополнительный JOIN и условие, $1 — внутренний ID команды или автора
+type prListScope struct {
+	join string
+	cond string
+}
+
+var (
+	teamPRsScope = prListScope{
+		join: "JOIN team_users tu ON tu.user_id = pr.author_id",
+		cond: "tu.team_id = $1",
+	}
+	authorPRsScope = prListScope{
+		cond: "pr.author_id = $1",
+	}
+)
+
+// ListTeamPRs возвращает страницу PR, авторы которых состоят в команде, их общее число и курсор следующей страницы.
+// Нулевые поля filter означают отсутствие фильтра; по умолчанию PR упорядочены от новых к старым.
+// При временных ошибках чтение повторяется.
+func (r *Repository) ListTeamPRs(ctx context.Context, teamName string, filter PRListFilter) (prs []models.TeamPullRequest, page PageInfo, err error) {
+	err = r.retry(ctx, "ListTeamPRs", func() (err error) {
+		prs, page, err = r.listTeamPRs(ctx, teamName, filter)
+		return err
+	})
+	return prs, page, err
+}
+
+// listTeamPRs читает страницу PR без повторов
+func (r *Repository) listTeamPRs(ctx context.Context, teamName string, filter PRListFilter) ([]models.TeamPullRequest, PageInfo, error) {
+	var teamID int64
+	err := r.reader(ctx).QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, PageInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to get team by name: %w", err)
+	}
+
+	prs, page, err := r.listPRs(ctx, teamPRsScope, teamID, filter)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list team PRs: %w", err)
+	}
+	return prs, page, nil
+}
+
+// ListAuthorPRs возвращает страницу PR автора, их общее число и курсор следующей страницы.
+// Нулевые поля filter означают отсутствие фильтра; по умолчанию PR упорядочены от новых к старым.
+// При временных ошибках чтение повторяется.
+func (r *Repository) ListAuthorPRs(ctx context.Context, authorID string, filter PRListFilter) (prs []models.TeamPullRequest, page PageInfo, err error) {
+	err = r.retry(ctx, "ListAuthorPRs", func() (err error) {
+		prs, page, err = r.listAuthorPRs(ctx, authorID, filter)
+		return err
+	})
+	return prs, page, err
+}
+
+// listAuthorPRs читает страницу PR без повторов
+func (r *Repository) listAuthorPRs(ctx context.Context, authorID string, filter PRListFilter) ([]models.TeamPullRequest, PageInfo, error) {
+	var internalAuthorID int64
+	err := r.reader(ctx).QueryRow(ctx, `SELECT id FROM users WHERE `+r.userIDMatch("external_id", "$1"), authorID).
+		Scan(&internalAuthorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, PageInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to get author by external id: %w", err)
+	}
+
+	prs, page, err := r.listPRs(ctx, authorPRsScope, internalAuthorID, filter)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to list author PRs: %w", err)
+	}
+	return prs, page, nil
+}
+
+// listPRs выбирает страницу PR по условию scope (см. teamPRsScope, authorPRsScope), считает их общее число
+// и строит курсор следующей страницы.
+// Ревьюеры всех PR страницы загружаются одним запросом getReviewersForPRs, поэтому число запросов
+// не зависит от размера страницы: batch со страницей и счетчиком плюс запрос ревьюеров.
+func (r *Repository) listPRs(ctx context.Context, scope prListScope, scopeID int64, filter PRListFilter) ([]models.TeamPullRequest, PageInfo, error) {
+	q := newPRListQuery(scopeID)
+	q.where(scope.cond)
+	q.applyFilter(filter)
+	orderBy, err := q.orderSQL(filter)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	countWhere, countArgs := q.snapshot()
+	if err := q.applyCursor(filter); err != nil {
+		return nil, PageInfo{}, err
+	}
+	// Строка сверх limit показывает, есть ли следующая страница
+	limit, pageArgs := q.page(filter.Limit+1, filter.Offset)
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT pr.id, pr.external_id, pr.repository, pr.title, u.external_id, pr.status, pr.labels, pr.created_at, pr.merged_at
+		FROM pull_requests pr
+		JOIN users u ON u.id = pr.author_id
+		`+scope.join+`
+		`+q.whereSQL()+`
+		`+orderBy+`
+		`+limit, pageArgs...)
+	batch.Queue(`
+		SELECT COUNT(*)
+		FROM pull_requests pr
+		`+scope.join+`
+		`+countWhere, countArgs...)
+
+	results := r.reader(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to query PRs: %w", err)
+	}
+	prs := []models.TeamPullRequest{}
+	var positions []PRCursor
+	for rows.Next() {
+		var pr models.TeamPullRequest
+		var pos PRCursor
+		if err := rows.Scan(&pos.ID, &pr.PullRequestID, &pr.Repository, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.Labels, &pr.CreatedAt, &pr.MergedAt); err != nil {
+			rows.Close()
+			return nil, PageInfo{}, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		pos.CreatedAt = pr.CreatedAt
+		prs = append(prs, pr)
+		positions = append(positions, pos)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to iterate PRs: %w", err)
+	}
+
+	page := PageInfo{NextCursor: nextPRCursor(filter, positions)}
+	if err := results.QueryRow().Scan(&page.Total); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to count PRs: %w", err)
+	}
+	// Освобождаем соединение batch до запроса ревьюеров
+	if err := results.Close(); err != nil {
+		return nil, PageInfo{}, fmt.Errorf("failed to close PRs batch: %w", err)
+	}
+
+	if len(prs) > filter.Limit {
+		prs, positions = prs[:filter.Limit], positions[:filter.Limit]
+	}
+	if len(prs) == 0 {
+		return prs, page, nil
+	}
+	internalIDs := make([]int64, len(positions))
+	for i, pos := range positions {
+		internalIDs[i] = pos.ID
+	}
+	reviewers, err := r.getReviewersForPRs(ctx, internalIDs)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	for i := range prs {
+		prReviewers := reviewers[internalIDs[i]]
+		if prReviewers == nil {
+			prReviewers = []models.AssignedReviewer{}
+		}
+		prs[i].AssignedReviewers = prReviewers
+		prs[i].AssignedReviewerIDs = make([]string, 0, len(prReviewers))
+		for _, reviewer := range prReviewers {
+			prs[i].AssignedReviewerIDs = append(prs[i].AssignedReviewerIDs, reviewer.UserID)
+		}
+	}
+
+	return prs, page, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// GetTeamSettings получает настройки команды по имени.
+// Если настройки ни разу не сохранялись, возвращаются пустые настройки.
+func (r *Repository) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	query := `
+		SELECT ts.announcement_template, ts.review_sla_hours, ts.reviewers_per_pr, ts.assignment_strategy,
+			ts.always_include_lead
+		FROM teams t
+		LEFT JOIN team_settings ts ON ts.team_id = t.id
+		WHERE t.name = $1
+	`
+
+	settings := &models.TeamSettings{TeamName: teamName}
+	err := r.pool.QueryRow(ctx, query, teamName).Scan(&settings.AnnouncementTemplate, &settings.ReviewSLAHours,
+		&settings.ReviewersPerPR, &settings.Strategy, &settings.AlwaysIncludeLead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetPRTeamSettings получает настройки команды PR (для PR без сохраненной команды — самой ранней команды автора).
+// ID из нескольких репозиториев дает ErrAmbiguousPR.
+func (r *Repository) GetPRTeamSettings(ctx context.Context, pullRequestID string) (*models.TeamSettings, error) {
+	prID, err := r.lookupPR(ctx, r.pool, prRef(pullRequestID), false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.name, ts.announcement_template, ts.review_sla_hours, ts.reviewers_per_pr, ts.assignment_strategy,
+			ts.always_include_lead
+		FROM pull_requests pr
+		JOIN teams t ON t.id = COALESCE(
+			pr.team_id,
+			(SELECT MIN(tu.team_id) FROM team_users tu WHERE tu.user_id = pr.author_id)
+		)
+		LEFT JOIN team_settings ts ON ts.team_id = t.id
+		WHERE pr.id = $1
+	`
+
+	var settings models.TeamSettings
+	err = r.pool.QueryRow(ctx, query, prID).Scan(&settings.TeamName, &settings.AnnouncementTemplate, &settings.ReviewSLAHours,
+		&settings.ReviewersPerPR, &settings.Strategy, &settings.AlwaysIncludeLead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR team settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateTeamSettings сохраняет настройки команды (upsert по team_id).
+// nil-поля остаются прежними; пустые строки и нули сбрасывают настройку к значению по умолчанию.
+func (r *Repository) UpdateTeamSettings(ctx context.Context, settings models.TeamSettings) (*models.TeamSettings, error) {
+	query := `
+		INSERT INTO team_settings (team_id, announcement_template, review_sla_hours, reviewers_per_pr, assignment_strategy,
+			always_include_lead)
+		SELECT id, NULLIF($2, ''), NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, ''), $6 FROM teams WHERE name = $1
+		ON CONFLICT (team_id) DO UPDATE
+		SET announcement_template = CASE WHEN $2::text IS NULL
+				THEN team_settings.announcement_template ELSE excluded.announcement_template END,
+			review_sla_hours = CASE WHEN $3::integer IS NULL
+				THEN team_settings.review_sla_hours ELSE excluded.review_sla_hours END,
+			reviewers_per_pr = CASE WHEN $4::integer IS NULL
+				THEN team_settings.reviewers_per_pr ELSE excluded.reviewers_per_pr END,
+			assignment_strategy = CASE WHEN $5::text IS NULL
+				THEN team_settings.assignment_strategy ELSE excluded.assignment_strategy END,
+			always_include_lead = CASE WHEN $6::boolean IS NULL
+				THEN team_settings.always_include_lead ELSE excluded.always_include_lead END,
+			updated_at = NOW()
+		RETURNING announcement_template, review_sla_hours, reviewers_per_pr, assignment_strategy, always_include_lead
+	`
+
+	updated := &models.TeamSettings{TeamName: settings.TeamName}
+	err := r.pool.QueryRow(ctx, query, settings.TeamName, settings.AnnouncementTemplate, settings.ReviewSLAHours,
+		settings.ReviewersPerPR, settings.Strategy, settings.AlwaysIncludeLead).
+		Scan(&updated.AnnouncementTemplate, &updated.ReviewSLAHours, &updated.ReviewersPerPR, &updated.Strategy,
+			&updated.AlwaysIncludeLead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update team settings: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetTeamAssignment возвращает действующие правила назначения команды:
+// сохраненные в team_settings, а для незаданных — значения по умолчанию
+func (r *Repository) GetTeamAssignment(ctx context.Context, teamName string) (*models.AssignmentSettings, error) {
+	query := `
+		SELECT ts.reviewers_per_pr, ts.assignment_strategy, ts.always_include_lead
+		FROM teams t
+		LEFT JOIN team_settings ts ON ts.team_id = t.id
+		WHERE t.name = $1
+	`
+
+	var perPR *int
+	var strategy *string
+	var includeLead *bool
+	err := r.pool.QueryRow(ctx, query, teamName).Scan(&perPR, &strategy, &includeLead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team assignment settings: %w", err)
+	}
+
+	settings := r.effectiveAssignment(perPR, strategy, includeLead)
+	return &settings, nil
+}
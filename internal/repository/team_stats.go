@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// teamStatsPR — PR команды из окна статистики: создан в окне или слит в окне
+type teamStatsPR struct {
+	status    string
+	createdAt time.Time
+	mergedAt  *time.Time
+	// firstReviewAt — первое одобрение PR ревьюером; nil, если одобрений нет
+	firstReviewAt *time.Time
+	reviewers     int
+}
+
+// GetTeamStats считает статистику PR, авторы которых состоят в команде: число открытых PR сейчас,
+// а с момента since — число слитых PR, среднее и p90 время до слияния, среднее время до первого
+// одобрения и среднее число ревьюеров у созданных PR. Открытые PR считаются в SQL, показатели окна —
+// по строкам PR окна (см. aggregateTeamStats).
+func (r *Repository) GetTeamStats(ctx context.Context, teamName string, since time.Time) (*models.TeamStats, error) {
+	var teamID int64
+	err := r.reader(ctx).QueryRow(ctx, "SELECT id FROM teams WHERE name = $1", teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team by name: %w", err)
+	}
+
+	stats := &models.TeamStats{
+		TeamName: teamName,
+		Since:    since.Format(time.DateOnly),
+	}
+	err = r.reader(ctx).QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM pull_requests pr
+		JOIN team_users tu ON tu.user_id = pr.author_id
+		WHERE tu.team_id = $1 AND pr.status = $2
+	`, teamID, models.StatusOpen).Scan(&stats.OpenPRs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open team PRs: %w", err)
+	}
+
+	query := `
+		SELECT pr.status, pr.created_at, pr.merged_at, MIN(prr.approved_at), COUNT(prr.reviewer_id)
+		FROM pull_requests pr
+		JOIN team_users tu ON tu.user_id = pr.author_id
+		LEFT JOIN pr_reviewers prr ON prr.pr_id = pr.id
+		WHERE tu.team_id = $1 AND (pr.created_at >= $2 OR (pr.status = $3 AND pr.merged_at >= $2))
+		GROUP BY pr.id
+	`
+	rows, err := r.reader(ctx).Query(ctx, query, teamID, since, models.StatusMerged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []teamStatsPR
+	for rows.Next() {
+		var pr teamStatsPR
+		if err := rows.Scan(&pr.status, &pr.createdAt, &pr.mergedAt, &pr.firstReviewAt, &pr.reviewers); err != nil {
+			return nil, fmt.Errorf("failed to scan team PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate team PRs: %w", err)
+	}
+
+	aggregateTeamStats(stats, prs, since)
+	return stats, nil
+}
+
+// aggregateTeamStats заполняет показатели окна: время до слияния — по PR, слитым начиная с since,
+// время до первого одобрения и число ревьюеров — по PR, созданным начиная с since. Среднее число
+// ревьюеров округляется до сотых.
+func aggregateTeamStats(stats *models.TeamStats, prs []teamStatsPR, since time.Time) {
+	var toMerge, toFirstReview []float64
+	var created, reviewers int
+	for _, pr := range prs {
+		if pr.status == models.StatusMerged && pr.mergedAt != nil && !pr.mergedAt.Before(since) {
+			toMerge = append(toMerge, pr.mergedAt.Sub(pr.createdAt).Seconds())
+		}
+		if pr.createdAt.Before(since) {
+			continue
+		}
+		created++
+		reviewers += pr.reviewers
+		if pr.firstReviewAt != nil {
+			toFirstReview = append(toFirstReview, pr.firstReviewAt.Sub(pr.createdAt).Seconds())
+		}
+	}
+
+	stats.MergedPRs = len(toMerge)
+	if len(toMerge) > 0 {
+		stats.AvgTimeToMerge = models.NewDurationStat(mean(toMerge))
+		stats.P90TimeToMerge = models.NewDurationStat(percentileCont(toMerge, 0.9))
+	}
+	if len(toFirstReview) > 0 {
+		stats.AvgTimeToFirstReview = models.NewDurationStat(mean(toFirstReview))
+	}
+	if created > 0 {
+		stats.AvgReviewersPerPR = math.Round(float64(reviewers)/float64(created)*100) / 100
+	}
+}
+
+// mean возвращает среднее непустого набора значений
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentileCont возвращает перцентиль p непустого набора с линейной интерполяцией между соседними
+// значениями, как percentile_cont в PostgreSQL. Порядок values меняется.
+func percentileCont(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	pos := p * float64(len(values)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return values[lo] + (values[hi]-values[lo])*(pos-float64(lo))
+}
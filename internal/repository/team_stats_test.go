@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// seededPR — PR команды с заданными временами для fakeDB статистики
+type seededPR struct {
+	status        string
+	createdAt     time.Time
+	mergedAt      *time.Time
+	firstReviewAt *time.Time
+	reviewers     int
+}
+
+// teamStatsDB возвращает fakeDB, который отвечает на запросы GetTeamStats по засеянным PR
+// команды 7 так же, как отфильтровал бы их SQL
+func teamStatsDB(prs []seededPR) *fakeDB {
+	return &fakeDB{
+		query: func(sql string, args []any) (*fakeRows, error) {
+			switch {
+			case strings.Contains(sql, "FROM teams"):
+				return newFakeRows([]string{"id"}, []any{int64(7)}), nil
+			case strings.Contains(sql, "COUNT(*)"):
+				open := 0
+				for _, pr := range prs {
+					if pr.status == args[1] {
+						open++
+					}
+				}
+				return newFakeRows([]string{"count"}, []any{open}), nil
+			case strings.Contains(sql, "MIN(prr.approved_at)"):
+				since := args[1].(time.Time)
+				var data [][]any
+				for _, pr := range prs {
+					mergedInWindow := pr.status == args[2] && pr.mergedAt != nil && !pr.mergedAt.Before(since)
+					if pr.createdAt.Before(since) && !mergedInWindow {
+						continue
+					}
+					row := []any{pr.status, pr.createdAt, nil, nil, pr.reviewers}
+					if pr.mergedAt != nil {
+						row[2] = *pr.mergedAt
+					}
+					if pr.firstReviewAt != nil {
+						row[3] = *pr.firstReviewAt
+					}
+					data = append(data, row)
+				}
+				return newFakeRows([]string{"status", "created_at", "merged_at", "min", "count"}, data...), nil
+			}
+			return nil, errFakeUnexpected
+		},
+	}
+}
+
+func TestGetTeamStats(t *testing.T) {
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return since.AddDate(0, 0, d-1) }
+	at := func(t time.Time) *time.Time { return &t }
+
+	cases := []struct {
+		name string
+		prs  []seededPR
+		want models.TeamStats
+	}{
+		{
+			name: "mixed window",
+			prs: []seededPR{
+				// Слит через 2 часа, первое одобрение через час
+				{status: models.StatusMerged, createdAt: day(2), mergedAt: at(day(2).Add(2 * time.Hour)),
+					firstReviewAt: at(day(2).Add(time.Hour)), reviewers: 2},
+				// Слит через сутки, первое одобрение через 2 часа
+				{status: models.StatusMerged, createdAt: day(3), mergedAt: at(day(4)),
+					firstReviewAt: at(day(3).Add(2 * time.Hour)), reviewers: 2},
+				// Создан до окна, слит в окне через 15 дней: входит только во время до слияния
+				{status: models.StatusMerged, createdAt: day(1).AddDate(0, 0, -11), mergedAt: at(day(5)),
+					firstReviewAt: at(day(1).AddDate(0, 0, -10)), reviewers: 1},
+				// Открыт без одобрений
+				{status: models.StatusOpen, createdAt: day(6), reviewers: 1},
+				// Закрыт, первое одобрение через 30 минут
+				{status: models.StatusClosed, createdAt: day(7), firstReviewAt: at(day(7).Add(30 * time.Minute)), reviewers: 1},
+				// Создан и слит до окна
+				{status: models.StatusMerged, createdAt: day(1).AddDate(0, 0, -30), mergedAt: at(day(1).AddDate(0, 0, -21)), reviewers: 2},
+				// Открыт с момента до окна: только в open_prs
+				{status: models.StatusOpen, createdAt: day(1).AddDate(0, 0, -16), reviewers: 2},
+			},
+			want: models.TeamStats{
+				OpenPRs:   2,
+				MergedPRs: 3,
+				// (7200 + 86400 + 1296000) / 3
+				AvgTimeToMerge: &models.DurationStat{Seconds: 463200, Human: "5d 8h 40m"},
+				// 86400 + (1296000 - 86400) * 0.8
+				P90TimeToMerge: &models.DurationStat{Seconds: 1054080, Human: "12d 4h 48m"},
+				// (3600 + 7200 + 1800) / 3
+				AvgTimeToFirstReview: &models.DurationStat{Seconds: 4200, Human: "1h 10m"},
+				// (2 + 2 + 1 + 1) / 4
+				AvgReviewersPerPR: 1.5,
+			},
+		},
+		{
+			name: "single merged PR",
+			prs: []seededPR{
+				{status: models.StatusMerged, createdAt: day(1), mergedAt: at(day(1).Add(45 * time.Second)),
+					firstReviewAt: at(day(1).Add(30 * time.Second)), reviewers: 1},
+			},
+			want: models.TeamStats{
+				MergedPRs:            1,
+				AvgTimeToMerge:       &models.DurationStat{Seconds: 45, Human: "45s"},
+				P90TimeToMerge:       &models.DurationStat{Seconds: 45, Human: "45s"},
+				AvgTimeToFirstReview: &models.DurationStat{Seconds: 30, Human: "30s"},
+				AvgReviewersPerPR:    1,
+			},
+		},
+		{
+			name: "reviewers average rounded",
+			prs: []seededPR{
+				{status: models.StatusOpen, createdAt: day(1), reviewers: 2},
+				{status: models.StatusOpen, createdAt: day(2), reviewers: 2},
+				{status: models.StatusOpen, createdAt: day(3), reviewers: 1},
+			},
+			want: models.TeamStats{OpenPRs: 3, AvgReviewersPerPR: 1.67},
+		},
+		{
+			name: "empty window",
+			prs: []seededPR{
+				{status: models.StatusOpen, createdAt: day(1).AddDate(0, 0, -5), reviewers: 2},
+			},
+			want: models.TeamStats{OpenPRs: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New(teamStatsDB(tc.prs), Options{})
+
+			stats, err := r.GetTeamStats(context.Background(), "backend", since)
+			require.NoError(t, err)
+
+			tc.want.TeamName = "backend"
+			tc.want.Since = "2025-10-01"
+			assert.Equal(t, tc.want, *stats)
+		})
+	}
+}
+
+func TestGetTeamStatsUnknownTeam(t *testing.T) {
+	r := New(&fakeDB{query: func(string, []any) (*fakeRows, error) {
+		return newFakeRows([]string{"id"}), nil
+	}}, Options{})
+
+	_, err := r.GetTeamStats(context.Background(), "ghost", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPercentileCont(t *testing.T) {
+	// Значения совпадают с percentile_cont(0.9) в PostgreSQL на тех же наборах
+	cases := []struct {
+		values []float64
+		want   float64
+	}{
+		{[]float64{10}, 10},
+		{[]float64{10, 20}, 19},
+		{[]float64{30, 10, 20}, 28},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 9.1},
+		{[]float64{5, 5, 5, 5}, 5},
+	}
+	for _, tc := range cases {
+		assert.InDelta(t, tc.want, percentileCont(tc.values, 0.9), 1e-9, "values %v", tc.values)
+	}
+}
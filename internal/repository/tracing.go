@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer создает спаны многошаговых методов репозитория; запросы pgx становятся их дочерними спанами.
+// Пока глобальный провайдер трасс не настроен, спаны не записываются.
+var tracer = otel.Tracer("github.com/untibullet/pr-manager-avito/internal/repository")
+
+// startSpan открывает спан метода репозитория
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "repository."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan отмечает ошибку метода в спане и завершает его
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
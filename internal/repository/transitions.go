@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// ErrInvalidTransition — запрещенный переход статуса PR (см. models.CanTransition)
+var ErrInvalidTransition = errors.New("invalid PR status transition")
+
+// TransitionError описывает запрещенный переход статуса PR.
+// errors.Is(err, ErrInvalidTransition) выполняется всегда, errors.Is(err, ErrAlreadyMerged) — для смерженного PR.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change PR status from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition || (target == ErrAlreadyMerged && e.From == models.StatusMerged)
+}
+
+// checkTransition возвращает *TransitionError, если PR нельзя перевести из статуса from в статус to
+func checkTransition(from, to string) error {
+	if models.CanTransition(from, to) {
+		return nil
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// lockPRStatus блокирует строку PR до конца транзакции и возвращает его внутренний ID, автора и статус.
+// Проверка перехода и обновление статуса под этой блокировкой не пересекаются с параллельными запросами.
+func (r *Repository) lockPRStatus(ctx context.Context, tx pgx.Tx, ref models.PRRef) (prID, authorID int64, status string, err error) {
+	if prID, err = r.lookupPR(ctx, tx, ref, true); err != nil {
+		return 0, 0, "", err
+	}
+	err = tx.QueryRow(ctx, `SELECT author_id, status FROM pull_requests WHERE id = $1`, prID).Scan(&authorID, &status)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to get PR status: %w", err)
+	}
+	return prID, authorID, status, nil
+}
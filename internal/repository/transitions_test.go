@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	// Все пары статусов: разрешены только переходы из models.statusTransitions. Переход в тот же статус
+	// тоже запрещен: MergePR, ClosePR и ReopenPR обрабатывают его как повтор до проверки перехода.
+	cases := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{models.StatusOpen, models.StatusOpen, false},
+		{models.StatusOpen, models.StatusMerged, true},
+		{models.StatusOpen, models.StatusClosed, true},
+		{models.StatusMerged, models.StatusOpen, false},
+		{models.StatusMerged, models.StatusMerged, false},
+		{models.StatusMerged, models.StatusClosed, false},
+		{models.StatusClosed, models.StatusOpen, true},
+		{models.StatusClosed, models.StatusMerged, false},
+		{models.StatusClosed, models.StatusClosed, false},
+		// Неизвестные статусы никуда не переходят и недостижимы
+		{"DRAFT", models.StatusOpen, false},
+		{models.StatusOpen, "DRAFT", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, models.CanTransition(tc.from, tc.to))
+
+			err := checkTransition(tc.from, tc.to)
+			if tc.allowed {
+				require.NoError(t, err)
+				return
+			}
+
+			var transition *TransitionError
+			require.ErrorAs(t, err, &transition)
+			assert.Equal(t, TransitionError{From: tc.from, To: tc.to}, *transition)
+			assert.EqualError(t, err, "cannot change PR status from "+tc.from+" to "+tc.to)
+			assert.ErrorIs(t, err, ErrInvalidTransition)
+			assert.Equal(t, tc.from == models.StatusMerged, errors.Is(err, ErrAlreadyMerged),
+				"only transitions out of MERGED are ErrAlreadyMerged")
+			assert.NotErrorIs(t, err, ErrAlreadyClosed)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// GetUnassignedPRs возвращает открытые PR без единого ревьюера, от самых старых к новым.
+// Если teamName не пустой, выбираются только PR авторов из этой команды.
+func (r *Repository) GetUnassignedPRs(ctx context.Context, teamName string, limit, offset int) ([]models.UnassignedPullRequest, error) {
+	if teamName != "" {
+		var exists bool
+		err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)`, teamName).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check team existence: %w", err)
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	query := `
+		SELECT pr.external_id AS pull_request_id,
+			pr.repository,
+			pr.title AS pull_request_name,
+			u.external_id AS author_id,
+			u.name AS author_name,
+			COALESCE(t.name, '') AS team_name,
+			pr.created_at,
+			EXTRACT(EPOCH FROM (NOW()::timestamp - pr.created_at))::bigint AS age_seconds
+		FROM pull_requests pr
+		JOIN users u ON u.id = pr.author_id
+		LEFT JOIN team_users tu ON tu.user_id = pr.author_id
+		LEFT JOIN teams t ON t.id = tu.team_id
+		WHERE pr.status = $1
+		  AND NOT EXISTS (SELECT 1 FROM pr_reviewers prr WHERE prr.pr_id = pr.id)
+		  AND ($2::text = '' OR t.name = $2)
+		ORDER BY pr.created_at, pr.id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, models.StatusOpen, teamName, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unassigned PRs: %w", err)
+	}
+	defer rows.Close()
+
+	prs, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.UnassignedPullRequest])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect unassigned PRs: %w", err)
+	}
+
+	return prs, nil
+}
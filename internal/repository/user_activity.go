@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// SetUsersActiveBatch обновляет статус активности нескольких пользователей одним запросом
+// (UPDATE по unnest массивов, то есть один round trip и одна транзакция).
+// Возвращает обновленных пользователей и внешние ID, которых нет в базе; отсутствующие пользователи
+// не прерывают обновление остальных. При повторе ID в пачке применяется последнее значение.
+// Как и у GetUser, команда — одна из команд пользователя, а отпуска — только текущие и будущие.
+func (r *Repository) SetUsersActiveBatch(ctx context.Context, updates []models.UserActivityUpdate) ([]models.User, []string, error) {
+	ids := make([]string, 0, len(updates))
+	states := make([]bool, 0, len(updates))
+	position := make(map[string]int, len(updates))
+	for _, u := range updates {
+		if i, ok := position[u.UserID]; ok {
+			states[i] = u.IsActive
+			continue
+		}
+		position[u.UserID] = len(ids)
+		ids = append(ids, u.UserID)
+		states = append(states, u.IsActive)
+	}
+
+	query := `
+		WITH input AS (
+			SELECT * FROM unnest($1::text[], $2::bool[]) AS t(external_id, is_active)
+		), updated AS (
+			UPDATE users u
+			SET is_active = i.is_active, updated_at = NOW()
+			FROM input i
+			WHERE ` + r.userIDMatch("u.external_id", "i.external_id") + `
+			RETURNING u.id, u.external_id, u.name, u.is_active, u.is_reviewer, i.external_id AS input_id
+		)
+		SELECT DISTINCT ON (up.id)
+			up.input_id, up.external_id, up.name, COALESCE(t.name, ''), up.is_active, up.is_reviewer,
+			COALESCE((
+				SELECT json_agg(json_build_object('vacation_id', v.id, 'from', v.starts_at, 'to', v.ends_at)
+					ORDER BY v.starts_at)
+				FROM user_vacations v
+				WHERE v.user_id = up.id AND v.ends_at > NOW()
+			), '[]'::json)
+		FROM updated up
+		LEFT JOIN team_users tu ON tu.user_id = up.id
+		LEFT JOIN teams t ON t.id = tu.team_id
+		ORDER BY up.id
+	`
+	rows, err := r.pool.Query(ctx, query, ids, states)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update users status batch: %w", err)
+	}
+	// Кэш очищается после закрытия rows, когда обновление уже зафиксировано
+	defer r.invalidateCache()
+	defer rows.Close()
+
+	users := make([]models.User, 0, len(ids))
+	found := make(map[string]struct{}, len(ids))
+	for rows.Next() {
+		var inputID string
+		var user models.User
+		if err := rows.Scan(&inputID, &user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.IsReviewer, &user.Vacations); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan updated user: %w", err)
+		}
+		found[inputID] = struct{}{}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to update users status batch: %w", err)
+	}
+
+	notFound := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return users, notFound, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// AddVacation добавляет отпуск пользователю на интервал [from, to).
+// Пересечение с уже существующим отпуском пользователя возвращает ErrVacationOverlap.
+func (r *Repository) AddVacation(ctx context.Context, userID string, from, to time.Time) (*models.Vacation, error) {
+	// Колонки хранят время без зоны в UTC
+	from, to = from.UTC(), to.UTC()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем пользователя, чтобы параллельные запросы не создали пересекающиеся отпуска
+	var internalID int64
+	userQuery := `SELECT id FROM users WHERE ` + r.userIDMatch("external_id", "$1") + ` FOR UPDATE`
+	err = tx.QueryRow(ctx, userQuery, userID).Scan(&internalID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by external id: %w", err)
+	}
+
+	var overlaps bool
+	overlapQuery := `
+		SELECT EXISTS(
+			SELECT 1 FROM user_vacations
+			WHERE user_id = $1 AND starts_at < $3 AND ends_at > $2
+		)
+	`
+	if err := tx.QueryRow(ctx, overlapQuery, internalID, from, to).Scan(&overlaps); err != nil {
+		return nil, fmt.Errorf("failed to check vacation overlap: %w", err)
+	}
+	if overlaps {
+		return nil, ErrVacationOverlap
+	}
+
+	vacation := &models.Vacation{From: from, To: to}
+	insertQuery := `INSERT INTO user_vacations (user_id, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRow(ctx, insertQuery, internalID, from, to).Scan(&vacation.VacationID); err != nil {
+		return nil, fmt.Errorf("failed to create vacation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.invalidateCache()
+
+	return vacation, nil
+}
+
+// DeleteVacation удаляет отпуск пользователя по ID
+func (r *Repository) DeleteVacation(ctx context.Context, userID string, vacationID int64) error {
+	query := `
+		DELETE FROM user_vacations v
+		USING users u
+		WHERE v.user_id = u.id
+		  AND v.id = $2
+		  AND ` + r.userIDMatch("u.external_id", "$1")
+
+	tag, err := r.pool.Exec(ctx, query, userID, vacationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete vacation: %w", err)
+	}
+	r.invalidateCache()
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// getUpcomingVacations получает из db текущие и будущие отпуска пользователя по внешнему ID
+func (r *Repository) getUpcomingVacations(ctx context.Context, db DB, userID string) ([]models.Vacation, error) {
+	query := `
+		SELECT v.id, v.starts_at, v.ends_at
+		FROM user_vacations v
+		JOIN users u ON u.id = v.user_id
+		WHERE ` + r.userIDMatch("u.external_id", "$1") + `
+		  AND v.ends_at > NOW()
+		ORDER BY v.starts_at
+	`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vacations: %w", err)
+	}
+
+	vacations, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Vacation])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect vacations: %w", err)
+	}
+	if vacations == nil {
+		vacations = []models.Vacation{}
+	}
+
+	return vacations, nil
+}
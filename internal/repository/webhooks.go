@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// webhookColumns — колонки подписки в порядке, который ожидает scanWebhook
+const webhookColumns = `id, url, secret, event_types, enabled, created_at, updated_at`
+
+// scanWebhook читает подписку из строки результата
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var hook models.Webhook
+	err := row.Scan(&hook.WebhookID, &hook.URL, &hook.Secret, &hook.EventTypes, &hook.Enabled, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// CreateWebhook создает подписку на события
+func (r *Repository) CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error) {
+	created, err := scanWebhook(r.pool.QueryRow(ctx, `
+		INSERT INTO webhooks (url, secret, event_types, enabled)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		hook.URL, hook.Secret, hook.EventTypes, hook.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return created, nil
+}
+
+// GetWebhook возвращает подписку по ID
+func (r *Repository) GetWebhook(ctx context.Context, webhookID int64) (*models.Webhook, error) {
+	hook, err := scanWebhook(r.pool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, webhookID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// ListWebhooks возвращает все подписки в порядке создания
+func (r *Repository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+}
+
+// ListEnabledWebhooks возвращает включенные подписки на событие eventType
+func (r *Repository) ListEnabledWebhooks(ctx context.Context, eventType string) ([]models.Webhook, error) {
+	return r.queryWebhooks(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE enabled AND $1 = ANY(event_types)
+		ORDER BY id
+	`, eventType)
+}
+
+// queryWebhooks выполняет запрос, возвращающий подписки
+func (r *Repository) queryWebhooks(ctx context.Context, query string, args ...any) ([]models.Webhook, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		hooks = append(hooks, *hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook частично изменяет подписку: незаданные поля update остаются прежними
+func (r *Repository) UpdateWebhook(ctx context.Context, webhookID int64, update models.WebhookUpdate) (*models.Webhook, error) {
+	hook, err := scanWebhook(r.pool.QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = COALESCE($2, url),
+		    secret = COALESCE($3, secret),
+		    event_types = COALESCE($4::text[], event_types),
+		    enabled = COALESCE($5, enabled),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		webhookID, update.URL, update.Secret, update.EventTypes, update.Enabled))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с историей доставок
+func (r *Repository) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordWebhookAttempt сохраняет попытку доставки события.
+// Подписка могла быть удалена во время доставки — тогда попытка не сохраняется.
+func (r *Repository) RecordWebhookAttempt(ctx context.Context, attempt models.WebhookAttempt) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_attempts (webhook_id, event_id, event_type, attempt, status_code, error, duration_ms, success)
+		SELECT id, $2, $3, $4, $5, NULLIF($6, ''), $7, $8 FROM webhooks WHERE id = $1
+	`, attempt.WebhookID, attempt.EventID, attempt.EventType, attempt.Attempt,
+		attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.Success)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookAttempts возвращает страницу попыток доставки подписки (новые первыми) и их общее число.
+// Для несуществующей подписки возвращает ErrNotFound.
+func (r *Repository) ListWebhookAttempts(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookAttempt, int, error) {
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = $1)`, webhookID).Scan(&exists); err != nil {
+		return nil, 0, fmt.Errorf("failed to check webhook: %w", err)
+	}
+	if !exists {
+		return nil, 0, ErrNotFound
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_attempts WHERE webhook_id = $1`, webhookID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook attempts: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, webhook_id, event_id, event_type, attempt, status_code, COALESCE(error, ''), duration_ms, success, created_at
+		FROM webhook_attempts
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]models.WebhookAttempt, 0)
+	for rows.Next() {
+		var a models.WebhookAttempt
+		if err := rows.Scan(&a.AttemptID, &a.WebhookID, &a.EventID, &a.EventType, &a.Attempt,
+			&a.StatusCode, &a.Error, &a.DurationMs, &a.Success, &a.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate webhook attempts: %w", err)
+	}
+	return attempts, total, nil
+}
+
+// PruneWebhookAttempts удаляет попытки доставки старше retention
+func (r *Repository) PruneWebhookAttempts(ctx context.Context, retention time.Duration) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM webhook_attempts WHERE created_at < NOW() - make_interval(secs => $1)`,
+		retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook attempts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
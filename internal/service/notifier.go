@@ -0,0 +1,24 @@
+package service
+
+import "github.com/untibullet/pr-manager-avito/internal/models"
+
+// Notifier публикует события сервиса (исходящие вебхуки, уведомления в Slack).
+// Publish не должен блокировать и не возвращает ошибок,
+// чтобы сбой доставки не влиял на результат запроса.
+type Notifier interface {
+	Publish(event models.WebhookEvent)
+}
+
+// MultiNotifier объединяет несколько Notifier в один — для публикации событий вне сервисного слоя (воркеры)
+func MultiNotifier(ns ...Notifier) Notifier {
+	return notifiers(ns)
+}
+
+// notifiers рассылает событие всем подключенным Notifier; пустой список ничего не публикует
+type notifiers []Notifier
+
+func (ns notifiers) Publish(event models.WebhookEvent) {
+	for _, n := range ns {
+		n.Publish(event)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+)
+
+// PRService реализует сценарии работы с PR.
+// Ошибки репозитория (repository.ErrNotFound и т.п.) возвращаются без изменений.
+// Успешные изменения публикуются как события исходящих вебхуков.
+type PRService struct {
+	repo     Store
+	notifier Notifier
+}
+
+// NewPRService создает сервис PR
+func NewPRService(repo Store, notifier Notifier) *PRService {
+	return &PRService{repo: repo, notifier: notifier}
+}
+
+// Create создает PR в репозитории repository (пустой — без репозитория) со сведениями meta и назначает
+// ревьюеров из команды teamName (пустая — команда автора) согласно стратегии назначения.
+// Публикует pr.created и reviewer.assigned для каждого назначенного ревьюера.
+func (s *PRService) Create(ctx context.Context, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error) {
+	pr, err := s.repo.CreatePR(ctx, repository, pullRequestID, pullRequestName, authorID, teamName, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(models.EventPRCreated, models.WebhookEventData{PullRequest: pr})
+	for _, reviewer := range pr.AssignedReviewers {
+		s.publish(models.EventReviewerAssigned, models.WebhookEventData{PullRequest: pr, ReviewerID: reviewer.UserID})
+	}
+
+	return pr, nil
+}
+
+// Reassign заменяет ревьюера PR на newReviewerID или, если он пуст, на автоматически выбранного
+// участника команды PR. Возвращает обновленный PR и внешний ID нового ревьюера, публикует reviewer.reassigned.
+// expectedVersion (nil — без проверки) передается в репозиторий, см. repository.ErrVersionConflict.
+func (s *PRService) Reassign(ctx context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (*models.PullRequest, string, error) {
+	newReviewerID, err := s.repo.ReassignReviewer(ctx, ref, oldReviewerID, newReviewerID, expectedVersion)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Обновленный PR читается из основного пула: реплика может еще не получить переназначение
+	pr, err := s.repo.GetPR(repository.WithPrimary(ctx), ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get updated PR: %w", err)
+	}
+
+	s.publish(models.EventReviewerReassigned, models.WebhookEventData{
+		PullRequest:   pr,
+		OldReviewerID: oldReviewerID,
+		NewReviewerID: newReviewerID,
+	})
+
+	return pr, newReviewerID, nil
+}
+
+// AddReviewer назначает на PR по ссылке ref дополнительного ревьюера: userID или, если он пуст, автоматически
+// выбранного участника команды PR. Возвращает обновленный PR и внешний ID ревьюера, публикует reviewer.assigned.
+// expectedVersion (nil — без проверки) передается в репозиторий.
+func (s *PRService) AddReviewer(ctx context.Context, ref models.PRRef, userID string, expectedVersion *int64) (*models.PullRequest, string, error) {
+	pr, reviewerID, err := s.repo.AddReviewer(ctx, ref, userID, expectedVersion)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.publish(models.EventReviewerAssigned, models.WebhookEventData{PullRequest: pr, ReviewerID: reviewerID})
+
+	return pr, reviewerID, nil
+}
+
+// Merge переводит PR в статус MERGED и публикует pr.merged.
+// Повторный merge уже слитого PR успешен, но событие не публикует: merged сообщает,
+// что PR слит этим вызовом. expectedVersion (nil — без проверки) передается в репозиторий.
+func (s *PRService) Merge(ctx context.Context, ref models.PRRef, expectedVersion *int64) (pr *models.PullRequest, merged bool, err error) {
+	pr, merged, err = s.repo.MergePR(ctx, ref, expectedVersion)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if merged {
+		s.publish(models.EventPRMerged, models.WebhookEventData{PullRequest: pr})
+	}
+
+	return pr, merged, nil
+}
+
+// publish отправляет событие в notifier
+func (s *PRService) publish(eventType string, data models.WebhookEventData) {
+	s.notifier.Publish(models.WebhookEvent{Type: eventType, Data: data})
+}
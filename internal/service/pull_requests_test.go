@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/untibullet/pr-manager-avito/internal/mocks"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+)
+
+// recorder — Notifier, запоминающий типы опубликованных событий
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) Publish(event models.WebhookEvent) {
+	r.events = append(r.events, event.Type)
+}
+
+func TestMergePublishesOnlyActualMerge(t *testing.T) {
+	pr := &models.PullRequest{PullRequestID: "pr-1", Status: models.StatusMerged}
+
+	for _, tc := range []struct {
+		name       string
+		merged     bool
+		err        error
+		wantEvents []string
+	}{
+		{name: "open PR merged", merged: true, wantEvents: []string{models.EventPRMerged}},
+		{name: "already merged", merged: false, wantEvents: nil},
+		{name: "closed PR", err: &repository.TransitionError{From: models.StatusClosed, To: models.StatusMerged}, wantEvents: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &mocks.Store{
+				MergePRFunc: func(context.Context, models.PRRef, *int64) (*models.PullRequest, bool, error) {
+					if tc.err != nil {
+						return nil, false, tc.err
+					}
+					return pr, tc.merged, nil
+				},
+			}
+			events := &recorder{}
+
+			got, merged, err := service.New(st, events).PRs.Merge(context.Background(), models.PRRef{ID: "pr-1"}, nil)
+
+			assert.Equal(t, tc.wantEvents, events.events)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				assert.False(t, merged)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, pr, got)
+			assert.Equal(t, tc.merged, merged)
+		})
+	}
+}
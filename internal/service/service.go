@@ -0,0 +1,19 @@
+// Package service содержит бизнес-сценарии сервиса: оркестрацию валидации, выбора ревьюеров,
+// записи событий и уведомлений. Хендлеры отвечают только за транспорт,
+// репозиторий — только за доступ к данным.
+package service
+
+// Services объединяет все сервисы приложения
+type Services struct {
+	PRs   *PRService
+	Teams *TeamService
+}
+
+// New создает все сервисы поверх репозитория.
+// События публикуются во все переданные Notifier; без них публикация отключена.
+func New(repo Store, ns ...Notifier) *Services {
+	return &Services{
+		PRs:   NewPRService(repo, notifiers(ns)),
+		Teams: NewTeamService(repo),
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// Store — операции хранилища, которые используют сервисы.
+// Реализуется *repository.Repository; в тестах подменяется моком.
+type Store interface {
+	CreateTeam(ctx context.Context, teamData models.Team) (*models.Team, error)
+	CreatePR(ctx context.Context, repository, pullRequestID, pullRequestName, authorID, teamName string, meta models.PRMetadata) (*models.PullRequest, error)
+	GetPR(ctx context.Context, ref models.PRRef) (*models.PullRequest, error)
+	ReassignReviewer(ctx context.Context, ref models.PRRef, oldReviewerID, newReviewerID string, expectedVersion *int64) (string, error)
+	AddReviewer(ctx context.Context, ref models.PRRef, userID string, expectedVersion *int64) (*models.PullRequest, string, error)
+	MergePR(ctx context.Context, ref models.PRRef, expectedVersion *int64) (pr *models.PullRequest, merged bool, err error)
+}
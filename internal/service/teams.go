@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// TeamService реализует сценарии работы с командами.
+// Ошибки репозитория возвращаются без изменений.
+type TeamService struct {
+	repo Store
+}
+
+// NewTeamService создает сервис команд
+func NewTeamService(repo Store) *TeamService {
+	return &TeamService{repo: repo}
+}
+
+// Create создает или обновляет команду и ее состав
+func (s *TeamService) Create(ctx context.Context, team models.Team) (*models.Team, error) {
+	return s.repo.CreateTeam(ctx, team)
+}
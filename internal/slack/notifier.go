@@ -0,0 +1,212 @@
+// Package slack отправляет в Slack уведомления о назначении ревьюеров и напоминания о ревью через incoming webhook.
+// Сообщения отправляются одной фоновой горутиной не чаще sendInterval, чтобы не упираться в лимит Slack;
+// ошибки отправки только логируются и не влияют на ответы API.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// queueSize — сколько уведомлений может ждать отправки; при заполненной очереди новые отбрасываются
+	queueSize = 500
+
+	// sendInterval — минимальный интервал между сообщениями (Slack допускает около 1 сообщения в секунду)
+	sendInterval = time.Second
+
+	// requestTimeout — таймаут запроса к Slack и поиска ID участника Slack
+	requestTimeout = 5 * time.Second
+)
+
+// Store — операции хранилища, которые использует уведомитель. Реализуется *repository.Repository.
+type Store interface {
+	GetSlackUserID(ctx context.Context, userID string) (string, error)
+}
+
+// Config задает параметры отправки
+type Config struct {
+	// WebhookURL — адрес incoming webhook Slack
+	WebhookURL string
+	// DryRun включает запись сообщений в лог вместо отправки
+	DryRun bool
+}
+
+// assignment — назначение ревьюера, о котором нужно сообщить
+type assignment struct {
+	pr            *models.PullRequest
+	reviewerID    string
+	oldReviewerID string
+	// reminder — напоминание о давнем назначении вместо сообщения о новом
+	reminder bool
+}
+
+// Notifier принимает события назначения ревьюеров и асинхронно отправляет сообщения в Slack.
+// Реализует service.Notifier.
+type Notifier struct {
+	store   Store
+	cfg     Config
+	client  *http.Client
+	limiter *rate.Limiter
+	queue   chan assignment
+	logger  *zap.Logger
+}
+
+// New создает уведомитель. Отправка начинается после вызова Run.
+func New(store Store, cfg Config, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		store:   store,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: requestTimeout},
+		limiter: rate.NewLimiter(rate.Every(sendInterval), 1),
+		queue:   make(chan assignment, queueSize),
+		logger:  logger,
+	}
+}
+
+// Publish ставит в очередь уведомление о назначении ревьюера или напоминание о ревью; остальные события пропускаются.
+// Никогда не блокирует вызывающего: при заполненной очереди уведомление отбрасывается с записью в лог.
+func (n *Notifier) Publish(event models.WebhookEvent) {
+	var a assignment
+	switch event.Type {
+	case models.EventReviewerAssigned:
+		a = assignment{pr: event.Data.PullRequest, reviewerID: event.Data.ReviewerID}
+	case models.EventReviewerReassigned:
+		a = assignment{pr: event.Data.PullRequest, reviewerID: event.Data.NewReviewerID, oldReviewerID: event.Data.OldReviewerID}
+	case models.EventReviewReminder:
+		a = assignment{pr: event.Data.PullRequest, reviewerID: event.Data.ReviewerID, reminder: true}
+	default:
+		return
+	}
+	if a.pr == nil || a.reviewerID == "" {
+		return
+	}
+
+	select {
+	case n.queue <- a:
+	default:
+		n.logger.Warn("SlackNotifier: очередь уведомлений заполнена, уведомление отброшено",
+			zap.String("pr_id", a.pr.PullRequestID),
+			zap.String("reviewer_id", a.reviewerID))
+	}
+}
+
+// Run отправляет уведомления из очереди до отмены ctx.
+// Уведомления, оставшиеся в очереди при остановке, не отправляются.
+func (n *Notifier) Run(ctx context.Context) {
+	n.logger.Info("SlackNotifier: запуск", zap.Bool("dry_run", n.cfg.DryRun))
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.logger.Info("SlackNotifier: остановка", zap.Int("pending_notifications", len(n.queue)))
+			return
+		case a := <-n.queue:
+			if err := n.limiter.Wait(ctx); err != nil {
+				continue
+			}
+			n.notify(ctx, a)
+		}
+	}
+}
+
+// notify формирует и отправляет одно уведомление
+func (n *Notifier) notify(ctx context.Context, a assignment) {
+	log := n.logger.With(zap.String("pr_id", a.pr.PullRequestID), zap.String("reviewer_id", a.reviewerID))
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	// Без ID участника Slack сообщение все равно отправляется, но без упоминания
+	slackUserID, err := n.store.GetSlackUserID(ctx, a.reviewerID)
+	if err != nil {
+		log.Warn("SlackNotifier: не удалось получить ID участника Slack", zap.Error(err))
+	}
+
+	text := FormatMessage(a.pr, a.reviewerID, slackUserID, a.oldReviewerID)
+	if a.reminder {
+		text = FormatReminder(a.pr, a.reviewerID, slackUserID)
+	}
+	if n.cfg.DryRun {
+		log.Info("SlackNotifier: dry-run, сообщение не отправлено", zap.String("text", text))
+		return
+	}
+
+	if err := n.send(ctx, text); err != nil {
+		log.Error("SlackNotifier: ошибка отправки сообщения", zap.Error(err))
+		return
+	}
+	log.Info("SlackNotifier: сообщение отправлено")
+}
+
+// send отправляет текст в incoming webhook
+func (n *Notifier) send(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Incoming webhook отвечает 200, но любой 2xx означает, что сообщение принято
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack responded with status %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// mrkdwnEscaper экранирует управляющие символы разметки Slack
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// mention возвращает упоминание ревьюера: по slackUserID, если он задан, иначе его user_id
+func mention(reviewerID, slackUserID string) string {
+	if slackUserID != "" {
+		return "<@" + slackUserID + ">"
+	}
+	return "`" + mrkdwnEscaper.Replace(reviewerID) + "`"
+}
+
+// FormatMessage возвращает текст уведомления о назначении ревьюера.
+// Ревьюер упоминается по slackUserID, если он задан; oldReviewerID указывается при переназначении.
+func FormatMessage(pr *models.PullRequest, reviewerID, slackUserID, oldReviewerID string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, вам назначено ревью: *%s* (`%s`), автор `%s`",
+		mention(reviewerID, slackUserID),
+		mrkdwnEscaper.Replace(pr.PullRequestName),
+		mrkdwnEscaper.Replace(pr.PullRequestID),
+		mrkdwnEscaper.Replace(pr.AuthorID))
+	if oldReviewerID != "" {
+		fmt.Fprintf(&b, " (вместо `%s`)", mrkdwnEscaper.Replace(oldReviewerID))
+	}
+	return b.String()
+}
+
+// FormatReminder возвращает текст напоминания о давно назначенном и еще не одобренном ревью
+func FormatReminder(pr *models.PullRequest, reviewerID, slackUserID string) string {
+	return fmt.Sprintf("%s, напоминание: ревью *%s* (`%s`) автора `%s` все еще ждет вас",
+		mention(reviewerID, slackUserID),
+		mrkdwnEscaper.Replace(pr.PullRequestName),
+		mrkdwnEscaper.Replace(pr.PullRequestID),
+		mrkdwnEscaper.Replace(pr.AuthorID))
+}
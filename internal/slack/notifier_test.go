@@ -0,0 +1,192 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// slackIDs — Store с ID участников Slack по user_id; для остальных пользователей возвращает ошибку
+type slackIDs map[string]string
+
+func (s slackIDs) GetSlackUserID(_ context.Context, userID string) (string, error) {
+	if id, ok := s[userID]; ok {
+		return id, nil
+	}
+	return "", errors.New("slack user id not found")
+}
+
+// slackRequest — запрос, полученный тестовым incoming webhook
+type slackRequest struct {
+	contentType string
+	payload     map[string]string
+}
+
+// slackServer — тестовый incoming webhook: запоминает запросы и отвечает status с телом body
+type slackServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []slackRequest
+}
+
+// newSlackServer запускает тестовый incoming webhook
+func newSlackServer(t *testing.T, status int, body string) *slackServer {
+	t.Helper()
+	s := &slackServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var payload map[string]string
+		assert.NoError(t, json.Unmarshal(data, &payload), string(data))
+		s.mu.Lock()
+		s.requests = append(s.requests, slackRequest{contentType: r.Header.Get("Content-Type"), payload: payload})
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// received возвращает полученные запросы
+func (s *slackServer) received() []slackRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]slackRequest(nil), s.requests...)
+}
+
+// testPR — PR уведомлений в тестах
+var testPR = &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "Fix <login> & logout", AuthorID: "u1"}
+
+func TestNotifierPayload(t *testing.T) {
+	cases := []struct {
+		name  string
+		event models.WebhookEvent
+		text  string
+	}{
+		{
+			name: "assigned with Slack mention",
+			event: models.WebhookEvent{Type: models.EventReviewerAssigned,
+				Data: models.WebhookEventData{PullRequest: testPR, ReviewerID: "u2"}},
+			text: "<@U222>, вам назначено ревью: *Fix &lt;login&gt; &amp; logout* (`pr-1`), автор `u1`",
+		},
+		{
+			name: "assigned without Slack account",
+			event: models.WebhookEvent{Type: models.EventReviewerAssigned,
+				Data: models.WebhookEventData{PullRequest: testPR, ReviewerID: "u3"}},
+			text: "`u3`, вам назначено ревью: *Fix &lt;login&gt; &amp; logout* (`pr-1`), автор `u1`",
+		},
+		{
+			name: "reassigned",
+			event: models.WebhookEvent{Type: models.EventReviewerReassigned,
+				Data: models.WebhookEventData{PullRequest: testPR, OldReviewerID: "u3", NewReviewerID: "u2"}},
+			text: "<@U222>, вам назначено ревью: *Fix &lt;login&gt; &amp; logout* (`pr-1`), автор `u1` (вместо `u3`)",
+		},
+		{
+			name: "reminder",
+			event: models.WebhookEvent{Type: models.EventReviewReminder,
+				Data: models.WebhookEventData{PullRequest: testPR, ReviewerID: "u2"}},
+			text: "<@U222>, напоминание: ревью *Fix &lt;login&gt; &amp; logout* (`pr-1`) автора `u1` все еще ждет вас",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newSlackServer(t, http.StatusOK, "ok")
+			n := New(slackIDs{"u2": "U222"}, Config{WebhookURL: srv.URL}, zap.NewNop())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				n.Run(ctx)
+				close(done)
+			}()
+			n.Publish(tc.event)
+			require.Eventually(t, func() bool { return len(srv.received()) == 1 }, 2*time.Second, 10*time.Millisecond)
+			cancel()
+			<-done
+
+			got := srv.received()[0]
+			assert.Equal(t, "application/json", got.contentType)
+			assert.Equal(t, map[string]string{"text": tc.text}, got.payload)
+		})
+	}
+}
+
+func TestNotifierSkipsUnrelatedEvents(t *testing.T) {
+	n := New(slackIDs{}, Config{}, zap.NewNop())
+
+	n.Publish(models.WebhookEvent{Type: models.EventPRMerged, Data: models.WebhookEventData{PullRequest: testPR}})
+	n.Publish(models.WebhookEvent{Type: models.EventReviewerAssigned, Data: models.WebhookEventData{ReviewerID: "u2"}})
+	n.Publish(models.WebhookEvent{Type: models.EventReviewerAssigned, Data: models.WebhookEventData{PullRequest: testPR}})
+
+	assert.Empty(t, n.queue)
+}
+
+func TestNotifierDryRun(t *testing.T) {
+	srv := newSlackServer(t, http.StatusOK, "ok")
+	core, logs := observer.New(zapcore.InfoLevel)
+	n := New(slackIDs{"u2": "U222"}, Config{WebhookURL: srv.URL, DryRun: true}, zap.New(core))
+
+	n.notify(context.Background(), assignment{pr: testPR, reviewerID: "u2"})
+
+	assert.Empty(t, srv.received(), "dry-run must not call Slack")
+	entries := logs.FilterMessage("SlackNotifier: dry-run, сообщение не отправлено").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, FormatMessage(testPR, "u2", "U222", ""), entries[0].ContextMap()["text"])
+}
+
+func TestNotifierResponseStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{name: "200", status: http.StatusOK, body: "ok"},
+		{name: "204", status: http.StatusNoContent},
+		{name: "400", status: http.StatusBadRequest, body: "invalid_payload\n",
+			err: "slack responded with status 400 Bad Request: invalid_payload"},
+		{name: "404", status: http.StatusNotFound, body: "no_service",
+			err: "slack responded with status 404 Not Found: no_service"},
+		{name: "500", status: http.StatusInternalServerError, body: "",
+			err: "slack responded with status 500 Internal Server Error: "},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newSlackServer(t, tc.status, tc.body)
+			core, logs := observer.New(zapcore.InfoLevel)
+			n := New(slackIDs{"u2": "U222"}, Config{WebhookURL: srv.URL}, zap.New(core))
+
+			err := n.send(context.Background(), "hello")
+			n.notify(context.Background(), assignment{pr: testPR, reviewerID: "u2"})
+
+			// Ошибка отправки только логируется: повторной попытки нет
+			assert.Len(t, srv.received(), 2)
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, 1, logs.FilterMessage("SlackNotifier: сообщение отправлено").Len())
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+			failures := logs.FilterMessage("SlackNotifier: ошибка отправки сообщения").All()
+			require.Len(t, failures, 1)
+			assert.Equal(t, zapcore.ErrorLevel, failures[0].Level)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// IdempotencyCleanupWorker периодически удаляет истекшие ключи идемпотентности,
+// отметки доставок входящих вебхуков и старую историю доставок исходящих
+type IdempotencyCleanupWorker struct {
+	repo     *repository.Repository
+	logger   *zap.Logger
+	interval time.Duration
+	// webhookRetention — срок хранения истории доставок исходящих вебхуков, 0 — без удаления
+	webhookRetention time.Duration
+}
+
+// NewIdempotencyCleanupWorker создает воркер очистки ключей идемпотентности
+func NewIdempotencyCleanupWorker(repo *repository.Repository, logger *zap.Logger, interval, webhookRetention time.Duration) *IdempotencyCleanupWorker {
+	return &IdempotencyCleanupWorker{
+		repo:             repo,
+		logger:           logger,
+		interval:         interval,
+		webhookRetention: webhookRetention,
+	}
+}
+
+// Run удаляет истекшие ключи сразу при старте и далее с заданным интервалом до отмены ctx
+func (w *IdempotencyCleanupWorker) Run(ctx context.Context) {
+	w.logger.Info("IdempotencyCleanupWorker: запуск", zap.Duration("interval", w.interval))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("IdempotencyCleanupWorker: остановка")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick выполняет одну очистку
+func (w *IdempotencyCleanupWorker) tick(ctx context.Context) {
+	pruned, err := w.repo.PruneIdempotencyKeys(ctx)
+	if err != nil {
+		w.logger.Error("IdempotencyCleanupWorker: ошибка удаления истекших ключей", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		w.logger.Info("IdempotencyCleanupWorker: истекшие ключи удалены", zap.Int64("rows_count", pruned))
+	}
+
+	pruned, err = w.repo.PruneWebhookDeliveries(ctx)
+	if err != nil {
+		w.logger.Error("IdempotencyCleanupWorker: ошибка удаления истекших доставок вебхуков", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		w.logger.Info("IdempotencyCleanupWorker: истекшие доставки вебхуков удалены", zap.Int64("rows_count", pruned))
+	}
+
+	if w.webhookRetention == 0 {
+		return
+	}
+	pruned, err = w.repo.PruneWebhookAttempts(ctx, w.webhookRetention)
+	if err != nil {
+		w.logger.Error("IdempotencyCleanupWorker: ошибка удаления истории доставок исходящих вебхуков", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		w.logger.Info("IdempotencyCleanupWorker: старая история доставок исходящих вебхуков удалена", zap.Int64("rows_count", pruned))
+	}
+}
@@ -0,0 +1,72 @@
+// Package worker содержит фоновые задачи сервиса
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/repository"
+	"go.uber.org/zap"
+)
+
+// LoadSnapshotWorker периодически сохраняет снимок загрузки ревьюеров и удаляет устаревшие снимки
+type LoadSnapshotWorker struct {
+	repo      *repository.Repository
+	logger    *zap.Logger
+	interval  time.Duration
+	retention time.Duration
+}
+
+// NewLoadSnapshotWorker создает воркер снимков. retention <= 0 отключает удаление старых снимков.
+func NewLoadSnapshotWorker(repo *repository.Repository, logger *zap.Logger, interval, retention time.Duration) *LoadSnapshotWorker {
+	return &LoadSnapshotWorker{
+		repo:      repo,
+		logger:    logger,
+		interval:  interval,
+		retention: retention,
+	}
+}
+
+// Run делает снимок сразу при старте и далее с заданным интервалом до отмены ctx.
+// Снимки идемпотентны в пределах дня, поэтому перезапуски не создают дубликатов.
+func (w *LoadSnapshotWorker) Run(ctx context.Context) {
+	w.logger.Info("LoadSnapshotWorker: запуск",
+		zap.Duration("interval", w.interval),
+		zap.Duration("retention", w.retention))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("LoadSnapshotWorker: остановка")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick выполняет один снимок и очистку по retention
+func (w *LoadSnapshotWorker) tick(ctx context.Context) {
+	saved, err := w.repo.SnapshotReviewerLoad(ctx)
+	if err != nil {
+		w.logger.Error("LoadSnapshotWorker: ошибка сохранения снимка", zap.Error(err))
+		return
+	}
+	w.logger.Info("LoadSnapshotWorker: снимок загрузки сохранен", zap.Int64("users_count", saved))
+
+	if w.retention <= 0 {
+		return
+	}
+	pruned, err := w.repo.PruneReviewerLoadHistory(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		w.logger.Error("LoadSnapshotWorker: ошибка удаления старых снимков", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		w.logger.Info("LoadSnapshotWorker: старые снимки удалены", zap.Int64("rows_count", pruned))
+	}
+}
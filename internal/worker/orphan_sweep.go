@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/metrics"
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"go.uber.org/zap"
+)
+
+// OrphanSweepStore — операции хранилища, которые использует воркер очистки.
+// Реализуется *repository.Repository.
+type OrphanSweepStore interface {
+	SweepOrphanedReviews(ctx context.Context, inactiveBefore time.Time, limit int) (*models.OrphanSweepResult, error)
+}
+
+// OrphanSweepWorker периодически переназначает ревью в открытых PR, зависшие на пользователях,
+// деактивированных дольше inactiveAfter без переназначения ревью (например, синхронизацией с HR-системой).
+// За запуск обрабатывается не больше limit ревью, остальные дожидаются следующего запуска.
+type OrphanSweepWorker struct {
+	store    OrphanSweepStore
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	interval time.Duration
+	// inactiveAfter — через сколько после деактивации ревью пользователя переназначаются
+	inactiveAfter time.Duration
+	// limit — сколько ревью обрабатывается за запуск
+	limit int
+	// now — источник текущего времени
+	now func() time.Time
+}
+
+// NewOrphanSweepWorker создает воркер очистки ревью давно деактивированных пользователей
+func NewOrphanSweepWorker(store OrphanSweepStore, m *metrics.Metrics, logger *zap.Logger, interval, inactiveAfter time.Duration, limit int) *OrphanSweepWorker {
+	return &OrphanSweepWorker{
+		store:         store,
+		metrics:       m,
+		logger:        logger,
+		interval:      interval,
+		inactiveAfter: inactiveAfter,
+		limit:         limit,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run запускает очистку сразу при старте и далее с заданным интервалом до отмены ctx
+func (w *OrphanSweepWorker) Run(ctx context.Context) {
+	w.logger.Info("OrphanSweepWorker: запуск",
+		zap.Duration("interval", w.interval),
+		zap.Duration("inactive_after", w.inactiveAfter),
+		zap.Int("limit", w.limit))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("OrphanSweepWorker: остановка")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick выполняет один запуск очистки и пишет в лог каждое переназначенное и снятое ревью
+// и число пропущенных недавних назначений
+func (w *OrphanSweepWorker) tick(ctx context.Context) {
+	result, err := w.store.SweepOrphanedReviews(ctx, w.now().Add(-w.inactiveAfter), w.limit)
+	if err != nil {
+		w.logger.Error("OrphanSweepWorker: ошибка переназначения ревью", zap.Error(err))
+		return
+	}
+	w.metrics.ObserveOrphanSweep(len(result.Reassigned), len(result.Unassigned))
+
+	for _, review := range result.Reassigned {
+		w.logger.Info("OrphanSweepWorker: ревью переназначено",
+			zap.String("pr_id", review.PullRequestID),
+			zap.String("repository", review.Repository),
+			zap.String("old_reviewer_id", review.OldReviewerID),
+			zap.String("new_reviewer_id", review.NewReviewerID))
+	}
+	for _, review := range result.Unassigned {
+		w.logger.Warn("OrphanSweepWorker: ревьюер снят без замены, нет кандидатов",
+			zap.String("pr_id", review.PullRequestID),
+			zap.String("repository", review.Repository),
+			zap.String("old_reviewer_id", review.OldReviewerID))
+	}
+	if len(result.SkippedRecent) > 0 {
+		w.logger.Info("OrphanSweepWorker: недавние назначения пропущены до истечения MIN_ASSIGNMENT_AGE_HOURS",
+			zap.Int("skipped_recent_count", len(result.SkippedRecent)))
+	}
+	if swept := len(result.Reassigned) + len(result.Unassigned); swept >= w.limit {
+		w.logger.Warn("OrphanSweepWorker: достигнут лимит за запуск, остальные ревью будут обработаны позже",
+			zap.Int("limit", w.limit))
+	}
+}
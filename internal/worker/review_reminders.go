@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+	"github.com/untibullet/pr-manager-avito/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	// reminderWindow — не чаще какого срока напоминать об одном назначении
+	reminderWindow = 24 * time.Hour
+	// reminderBatchSize — сколько назначений отмечается за один запрос
+	reminderBatchSize = 100
+)
+
+// ReviewReminderStore — операции хранилища, которые использует воркер напоминаний.
+// Реализуется *repository.Repository.
+type ReviewReminderStore interface {
+	ClaimReviewReminders(ctx context.Context, assignedBefore, remindedBefore, now time.Time, limit int) ([]models.ReviewReminder, error)
+	GetPR(ctx context.Context, ref models.PRRef) (*models.PullRequest, error)
+}
+
+// ReviewReminderWorker периодически напоминает ревьюерам о давних неодобренных назначениях в открытых PR.
+// О каждом назначении напоминает не чаще раза в reminderWindow: событие review.reminder уходит
+// в исходящие вебхуки и Slack, а в лог пишется строка на каждое напоминание.
+type ReviewReminderWorker struct {
+	store    ReviewReminderStore
+	notifier service.Notifier
+	logger   *zap.Logger
+	interval time.Duration
+	// after — через сколько после назначения напоминать
+	after time.Duration
+	// now — источник текущего времени
+	now func() time.Time
+}
+
+// NewReviewReminderWorker создает воркер напоминаний
+func NewReviewReminderWorker(store ReviewReminderStore, notifier service.Notifier, logger *zap.Logger, interval, after time.Duration) *ReviewReminderWorker {
+	return &ReviewReminderWorker{
+		store:    store,
+		notifier: notifier,
+		logger:   logger,
+		interval: interval,
+		after:    after,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run проверяет назначения сразу при старте и далее с заданным интервалом до отмены ctx
+func (w *ReviewReminderWorker) Run(ctx context.Context) {
+	w.logger.Info("ReviewReminderWorker: запуск",
+		zap.Duration("interval", w.interval),
+		zap.Duration("after", w.after))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("ReviewReminderWorker: остановка")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick отмечает назначения, о которых пора напомнить, пачками и публикует по напоминанию на каждое
+func (w *ReviewReminderWorker) tick(ctx context.Context) {
+	now := w.now()
+	sent := 0
+	for ctx.Err() == nil {
+		reminders, err := w.store.ClaimReviewReminders(ctx, now.Add(-w.after), now.Add(-reminderWindow), now, reminderBatchSize)
+		if err != nil {
+			w.logger.Error("ReviewReminderWorker: ошибка выбора назначений для напоминания", zap.Error(err))
+			return
+		}
+
+		for _, reminder := range reminders {
+			w.remind(ctx, reminder)
+		}
+		sent += len(reminders)
+
+		if len(reminders) < reminderBatchSize {
+			break
+		}
+	}
+	if sent > 0 {
+		w.logger.Info("ReviewReminderWorker: напоминания отправлены", zap.Int("reminders_count", sent))
+	}
+}
+
+// remind публикует одно напоминание. Если PR не удалось получить, событие уходит с PR из одного ID.
+func (w *ReviewReminderWorker) remind(ctx context.Context, reminder models.ReviewReminder) {
+	log := w.logger.With(zap.String("pr_id", reminder.PullRequestID), zap.String("reviewer_id", reminder.ReviewerID))
+
+	pr, err := w.store.GetPR(ctx, models.PRRef{Repository: &reminder.Repository, ID: reminder.PullRequestID})
+	if err != nil {
+		log.Warn("ReviewReminderWorker: не удалось получить PR для напоминания", zap.Error(err))
+		pr = &models.PullRequest{PullRequestID: reminder.PullRequestID, Repository: reminder.Repository, Status: models.StatusOpen}
+	}
+
+	assignedAt := reminder.AssignedAt
+	log.Info("ReviewReminderWorker: напоминание о ревью", zap.Time("assigned_at", assignedAt))
+	w.notifier.Publish(models.WebhookEvent{
+		Type: models.EventReviewReminder,
+		Data: models.WebhookEventData{
+			PullRequest: pr,
+			ReviewerID:  reminder.ReviewerID,
+			AssignedAt:  &assignedAt,
+		},
+	})
+}
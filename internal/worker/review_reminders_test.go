@@ -0,0 +1,237 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/untibullet/pr-manager-avito/internal/models"
+)
+
+// assignment — строка pr_reviewers в памяти reminderStore
+type assignment struct {
+	reminder       models.ReviewReminder
+	approved       bool
+	merged         bool
+	lastRemindedAt *time.Time
+}
+
+// reminderStore повторяет условие и отметку ClaimReviewReminders в памяти
+type reminderStore struct {
+	mu          sync.Mutex
+	assignments []*assignment
+	claims      int
+	getPRErr    error
+}
+
+func (s *reminderStore) add(prID, reviewerID string, assignedAt time.Time) *assignment {
+	a := &assignment{reminder: models.ReviewReminder{
+		PullRequestID: prID, Repository: "backend", ReviewerID: reviewerID, AssignedAt: assignedAt,
+	}}
+	s.assignments = append(s.assignments, a)
+	return a
+}
+
+func (s *reminderStore) ClaimReviewReminders(_ context.Context, assignedBefore, remindedBefore, now time.Time, limit int) ([]models.ReviewReminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims++
+
+	due := make([]*assignment, 0)
+	for _, a := range s.assignments {
+		if a.merged || a.approved || !a.reminder.AssignedAt.Before(assignedBefore) {
+			continue
+		}
+		if a.lastRemindedAt != nil && !a.lastRemindedAt.Before(remindedBefore) {
+			continue
+		}
+		due = append(due, a)
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].reminder.AssignedAt.Before(due[j].reminder.AssignedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	reminders := make([]models.ReviewReminder, 0, len(due))
+	for _, a := range due {
+		claimed := now
+		a.lastRemindedAt = &claimed
+		reminders = append(reminders, a.reminder)
+	}
+	return reminders, nil
+}
+
+func (s *reminderStore) GetPR(_ context.Context, ref models.PRRef) (*models.PullRequest, error) {
+	if s.getPRErr != nil {
+		return nil, s.getPRErr
+	}
+	return &models.PullRequest{PullRequestID: ref.ID, PullRequestName: "Fix " + ref.ID, Repository: *ref.Repository, Status: models.StatusOpen}, nil
+}
+
+// recordingNotifier запоминает опубликованные события
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []models.WebhookEvent
+}
+
+func (n *recordingNotifier) Publish(event models.WebhookEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.events)
+}
+
+// clock — управляемый источник времени для поля now воркера
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+var reminderT0 = time.Date(2025, 11, 18, 9, 0, 0, 0, time.UTC)
+
+func newTestReminderWorker(store ReviewReminderStore, notifier *recordingNotifier, c *clock, after time.Duration) *ReviewReminderWorker {
+	w := NewReviewReminderWorker(store, notifier, zap.NewNop(), time.Hour, after)
+	w.now = c.now
+	return w
+}
+
+func TestReviewReminderCadence(t *testing.T) {
+	store := &reminderStore{}
+	store.add("pr-1", "u2", reminderT0)
+	notifier := &recordingNotifier{}
+	c := &clock{t: reminderT0}
+	w := newTestReminderWorker(store, notifier, c, 4*time.Hour)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		total   int
+	}{
+		{name: "right after assignment", total: 0},
+		{name: "before the delay", advance: 4*time.Hour - time.Minute, total: 0},
+		{name: "at the delay", advance: time.Minute, total: 0},
+		{name: "after the delay", advance: time.Second, total: 1},
+		{name: "an hour later", advance: time.Hour, total: 1},
+		{name: "just before the window ends", advance: reminderWindow - time.Hour - time.Second, total: 1},
+		{name: "at the window end", advance: time.Second, total: 1},
+		{name: "after the window", advance: time.Second, total: 2},
+		{name: "next window", advance: reminderWindow + time.Second, total: 3},
+	}
+	for _, step := range steps {
+		c.advance(step.advance)
+		w.tick(context.Background())
+		require.Equal(t, step.total, notifier.count(), "%s (%s after assignment)", step.name, c.t.Sub(reminderT0))
+	}
+
+	event := notifier.events[0]
+	assert.Equal(t, models.EventReviewReminder, event.Type)
+	assert.Equal(t, "u2", event.Data.ReviewerID)
+	require.NotNil(t, event.Data.AssignedAt)
+	assert.Equal(t, reminderT0, *event.Data.AssignedAt)
+	require.NotNil(t, event.Data.PullRequest)
+	assert.Equal(t, "Fix pr-1", event.Data.PullRequest.PullRequestName)
+}
+
+func TestReviewReminderOncePerWindow(t *testing.T) {
+	store := &reminderStore{}
+	due := store.add("pr-1", "u2", reminderT0)
+	approved := store.add("pr-2", "u3", reminderT0)
+	approved.approved = true
+	merged := store.add("pr-3", "u4", reminderT0)
+	merged.merged = true
+	notifier := &recordingNotifier{}
+	c := &clock{t: reminderT0.Add(5 * time.Hour)}
+
+	// Два экземпляра сервиса на общем хранилище и повторные проходы в том же окне
+	first := newTestReminderWorker(store, notifier, c, 4*time.Hour)
+	second := newTestReminderWorker(store, notifier, c, 4*time.Hour)
+	for range 3 {
+		first.tick(context.Background())
+		second.tick(context.Background())
+		c.advance(time.Hour)
+	}
+
+	require.Equal(t, 1, notifier.count(), "one reminder per assignment per window")
+	require.NotNil(t, due.lastRemindedAt)
+	assert.Equal(t, reminderT0.Add(5*time.Hour), *due.lastRemindedAt, "last_reminded_at is the claim time")
+	assert.Nil(t, approved.lastRemindedAt, "approved reviews are not reminded")
+	assert.Nil(t, merged.lastRemindedAt, "reviews of closed PRs are not reminded")
+}
+
+func TestReviewReminderClaimsInBatches(t *testing.T) {
+	store := &reminderStore{}
+	total := 2*reminderBatchSize + 17
+	for i := range total {
+		store.add(fmt.Sprintf("pr-%d", i), "u2", reminderT0.Add(time.Duration(i)*time.Second))
+	}
+	notifier := &recordingNotifier{}
+	c := &clock{t: reminderT0.Add(5 * time.Hour)}
+	w := newTestReminderWorker(store, notifier, c, 4*time.Hour)
+
+	w.tick(context.Background())
+	assert.Equal(t, total, notifier.count())
+	assert.Equal(t, 3, store.claims, "claims stop at the first short batch")
+
+	seen := make(map[string]int)
+	for _, event := range notifier.events {
+		seen[event.Data.PullRequest.PullRequestID]++
+	}
+	assert.Len(t, seen, total, "every assignment is reminded exactly once")
+
+	w.tick(context.Background())
+	assert.Equal(t, total, notifier.count())
+	assert.Equal(t, 4, store.claims)
+}
+
+func TestReviewReminderFallsBackWithoutPR(t *testing.T) {
+	store := &reminderStore{getPRErr: errors.New("connection refused")}
+	store.add("pr-1", "u2", reminderT0)
+	notifier := &recordingNotifier{}
+	c := &clock{t: reminderT0.Add(5 * time.Hour)}
+
+	newTestReminderWorker(store, notifier, c, 4*time.Hour).tick(context.Background())
+
+	require.Equal(t, 1, notifier.count(), "the reminder is sent even without PR details")
+	pr := notifier.events[0].Data.PullRequest
+	require.NotNil(t, pr)
+	assert.Equal(t, "pr-1", pr.PullRequestID)
+	assert.Equal(t, "backend", pr.Repository)
+	assert.Equal(t, models.StatusOpen, pr.Status)
+}
+
+func TestReviewReminderRunTicksAtStartAndStops(t *testing.T) {
+	store := &reminderStore{}
+	store.add("pr-1", "u2", reminderT0)
+	notifier := &recordingNotifier{}
+	c := &clock{t: reminderT0.Add(5 * time.Hour)}
+	w := newTestReminderWorker(store, notifier, c, 4*time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return notifier.count() == 1 }, time.Second, 10*time.Millisecond,
+		"the first check runs at start, not after the interval")
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Пользователи, чьи внешние ID совпадают после нормализации (trim + lower)
+CREATE VIEW user_external_id_collisions AS
+SELECT lower(btrim(external_id)) AS normalized_id,
+       array_agg(external_id ORDER BY id) AS external_ids,
+       COUNT(*) AS users_count
+FROM users
+GROUP BY lower(btrim(external_id))
+HAVING COUNT(*) > 1;
+
+-- Уникальный нормализованный индекс создается только при отсутствии коллизий.
+-- Коллизии не сливаются автоматически: они выводятся в лог миграции и доступны
+-- через представление user_external_id_collisions. После ручного разрешения
+-- индекс нужно пересоздать как UNIQUE, иначе режим ID_NORMALIZATION=fold не заработает.
+DO $$
+DECLARE
+    collisions TEXT;
+BEGIN
+    SELECT string_agg(normalized_id || ' <- ' || array_to_string(external_ids, ', '), '; ')
+    INTO collisions
+    FROM user_external_id_collisions;
+
+    IF collisions IS NULL THEN
+        CREATE UNIQUE INDEX idx_users_external_id_folded ON users (lower(btrim(external_id)));
+    ELSE
+        RAISE WARNING 'external_id collisions after normalization, unique folded index not created: %', collisions;
+        CREATE INDEX idx_users_external_id_folded ON users (lower(btrim(external_id)));
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_external_id_folded;
+DROP VIEW IF EXISTS user_external_id_collisions;
+-- +goose StatementEnd
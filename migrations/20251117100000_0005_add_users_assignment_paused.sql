@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN assignment_paused BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN assignment_paused_until TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS assignment_paused_until,
+    DROP COLUMN IF EXISTS assignment_paused;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE reviewer_load_history (
+    snapshot_date DATE NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    open_reviews INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (snapshot_date, user_id)
+);
+
+CREATE INDEX idx_reviewer_load_history_user_date ON reviewer_load_history(user_id, snapshot_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reviewer_load_history;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    ADD COLUMN approved BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN approved_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS approved;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Список открытых PR без ревьюеров сортируется по дате создания;
+-- anti-join по pr_reviewers использует idx_pr_reviewers_pr_id
+CREATE INDEX idx_pull_requests_open_created_at ON pull_requests (created_at, id) WHERE status = 'OPEN';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_open_created_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сохраненные ответы мутирующих запросов с заголовком Idempotency-Key.
+-- Пока запрос выполняется, status_code и response_body пустые (ключ зарезервирован).
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT NOT NULL,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (idempotency_key, method, path)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Учетные записи пользователей во внешних системах (логин GitHub, ID пользователя GitLab)
+CREATE TABLE user_external_accounts (
+    provider TEXT NOT NULL,
+    account_id TEXT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, account_id)
+);
+
+CREATE INDEX idx_user_external_accounts_user_id ON user_external_accounts (user_id);
+
+-- Обработанные доставки входящих вебхуков: повтор доставки с тем же ID не применяется второй раз
+CREATE TABLE webhook_deliveries (
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (provider, delivery_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_expires_at ON webhook_deliveries (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_expires_at;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_user_external_accounts_user_id;
+DROP TABLE IF EXISTS user_external_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Подписки внешних систем на события сервиса (исходящие вебхуки)
+CREATE TABLE webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT webhooks_event_types_check CHECK (cardinality(event_types) > 0)
+);
+
+-- История попыток доставки событий подписчикам
+CREATE TABLE webhook_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_attempts_webhook_created ON webhook_attempts (webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_attempts_created_at ON webhook_attempts (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_attempts_created_at;
+DROP INDEX IF EXISTS idx_webhook_attempts_webhook_created;
+DROP TABLE IF EXISTS webhook_attempts;
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ID участника Slack для упоминания в уведомлениях о назначении ревью
+ALTER TABLE users
+    ADD COLUMN slack_user_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS slack_user_id;
+-- +goose StatementEnd
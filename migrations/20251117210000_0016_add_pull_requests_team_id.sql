@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Команда, из которой назначаются ревьюеры PR; переназначения берут кандидатов из нее же
+ALTER TABLE pull_requests
+    ADD COLUMN team_id BIGINT REFERENCES teams(id) ON DELETE SET NULL;
+
+-- Для существующих PR берется команда автора, которую выбрал бы CreatePR без team_name
+UPDATE pull_requests pr
+SET team_id = (SELECT MIN(tu.team_id) FROM team_users tu WHERE tu.user_id = pr.author_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS team_id;
+-- +goose StatementEnd
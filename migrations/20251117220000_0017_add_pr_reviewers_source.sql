@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Откуда назначен ревьюер: из команды PR (team) или из резервной команды (fallback)
+ALTER TABLE pr_reviewers
+    ADD COLUMN source TEXT NOT NULL DEFAULT 'team'
+        CHECK (source IN ('team', 'fallback'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS source;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Необязательные сведения о PR из внешней системы
+ALTER TABLE pull_requests
+    ADD COLUMN description TEXT,
+    ADD COLUMN source_branch TEXT,
+    ADD COLUMN target_branch TEXT,
+    ADD COLUMN url TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS url,
+    DROP COLUMN IF EXISTS target_branch,
+    DROP COLUMN IF EXISTS source_branch,
+    DROP COLUMN IF EXISTS description;
+-- +goose StatementEnd
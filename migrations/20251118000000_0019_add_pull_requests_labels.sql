@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Метки PR (hotfix, feature, ...): нормализованы к нижнему регистру, без повторов
+ALTER TABLE pull_requests
+    ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+
+-- GIN-индекс для фильтра по метке (labels @> ARRAY[...])
+CREATE INDEX idx_pull_requests_labels ON pull_requests USING GIN (labels);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_labels;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS labels;
+-- +goose StatementEnd
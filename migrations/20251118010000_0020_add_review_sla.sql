@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SLA команды: за сколько часов ревьюер должен взяться за PR (NULL — без SLA)
+ALTER TABLE team_settings
+    ADD COLUMN review_sla_hours INTEGER CHECK (review_sla_hours > 0);
+
+-- Срок ревью по SLA команды PR, вычисляется при назначении ревьюера
+ALTER TABLE pr_reviewers
+    ADD COLUMN review_due_at TIMESTAMP;
+
+-- Индекс для выборки просроченных назначений
+CREATE INDEX idx_pr_reviewers_review_due_at ON pr_reviewers(review_due_at)
+    WHERE review_due_at IS NOT NULL AND NOT approved;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pr_reviewers_review_due_at;
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS review_due_at;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS review_sla_hours;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Когда ревьюеру в последний раз напоминали о PR; защищает от повторных напоминаний после перезапуска
+ALTER TABLE pr_reviewers
+    ADD COLUMN last_reminded_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS last_reminded_at;
+-- +goose StatementEnd
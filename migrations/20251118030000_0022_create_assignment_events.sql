@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал изменений ревьюеров PR: кто, когда и почему назначил или снял ревьюера
+CREATE TABLE assignment_events (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id BIGINT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    actor TEXT NOT NULL,
+    old_reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    new_reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('AUTO_INITIAL', 'AUTO_REASSIGN', 'MANUAL', 'DEACTIVATION')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_assignment_events_pr_id ON assignment_events(pr_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_assignment_events_pr_id;
+DROP TABLE IF EXISTS assignment_events;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Правила назначения команды (NULL — глобальные значения из конфигурации)
+ALTER TABLE team_settings
+    ADD COLUMN reviewers_per_pr INTEGER CHECK (reviewers_per_pr > 0),
+    ADD COLUMN assignment_strategy TEXT CHECK (assignment_strategy IN ('random', 'least_loaded', 'round_robin'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS assignment_strategy,
+    DROP COLUMN IF EXISTS reviewers_per_pr;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сколько открытых ревью можно назначить пользователю одновременно (NULL — без ограничения)
+ALTER TABLE users
+    ADD COLUMN max_open_reviews INTEGER CHECK (max_open_reviews > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS max_open_reviews;
+-- +goose StatementEnd
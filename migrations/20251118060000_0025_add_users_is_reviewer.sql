@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Участвует ли пользователь в автоматическом назначении ревьюеров (false — только для наблюдения)
+ALTER TABLE users
+    ADD COLUMN is_reviewer BOOLEAN NOT NULL DEFAULT true;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_reviewer;
+-- +goose StatementEnd
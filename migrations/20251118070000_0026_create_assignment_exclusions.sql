@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Запреты назначения: reviewer_user_id не назначается ревьюером PR автора author_user_id.
+-- Запрет направленный: обратная пара задается отдельной записью.
+CREATE TABLE assignment_exclusions (
+    author_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewer_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (author_user_id, reviewer_user_id),
+    CONSTRAINT assignment_exclusions_not_self_check CHECK (author_user_id <> reviewer_user_id)
+);
+
+CREATE INDEX idx_assignment_exclusions_reviewer ON assignment_exclusions(reviewer_user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS assignment_exclusions;
+-- +goose StatementEnd
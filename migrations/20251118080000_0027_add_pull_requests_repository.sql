@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Репозиторий (проект) PR: внешние ID PR уникальны только в пределах репозитория.
+-- Существующие PR получают пустой репозиторий, как PR, созданные без него.
+ALTER TABLE pull_requests
+    ADD COLUMN repository VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE pull_requests
+    DROP CONSTRAINT pull_requests_external_id_key;
+
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_repository_external_id_key UNIQUE (repository, external_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Откат невозможен, если один внешний ID уже используется в нескольких репозиториях
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_repository_external_id_key;
+
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_external_id_key UNIQUE (external_id);
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS repository;
+-- +goose StatementEnd
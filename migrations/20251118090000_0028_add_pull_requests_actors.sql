@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Кто создал, слил и закрыл PR: инициатор запроса (X-Actor-Id, actor_id) или api / webhook:* / system.
+-- У PR, измененных до появления колонок, значения не заполнены.
+ALTER TABLE pull_requests
+    ADD COLUMN created_by VARCHAR(255),
+    ADD COLUMN merged_by VARCHAR(255),
+    ADD COLUMN closed_by VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS closed_by,
+    DROP COLUMN IF EXISTS merged_by,
+    DROP COLUMN IF EXISTS created_by;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Момент деактивации пользователя: по нему очистка находит ревью, зависшие на давно неактивных пользователях.
+-- Активность меняется многими запросами (setIsActive, team/add, синхронизация активности и т. п.),
+-- поэтому колонку поддерживает триггер. Для уже неактивных пользователей берется время последнего изменения.
+ALTER TABLE users
+    ADD COLUMN deactivated_at TIMESTAMP;
+
+UPDATE users SET deactivated_at = updated_at WHERE NOT is_active;
+
+CREATE FUNCTION users_set_deactivated_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.is_active THEN
+        NEW.deactivated_at := NULL;
+    ELSIF TG_OP = 'INSERT' OR OLD.is_active THEN
+        NEW.deactivated_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_deactivated_at
+    BEFORE INSERT OR UPDATE OF is_active ON users
+    FOR EACH ROW EXECUTE FUNCTION users_set_deactivated_at();
+
+-- Замена ревьюера фоновой очисткой записывается в журнал отдельной причиной
+ALTER TABLE assignment_events
+    DROP CONSTRAINT assignment_events_reason_check;
+
+ALTER TABLE assignment_events
+    ADD CONSTRAINT assignment_events_reason_check
+    CHECK (reason IN ('AUTO_INITIAL', 'AUTO_REASSIGN', 'MANUAL', 'DEACTIVATION', 'DEACTIVATION_SWEEP'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE assignment_events SET reason = 'DEACTIVATION' WHERE reason = 'DEACTIVATION_SWEEP';
+
+ALTER TABLE assignment_events
+    DROP CONSTRAINT IF EXISTS assignment_events_reason_check;
+
+ALTER TABLE assignment_events
+    ADD CONSTRAINT assignment_events_reason_check
+    CHECK (reason IN ('AUTO_INITIAL', 'AUTO_REASSIGN', 'MANUAL', 'DEACTIVATION'));
+
+DROP TRIGGER IF EXISTS users_deactivated_at ON users;
+DROP FUNCTION IF EXISTS users_set_deactivated_at();
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deactivated_at;
+-- +goose StatementEnd
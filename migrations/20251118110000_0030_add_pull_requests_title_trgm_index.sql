@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Поиск PR по подстроке названия (ILIKE '%q%') без последовательного сканирования таблицы.
+-- Триграммный GIN-индекс используется для запросов от трех символов; pg_trgm — доверенное расширение (PostgreSQL 13+).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_pull_requests_title_trgm ON pull_requests USING gin (title gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_title_trgm;
+-- +goose StatementEnd
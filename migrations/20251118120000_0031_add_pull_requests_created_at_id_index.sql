@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keyset-пагинация списков PR: условие (created_at, id) < ($1, $2) и сортировка
+-- по (created_at, id) читают индекс по диапазону вместо пропуска OFFSET строк
+CREATE INDEX idx_pull_requests_created_at_id ON pull_requests (created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_created_at_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Версия PR для оптимистической блокировки: растет при каждом изменении PR и его ревьюеров.
+-- Изменения с ожидаемой версией проверяют ее в WHERE своего UPDATE.
+ALTER TABLE pull_requests
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Отчет о справедливости назначений считает события по ревьюеру за окно времени
+CREATE INDEX idx_assignment_events_new_reviewer_created_at ON assignment_events (new_reviewer_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_assignment_events_new_reviewer_created_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Роль участника в команде: lead — технический лидер, member — остальные участники
+ALTER TABLE team_users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'lead'));
+
+-- Назначать ли лидера команды ревьюером на каждый новый PR; NULL — нет
+ALTER TABLE team_settings
+    ADD COLUMN always_include_lead BOOLEAN;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS always_include_lead;
+
+ALTER TABLE team_users
+    DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
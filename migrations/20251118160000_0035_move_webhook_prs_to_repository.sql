@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Вебхуки GitHub и GitLab создают PR в репозитории из события. Раньше они попадали в репозиторий
+-- по умолчанию, поэтому такие PR (ID вида "owner/repo#номер" и "group/project!iid") переносятся
+-- в репозиторий из своего ID, если там еще нет PR с тем же ID.
+UPDATE pull_requests pr
+SET repository = substring(pr.external_id FROM '^(.+)[#!][0-9]+$')
+WHERE pr.repository = ''
+  AND pr.external_id ~ '^[^#!]+/[^#!]+[#!][0-9]+$'
+  AND NOT EXISTS (
+      SELECT 1 FROM pull_requests other
+      WHERE other.repository = substring(pr.external_id FROM '^(.+)[#!][0-9]+$')
+        AND other.external_id = pr.external_id
+  );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- PR, ID которых начинается с имени их репозитория, возвращаются в репозиторий по умолчанию
+UPDATE pull_requests pr
+SET repository = ''
+WHERE pr.repository <> ''
+  AND left(pr.external_id, length(pr.repository) + 1) IN (pr.repository || '#', pr.repository || '!')
+  AND NOT EXISTS (
+      SELECT 1 FROM pull_requests other
+      WHERE other.repository = '' AND other.external_id = pr.external_id
+  );
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+// Package migrations встраивает SQL-миграции goose в бинарник, чтобы сервис мог проверить,
+// что схема БД не отстает от кода. В Docker Compose миграции применяет отдельный контейнер migrator,
+// вручную — команда migrate того же бинарника.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// FS возвращает встроенные файлы миграций для goose
+func FS() fs.FS {
+	return files
+}
+
+// LatestVersion возвращает версию goose самой новой миграции (числовой префикс имени файла)
+func LatestVersion() (int64, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var latest int64
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration version in %q: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations embedded")
+	}
+	return latest, nil
+}
@@ -0,0 +1,43 @@
+import http from 'k6/http';
+import { check, sleep } from 'k6';
+
+// --- Конфигурация теста ---
+// Сервис должен быть запущен с RATE_LIMIT_RPS=1, RATE_LIMIT_BURST=5
+// и RATE_LIMIT_API_KEYS=rate-limit-1,rate-limit-2.
+// У каждого VU свой известный X-API-Key, корзина пополняется паузой между итерациями
+export const options = {
+  vus: 2,
+  iterations: 10,
+};
+
+const burst = 5;
+const requests = 10;
+
+// --- Основной сценарий теста ---
+export default function () {
+  const baseUrl = 'http://localhost:8081';
+  const headers = { 'X-API-Key': `rate-limit-${__VU}` };
+
+  // Пачка параллельных запросов больше burst: токен пополняется раз в секунду, поэтому пройти должны ровно burst
+  const batch = [];
+  for (let i = 0; i < requests; i++) {
+    batch.push(['GET', `${baseUrl}/team/get?team_name=rate-limit-probe`, null, { headers }]);
+  }
+  const responses = http.batch(batch);
+  const limited = responses.filter((r) => r.status === 429);
+
+  check(limited, {
+    'requests above burst got 429': (l) => l.length === requests - burst,
+    'all 429 have code RATE_LIMITED': (l) => l.every((r) => r.json('error.code') === 'RATE_LIMITED'),
+    'all 429 have Retry-After': (l) => l.every((r) => r.headers['Retry-After'] === '1'),
+  });
+
+  // Проверки здоровья не ограничиваются даже при исчерпанной корзине
+  const healthRes = http.get(`${baseUrl}/health`, { headers });
+  check(healthRes, {
+    'health is not rate limited': (r) => r.status !== 429,
+  });
+
+  // Ждем, пока корзина ключа снова заполнится до burst
+  sleep(burst + 1);
+}
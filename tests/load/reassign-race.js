@@ -0,0 +1,64 @@
+import http from 'k6/http';
+import { check } from 'k6';
+
+// --- Конфигурация теста ---
+// Каждая итерация создает свою команду и PR и переназначает одного ревьювера двумя параллельными запросами
+export const options = {
+  vus: 5,
+  iterations: 50,
+};
+
+// --- Основной сценарий теста ---
+export default function () {
+  // Объекты ревьюверов в assigned_reviewers отдаются только под /api/v1
+  const baseUrl = 'http://localhost:8081/api/v1';
+  const headers = { 'Content-Type': 'application/json' };
+
+  // --- Генерация уникальных данных внутри цикла ---
+  const suffix = `${__VU}-${__ITER}-${Date.now()}`;
+  const teamName = `race-team-${suffix}`;
+  const prId = `race-pr-${suffix}`;
+  const authorId = `race-u-${suffix}-1`;
+
+  // Команда из автора и 5 кандидатов, чтобы замены хватило обоим запросам
+  const members = [];
+  for (let i = 1; i <= 6; i++) {
+    members.push({ user_id: `race-u-${suffix}-${i}`, username: `User ${i}`, is_active: true });
+  }
+  const teamRes = http.post(`${baseUrl}/team/add`, JSON.stringify({ team_name: teamName, members }), { headers });
+  check(teamRes, {
+    'team created successfully': (r) => r.status === 201,
+  });
+
+  const prRes = http.post(`${baseUrl}/pullRequest/create`, JSON.stringify({
+    pull_request_id: prId,
+    pull_request_name: 'Race check',
+    author_id: authorId,
+  }), { headers });
+  check(prRes, {
+    'pr created with 2 reviewers': (r) => r.status === 201 && r.json('pr.assigned_reviewers').length === 2,
+  });
+  if (prRes.status !== 201) {
+    return;
+  }
+
+  // Два параллельных переназначения одного и того же ревьювера
+  const oldReviewer = prRes.json('pr.assigned_reviewers.0.user_id');
+  const reassignPayload = JSON.stringify({ pull_request_id: prId, old_user_id: oldReviewer });
+  const responses = http.batch([
+    ['POST', `${baseUrl}/pullRequest/reassign`, reassignPayload, { headers }],
+    ['POST', `${baseUrl}/pullRequest/reassign`, reassignPayload, { headers }],
+  ]);
+  const statuses = responses.map((r) => r.status).sort();
+  check(statuses, {
+    'exactly one reassign succeeded, the other got NOT_ASSIGNED': (s) => s[0] === 200 && s[1] === 409,
+  });
+
+  // Итоговый состав: ровно 2 разных ревьювера, снятого среди них нет
+  const getRes = http.get(`${baseUrl}/pullRequest/get?pull_request_id=${prId}`);
+  const reviewers = getRes.json('pr.assigned_reviewers').map((r) => r.user_id);
+  check(reviewers, {
+    'final reviewers are 2 distinct users': (ids) => ids.length === 2 && new Set(ids).size === 2,
+    'removed reviewer is not assigned': (ids) => !ids.includes(oldReviewer),
+  });
+}
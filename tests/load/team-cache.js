@@ -0,0 +1,47 @@
+import http from 'k6/http';
+import { check } from 'k6';
+import { Trend } from 'k6/metrics';
+
+// --- Конфигурация теста ---
+// Сравнение чтения команды с кэшем и без: прогоните сценарий дважды — на сервисе
+// с CACHE_TTL=30s и с CACHE_TTL=0 — и сравните team_get_duration
+export const options = {
+  vus: 20,
+  duration: '30s',
+  thresholds: {
+    checks: ['rate==1'],
+  },
+};
+
+const baseUrl = 'http://localhost:8081';
+const teamName = 'cache-bench';
+const members = 50;
+
+const teamGetDuration = new Trend('team_get_duration', true);
+
+// --- Подготовка: одна команда, которую читают все VU ---
+export function setup() {
+  const payload = {
+    team_name: teamName,
+    members: Array.from({ length: members }, (_, i) => ({
+      user_id: `cb${i}`,
+      username: `Bench ${i}`,
+      is_active: true,
+    })),
+  };
+  const res = http.post(`${baseUrl}/team/add`, JSON.stringify(payload), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  check(res, { 'team created': (r) => r.status === 201 });
+}
+
+// --- Основной сценарий теста ---
+export default function () {
+  const res = http.get(`${baseUrl}/team/get?team_name=${teamName}`);
+  teamGetDuration.add(res.timings.duration);
+
+  check(res, {
+    'team fetched successfully': (r) => r.status === 200,
+    'all members returned': (r) => r.json('members_total') === members,
+  });
+}